@@ -0,0 +1,64 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package main
+
+import (
+	"flag"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// namespace is the namespace of the resources to operate on, set from the command line.
+var namespace string
+
+// newClient returns a client to the Kubernetes cluster of the current context.
+func newClient() (k8s.Client, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		return nil, err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
+	if err != nil {
+		return nil, err
+	}
+	return k8s.WrapClient(c), nil
+}
+
+// newClientset returns a Kubernetes clientset, for the operations not supported by the controller-runtime client
+// such as retrieving logs.
+func newClientset() (*kubernetes.Clientset, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(cfg)
+}
+
+// resourceKey returns the namespaced name of the resource with the given name, in the namespace set from the
+// command line or in the namespace of the current context.
+func resourceKey(name string) (types.NamespacedName, error) {
+	if namespace != "" {
+		return types.NamespacedName{Namespace: namespace, Name: name}, nil
+	}
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig := flag.CommandLine.Lookup("kubeconfig"); kubeconfig != nil {
+		loadingRules.ExplicitPath = kubeconfig.Value.String()
+	}
+	ns, _, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).Namespace()
+	if err != nil {
+		return types.NamespacedName{}, err
+	}
+	return types.NamespacedName{Namespace: ns, Name: name}, nil
+}
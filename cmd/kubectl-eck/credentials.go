@@ -0,0 +1,129 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates/http"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/name"
+	esname "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/name"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var passwordCmd = &cobra.Command{
+	Use:   "password NAME",
+	Short: "Print the password of the elastic user of an Elasticsearch cluster",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient()
+		if err != nil {
+			return err
+		}
+		key, err := resourceKey(args[0])
+		if err != nil {
+			return err
+		}
+		password, err := elasticPassword(c, key)
+		if err != nil {
+			return err
+		}
+		fmt.Println(password)
+		return nil
+	},
+}
+
+// elasticPassword returns the password of the elastic user of the given cluster.
+func elasticPassword(c k8s.Client, es types.NamespacedName) (string, error) {
+	var secret corev1.Secret
+	if err := c.Get(types.NamespacedName{Namespace: es.Namespace, Name: esname.ElasticUserSecret(es.Name)}, &secret); err != nil {
+		return "", err
+	}
+	password, exists := secret.Data[user.ExternalUserName]
+	if !exists {
+		return "", fmt.Errorf("no password found for user %s in secret %s", user.ExternalUserName, secret.Name)
+	}
+	return string(password), nil
+}
+
+var decodeCA bool
+
+var caCmd = &cobra.Command{
+	Use:   "ca KIND NAME",
+	Short: "Print the CA certificates to trust to reach the HTTP endpoint of a resource",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		k, err := parseKind(args[0])
+		if err != nil {
+			return err
+		}
+		c, err := newClient()
+		if err != nil {
+			return err
+		}
+		key, err := resourceKey(args[1])
+		if err != nil {
+			return err
+		}
+		ca, err := publicCA(c, k.namer, key)
+		if err != nil {
+			return err
+		}
+		if !decodeCA {
+			fmt.Print(string(ca))
+			return nil
+		}
+		return printCertificates(os.Stdout, ca)
+	},
+}
+
+// publicCA returns the PEM-encoded CA certificates to trust to reach the HTTP endpoint of the given resource.
+func publicCA(c k8s.Client, namer name.Namer, key types.NamespacedName) ([]byte, error) {
+	var secret corev1.Secret
+	if err := c.Get(http.PublicCertsSecretRef(namer, key), &secret); err != nil {
+		return nil, err
+	}
+	ca, exists := secret.Data[certificates.CAFileName]
+	if !exists {
+		// custom certificates may not come with their CA, trust the certificate chain in that case
+		ca = secret.Data[certificates.CertFileName]
+	}
+	return ca, nil
+}
+
+func init() {
+	caCmd.Flags().BoolVar(&decodeCA, "decode", false, "Print the details of the CA certificates instead of their PEM encoding")
+}
+
+// printCertificates writes the details of the given PEM-encoded certificates.
+func printCertificates(out io.Writer, pemCerts []byte) error {
+	certs, err := certificates.ParsePEMCerts(pemCerts)
+	if err != nil {
+		return err
+	}
+	for i, cert := range certs {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "Subject:    %s\n", cert.Subject)
+		fmt.Fprintf(out, "Issuer:     %s\n", cert.Issuer)
+		fmt.Fprintf(out, "Serial:     %s\n", cert.SerialNumber)
+		fmt.Fprintf(out, "Not before: %s\n", cert.NotBefore.UTC())
+		fmt.Fprintf(out, "Not after:  %s\n", cert.NotAfter.UTC())
+		fmt.Fprintf(out, "Is CA:      %t\n", cert.IsCA)
+		if len(cert.DNSNames) > 0 {
+			fmt.Fprintf(out, "DNS names:  %s\n", strings.Join(cert.DNSNames, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,359 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	esname "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/name"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/services"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
+	"github.com/elastic/cloud-on-k8s/pkg/dev/portforward"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// redactedValue replaces the values of the secrets included in a diagnostic bundle.
+	redactedValue = "REDACTED"
+	// operatorLabelSelector selects the operator pods.
+	operatorLabelSelector = "control-plane=elastic-operator"
+	// diagnosticsTimeout bounds the time spent on each Elasticsearch API call.
+	diagnosticsTimeout = 1 * time.Minute
+)
+
+// elasticsearchDiagnostics are the Elasticsearch APIs included in a diagnostic bundle, by file name.
+var elasticsearchDiagnostics = []struct {
+	file string
+	path string
+}{
+	{file: "cluster_health.json", path: "/_cluster/health"},
+	{file: "cluster_state.json", path: "/_cluster/state"},
+	{file: "nodes_stats.json", path: "/_nodes/stats"},
+	{file: "cat_shards.txt", path: "/_cat/shards?v"},
+}
+
+var (
+	diagnosticsOutput            string
+	diagnosticsOperatorNamespace string
+	diagnosticsNoRedact          bool
+)
+
+var diagnosticsCmd = &cobra.Command{
+	Use:   "diagnostics [NAME]",
+	Short: "Collect a diagnostic bundle for an Elasticsearch cluster, or for all the clusters of a namespace",
+	Long: `Collect a diagnostic bundle for an Elasticsearch cluster, or for all the clusters of a namespace if no name is given.
+
+The bundle is a single tarball including the Elasticsearch resources and APIs output, the pods, StatefulSets,
+secrets and events of the namespace, and the operator logs. Secret values are redacted unless --no-redact is set.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient()
+		if err != nil {
+			return err
+		}
+		var esName string
+		if len(args) == 1 {
+			esName = args[0]
+		}
+		key, err := resourceKey(esName)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		root := diagnosticsRoot(key, now)
+		output := diagnosticsOutput
+		if output == "" {
+			output = root + ".tar.gz"
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		gz := gzip.NewWriter(f)
+		b := newBundle(tar.NewWriter(gz), root, now)
+
+		collectDiagnostics(c, b, key, !diagnosticsNoRedact)
+		clientset, err := newClientset()
+		if err != nil {
+			b.addError("operator logs", err)
+		} else {
+			collectOperatorLogs(clientset.CoreV1(), b, diagnosticsOperatorNamespace)
+		}
+
+		if err := b.close(); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+		fmt.Printf("Diagnostic bundle written to %s\n", output)
+		if len(b.errors) > 0 {
+			fmt.Printf("%d items could not be collected, see %s\n", len(b.errors), path.Join(root, "errors.txt"))
+		}
+		return nil
+	},
+}
+
+func init() {
+	diagnosticsCmd.Flags().StringVarP(&diagnosticsOutput, "output", "o", "", "Path of the tarball to write, defaults to a name derived from the namespace, the cluster name and the current time")
+	diagnosticsCmd.Flags().StringVar(&diagnosticsOperatorNamespace, "operator-namespace", "elastic-system", "Namespace of the operator, to retrieve its logs")
+	diagnosticsCmd.Flags().BoolVar(&diagnosticsNoRedact, "no-redact", false, "Include secret values in the bundle instead of redacting them")
+}
+
+// diagnosticsRoot returns the name of the root directory of the bundle for the given cluster,
+// or the given namespace if the cluster name is empty.
+func diagnosticsRoot(key types.NamespacedName, now time.Time) string {
+	parts := []string{"eck-diagnostics", key.Namespace}
+	if key.Name != "" {
+		parts = append(parts, key.Name)
+	}
+	parts = append(parts, now.UTC().Format("20060102-150405"))
+	return strings.Join(parts, "-")
+}
+
+// bundle writes diagnostic files to a tarball, under a root directory.
+// Items that could not be collected are recorded so that the rest of the bundle can still be collected.
+type bundle struct {
+	tw     *tar.Writer
+	root   string
+	now    time.Time
+	errors []string
+}
+
+func newBundle(tw *tar.Writer, root string, now time.Time) *bundle {
+	return &bundle{tw: tw, root: root, now: now}
+}
+
+// add writes the given content to the given file of the bundle.
+func (b *bundle) add(file string, content []byte) error {
+	if err := b.tw.WriteHeader(&tar.Header{
+		Name:    path.Join(b.root, file),
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: b.now,
+	}); err != nil {
+		return err
+	}
+	_, err := b.tw.Write(content)
+	return err
+}
+
+// addJSON writes the JSON encoding of the given object to the given file of the bundle.
+func (b *bundle) addJSON(file string, obj interface{}) {
+	content, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		b.addError(file, err)
+		return
+	}
+	if err := b.add(file, content); err != nil {
+		b.addError(file, err)
+	}
+}
+
+// addError records that the given item could not be collected.
+func (b *bundle) addError(item string, err error) {
+	msg := fmt.Sprintf("%s: %s", item, err)
+	fmt.Fprintln(os.Stderr, msg)
+	b.errors = append(b.errors, msg)
+}
+
+// close writes the errors encountered, if any, and closes the tarball.
+func (b *bundle) close() error {
+	if len(b.errors) > 0 {
+		if err := b.add("errors.txt", []byte(strings.Join(b.errors, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+	return b.tw.Close()
+}
+
+// collectDiagnostics adds the Kubernetes resources of the given cluster to the bundle, along with the output of the
+// Elasticsearch APIs. If the cluster name is empty, all the clusters of the namespace are included.
+func collectDiagnostics(c k8s.Client, b *bundle, key types.NamespacedName, redact bool) {
+	var clusters []v1alpha1.Elasticsearch
+	selector := labels.Everything()
+	if key.Name != "" {
+		var es v1alpha1.Elasticsearch
+		if err := c.Get(key, &es); err != nil {
+			b.addError("elasticsearch "+key.String(), err)
+			return
+		}
+		clusters = append(clusters, es)
+		selector = label.NewLabelSelectorForElasticsearch(es)
+	} else {
+		var list v1alpha1.ElasticsearchList
+		if err := c.List(&client.ListOptions{Namespace: key.Namespace}, &list); err != nil {
+			b.addError("elasticsearch", err)
+			return
+		}
+		clusters = list.Items
+	}
+
+	listOpts := &client.ListOptions{Namespace: key.Namespace, LabelSelector: selector}
+	var pods corev1.PodList
+	if err := c.List(listOpts, &pods); err != nil {
+		b.addError("pods", err)
+	} else {
+		b.addJSON("pods.json", pods)
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := c.List(listOpts, &statefulSets); err != nil {
+		b.addError("statefulsets", err)
+	} else {
+		b.addJSON("statefulsets.json", statefulSets)
+	}
+	var secrets corev1.SecretList
+	if err := c.List(listOpts, &secrets); err != nil {
+		b.addError("secrets", err)
+	} else {
+		if redact {
+			redactSecrets(secrets.Items)
+		}
+		b.addJSON("secrets.json", secrets)
+	}
+	// events are not labeled, include the ones of the whole namespace
+	var events corev1.EventList
+	if err := c.List(&client.ListOptions{Namespace: key.Namespace}, &events); err != nil {
+		b.addError("events", err)
+	} else {
+		b.addJSON("events.json", events)
+	}
+
+	dialer := portforward.NewForwardingDialer()
+	for _, es := range clusters {
+		dir := path.Join("elasticsearch", es.Name)
+		b.addJSON(path.Join(dir, "elasticsearch.json"), es)
+		esClient, err := newElasticsearchClient(c, es, dialer)
+		if err != nil {
+			b.addError(dir, err)
+			continue
+		}
+		collectElasticsearchAPIs(esClient, b, dir)
+		esClient.Close()
+	}
+}
+
+// redactSecrets replaces the values of the given secrets, keeping their keys and metadata.
+func redactSecrets(secrets []corev1.Secret) {
+	for i := range secrets {
+		for k := range secrets[i].Data {
+			secrets[i].Data[k] = []byte(redactedValue)
+		}
+		for k := range secrets[i].StringData {
+			secrets[i].StringData[k] = redactedValue
+		}
+		// the last applied configuration holds the values of secrets created with kubectl apply
+		delete(secrets[i].Annotations, corev1.LastAppliedConfigAnnotation)
+	}
+}
+
+// newElasticsearchClient returns a client to the given cluster, authenticated as the elastic user
+// and forwarding connections from outside the Kubernetes cluster.
+func newElasticsearchClient(c k8s.Client, es v1alpha1.Elasticsearch, dialer *portforward.ForwardingDialer) (esclient.Client, error) {
+	key := k8s.ExtractNamespacedName(&es)
+	password, err := elasticPassword(c, key)
+	if err != nil {
+		return nil, err
+	}
+	var caCerts []*x509.Certificate
+	if es.Spec.HTTP.TLS.Enabled() {
+		ca, err := publicCA(c, esname.ESNamer, key)
+		if err != nil {
+			return nil, err
+		}
+		caCerts, err = certificates.ParsePEMCerts(ca)
+		if err != nil {
+			return nil, err
+		}
+	}
+	v, err := version.Parse(es.Spec.Version)
+	if err != nil {
+		return nil, err
+	}
+	return esclient.NewElasticsearchClient(
+		dialer,
+		services.ExternalServiceURL(es),
+		esclient.UserAuth{Name: user.ExternalUserName, Password: password},
+		*v,
+		caCerts,
+	), nil
+}
+
+// collectElasticsearchAPIs adds the output of the Elasticsearch diagnostic APIs to the given directory of the bundle.
+func collectElasticsearchAPIs(esClient esclient.Client, b *bundle, dir string) {
+	for _, api := range elasticsearchDiagnostics {
+		file := path.Join(dir, api.file)
+		content, err := getElasticsearchAPI(esClient, api.path)
+		if err != nil {
+			b.addError(file, err)
+			continue
+		}
+		if err := b.add(file, content); err != nil {
+			b.addError(file, err)
+		}
+	}
+}
+
+func getElasticsearchAPI(esClient esclient.Client, pathWithQuery string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, pathWithQuery, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := esClient.Request(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+// collectOperatorLogs adds the logs of the operator pods running in the given namespace to the bundle.
+func collectOperatorLogs(pods corev1client.PodsGetter, b *bundle, namespace string) {
+	podsClient := pods.Pods(namespace)
+	operatorPods, err := podsClient.List(metav1.ListOptions{LabelSelector: operatorLabelSelector})
+	if err != nil {
+		b.addError("operator logs", err)
+		return
+	}
+	for _, pod := range operatorPods.Items {
+		file := path.Join("operator", pod.Name+".log")
+		logs, err := podsClient.GetLogs(pod.Name, &corev1.PodLogOptions{}).DoRaw()
+		if err != nil {
+			b.addError(file, err)
+			continue
+		}
+		if err := b.add(file, logs); err != nil {
+			b.addError(file, err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// readBundle returns the content of the files of the given tarball, by file name.
+func readBundle(t *testing.T, data []byte) map[string]string {
+	files := map[string]string{}
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		content, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+		files[header.Name] = string(content)
+	}
+}
+
+func Test_diagnosticsRoot(t *testing.T) {
+	now := time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+	require.Equal(t, "eck-diagnostics-ns-20190701-120000", diagnosticsRoot(types.NamespacedName{Namespace: "ns"}, now))
+	require.Equal(t, "eck-diagnostics-ns-es-20190701-120000", diagnosticsRoot(types.NamespacedName{Namespace: "ns", Name: "es"}, now))
+}
+
+func Test_redactSecrets(t *testing.T) {
+	secrets := []corev1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "es-elastic-user",
+				Annotations: map[string]string{
+					corev1.LastAppliedConfigAnnotation: `{"data":{"elastic":"secret"}}`,
+					"foo":                              "bar",
+				},
+			},
+			Data:       map[string][]byte{"elastic": []byte("secret")},
+			StringData: map[string]string{"other": "secret"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "empty"},
+		},
+	}
+	redactSecrets(secrets)
+	require.Equal(t, []corev1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "es-elastic-user",
+				Annotations: map[string]string{"foo": "bar"},
+			},
+			Data:       map[string][]byte{"elastic": []byte(redactedValue)},
+			StringData: map[string]string{"other": redactedValue},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "empty"},
+		},
+	}, secrets)
+}
+
+func Test_collectElasticsearchAPIs(t *testing.T) {
+	esClient := esclient.NewMockClient(version.MustParse("7.2.0"), func(req *http.Request) *http.Response {
+		if req.URL.Path == "/_nodes/stats" {
+			return esclient.NewMockResponse(500, req, `{"error":{"reason":"boom"}}`)
+		}
+		return esclient.NewMockResponse(200, req, req.URL.RequestURI())
+	})
+
+	var out bytes.Buffer
+	b := newBundle(tar.NewWriter(&out), "root", time.Now())
+	collectElasticsearchAPIs(esClient, b, "elasticsearch/es")
+	require.NoError(t, b.close())
+
+	files := readBundle(t, out.Bytes())
+	// failures are reported without preventing the collection of the other APIs
+	require.Contains(t, files["root/errors.txt"], "elasticsearch/es/nodes_stats.json")
+	require.Contains(t, files["root/errors.txt"], "boom")
+	delete(files, "root/errors.txt")
+	require.Equal(t, map[string]string{
+		"root/elasticsearch/es/cluster_health.json": "/_cluster/health",
+		"root/elasticsearch/es/cluster_state.json":  "/_cluster/state",
+		"root/elasticsearch/es/cat_shards.txt":      "/_cat/shards?v",
+	}, files)
+}
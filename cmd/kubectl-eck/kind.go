@@ -0,0 +1,102 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	apmv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1alpha1"
+	esv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	kbv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	apmconfig "github.com/elastic/cloud-on-k8s/pkg/controller/apmserver/config"
+	apmname "github.com/elastic/cloud-on-k8s/pkg/controller/apmserver/name"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/name"
+	esname "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/name"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/network"
+	kbname "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/name"
+	kbpod "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/pod"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// resource is a resource managed by the operator.
+type resource interface {
+	metav1.Object
+	runtime.Object
+}
+
+// kind describes a kind of resource managed by the operator.
+type kind struct {
+	name        string
+	aliases     []string
+	newResource func() resource
+	namer       name.Namer
+	httpService func(name string) string
+	httpPort    int
+	// podTemplates returns the user-provided pod templates of the given resource, restricted to the given
+	// Elasticsearch node spec if not empty.
+	podTemplates func(r resource, nodeSpec string) []*corev1.PodTemplateSpec
+}
+
+var kinds = []kind{
+	{
+		name:        "elasticsearch",
+		aliases:     []string{"es"},
+		newResource: func() resource { return &esv1alpha1.Elasticsearch{} },
+		namer:       esname.ESNamer,
+		httpService: esname.HTTPService,
+		httpPort:    network.HTTPPort,
+		podTemplates: func(r resource, nodeSpec string) []*corev1.PodTemplateSpec {
+			es := r.(*esv1alpha1.Elasticsearch)
+			var templates []*corev1.PodTemplateSpec
+			for i := range es.Spec.Nodes {
+				if nodeSpec == "" || es.Spec.Nodes[i].Name == nodeSpec {
+					templates = append(templates, &es.Spec.Nodes[i].PodTemplate)
+				}
+			}
+			return templates
+		},
+	},
+	{
+		name:        "kibana",
+		aliases:     []string{"kb"},
+		newResource: func() resource { return &kbv1alpha1.Kibana{} },
+		namer:       kbname.KBNamer,
+		httpService: kbname.HTTPService,
+		httpPort:    kbpod.HTTPPort,
+		podTemplates: func(r resource, _ string) []*corev1.PodTemplateSpec {
+			return []*corev1.PodTemplateSpec{&r.(*kbv1alpha1.Kibana).Spec.PodTemplate}
+		},
+	},
+	{
+		name:        "apmserver",
+		aliases:     []string{"apm"},
+		newResource: func() resource { return &apmv1alpha1.ApmServer{} },
+		namer:       apmname.APMNamer,
+		httpService: apmname.HTTPService,
+		httpPort:    apmconfig.DefaultHTTPPort,
+		podTemplates: func(r resource, _ string) []*corev1.PodTemplateSpec {
+			return []*corev1.PodTemplateSpec{&r.(*apmv1alpha1.ApmServer).Spec.PodTemplate}
+		},
+	},
+}
+
+// parseKind returns the kind with the given name or alias.
+func parseKind(s string) (kind, error) {
+	s = strings.ToLower(s)
+	for _, k := range kinds {
+		if s == k.name {
+			return k, nil
+		}
+		for _, alias := range k.aliases {
+			if s == alias {
+				return k, nil
+			}
+		}
+	}
+	return kind{}, fmt.Errorf("unsupported resource kind %s, expected one of elasticsearch (es), kibana (kb), apmserver (apm)", s)
+}
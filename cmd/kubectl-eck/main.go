@@ -0,0 +1,43 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// kubectl-eck is a kubectl plugin for day-2 operations on the resources managed by the operator.
+// Once the binary is in the PATH, it can be invoked with `kubectl eck`.
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/spf13/cobra"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+)
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:          "kubectl-eck",
+		Short:        "Day-2 operations on Elastic resources managed by ECK",
+		SilenceUsage: true,
+	}
+	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "", "Namespace of the resource, defaults to the namespace of the current context")
+	// the kubeconfig flag is registered by controller-runtime, which uses it to build the client config
+	if kubeconfig := flag.CommandLine.Lookup("kubeconfig"); kubeconfig != nil {
+		rootCmd.PersistentFlags().AddGoFlag(kubeconfig)
+	}
+
+	rootCmd.AddCommand(
+		statusCmd,
+		passwordCmd,
+		caCmd,
+		portForwardCmd,
+		pauseCmd,
+		resumeCmd,
+		restartCmd,
+		diagnosticsCmd,
+	)
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause KIND NAME",
+	Short: "Pause the reconciliation of a resource by the operator",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSetPaused(args[0], args[1], true)
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume KIND NAME",
+	Short: "Resume the reconciliation of a paused resource by the operator",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSetPaused(args[0], args[1], false)
+	},
+}
+
+func runSetPaused(kindName string, resourceName string, paused bool) error {
+	k, err := parseKind(kindName)
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	key, err := resourceKey(resourceName)
+	if err != nil {
+		return err
+	}
+	r, err := setPaused(c, k, key, paused)
+	if err != nil {
+		return err
+	}
+	state := "resumed"
+	if paused {
+		state = "paused"
+	}
+	fmt.Printf("%s %s/%s reconciliation %s\n", k.name, r.GetNamespace(), r.GetName(), state)
+	return nil
+}
+
+// setPaused sets or removes the pause annotation of the given resource, if not already in the expected state.
+func setPaused(c k8s.Client, k kind, key types.NamespacedName, paused bool) (resource, error) {
+	r := k.newResource()
+	if err := c.Get(key, r); err != nil {
+		return nil, err
+	}
+	if isPaused(r) == paused {
+		return r, nil
+	}
+	annotations := r.GetAnnotations()
+	if paused {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[common.PauseAnnotationName] = "true"
+	} else {
+		delete(annotations, common.PauseAnnotationName)
+	}
+	r.SetAnnotations(annotations)
+	return r, c.Update(r)
+}
+
+// isPaused returns true if the given resource is paused, as seen by the operator.
+func isPaused(r resource) bool {
+	return common.IsPaused(metav1.ObjectMeta{Annotations: r.GetAnnotations()})
+}
@@ -0,0 +1,73 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis"
+	kbv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_setPaused(t *testing.T) {
+	require.NoError(t, apis.AddToScheme(scheme.Scheme))
+	kb, err := parseKind("kb")
+	require.NoError(t, err)
+	key := types.NamespacedName{Namespace: "ns", Name: "kibana"}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		paused      bool
+		want        map[string]string
+	}{
+		{
+			name:        "pause",
+			annotations: nil,
+			paused:      true,
+			want:        map[string]string{common.PauseAnnotationName: "true"},
+		},
+		{
+			name:        "pause an explicitly resumed resource",
+			annotations: map[string]string{common.PauseAnnotationName: "false", "foo": "bar"},
+			paused:      true,
+			want:        map[string]string{common.PauseAnnotationName: "true", "foo": "bar"},
+		},
+		{
+			name:        "resume",
+			annotations: map[string]string{common.PauseAnnotationName: "true", "foo": "bar"},
+			paused:      false,
+			want:        map[string]string{"foo": "bar"},
+		},
+		{
+			name:        "resume a resource not paused",
+			annotations: map[string]string{"foo": "bar"},
+			paused:      false,
+			want:        map[string]string{"foo": "bar"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.WrapClient(fake.NewFakeClient(&kbv1alpha1.Kibana{
+				ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name, Annotations: tt.annotations},
+			}))
+			r, err := setPaused(c, kb, key, tt.paused)
+			require.NoError(t, err)
+			require.Equal(t, tt.paused, isPaused(r))
+
+			var updated kbv1alpha1.Kibana
+			require.NoError(t, c.Get(key, &updated))
+			require.Equal(t, tt.want, updated.Annotations)
+			require.Equal(t, tt.paused, common.IsPaused(updated.ObjectMeta))
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/elastic/cloud-on-k8s/pkg/dev/portforward"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
+)
+
+var localPort int
+
+var portForwardCmd = &cobra.Command{
+	Use:   "port-forward KIND NAME",
+	Short: "Forward a local port to the HTTP service of a resource",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		k, err := parseKind(args[0])
+		if err != nil {
+			return err
+		}
+		key, err := resourceKey(args[1])
+		if err != nil {
+			return err
+		}
+		port := localPort
+		if port == 0 {
+			port = k.httpPort
+		}
+		listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+		if err != nil {
+			return err
+		}
+
+		stop := signals.SetupSignalHandler()
+		go func() {
+			<-stop
+			_ = listener.Close()
+		}()
+
+		remoteAddr := fmt.Sprintf("%s.%s.svc:%d", k.httpService(key.Name), key.Namespace, k.httpPort)
+		fmt.Printf("Forwarding from %s to %s\n", listener.Addr(), remoteAddr)
+		return forward(listener, portforward.NewForwardingDialer(), remoteAddr, stop)
+	},
+}
+
+func init() {
+	portForwardCmd.Flags().IntVar(&localPort, "local-port", 0, "Local port to listen on, defaults to the port of the HTTP service")
+}
+
+// forward accepts connections on the given listener and forwards them to the given remote address
+// until the stop channel is closed.
+func forward(listener net.Listener, dialer *portforward.ForwardingDialer, remoteAddr string, stop <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-stop:
+				// the listener was closed on purpose
+				return nil
+			default:
+				return err
+			}
+		}
+		go func() {
+			defer conn.Close()
+			remote, err := dialer.DialContext(ctx, "tcp", remoteAddr)
+			if err != nil {
+				fmt.Printf("Failed to forward connection to %s: %s\n", remoteAddr, err)
+				return
+			}
+			defer remote.Close()
+			done := make(chan struct{}, 2)
+			go func() { _, _ = io.Copy(remote, conn); done <- struct{}{} }()
+			go func() { _, _ = io.Copy(conn, remote); done <- struct{}{} }()
+			// close both ends as soon as one side is done
+			<-done
+		}()
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// RestartedAtAnnotationName is set on the pod templates of a resource to trigger a restart of its pods.
+// Since it changes the pod templates, the operator rolls the change out as for any other specification change,
+// which for Elasticsearch means a safe rolling restart of the nodes.
+const RestartedAtAnnotationName = "eck.k8s.elastic.co/restarted-at"
+
+var restartNodeSpec string
+
+var restartCmd = &cobra.Command{
+	Use:   "restart KIND NAME",
+	Short: "Trigger a rolling restart of the pods of a resource",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		k, err := parseKind(args[0])
+		if err != nil {
+			return err
+		}
+		c, err := newClient()
+		if err != nil {
+			return err
+		}
+		key, err := resourceKey(args[1])
+		if err != nil {
+			return err
+		}
+		if err := restart(c, k, key, restartNodeSpec, time.Now()); err != nil {
+			return err
+		}
+		fmt.Printf("%s %s restart triggered\n", k.name, key)
+		return nil
+	},
+}
+
+func init() {
+	restartCmd.Flags().StringVar(&restartNodeSpec, "node-spec", "", "Only restart the Elasticsearch nodes of the given node spec")
+}
+
+// restart annotates the pod templates of the given resource with the given time, to trigger a restart of its pods.
+func restart(c k8s.Client, k kind, key types.NamespacedName, nodeSpec string, now time.Time) error {
+	r := k.newResource()
+	if err := c.Get(key, r); err != nil {
+		return err
+	}
+	templates := k.podTemplates(r, nodeSpec)
+	if len(templates) == 0 {
+		return fmt.Errorf("no node spec %s in %s %s", nodeSpec, k.name, key)
+	}
+	for _, template := range templates {
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[RestartedAtAnnotationName] = now.UTC().Format(time.RFC3339)
+	}
+	return c.Update(r)
+}
@@ -0,0 +1,77 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis"
+	esv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_restart(t *testing.T) {
+	require.NoError(t, apis.AddToScheme(scheme.Scheme))
+	es, err := parseKind("elasticsearch")
+	require.NoError(t, err)
+	key := types.NamespacedName{Namespace: "ns", Name: "es"}
+	now := time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		nodeSpec string
+		wantErr  bool
+		want     map[string]bool
+	}{
+		{
+			name:     "restart all nodes",
+			nodeSpec: "",
+			want:     map[string]bool{"master": true, "data": true},
+		},
+		{
+			name:     "restart a single node spec",
+			nodeSpec: "data",
+			want:     map[string]bool{"master": false, "data": true},
+		},
+		{
+			name:     "unknown node spec",
+			nodeSpec: "ml",
+			wantErr:  true,
+			want:     map[string]bool{"master": false, "data": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.WrapClient(fake.NewFakeClient(&esv1alpha1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+				Spec: esv1alpha1.ElasticsearchSpec{
+					Nodes: []esv1alpha1.NodeSpec{{Name: "master"}, {Name: "data"}},
+				},
+			}))
+			err := restart(c, es, key, tt.nodeSpec, now)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			var updated esv1alpha1.Elasticsearch
+			require.NoError(t, c.Get(key, &updated))
+			for _, node := range updated.Spec.Nodes {
+				restartedAt, restarted := node.PodTemplate.Annotations[RestartedAtAnnotationName]
+				require.Equal(t, tt.want[node.Name], restarted, node.Name)
+				if restarted {
+					require.Equal(t, "2019-07-01T12:00:00Z", restartedAt)
+				}
+			}
+		})
+	}
+}
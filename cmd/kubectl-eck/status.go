@@ -0,0 +1,113 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status NAME",
+	Short: "Show the status of an Elasticsearch cluster, with per-node details",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient()
+		if err != nil {
+			return err
+		}
+		key, err := resourceKey(args[0])
+		if err != nil {
+			return err
+		}
+		var es v1alpha1.Elasticsearch
+		if err := c.Get(key, &es); err != nil {
+			return err
+		}
+		var pods corev1.PodList
+		if err := c.List(&client.ListOptions{
+			Namespace:     key.Namespace,
+			LabelSelector: labels.SelectorFromSet(map[string]string{label.ClusterNameLabelName: key.Name}),
+		}, &pods); err != nil {
+			return err
+		}
+		return printStatus(os.Stdout, es, pods.Items)
+	},
+}
+
+// printStatus writes a human-readable status of the given cluster and of its pods.
+func printStatus(out io.Writer, es v1alpha1.Elasticsearch, pods []corev1.Pod) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Elasticsearch:\t%s/%s\n", es.Namespace, es.Name)
+	fmt.Fprintf(w, "Version:\t%s\n", es.Spec.Version)
+	fmt.Fprintf(w, "Health:\t%s\n", valueOrUnknown(string(es.Status.Health)))
+	fmt.Fprintf(w, "Phase:\t%s\n", valueOrUnknown(string(es.Status.Phase)))
+	fmt.Fprintf(w, "Available nodes:\t%d\n", es.Status.AvailableNodes)
+	fmt.Fprintf(w, "Elected master:\t%s\n", valueOrUnknown(es.Status.MasterNode))
+	fmt.Fprintf(w, "Paused:\t%t\n", common.IsPaused(es.ObjectMeta))
+	fmt.Fprintln(w)
+
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	fmt.Fprintln(w, "NODE\tREADY\tSTATUS\tROLES\tVERSION\tIP\tK8S NODE\tRESTARTS")
+	for _, p := range pods {
+		ready, restarts := 0, int32(0)
+		for _, s := range p.Status.ContainerStatuses {
+			if s.Ready {
+				ready++
+			}
+			restarts += s.RestartCount
+		}
+		fmt.Fprintf(w, "%s\t%d/%d\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			p.Name,
+			ready, len(p.Spec.Containers),
+			p.Status.Phase,
+			nodeRoles(p),
+			valueOrUnknown(p.Labels[label.VersionLabelName]),
+			valueOrUnknown(p.Status.PodIP),
+			valueOrUnknown(p.Spec.NodeName),
+			restarts,
+		)
+	}
+	return w.Flush()
+}
+
+// nodeRoles returns the Elasticsearch node roles of the given pod, as a comma-separated list.
+func nodeRoles(pod corev1.Pod) string {
+	var roles []string
+	for role, labelName := range map[string]common.TrueFalseLabel{
+		"master": label.NodeTypesMasterLabelName,
+		"data":   label.NodeTypesDataLabelName,
+		"ingest": label.NodeTypesIngestLabelName,
+		"ml":     label.NodeTypesMLLabelName,
+	} {
+		if labelName.HasValue(true, pod.Labels) {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		return "-"
+	}
+	sort.Strings(roles)
+	return strings.Join(roles, ",")
+}
+
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,68 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPod(name string, ready bool, roles ...common.TrueFalseLabel) corev1.Pod {
+	labels := map[string]string{label.VersionLabelName: "7.2.0"}
+	for _, role := range roles {
+		role.Set(true, labels)
+	}
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec: corev1.PodSpec{
+			NodeName:   "k8s-node",
+			Containers: []corev1.Container{{Name: "elasticsearch"}},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			PodIP:             "10.0.0.1",
+			ContainerStatuses: []corev1.ContainerStatus{{Ready: ready, RestartCount: 2}},
+		},
+	}
+}
+
+func Test_printStatus(t *testing.T) {
+	es := v1alpha1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+		Spec:       v1alpha1.ElasticsearchSpec{Version: "7.2.0"},
+		Status: v1alpha1.ElasticsearchStatus{
+			ReconcilerStatus: commonv1alpha1.ReconcilerStatus{AvailableNodes: 1},
+			Health:           v1alpha1.ElasticsearchYellowHealth,
+			Phase:            v1alpha1.ElasticsearchOperationalPhase,
+			MasterNode:       "es-master-0",
+		},
+	}
+	pods := []corev1.Pod{
+		newPod("es-master-0", true, label.NodeTypesMasterLabelName, label.NodeTypesDataLabelName),
+		newPod("es-data-0", false, label.NodeTypesDataLabelName, label.NodeTypesIngestLabelName),
+	}
+	var out bytes.Buffer
+	require.NoError(t, printStatus(&out, es, pods))
+	require.Equal(t, `Elasticsearch:    ns/es
+Version:          7.2.0
+Health:           yellow
+Phase:            Operational
+Available nodes:  1
+Elected master:   es-master-0
+Paused:           false
+
+NODE         READY  STATUS   ROLES        VERSION  IP        K8S NODE  RESTARTS
+es-data-0    0/1    Running  data,ingest  7.2.0    10.0.0.1  k8s-node  2
+es-master-0  1/1    Running  data,master  7.2.0    10.0.0.1  k8s-node  2
+`, out.String())
+}
@@ -15,11 +15,15 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/about"
 	"github.com/elastic/cloud-on-k8s/pkg/apis"
 	"github.com/elastic/cloud-on-k8s/pkg/controller"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/cache"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/leaderelection"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
 	"github.com/elastic/cloud-on-k8s/pkg/dev"
 	"github.com/elastic/cloud-on-k8s/pkg/dev/portforward"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/stringsutil"
 	"github.com/elastic/cloud-on-k8s/pkg/webhook"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -35,12 +39,16 @@ const (
 	DefaultMetricPort = 8080
 
 	AutoPortForwardFlagName = "auto-port-forward"
-	NamespaceFlagName       = "namespace"
+	NamespacesFlagName      = "namespaces"
+	// NamespaceFlagName is deprecated in favor of NamespacesFlagName.
+	NamespaceFlagName = "namespace"
 
 	CACertValidityFlag     = "ca-cert-validity"
 	CACertRotateBeforeFlag = "ca-cert-rotate-before"
 	CertValidityFlag       = "cert-validity"
 	CertRotateBeforeFlag   = "cert-rotate-before"
+	CertKeyAlgorithmFlag   = "cert-key-algorithm"
+	CertKeySizeFlag        = "cert-key-size"
 
 	AutoInstallWebhooksFlag = "auto-install-webhooks"
 	OperatorNamespaceFlag   = "operator-namespace"
@@ -48,6 +56,14 @@ const (
 	WebhookPodsLabelFlag    = "webhook-pods-label"
 
 	DebugHTTPServerListenAddressFlag = "debug-http-listen"
+
+	EnableLeaderElectionFlag        = "enable-leader-election"
+	LeaderElectionNamespaceFlag     = "leader-election-namespace"
+	LeaderElectionLeaseDurationFlag = "leader-election-lease-duration"
+	LeaderElectionRenewDeadlineFlag = "leader-election-renew-deadline"
+
+	TracingOTLPEndpointFlag = "tracing-otlp-endpoint"
+	TracingServiceNameFlag  = "tracing-service-name"
 )
 
 var (
@@ -67,11 +83,20 @@ var (
 
 func init() {
 
+	Cmd.Flags().StringSlice(
+		NamespacesFlagName,
+		nil,
+		"comma-separated list of namespaces in which this operator should manage resources (defaults to all namespaces)",
+	)
 	Cmd.Flags().String(
 		NamespaceFlagName,
 		"",
 		"namespace in which this operator should manage resources (defaults to all namespaces)",
 	)
+	if err := Cmd.Flags().MarkDeprecated(NamespaceFlagName, "use --"+NamespacesFlagName+" instead"); err != nil {
+		log.Error(err, "Unexpected error while deprecating flags")
+		os.Exit(1)
+	}
 	Cmd.Flags().Bool(
 		AutoPortForwardFlagName,
 		false,
@@ -108,10 +133,20 @@ func init() {
 		certificates.DefaultRotateBefore,
 		"Duration representing how long before expiration TLS certificates should be reissued",
 	)
+	Cmd.Flags().String(
+		CertKeyAlgorithmFlag,
+		string(certificates.RSAKeyAlgorithm),
+		"Algorithm of the private keys generated for CA and TLS certificates (either rsa or ecdsa)",
+	)
+	Cmd.Flags().Int(
+		CertKeySizeFlag,
+		0,
+		"Size in bits of the private keys generated for CA and TLS certificates: at least 2048 for rsa, 256 or 384 for ecdsa (0 uses the algorithm default)",
+	)
 	Cmd.Flags().Bool(
 		AutoInstallWebhooksFlag,
 		true,
-		"enables automatic webhook installation (RBAC permission for service, secret and validatingwebhookconfigurations needed)",
+		"enables automatic webhook installation (RBAC permission for service, secret, validatingwebhookconfigurations, mutatingwebhookconfigurations and customresourcedefinitions needed)",
 	)
 	Cmd.Flags().String(
 		OperatorNamespaceFlag,
@@ -128,6 +163,36 @@ func init() {
 		"",
 		"k8s secret mounted into /tmp/cert to be used for webhook certificates",
 	)
+	Cmd.Flags().Bool(
+		EnableLeaderElectionFlag,
+		true,
+		"Elect a leader among the operator replicas: only the leader runs the controllers, while all replicas serve webhooks",
+	)
+	Cmd.Flags().String(
+		LeaderElectionNamespaceFlag,
+		"",
+		"k8s namespace in which the leader election lock is maintained (defaults to the operator namespace)",
+	)
+	Cmd.Flags().Duration(
+		LeaderElectionLeaseDurationFlag,
+		leaderelection.DefaultLeaseDuration,
+		"Duration non-leader operator replicas wait before trying to acquire the leadership",
+	)
+	Cmd.Flags().Duration(
+		LeaderElectionRenewDeadlineFlag,
+		leaderelection.DefaultRenewDeadline,
+		"Duration during which the leader retries refreshing the leadership before giving it up",
+	)
+	Cmd.Flags().String(
+		TracingOTLPEndpointFlag,
+		"",
+		"OTLP/HTTP endpoint to export reconciliation traces to, such as http://otel-collector:4318 (tracing is disabled if empty)",
+	)
+	Cmd.Flags().String(
+		TracingServiceNameFlag,
+		"elastic-operator",
+		"Service name the exported reconciliation traces are attributed to",
+	)
 	Cmd.Flags().String(
 		DebugHTTPServerListenAddressFlag,
 		"localhost:6060",
@@ -193,11 +258,30 @@ func execute() {
 		os.Exit(1)
 	}
 
+	roles := viper.GetStringSlice(operator.RoleFlag)
+	err = operator.ValidateRoles(roles)
+	if err != nil {
+		log.Error(err, "invalid roles specified")
+		os.Exit(1)
+	}
+
 	// Create a new Cmd to provide shared dependencies and start components
 	log.Info("Setting up manager")
-	opts := manager.Options{
-		// restrict the operator to watch resources within a single namespace, unless empty
-		Namespace: viper.GetString(NamespaceFlagName),
+	opts := manager.Options{}
+
+	// restrict the operator to watch resources within a set of namespaces, unless empty
+	managedNamespaces := managedNamespaces()
+	watchedNamespaces := operator.WatchedNamespaces(managedNamespaces, operatorNamespace, roles)
+	switch len(watchedNamespaces) {
+	case 0:
+		log.Info("Operator configured to manage all namespaces")
+	case 1:
+		log.Info("Operator configured to manage a single namespace", "namespace", watchedNamespaces[0])
+		opts.Namespace = watchedNamespaces[0]
+	default:
+		log.Info("Operator configured to manage multiple namespaces",
+			"namespaces", managedNamespaces, "watched_namespaces", watchedNamespaces)
+		opts.NewCache = cache.MultiNamespacedCacheBuilder(watchedNamespaces)
 	}
 
 	// only expose prometheus metrics if provided a specific port
@@ -220,14 +304,22 @@ func execute() {
 		os.Exit(1)
 	}
 
+	if endpoint := viper.GetString(TracingOTLPEndpointFlag); endpoint != "" {
+		log.Info("Exporting reconciliation traces", "endpoint", endpoint)
+		tracer := tracing.NewTracer(tracing.NewOTLPExporter(endpoint, viper.GetString(TracingServiceNameFlag)))
+		if err := mgr.Add(tracer); err != nil {
+			log.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
+		tracing.SetTracer(tracer)
+	}
+
 	// Verify cert validity options
 	caCertValidity, caCertRotateBefore := ValidateCertExpirationFlags(CACertValidityFlag, CACertRotateBeforeFlag)
 	certValidity, certRotateBefore := ValidateCertExpirationFlags(CertValidityFlag, CertRotateBeforeFlag)
-	// Setup all Controllers
-	roles := viper.GetStringSlice(operator.RoleFlag)
-	err = operator.ValidateRoles(roles)
+	certKeyParams, err := certificates.NewKeyParams(viper.GetString(CertKeyAlgorithmFlag), viper.GetInt(CertKeySizeFlag))
 	if err != nil {
-		log.Error(err, "invalid roles specified")
+		log.Error(err, "invalid certificate key options", CertKeyAlgorithmFlag, viper.GetString(CertKeyAlgorithmFlag), CertKeySizeFlag, viper.GetInt(CertKeySizeFlag))
 		os.Exit(1)
 	}
 
@@ -244,8 +336,7 @@ func execute() {
 		os.Exit(1)
 	}
 
-	log.Info("Setting up controllers", "roles", roles)
-	if err := controller.AddToManager(mgr, roles, operator.Parameters{
+	params := operator.Parameters{
 		Dialer:            dialer,
 		OperatorNamespace: operatorNamespace,
 		OperatorInfo:      operatorInfo,
@@ -257,7 +348,37 @@ func execute() {
 			Validity:     certValidity,
 			RotateBefore: certRotateBefore,
 		},
-	}); err != nil {
+		CertKeyParams: certKeyParams,
+	}
+	addControllers := func() error {
+		log.Info("Setting up controllers", "roles", roles)
+		return controller.AddToManager(mgr, roles, params)
+	}
+
+	if viper.GetBool(EnableLeaderElectionFlag) {
+		// controllers, and the Elasticsearch observers they start, only run on the elected leader,
+		// whereas webhooks are served by all replicas
+		leaderElectionParams := leaderelection.Params{
+			Namespace:     viper.GetString(LeaderElectionNamespaceFlag),
+			LockName:      leaderelection.LockName(roles, managedNamespaces),
+			LeaseDuration: viper.GetDuration(LeaderElectionLeaseDurationFlag),
+			RenewDeadline: viper.GetDuration(LeaderElectionRenewDeadlineFlag),
+			RetryPeriod:   leaderelection.DefaultRetryPeriod,
+		}
+		if leaderElectionParams.Namespace == "" {
+			leaderElectionParams.Namespace = operatorNamespace
+		}
+		if err := leaderElectionParams.Validate(); err != nil {
+			log.Error(err, "invalid leader election options")
+			os.Exit(1)
+		}
+		if err := mgr.Add(leaderelection.NewRunnable(
+			clientset, mgr.GetRecorder("leader-election"), leaderElectionParams, addControllers,
+		)); err != nil {
+			log.Error(err, "unable to set up leader election")
+			os.Exit(1)
+		}
+	} else if err := addControllers(); err != nil {
 		log.Error(err, "unable to register controllers to the manager")
 		os.Exit(1)
 	}
@@ -286,17 +407,33 @@ func newWebhookParameters() (*webhook.Parameters, error) {
 	}
 	svcSelector := viper.GetString(WebhookPodsLabelFlag)
 	sec := viper.GetString(WebhookSecretFlag)
+	watchedNamespaces := operator.WatchedNamespaces(managedNamespaces(), ns, viper.GetStringSlice(operator.RoleFlag))
 	return &webhook.Parameters{
 		Bootstrap: webhook.NewBootstrapOptions(webhook.BootstrapOptionsParams{
-			Namespace:        ns,
-			ManagedNamespace: viper.GetString(NamespaceFlagName),
-			SecretName:       sec,
-			ServiceSelector:  svcSelector,
+			Namespace:         ns,
+			WatchedNamespaces: watchedNamespaces,
+			SecretName:        sec,
+			ServiceSelector:   svcSelector,
 		}),
 		AutoInstall: autoInstall,
 	}, nil
 }
 
+// managedNamespaces returns the namespaces in which the operator manages resources (all namespaces if empty),
+// merging the deprecated single namespace flag into the list.
+func managedNamespaces() []string {
+	var namespaces []string
+	for _, ns := range viper.GetStringSlice(NamespacesFlagName) {
+		if ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	if ns := viper.GetString(NamespaceFlagName); ns != "" && !stringsutil.StringInSlice(ns, namespaces) {
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces
+}
+
 func ValidateCertExpirationFlags(validityFlag string, rotateBeforeFlag string) (time.Duration, time.Duration) {
 	certValidity := viper.GetDuration(validityFlag)
 	certRotateBefore := viper.GetDuration(rotateBeforeFlag)
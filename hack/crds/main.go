@@ -0,0 +1,190 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Merges the CustomResourceDefinitions generated by controller-gen for each API version of a kind into a single
+// CustomResourceDefinition serving all of them.
+//
+// The controller-gen version in use generates one CustomResourceDefinition per API version, with a single schema,
+// and cannot express the fields required by webhook conversion. This program runs after controller-gen. It merges the
+// files of all versions of a kind into the file of the storage version, and moves the schema, subresources and printer
+// columns into each version when they differ between versions. It disables the pruning bypass, which webhook
+// conversion requires, and preserves the unknown fields of free-form objects, generated without properties, and of
+// the status, whose embedded structs are ignored by controller-gen.
+//
+// Only the storage version is served: the other versions can only be read and written once the conversion webhook is
+// configured, otherwise the API server would not convert them and would prune the fields of their divergent schemas.
+// The conversion webhook is configured by the operator, which knows the namespace of its webhook service, and serves
+// the other versions at the same time.
+//
+// Usage: go run hack/crds/main.go config/crds
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/ghodss/yaml"
+)
+
+// crd describes a CustomResourceDefinition served with several API versions.
+type crd struct {
+	// fileName is the name of the file generated for a version, formatted with the version.
+	fileName string
+	// versions are the versions of the kind, starting with the storage version.
+	versions []string
+}
+
+var crds = []crd{
+	{fileName: "elasticsearch_%s_elasticsearch.yaml", versions: []string{"v1alpha1", "v1beta1"}},
+	{fileName: "kibana_%s_kibana.yaml", versions: []string{"v1alpha1", "v1beta1"}},
+	{fileName: "apm_%s_apmserver.yaml", versions: []string{"v1alpha1", "v1beta1"}},
+}
+
+// versionedFields maps the fields of a CustomResourceDefinition spec that can be set per version to their name in a
+// version.
+var versionedFields = map[string]string{
+	"validation":               "schema",
+	"subresources":             "subresources",
+	"additionalPrinterColumns": "additionalPrinterColumns",
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: crds <crds directory>")
+		os.Exit(2)
+	}
+	dir := os.Args[1]
+	for _, c := range crds {
+		if err := merge(dir, c); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to merge %s: %v\n", fmt.Sprintf(c.fileName, c.versions[0]), err)
+			os.Exit(1)
+		}
+	}
+}
+
+// merge merges the files generated for the versions of the given CustomResourceDefinition into the file of its
+// storage version, and removes the other files.
+func merge(dir string, c crd) error {
+	specs := make([]map[string]interface{}, 0, len(c.versions))
+	var merged map[string]interface{}
+	for _, v := range c.versions {
+		var doc map[string]interface{}
+		bytes, err := ioutil.ReadFile(filepath.Join(dir, fmt.Sprintf(c.fileName, v)))
+		if err != nil {
+			return err
+		}
+		if err := yaml.Unmarshal(bytes, &doc); err != nil {
+			return err
+		}
+		spec, ok := doc["spec"].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("no spec in the %s CustomResourceDefinition", v)
+		}
+		if merged == nil {
+			merged = doc
+		}
+		specs = append(specs, spec)
+	}
+
+	versions := make([]map[string]interface{}, len(c.versions))
+	for i, v := range c.versions {
+		versions[i] = map[string]interface{}{"name": v, "served": i == 0, "storage": i == 0}
+	}
+	spec := merged["spec"].(map[string]interface{})
+	for field, versionField := range versionedFields {
+		values := make([]interface{}, len(specs))
+		for i, s := range specs {
+			values[i] = s[field]
+		}
+		if allEqual(values) {
+			continue
+		}
+		delete(spec, field)
+		for i, value := range values {
+			if value != nil {
+				versions[i][versionField] = value
+			}
+		}
+	}
+	spec["version"] = c.versions[0]
+	spec["versions"] = versions
+	spec["preserveUnknownFields"] = false
+
+	if validation, ok := spec["validation"].(map[string]interface{}); ok {
+		preserveUnknownFields(validation)
+	}
+	for _, v := range versions {
+		if schema, ok := v["schema"].(map[string]interface{}); ok {
+			preserveUnknownFields(schema)
+		}
+	}
+
+	bytes, err := yaml.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf(c.fileName, c.versions[0])), bytes, 0644); err != nil {
+		return err
+	}
+	for _, v := range c.versions[1:] {
+		if err := os.Remove(filepath.Join(dir, fmt.Sprintf(c.fileName, v))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func allEqual(values []interface{}) bool {
+	for _, v := range values[1:] {
+		if !reflect.DeepEqual(values[0], v) {
+			return false
+		}
+	}
+	return true
+}
+
+// preserveUnknownFields marks the free-form objects and the status of the given validation schema to preserve their
+// unknown fields.
+func preserveUnknownFields(validation map[string]interface{}) {
+	root, ok := validation["openAPIV3Schema"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	properties, _ := root["properties"].(map[string]interface{})
+	for name, p := range properties {
+		property, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch name {
+		case "metadata":
+			// the metadata of the root object cannot be specified beyond its name
+		case "status":
+			// embedded structs are missing from the generated status
+			property["x-kubernetes-preserve-unknown-fields"] = true
+			preserveFreeFormObjects(property)
+		default:
+			preserveFreeFormObjects(property)
+		}
+	}
+}
+
+// preserveFreeFormObjects marks the objects without properties in the given schema to preserve their unknown fields.
+func preserveFreeFormObjects(schema map[string]interface{}) {
+	properties, hasProperties := schema["properties"].(map[string]interface{})
+	if schema["type"] == "object" && !hasProperties && schema["additionalProperties"] == nil {
+		schema["x-kubernetes-preserve-unknown-fields"] = true
+	}
+	for _, p := range properties {
+		if property, ok := p.(map[string]interface{}); ok {
+			preserveFreeFormObjects(property)
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		preserveFreeFormObjects(items)
+	}
+}
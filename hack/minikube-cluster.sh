@@ -10,7 +10,7 @@
 
 set -eu
 
-: "${MINIKUBE_KUBERNETES_VERSION:=v1.12.8}"
+: "${MINIKUBE_KUBERNETES_VERSION:=v1.15.0}"
 : "${MINIKUBE_MEMORY:=8192}"
 : "${MINIKUBE_CPUS:=4}"
 
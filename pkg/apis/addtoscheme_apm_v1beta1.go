@@ -2,13 +2,13 @@
 // or more contributor license agreements. Licensed under the Elastic License;
 // you may not use this file except in compliance with the Elastic License.
 
-package controller
+package apis
 
 import (
-	"github.com/elastic/cloud-on-k8s/pkg/controller/apmserverelasticsearchassociation"
-	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+	"github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1beta1"
 )
 
 func init() {
-	Register(operator.NamespaceOperator, apmserverelasticsearchassociation.Add)
+	// Register the types with the Scheme so the components can map objects to GroupVersionKinds and back
+	AddToSchemes = append(AddToSchemes, v1beta1.SchemeBuilder.AddToScheme)
 }
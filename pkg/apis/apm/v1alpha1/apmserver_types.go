@@ -36,10 +36,24 @@ type ApmServerSpec struct {
 	// If the namespace is not specified, the current resource namespace will be used.
 	ElasticsearchRef commonv1alpha1.ObjectSelector `json:"elasticsearchRef,omitempty"`
 
+	// ExternalElasticsearchRef references an Elasticsearch cluster that is not managed by the operator. The operator
+	// creates the APM Server user in that cluster with the given admin credentials. It cannot be used together with
+	// ElasticsearchRef.
+	// +optional
+	ExternalElasticsearchRef *commonv1alpha1.ExternalElasticsearchRef `json:"externalElasticsearchRef,omitempty"`
+
 	// Elasticsearch configures how the APM server connects to Elasticsearch
 	// +optional
 	Elasticsearch ElasticsearchOutput `json:"elasticsearch,omitempty"`
 
+	// KibanaRef references a Kibana resource in the Kubernetes cluster, used for agent central configuration.
+	// If the namespace is not specified, the current resource namespace will be used.
+	KibanaRef commonv1alpha1.ObjectSelector `json:"kibanaRef,omitempty"`
+
+	// Kibana configures how the APM server connects to Kibana
+	// +optional
+	Kibana KibanaConnection `json:"kibana,omitempty"`
+
 	// PodTemplate can be used to propagate configuration to APM Server pods.
 	// This allows specifying custom annotations, labels, environment variables,
 	// affinity, resources, etc. for the pods created from this NodeSpec.
@@ -76,6 +90,25 @@ type ElasticsearchOutputSSL struct {
 	CertificateAuthorities commonv1alpha1.SecretRef `json:"certificateAuthorities,omitempty"`
 }
 
+// KibanaConnection contains configuration for the connection to Kibana
+type KibanaConnection struct {
+	// Host is the URL of the Kibana instance.
+	Host string `json:"host,omitempty"`
+
+	// Auth configures authentication for APM Server to use.
+	Auth commonv1alpha1.ElasticsearchAuth `json:"auth,omitempty"`
+
+	// SSL configures TLS-related configuration for Kibana
+	SSL KibanaConnectionSSL `json:"ssl,omitempty"`
+}
+
+// KibanaConnectionSSL contains TLS-related configuration for Kibana
+type KibanaConnectionSSL struct {
+	// CertificateAuthorities is a secret that contains a `tls.crt` entry that contain certificates for server
+	// verifications.
+	CertificateAuthorities commonv1alpha1.SecretRef `json:"certificateAuthorities,omitempty"`
+}
+
 // ApmServerHealth expresses the status of the Apm Server instances.
 type ApmServerHealth string
 
@@ -96,6 +129,10 @@ type ApmServerStatus struct {
 	SecretTokenSecretName string `json:"secretTokenSecret,omitempty"`
 	// Association is the status of any auto-linking to Elasticsearch clusters.
 	Association commonv1alpha1.AssociationStatus
+	// KibanaAssociation is the status of any auto-linking to Kibana instances.
+	KibanaAssociation commonv1alpha1.AssociationStatus `json:"kibanaAssociation,omitempty"`
+	// Selector is the label selector of the APM Server pods, used by the scale subresource.
+	Selector string `json:"selector,omitempty"`
 }
 
 // IsDegraded returns true if the current status is worse than the previous.
@@ -108,6 +145,11 @@ func (e ElasticsearchOutput) IsConfigured() bool {
 	return len(e.Hosts) > 0
 }
 
+// IsConfigured returns true if the Kibana connection is populated with non-default values.
+func (k KibanaConnection) IsConfigured() bool {
+	return k.Host != ""
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -115,6 +157,7 @@ func (e ElasticsearchOutput) IsConfigured() bool {
 // +k8s:openapi-gen=true
 // +kubebuilder:categories=elastic
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.nodeCount,statuspath=.status.availableNodes,selectorpath=.status.selector
 // +kubebuilder:printcolumn:name="health",type="string",JSONPath=".status.health"
 // +kubebuilder:printcolumn:name="nodes",type="integer",JSONPath=".status.availableNodes",description="Available nodes"
 // +kubebuilder:printcolumn:name="version",type="string",JSONPath=".spec.version",description="APM version"
@@ -153,6 +196,10 @@ func (as *ApmServer) ElasticsearchRef() commonv1alpha1.ObjectSelector {
 	return as.Spec.ElasticsearchRef
 }
 
+func (as *ApmServer) KibanaRef() commonv1alpha1.ObjectSelector {
+	return as.Spec.KibanaRef
+}
+
 func (as *ApmServer) SecureSettings() []commonv1alpha1.SecretSource {
 	return as.Spec.SecureSettings
 }
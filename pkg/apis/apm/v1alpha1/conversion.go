@@ -0,0 +1,112 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1beta1"
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+)
+
+var _ commonv1alpha1.Convertible = &ApmServer{}
+
+// ConvertTo converts this ApmServer to the v1beta1 hub version.
+func (as *ApmServer) ConvertTo(hub commonv1alpha1.Hub) error {
+	dst, ok := hub.(*v1beta1.ApmServer)
+	if !ok {
+		return fmt.Errorf("cannot convert ApmServer to %T", hub)
+	}
+	src := as.DeepCopy()
+
+	dst.TypeMeta = src.TypeMeta
+	dst.ObjectMeta = src.ObjectMeta
+	dst.SetGroupVersionKind(v1beta1.SchemeGroupVersion.WithKind(Kind))
+	dst.Spec = v1beta1.ApmServerSpec{
+		Version:                  src.Spec.Version,
+		Image:                    src.Spec.Image,
+		Count:                    src.Spec.NodeCount,
+		Config:                   src.Spec.Config,
+		HTTP:                     src.Spec.HTTP,
+		ElasticsearchRef:         src.Spec.ElasticsearchRef,
+		ExternalElasticsearchRef: src.Spec.ExternalElasticsearchRef,
+		Elasticsearch: v1beta1.ElasticsearchOutput{
+			Hosts: src.Spec.Elasticsearch.Hosts,
+			Auth:  src.Spec.Elasticsearch.Auth,
+			SSL: v1beta1.ElasticsearchOutputSSL{
+				CertificateAuthorities: src.Spec.Elasticsearch.SSL.CertificateAuthorities,
+			},
+		},
+		KibanaRef: src.Spec.KibanaRef,
+		Kibana: v1beta1.KibanaConnection{
+			Host: src.Spec.Kibana.Host,
+			Auth: src.Spec.Kibana.Auth,
+			SSL: v1beta1.KibanaConnectionSSL{
+				CertificateAuthorities: src.Spec.Kibana.SSL.CertificateAuthorities,
+			},
+		},
+		PodTemplate:    src.Spec.PodTemplate,
+		SecureSettings: src.Spec.SecureSettings,
+	}
+	dst.Status = v1beta1.ApmServerStatus{
+		ReconcilerStatus:      src.Status.ReconcilerStatus,
+		Health:                v1beta1.ApmServerHealth(src.Status.Health),
+		ExternalService:       src.Status.ExternalService,
+		SecretTokenSecretName: src.Status.SecretTokenSecretName,
+		Association:           src.Status.Association,
+		KibanaAssociation:     src.Status.KibanaAssociation,
+		Selector:              src.Status.Selector,
+	}
+	return nil
+}
+
+// ConvertFrom converts the given v1beta1 hub version to this ApmServer.
+func (as *ApmServer) ConvertFrom(hub commonv1alpha1.Hub) error {
+	in, ok := hub.(*v1beta1.ApmServer)
+	if !ok {
+		return fmt.Errorf("cannot convert %T to ApmServer", hub)
+	}
+	src := in.DeepCopy()
+
+	as.TypeMeta = src.TypeMeta
+	as.ObjectMeta = src.ObjectMeta
+	as.SetGroupVersionKind(SchemeGroupVersion.WithKind(Kind))
+	as.Spec = ApmServerSpec{
+		Version:                  src.Spec.Version,
+		Image:                    src.Spec.Image,
+		NodeCount:                src.Spec.Count,
+		Config:                   src.Spec.Config,
+		HTTP:                     src.Spec.HTTP,
+		ElasticsearchRef:         src.Spec.ElasticsearchRef,
+		ExternalElasticsearchRef: src.Spec.ExternalElasticsearchRef,
+		Elasticsearch: ElasticsearchOutput{
+			Hosts: src.Spec.Elasticsearch.Hosts,
+			Auth:  src.Spec.Elasticsearch.Auth,
+			SSL: ElasticsearchOutputSSL{
+				CertificateAuthorities: src.Spec.Elasticsearch.SSL.CertificateAuthorities,
+			},
+		},
+		KibanaRef: src.Spec.KibanaRef,
+		Kibana: KibanaConnection{
+			Host: src.Spec.Kibana.Host,
+			Auth: src.Spec.Kibana.Auth,
+			SSL: KibanaConnectionSSL{
+				CertificateAuthorities: src.Spec.Kibana.SSL.CertificateAuthorities,
+			},
+		},
+		PodTemplate:    src.Spec.PodTemplate,
+		SecureSettings: src.Spec.SecureSettings,
+	}
+	as.Status = ApmServerStatus{
+		ReconcilerStatus:      src.Status.ReconcilerStatus,
+		Health:                ApmServerHealth(src.Status.Health),
+		ExternalService:       src.Status.ExternalService,
+		SecretTokenSecretName: src.Status.SecretTokenSecretName,
+		Association:           src.Status.Association,
+		KibanaAssociation:     src.Status.KibanaAssociation,
+		Selector:              src.Status.Selector,
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1beta1"
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApmServer_RoundTrip(t *testing.T) {
+	as := ApmServer{
+		TypeMeta:   metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: Kind},
+		ObjectMeta: metav1.ObjectMeta{Name: "apm", Namespace: "ns", Labels: map[string]string{"a": "b"}},
+		Spec: ApmServerSpec{
+			Version:          "7.2.0",
+			Image:            "my-image",
+			NodeCount:        2,
+			Config:           &commonv1alpha1.Config{Data: map[string]interface{}{"apm-server.rum.enabled": true}},
+			ElasticsearchRef: commonv1alpha1.ObjectSelector{Name: "es", Namespace: "other"},
+			ExternalElasticsearchRef: &commonv1alpha1.ExternalElasticsearchRef{
+				URL:                    "https://legacy-es:9200",
+				AdminSecretName:        "legacy-es-admin",
+				CertificateAuthorities: commonv1alpha1.SecretRef{SecretName: "legacy-es-ca"},
+			},
+			Elasticsearch: ElasticsearchOutput{
+				Hosts: []string{"https://es:9200"},
+				Auth: commonv1alpha1.ElasticsearchAuth{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "es-user"},
+					Key:                  "apm",
+				}},
+				SSL: ElasticsearchOutputSSL{CertificateAuthorities: commonv1alpha1.SecretRef{SecretName: "es-ca"}},
+			},
+			KibanaRef: commonv1alpha1.ObjectSelector{Name: "kb"},
+			Kibana: KibanaConnection{
+				Host: "https://kb:5601",
+				Auth: commonv1alpha1.ElasticsearchAuth{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "kb-user"},
+					Key:                  "apm",
+				}},
+				SSL: KibanaConnectionSSL{CertificateAuthorities: commonv1alpha1.SecretRef{SecretName: "kb-ca"}},
+			},
+			PodTemplate:    corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"c": "d"}}},
+			SecureSettings: []commonv1alpha1.SecretSource{{SecretName: "secure-settings"}},
+		},
+		Status: ApmServerStatus{
+			ReconcilerStatus:      commonv1alpha1.ReconcilerStatus{AvailableNodes: 2},
+			Health:                ApmServerGreen,
+			ExternalService:       "apm-apm-http",
+			SecretTokenSecretName: "apm-apm-token",
+			Association:           commonv1alpha1.AssociationEstablished,
+			KibanaAssociation:     commonv1alpha1.AssociationPending,
+			Selector:              "apm.k8s.elastic.co/name=apm",
+		},
+	}
+
+	var hub v1beta1.ApmServer
+	require.NoError(t, as.ConvertTo(&hub))
+	require.Equal(t, v1beta1.SchemeGroupVersion.String(), hub.APIVersion)
+	require.Equal(t, int32(2), hub.Spec.Count)
+	require.Equal(t, as.Spec.Elasticsearch.Hosts, hub.Spec.Elasticsearch.Hosts)
+	require.Equal(t, as.Spec.Kibana.Host, hub.Spec.Kibana.Host)
+
+	var converted ApmServer
+	require.NoError(t, converted.ConvertFrom(&hub))
+	require.Equal(t, as, converted)
+
+	var convertedHub v1beta1.ApmServer
+	require.NoError(t, converted.ConvertTo(&convertedHub))
+	require.Equal(t, hub, convertedHub)
+}
@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated
 // +build !ignore_autogenerated
 
 // Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
@@ -83,7 +84,14 @@ func (in *ApmServerSpec) DeepCopyInto(out *ApmServerSpec) {
 	}
 	in.HTTP.DeepCopyInto(&out.HTTP)
 	out.ElasticsearchRef = in.ElasticsearchRef
+	if in.ExternalElasticsearchRef != nil {
+		in, out := &in.ExternalElasticsearchRef, &out.ExternalElasticsearchRef
+		*out = new(commonv1alpha1.ExternalElasticsearchRef)
+		**out = **in
+	}
 	in.Elasticsearch.DeepCopyInto(&out.Elasticsearch)
+	out.KibanaRef = in.KibanaRef
+	in.Kibana.DeepCopyInto(&out.Kibana)
 	in.PodTemplate.DeepCopyInto(&out.PodTemplate)
 	if in.SecureSettings != nil {
 		in, out := &in.SecureSettings, &out.SecureSettings
@@ -161,3 +169,38 @@ func (in *ElasticsearchOutputSSL) DeepCopy() *ElasticsearchOutputSSL {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaConnection) DeepCopyInto(out *KibanaConnection) {
+	*out = *in
+	in.Auth.DeepCopyInto(&out.Auth)
+	out.SSL = in.SSL
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaConnection.
+func (in *KibanaConnection) DeepCopy() *KibanaConnection {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaConnectionSSL) DeepCopyInto(out *KibanaConnectionSSL) {
+	*out = *in
+	out.CertificateAuthorities = in.CertificateAuthorities
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaConnectionSSL.
+func (in *KibanaConnectionSSL) DeepCopy() *KibanaConnectionSSL {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaConnectionSSL)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -42,6 +42,23 @@ func (s *ObjectSelector) IsDefined() bool {
 	return s != nil && s.Name != ""
 }
 
+// ExternalElasticsearchRef references an Elasticsearch cluster that is not managed by the operator.
+type ExternalElasticsearchRef struct {
+	// URL is the HTTPS URL of the Elasticsearch cluster.
+	URL string `json:"url"`
+	// AdminSecretName is the name of a secret in the namespace of the associated resource, with the `username`
+	// and `password` of an Elasticsearch user allowed to manage users.
+	AdminSecretName string `json:"adminSecretName"`
+	// CertificateAuthorities is a secret in the namespace of the associated resource that contains a `tls.crt`
+	// entry with the certificates used to verify the Elasticsearch HTTP certificates. It is required.
+	CertificateAuthorities SecretRef `json:"certificateAuthorities,omitempty"`
+}
+
+// IsDefined checks if the reference is not nil and has a URL.
+func (r *ExternalElasticsearchRef) IsDefined() bool {
+	return r != nil && r.URL != ""
+}
+
 // HTTPConfig configures an HTTP-based service.
 type HTTPConfig struct {
 	// Service is a template for the Kubernetes Service
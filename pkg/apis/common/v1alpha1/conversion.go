@@ -0,0 +1,23 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Hub is implemented by the API version of a kind that all other versions are converted to and from.
+type Hub interface {
+	runtime.Object
+	Hub()
+}
+
+// Convertible is implemented by the API versions of a kind that can be converted to and from its Hub version.
+// Conversions must be lossless: converting to the hub and back must return the original object.
+type Convertible interface {
+	runtime.Object
+	ConvertTo(dst Hub) error
+	ConvertFrom(src Hub) error
+}
@@ -43,6 +43,22 @@ func (in *ElasticsearchAuth) DeepCopy() *ElasticsearchAuth {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalElasticsearchRef) DeepCopyInto(out *ExternalElasticsearchRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalElasticsearchRef.
+func (in *ExternalElasticsearchRef) DeepCopy() *ExternalElasticsearchRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalElasticsearchRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPConfig) DeepCopyInto(out *HTTPConfig) {
 	*out = *in
@@ -0,0 +1,213 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ChangeBudgetAnnotation carries a v1beta1 change budget that cannot be represented in v1alpha1, the storage
+// version: a percentage of the number of nodes, or a value left unset. The v1alpha1 change budget holds the absolute
+// numbers of pods the percentages resolve to when the cluster is converted, and the annotation restores the original
+// change budget on conversion back to v1beta1. The annotation takes precedence over the v1alpha1 change budget, it
+// must be removed to update the change budget in v1alpha1.
+const ChangeBudgetAnnotation = "elasticsearch.k8s.elastic.co/change-budget"
+
+var _ commonv1alpha1.Convertible = &Elasticsearch{}
+
+// ConvertTo converts this Elasticsearch to the v1beta1 hub version.
+func (e *Elasticsearch) ConvertTo(hub commonv1alpha1.Hub) error {
+	dst, ok := hub.(*v1beta1.Elasticsearch)
+	if !ok {
+		return fmt.Errorf("cannot convert Elasticsearch to %T", hub)
+	}
+	src := e.DeepCopy()
+
+	dst.TypeMeta = src.TypeMeta
+	dst.ObjectMeta = src.ObjectMeta
+	dst.SetGroupVersionKind(v1beta1.SchemeGroupVersion.WithKind(Kind))
+
+	dst.Spec = v1beta1.ElasticsearchSpec{
+		Version:             src.Spec.Version,
+		Image:               src.Spec.Image,
+		SetVMMaxMapCount:    src.Spec.SetVMMaxMapCount,
+		HTTP:                src.Spec.HTTP,
+		PodDisruptionBudget: src.Spec.PodDisruptionBudget,
+		SecureSettings:      src.Spec.SecureSettings,
+		Monitoring:          v1beta1.MonitoringSpec{ElasticsearchRef: src.Spec.Monitoring.ElasticsearchRef},
+	}
+	for _, n := range src.Spec.Nodes {
+		dst.Spec.NodeSets = append(dst.Spec.NodeSets, v1beta1.NodeSet{
+			Name:                 n.Name,
+			Config:               n.Config,
+			Count:                n.NodeCount,
+			PodTemplate:          n.PodTemplate,
+			VolumeClaimTemplates: n.VolumeClaimTemplates,
+		})
+	}
+	for _, g := range src.Spec.UpdateStrategy.Groups {
+		dst.Spec.UpdateStrategy.Groups = append(dst.Spec.UpdateStrategy.Groups, v1beta1.GroupingDefinition{Selector: g.Selector})
+	}
+	if original, err := originalChangeBudget(src.ObjectMeta.Annotations); err != nil {
+		return err
+	} else if original != nil {
+		dst.Spec.UpdateStrategy.ChangeBudget = original
+	} else if changeBudget := src.Spec.UpdateStrategy.ChangeBudget; changeBudget != nil {
+		maxUnavailable := intstr.FromInt(changeBudget.MaxUnavailable)
+		maxSurge := intstr.FromInt(changeBudget.MaxSurge)
+		dst.Spec.UpdateStrategy.ChangeBudget = &v1beta1.ChangeBudget{MaxUnavailable: &maxUnavailable, MaxSurge: &maxSurge}
+	}
+	dst.Annotations = withoutChangeBudgetAnnotation(dst.Annotations)
+
+	dst.Status = v1beta1.ElasticsearchStatus{
+		ReconcilerStatus:      src.Status.ReconcilerStatus,
+		Health:                v1beta1.ElasticsearchHealth(src.Status.Health),
+		Phase:                 v1beta1.ElasticsearchOrchestrationPhase(src.Status.Phase),
+		ClusterUUID:           src.Status.ClusterUUID,
+		MasterNode:            src.Status.MasterNode,
+		ExternalService:       src.Status.ExternalService,
+		ZenDiscovery:          v1beta1.ZenDiscoveryStatus{MinimumMasterNodes: src.Status.ZenDiscovery.MinimumMasterNodes},
+		ActivePrimaryShards:   src.Status.ActivePrimaryShards,
+		MonitoringAssociation: src.Status.MonitoringAssociation,
+	}
+	return nil
+}
+
+// ConvertFrom converts the given v1beta1 hub version to this Elasticsearch.
+func (e *Elasticsearch) ConvertFrom(hub commonv1alpha1.Hub) error {
+	in, ok := hub.(*v1beta1.Elasticsearch)
+	if !ok {
+		return fmt.Errorf("cannot convert %T to Elasticsearch", hub)
+	}
+	src := in.DeepCopy()
+
+	e.TypeMeta = src.TypeMeta
+	e.ObjectMeta = src.ObjectMeta
+	e.SetGroupVersionKind(SchemeGroupVersion.WithKind(Kind))
+
+	e.Spec = ElasticsearchSpec{
+		Version:             src.Spec.Version,
+		Image:               src.Spec.Image,
+		SetVMMaxMapCount:    src.Spec.SetVMMaxMapCount,
+		HTTP:                src.Spec.HTTP,
+		PodDisruptionBudget: src.Spec.PodDisruptionBudget,
+		SecureSettings:      src.Spec.SecureSettings,
+		Monitoring:          MonitoringSpec{ElasticsearchRef: src.Spec.Monitoring.ElasticsearchRef},
+	}
+	for _, n := range src.Spec.NodeSets {
+		e.Spec.Nodes = append(e.Spec.Nodes, NodeSpec{
+			Name:                 n.Name,
+			Config:               n.Config,
+			NodeCount:            n.Count,
+			PodTemplate:          n.PodTemplate,
+			VolumeClaimTemplates: n.VolumeClaimTemplates,
+		})
+	}
+	for _, g := range src.Spec.UpdateStrategy.Groups {
+		e.Spec.UpdateStrategy.Groups = append(e.Spec.UpdateStrategy.Groups, GroupingDefinition{Selector: g.Selector})
+	}
+	e.Annotations = withoutChangeBudgetAnnotation(e.Annotations)
+	if changeBudget := src.Spec.UpdateStrategy.ChangeBudget; changeBudget != nil {
+		resolved, err := resolveChangeBudget(*changeBudget, int(src.Spec.NodeCount()))
+		if err != nil {
+			return err
+		}
+		e.Spec.UpdateStrategy.ChangeBudget = &resolved
+		if !isAbsolute(*changeBudget) {
+			// preserve the percentages or unset values for the conversion back to v1beta1
+			original, err := json.Marshal(changeBudget)
+			if err != nil {
+				return err
+			}
+			if e.Annotations == nil {
+				e.Annotations = make(map[string]string)
+			}
+			e.Annotations[ChangeBudgetAnnotation] = string(original)
+		}
+	}
+
+	e.Status = ElasticsearchStatus{
+		ReconcilerStatus:      src.Status.ReconcilerStatus,
+		Health:                ElasticsearchHealth(src.Status.Health),
+		Phase:                 ElasticsearchOrchestrationPhase(src.Status.Phase),
+		ClusterUUID:           src.Status.ClusterUUID,
+		MasterNode:            src.Status.MasterNode,
+		ExternalService:       src.Status.ExternalService,
+		ZenDiscovery:          ZenDiscoveryStatus{MinimumMasterNodes: src.Status.ZenDiscovery.MinimumMasterNodes},
+		ActivePrimaryShards:   src.Status.ActivePrimaryShards,
+		MonitoringAssociation: src.Status.MonitoringAssociation,
+	}
+	return nil
+}
+
+// ResolveChangeBudget returns the change budget of the cluster in absolute numbers of pods. A change budget set in
+// percentages through v1beta1 is resolved against the current number of nodes.
+func (e Elasticsearch) ResolveChangeBudget() (ChangeBudget, error) {
+	original, err := originalChangeBudget(e.Annotations)
+	if err != nil {
+		return ChangeBudget{}, err
+	}
+	if original == nil {
+		return e.Spec.UpdateStrategy.ResolveChangeBudget(), nil
+	}
+	return resolveChangeBudget(*original, int(e.Spec.NodeCount()))
+}
+
+// originalChangeBudget returns the v1beta1 change budget preserved in the given annotations, nil if none.
+func originalChangeBudget(annotations map[string]string) (*v1beta1.ChangeBudget, error) {
+	serialized, exists := annotations[ChangeBudgetAnnotation]
+	if !exists {
+		return nil, nil
+	}
+	var changeBudget v1beta1.ChangeBudget
+	if err := json.Unmarshal([]byte(serialized), &changeBudget); err != nil {
+		return nil, fmt.Errorf("cannot parse annotation %s: %v", ChangeBudgetAnnotation, err)
+	}
+	return &changeBudget, nil
+}
+
+// withoutChangeBudgetAnnotation removes the change budget annotation from the given annotations.
+func withoutChangeBudgetAnnotation(annotations map[string]string) map[string]string {
+	if _, exists := annotations[ChangeBudgetAnnotation]; !exists {
+		return annotations
+	}
+	delete(annotations, ChangeBudgetAnnotation)
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
+
+// isAbsolute returns true if the given change budget can be represented in v1alpha1.
+func isAbsolute(changeBudget v1beta1.ChangeBudget) bool {
+	return changeBudget.MaxUnavailable != nil && changeBudget.MaxUnavailable.Type == intstr.Int &&
+		changeBudget.MaxSurge != nil && changeBudget.MaxSurge.Type == intstr.Int
+}
+
+// resolveChangeBudget resolves the given v1beta1 change budget into absolute numbers of pods for the given number of
+// nodes. Unset values are defaulted.
+func resolveChangeBudget(changeBudget v1beta1.ChangeBudget, nodeCount int) (ChangeBudget, error) {
+	resolved := DefaultChangeBudget
+	if changeBudget.MaxUnavailable != nil {
+		maxUnavailable, err := intstr.GetValueFromIntOrPercent(changeBudget.MaxUnavailable, nodeCount, false)
+		if err != nil {
+			return ChangeBudget{}, fmt.Errorf("invalid change budget maxUnavailable: %v", err)
+		}
+		resolved.MaxUnavailable = maxUnavailable
+	}
+	if changeBudget.MaxSurge != nil {
+		maxSurge, err := intstr.GetValueFromIntOrPercent(changeBudget.MaxSurge, nodeCount, true)
+		if err != nil {
+			return ChangeBudget{}, fmt.Errorf("invalid change budget maxSurge: %v", err)
+		}
+		resolved.MaxSurge = maxSurge
+	}
+	return resolved, nil
+}
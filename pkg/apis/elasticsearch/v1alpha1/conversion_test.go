@@ -0,0 +1,235 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	"testing"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1beta1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func alphaFixture() Elasticsearch {
+	setVMMaxMapCount := false
+	return Elasticsearch{
+		TypeMeta: metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: Kind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "es",
+			Namespace:   "ns",
+			Annotations: map[string]string{"foo": "bar"},
+		},
+		Spec: ElasticsearchSpec{
+			Version:          "7.2.0",
+			Image:            "my-image",
+			SetVMMaxMapCount: &setVMMaxMapCount,
+			HTTP: commonv1alpha1.HTTPConfig{
+				TLS: commonv1alpha1.TLSOptions{SelfSignedCertificate: &commonv1alpha1.SelfSignedCertificate{
+					SubjectAlternativeNames: []commonv1alpha1.SubjectAlternativeName{{DNS: "es.local"}},
+				}},
+			},
+			Nodes: []NodeSpec{
+				{
+					Name:      "master",
+					Config:    &commonv1alpha1.Config{Data: map[string]interface{}{"node.master": true}},
+					NodeCount: 3,
+					PodTemplate: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"a": "b"}},
+					},
+					VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+						{ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch-data"}},
+					},
+				},
+				{
+					Name:      "data",
+					NodeCount: 5,
+				},
+			},
+			UpdateStrategy: UpdateStrategy{
+				Groups: []GroupingDefinition{
+					{Selector: metav1.LabelSelector{MatchLabels: map[string]string{"zone": "a"}}},
+				},
+				ChangeBudget: &ChangeBudget{MaxUnavailable: 1, MaxSurge: 2},
+			},
+			PodDisruptionBudget: &commonv1alpha1.PodDisruptionBudgetTemplate{},
+			SecureSettings:      []commonv1alpha1.SecretSource{{SecretName: "secure-settings"}},
+			Monitoring: MonitoringSpec{
+				ElasticsearchRef: commonv1alpha1.ObjectSelector{Name: "monitoring", Namespace: "observability"},
+			},
+		},
+		Status: ElasticsearchStatus{
+			ReconcilerStatus:      commonv1alpha1.ReconcilerStatus{AvailableNodes: 8},
+			Health:                ElasticsearchGreenHealth,
+			Phase:                 ElasticsearchOperationalPhase,
+			ClusterUUID:           "uuid",
+			MasterNode:            "es-master-0",
+			ExternalService:       "es-es-http",
+			ZenDiscovery:          ZenDiscoveryStatus{MinimumMasterNodes: 2},
+			ActivePrimaryShards:   12,
+			MonitoringAssociation: commonv1alpha1.AssociationEstablished,
+		},
+	}
+}
+
+func TestElasticsearch_ConvertTo(t *testing.T) {
+	src := alphaFixture()
+	var dst v1beta1.Elasticsearch
+	require.NoError(t, src.ConvertTo(&dst))
+
+	require.Equal(t, v1beta1.SchemeGroupVersion.String(), dst.APIVersion)
+	require.Equal(t, src.ObjectMeta, dst.ObjectMeta)
+	require.Equal(t, []v1beta1.NodeSet{
+		{
+			Name:                 "master",
+			Config:               src.Spec.Nodes[0].Config,
+			Count:                3,
+			PodTemplate:          src.Spec.Nodes[0].PodTemplate,
+			VolumeClaimTemplates: src.Spec.Nodes[0].VolumeClaimTemplates,
+		},
+		{
+			Name:  "data",
+			Count: 5,
+		},
+	}, dst.Spec.NodeSets)
+	require.Equal(t, int32(8), dst.Spec.NodeCount())
+	require.Equal(t, &v1beta1.ChangeBudget{MaxUnavailable: intOrString(intstr.FromInt(1)), MaxSurge: intOrString(intstr.FromInt(2))},
+		dst.Spec.UpdateStrategy.ChangeBudget)
+	require.Equal(t, v1beta1.ElasticsearchGreenHealth, dst.Status.Health)
+}
+
+func TestElasticsearch_RoundTrip(t *testing.T) {
+	withoutChangeBudget := alphaFixture()
+	withoutChangeBudget.Spec.UpdateStrategy.ChangeBudget = nil
+	empty := Elasticsearch{TypeMeta: metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: Kind}}
+
+	for _, es := range []Elasticsearch{alphaFixture(), withoutChangeBudget, empty} {
+		var hub v1beta1.Elasticsearch
+		require.NoError(t, es.DeepCopy().ConvertTo(&hub))
+		var converted Elasticsearch
+		require.NoError(t, converted.ConvertFrom(&hub))
+		require.Equal(t, es, converted)
+	}
+}
+
+func TestElasticsearch_RoundTripFromHub(t *testing.T) {
+	es := alphaFixture()
+	var hub v1beta1.Elasticsearch
+	require.NoError(t, es.ConvertTo(&hub))
+
+	var spoke Elasticsearch
+	require.NoError(t, spoke.ConvertFrom(&hub))
+	var converted v1beta1.Elasticsearch
+	require.NoError(t, spoke.ConvertTo(&converted))
+	require.Equal(t, hub, converted)
+}
+
+func intOrString(v intstr.IntOrString) *intstr.IntOrString {
+	return &v
+}
+
+// hubFixture returns a v1beta1 cluster of 8 nodes with the given change budget.
+func hubFixture(t *testing.T, changeBudget *v1beta1.ChangeBudget) v1beta1.Elasticsearch {
+	es := alphaFixture()
+	var hub v1beta1.Elasticsearch
+	require.NoError(t, es.ConvertTo(&hub))
+	hub.Spec.UpdateStrategy.ChangeBudget = changeBudget
+	return hub
+}
+
+func TestElasticsearch_ConvertFrom_ChangeBudget(t *testing.T) {
+	tests := []struct {
+		name           string
+		changeBudget   *v1beta1.ChangeBudget
+		want           *ChangeBudget
+		wantAnnotation bool
+		wantErr        bool
+	}{
+		{
+			name:         "no change budget",
+			changeBudget: nil,
+			want:         nil,
+		},
+		{
+			name:         "absolute numbers",
+			changeBudget: &v1beta1.ChangeBudget{MaxUnavailable: intOrString(intstr.FromInt(1)), MaxSurge: intOrString(intstr.FromInt(2))},
+			want:         &ChangeBudget{MaxUnavailable: 1, MaxSurge: 2},
+		},
+		{
+			name:           "percentages are resolved against the number of nodes",
+			changeBudget:   &v1beta1.ChangeBudget{MaxUnavailable: intOrString(intstr.FromString("30%")), MaxSurge: intOrString(intstr.FromString("10%"))},
+			want:           &ChangeBudget{MaxUnavailable: 2, MaxSurge: 1},
+			wantAnnotation: true,
+		},
+		{
+			name:           "unset values are defaulted",
+			changeBudget:   &v1beta1.ChangeBudget{MaxUnavailable: intOrString(intstr.FromString("50%"))},
+			want:           &ChangeBudget{MaxUnavailable: 4, MaxSurge: DefaultChangeBudget.MaxSurge},
+			wantAnnotation: true,
+		},
+		{
+			name:         "invalid percentage",
+			changeBudget: &v1beta1.ChangeBudget{MaxUnavailable: intOrString(intstr.FromString("half"))},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := hubFixture(t, tt.changeBudget)
+			var es Elasticsearch
+			err := es.ConvertFrom(&hub)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, es.Spec.UpdateStrategy.ChangeBudget)
+			_, hasAnnotation := es.Annotations[ChangeBudgetAnnotation]
+			require.Equal(t, tt.wantAnnotation, hasAnnotation)
+
+			// the original change budget is restored on conversion back to v1beta1
+			var converted v1beta1.Elasticsearch
+			require.NoError(t, es.ConvertTo(&converted))
+			require.Equal(t, hub, converted)
+		})
+	}
+}
+
+func TestElasticsearch_ResolveChangeBudget(t *testing.T) {
+	hub := hubFixture(t, &v1beta1.ChangeBudget{
+		MaxUnavailable: intOrString(intstr.FromString("30%")),
+		MaxSurge:       intOrString(intstr.FromString("10%")),
+	})
+	var es Elasticsearch
+	require.NoError(t, es.ConvertFrom(&hub))
+	resolved, err := es.ResolveChangeBudget()
+	require.NoError(t, err)
+	require.Equal(t, ChangeBudget{MaxUnavailable: 2, MaxSurge: 1}, resolved)
+
+	// the percentages follow the number of nodes, even when updated in v1alpha1
+	es.Spec.Nodes[1].NodeCount = 17
+	resolved, err = es.ResolveChangeBudget()
+	require.NoError(t, err)
+	require.Equal(t, ChangeBudget{MaxUnavailable: 6, MaxSurge: 2}, resolved)
+
+	// without annotation, the v1alpha1 change budget is used
+	require.Equal(t, ChangeBudget{MaxUnavailable: 1, MaxSurge: 2}, mustResolveChangeBudget(t, alphaFixture()))
+	withoutChangeBudget := alphaFixture()
+	withoutChangeBudget.Spec.UpdateStrategy.ChangeBudget = nil
+	require.Equal(t, DefaultChangeBudget, mustResolveChangeBudget(t, withoutChangeBudget))
+
+	invalid := alphaFixture()
+	invalid.Annotations[ChangeBudgetAnnotation] = "{"
+	_, err = invalid.ResolveChangeBudget()
+	require.Error(t, err)
+}
+
+func mustResolveChangeBudget(t *testing.T, es Elasticsearch) ChangeBudget {
+	resolved, err := es.ResolveChangeBudget()
+	require.NoError(t, err)
+	return resolved
+}
@@ -53,6 +53,20 @@ type ElasticsearchSpec struct {
 	// entries and the `path` field to change the target path of a secret entry key.
 	// The secret must exist in the same namespace as the Elasticsearch resource.
 	SecureSettings []commonv1alpha1.SecretSource `json:"secureSettings,omitempty"`
+
+	// Monitoring configures the collection of monitoring data for this cluster.
+	// +optional
+	Monitoring MonitoringSpec `json:"monitoring,omitempty"`
+}
+
+// MonitoringSpec configures the collection of monitoring data.
+type MonitoringSpec struct {
+	// ElasticsearchRef references the Elasticsearch cluster monitoring data is shipped to.
+	// It is usually a dedicated monitoring cluster.
+	// The operator creates a user in the referenced cluster and configures a monitoring exporter
+	// with its credentials and the cluster CA.
+	// +optional
+	ElasticsearchRef commonv1alpha1.ObjectSelector `json:"elasticsearchRef,omitempty"`
 }
 
 // NodeCount returns the total number of nodes of the Elasticsearch cluster
@@ -221,6 +235,10 @@ type ElasticsearchStatus struct {
 	MasterNode      string                          `json:"masterNode,omitempty"`
 	ExternalService string                          `json:"service,omitempty"`
 	ZenDiscovery    ZenDiscoveryStatus              `json:"zenDiscovery,omitempty"`
+	// ActivePrimaryShards is the number of active primary shards last reported by Elasticsearch.
+	ActivePrimaryShards int `json:"activePrimaryShards,omitempty"`
+	// MonitoringAssociation is the status of the association with the monitoring cluster.
+	MonitoringAssociation commonv1alpha1.AssociationStatus `json:"monitoringAssociation,omitempty"`
 }
 
 type ZenDiscoveryStatus struct {
@@ -268,6 +286,17 @@ func (e Elasticsearch) Kind() string {
 	return Kind
 }
 
+// ElasticsearchRef returns the reference to the Elasticsearch cluster monitoring data is shipped to.
+// It makes an Elasticsearch cluster associated with its monitoring cluster.
+func (e *Elasticsearch) ElasticsearchRef() commonv1alpha1.ObjectSelector {
+	return e.Spec.Monitoring.ElasticsearchRef
+}
+
+// ElasticsearchAuth returns the authentication to the monitoring cluster, always managed by the operator.
+func (e *Elasticsearch) ElasticsearchAuth() commonv1alpha1.ElasticsearchAuth {
+	return commonv1alpha1.ElasticsearchAuth{}
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // ElasticsearchList contains a list of Elasticsearch clusters
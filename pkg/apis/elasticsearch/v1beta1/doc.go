@@ -0,0 +1,11 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Package v1beta1 contains API Schema definitions for the elasticsearch v1beta1 API group
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen=package,register
+// +k8s:conversion-gen=github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch
+// +k8s:defaulter-gen=TypeMeta
+// +groupName=elasticsearch.k8s.elastic.co
+package v1beta1
@@ -0,0 +1,114 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1beta1"
+)
+
+var _ commonv1alpha1.Convertible = &Kibana{}
+
+// ConvertTo converts this Kibana to the v1beta1 hub version.
+func (k *Kibana) ConvertTo(hub commonv1alpha1.Hub) error {
+	dst, ok := hub.(*v1beta1.Kibana)
+	if !ok {
+		return fmt.Errorf("cannot convert Kibana to %T", hub)
+	}
+	src := k.DeepCopy()
+
+	dst.TypeMeta = src.TypeMeta
+	dst.ObjectMeta = src.ObjectMeta
+	dst.SetGroupVersionKind(v1beta1.SchemeGroupVersion.WithKind(Kind))
+	dst.Spec = v1beta1.KibanaSpec{
+		Version:                  src.Spec.Version,
+		Image:                    src.Spec.Image,
+		Count:                    src.Spec.NodeCount,
+		ElasticsearchRef:         src.Spec.ElasticsearchRef,
+		ExternalElasticsearchRef: src.Spec.ExternalElasticsearchRef,
+		Elasticsearch: v1beta1.BackendElasticsearch{
+			URL:                    src.Spec.Elasticsearch.URL,
+			Auth:                   src.Spec.Elasticsearch.Auth,
+			CertificateAuthorities: src.Spec.Elasticsearch.CertificateAuthorities,
+		},
+		Config:         src.Spec.Config,
+		HTTP:           src.Spec.HTTP,
+		PodTemplate:    src.Spec.PodTemplate,
+		SecureSettings: src.Spec.SecureSettings,
+	}
+	for _, so := range src.Spec.SavedObjects {
+		dst.Spec.SavedObjects = append(dst.Spec.SavedObjects, v1beta1.SavedObjectsSource(so))
+	}
+	dst.Status = v1beta1.KibanaStatus{
+		ReconcilerStatus:        src.Status.ReconcilerStatus,
+		Health:                  v1beta1.KibanaHealth(src.Status.Health),
+		AssociationStatus:       src.Status.AssociationStatus,
+		ElasticsearchConnection: v1beta1.ElasticsearchConnectionState(src.Status.ElasticsearchConnection),
+		Selector:                src.Status.Selector,
+	}
+	for _, p := range src.Status.Plugins {
+		dst.Status.Plugins = append(dst.Status.Plugins, v1beta1.KibanaPluginStatus{
+			ID:      p.ID,
+			State:   v1beta1.KibanaHealth(p.State),
+			Message: p.Message,
+		})
+	}
+	for _, so := range src.Status.SavedObjects {
+		dst.Status.SavedObjects = append(dst.Status.SavedObjects, v1beta1.SavedObjectsImportStatus(so))
+	}
+	return nil
+}
+
+// ConvertFrom converts the given v1beta1 hub version to this Kibana.
+func (k *Kibana) ConvertFrom(hub commonv1alpha1.Hub) error {
+	in, ok := hub.(*v1beta1.Kibana)
+	if !ok {
+		return fmt.Errorf("cannot convert %T to Kibana", hub)
+	}
+	src := in.DeepCopy()
+
+	k.TypeMeta = src.TypeMeta
+	k.ObjectMeta = src.ObjectMeta
+	k.SetGroupVersionKind(SchemeGroupVersion.WithKind(Kind))
+	k.Spec = KibanaSpec{
+		Version:                  src.Spec.Version,
+		Image:                    src.Spec.Image,
+		NodeCount:                src.Spec.Count,
+		ElasticsearchRef:         src.Spec.ElasticsearchRef,
+		ExternalElasticsearchRef: src.Spec.ExternalElasticsearchRef,
+		Elasticsearch: BackendElasticsearch{
+			URL:                    src.Spec.Elasticsearch.URL,
+			Auth:                   src.Spec.Elasticsearch.Auth,
+			CertificateAuthorities: src.Spec.Elasticsearch.CertificateAuthorities,
+		},
+		Config:         src.Spec.Config,
+		HTTP:           src.Spec.HTTP,
+		PodTemplate:    src.Spec.PodTemplate,
+		SecureSettings: src.Spec.SecureSettings,
+	}
+	for _, so := range src.Spec.SavedObjects {
+		k.Spec.SavedObjects = append(k.Spec.SavedObjects, SavedObjectsSource(so))
+	}
+	k.Status = KibanaStatus{
+		ReconcilerStatus:        src.Status.ReconcilerStatus,
+		Health:                  KibanaHealth(src.Status.Health),
+		AssociationStatus:       src.Status.AssociationStatus,
+		ElasticsearchConnection: ElasticsearchConnectionState(src.Status.ElasticsearchConnection),
+		Selector:                src.Status.Selector,
+	}
+	for _, p := range src.Status.Plugins {
+		k.Status.Plugins = append(k.Status.Plugins, KibanaPluginStatus{
+			ID:      p.ID,
+			State:   KibanaHealth(p.State),
+			Message: p.Message,
+		})
+	}
+	for _, so := range src.Status.SavedObjects {
+		k.Status.SavedObjects = append(k.Status.SavedObjects, SavedObjectsImportStatus(so))
+	}
+	return nil
+}
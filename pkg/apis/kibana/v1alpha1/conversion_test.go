@@ -0,0 +1,72 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	"testing"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1beta1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestKibana_RoundTrip(t *testing.T) {
+	kb := Kibana{
+		TypeMeta:   metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: Kind},
+		ObjectMeta: metav1.ObjectMeta{Name: "kb", Namespace: "ns", Labels: map[string]string{"a": "b"}},
+		Spec: KibanaSpec{
+			Version:          "7.2.0",
+			Image:            "my-image",
+			NodeCount:        2,
+			ElasticsearchRef: commonv1alpha1.ObjectSelector{Name: "es"},
+			ExternalElasticsearchRef: &commonv1alpha1.ExternalElasticsearchRef{
+				URL:                    "https://legacy-es:9200",
+				AdminSecretName:        "legacy-es-admin",
+				CertificateAuthorities: commonv1alpha1.SecretRef{SecretName: "legacy-es-ca"},
+			},
+			Elasticsearch: BackendElasticsearch{
+				URL: "https://es:9200",
+				Auth: commonv1alpha1.ElasticsearchAuth{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "es-user"},
+					Key:                  "kibana",
+				}},
+				CertificateAuthorities: commonv1alpha1.SecretRef{SecretName: "es-ca"},
+			},
+			Config:         &commonv1alpha1.Config{Data: map[string]interface{}{"logging.verbose": true}},
+			PodTemplate:    corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"c": "d"}}},
+			SecureSettings: []commonv1alpha1.SecretSource{{SecretName: "secure-settings"}},
+			SavedObjects:   []SavedObjectsSource{{ConfigMapName: "dashboards", Space: "marketing", Overwrite: true}},
+		},
+		Status: KibanaStatus{
+			ReconcilerStatus:        commonv1alpha1.ReconcilerStatus{AvailableNodes: 2},
+			Health:                  KibanaGreen,
+			AssociationStatus:       commonv1alpha1.AssociationEstablished,
+			ElasticsearchConnection: ElasticsearchConnected,
+			Plugins: []KibanaPluginStatus{
+				{ID: "plugin:reporting@7.2.0", State: KibanaYellow, Message: "Waiting for Elasticsearch"},
+			},
+			SavedObjects: []SavedObjectsImportStatus{
+				{ConfigMapName: "dashboards", Space: "marketing", Hash: "1234", Success: true, SuccessCount: 3},
+			},
+			Selector: "kibana.k8s.elastic.co/name=kb",
+		},
+	}
+
+	var hub v1beta1.Kibana
+	require.NoError(t, kb.ConvertTo(&hub))
+	require.Equal(t, v1beta1.SchemeGroupVersion.String(), hub.APIVersion)
+	require.Equal(t, int32(2), hub.Spec.Count)
+	require.Equal(t, kb.Spec.Elasticsearch.Auth, hub.Spec.Elasticsearch.Auth)
+
+	var converted Kibana
+	require.NoError(t, converted.ConvertFrom(&hub))
+	require.Equal(t, kb, converted)
+
+	var convertedHub v1beta1.Kibana
+	require.NoError(t, converted.ConvertTo(&convertedHub))
+	require.Equal(t, hub, convertedHub)
+}
@@ -31,6 +31,12 @@ type KibanaSpec struct {
 	// If the namespace is not specified, the current resource namespace will be used.
 	ElasticsearchRef commonv1alpha1.ObjectSelector `json:"elasticsearchRef,omitempty"`
 
+	// ExternalElasticsearchRef references an Elasticsearch cluster that is not managed by the operator. The operator
+	// creates the Kibana user in that cluster with the given admin credentials. It cannot be used together with
+	// ElasticsearchRef.
+	// +optional
+	ExternalElasticsearchRef *commonv1alpha1.ExternalElasticsearchRef `json:"externalElasticsearchRef,omitempty"`
+
 	// Elasticsearch configures how Kibana connects to Elasticsearch
 	// +optional
 	Elasticsearch BackendElasticsearch `json:"elasticsearch,omitempty"`
@@ -55,6 +61,13 @@ type KibanaSpec struct {
 	// entries and the `path` field to change the target path of a secret entry key.
 	// The secret must exist in the same namespace as the Kibana resource.
 	SecureSettings []commonv1alpha1.SecretSource `json:"secureSettings,omitempty"`
+
+	// SavedObjects references ConfigMaps containing saved objects, such as dashboards, visualizations or index
+	// patterns, exported from Kibana in NDJSON format. They are imported into Kibana through its saved objects API.
+	// Without a reference to an Elasticsearch cluster, they are imported with the credentials of the Elasticsearch
+	// backend, which must be granted the Kibana privileges to manage saved objects.
+	// +optional
+	SavedObjects []SavedObjectsSource `json:"savedObjects,omitempty"`
 }
 
 // BackendElasticsearch contains configuration for an Elasticsearch backend for Kibana
@@ -74,14 +87,32 @@ func (b BackendElasticsearch) IsConfigured() bool {
 	return b.URL != "" && b.Auth.IsConfigured() && b.CertificateAuthorities.SecretName != ""
 }
 
+// SavedObjectsSource references a ConfigMap containing saved objects to import into Kibana.
+type SavedObjectsSource struct {
+	// ConfigMapName is the name of a ConfigMap in the Kibana namespace. Each of its entries contains saved objects
+	// in NDJSON format, as exported by Kibana.
+	ConfigMapName string `json:"configMapName"`
+	// Space is the ID of the Kibana space the saved objects are imported into. The space is created if it does not
+	// exist. Defaults to the default space.
+	// +optional
+	Space string `json:"space,omitempty"`
+	// Overwrite replaces existing saved objects with the same ID. Conflicting saved objects are not imported otherwise.
+	// +optional
+	Overwrite bool `json:"overwrite,omitempty"`
+}
+
 // KibanaHealth expresses the status of the Kibana instances.
 type KibanaHealth string
 
 const (
-	// KibanaRed means no instance is currently available.
+	// KibanaRed means no instance is currently available, or Kibana reports a red status.
 	KibanaRed KibanaHealth = "red"
-	// KibanaGreen means at least one instance is available.
+	// KibanaYellow means Kibana is available but reports a yellow status.
+	KibanaYellow KibanaHealth = "yellow"
+	// KibanaGreen means at least one instance is available and Kibana reports a green status.
 	KibanaGreen KibanaHealth = "green"
+	// KibanaUnknown means the Kibana instances have not been observed yet.
+	KibanaUnknown KibanaHealth = "unknown"
 )
 
 // KibanaStatus defines the observed state of Kibana
@@ -89,11 +120,54 @@ type KibanaStatus struct {
 	commonv1alpha1.ReconcilerStatus
 	Health            KibanaHealth                     `json:"health,omitempty"`
 	AssociationStatus commonv1alpha1.AssociationStatus `json:"associationStatus,omitempty"`
+	// ElasticsearchConnection is the state of the connection from Kibana to Elasticsearch, as reported by Kibana.
+	ElasticsearchConnection ElasticsearchConnectionState `json:"elasticsearchConnection,omitempty"`
+	// Plugins lists the Kibana plugins reporting a red or yellow state.
+	Plugins []KibanaPluginStatus `json:"plugins,omitempty"`
+	// SavedObjects reports the import of the saved objects referenced in the specification.
+	SavedObjects []SavedObjectsImportStatus `json:"savedObjects,omitempty"`
+	// Selector is the label selector of the Kibana pods, used by the scale subresource.
+	Selector string `json:"selector,omitempty"`
+}
+
+// ElasticsearchConnectionState expresses the state of the connection from Kibana to Elasticsearch.
+type ElasticsearchConnectionState string
+
+const (
+	// ElasticsearchConnected means Kibana reports it is connected to Elasticsearch.
+	ElasticsearchConnected ElasticsearchConnectionState = "Connected"
+	// ElasticsearchDisconnected means Kibana reports it cannot use Elasticsearch.
+	ElasticsearchDisconnected ElasticsearchConnectionState = "Disconnected"
+	// ElasticsearchConnectionUnknown means the Kibana status API could not be reached.
+	ElasticsearchConnectionUnknown ElasticsearchConnectionState = "Unknown"
+)
+
+// KibanaPluginStatus is the state of a Kibana plugin as reported by the Kibana status API.
+type KibanaPluginStatus struct {
+	// ID is the identifier of the plugin, such as "plugin:elasticsearch@7.2.0".
+	ID      string       `json:"id"`
+	State   KibanaHealth `json:"state"`
+	Message string       `json:"message,omitempty"`
+}
+
+// SavedObjectsImportStatus is the result of the import of the saved objects from a ConfigMap.
+type SavedObjectsImportStatus struct {
+	ConfigMapName string `json:"configMapName"`
+	Space         string `json:"space,omitempty"`
+	// Hash of the imported saved objects and import options. The saved objects are imported again when it changes.
+	Hash string `json:"hash,omitempty"`
+	// Success is true if all the saved objects were imported.
+	Success bool `json:"success"`
+	// SuccessCount is the number of imported saved objects.
+	SuccessCount int `json:"successCount,omitempty"`
+	// Message describes why the import failed.
+	Message string `json:"message,omitempty"`
 }
 
 // IsDegraded returns true if the current status is worse than the previous.
+// An unknown health, for example while the operator restarts, is not considered worse.
 func (ks KibanaStatus) IsDegraded(prev KibanaStatus) bool {
-	return prev.Health == KibanaGreen && ks.Health != KibanaGreen
+	return prev.Health == KibanaGreen && ks.Health != KibanaGreen && ks.Health != KibanaUnknown
 }
 
 // IsMarkedForDeletion returns true if the Kibana is going to be deleted
@@ -127,6 +201,7 @@ func (k *Kibana) Kind() string {
 // +kubebuilder:categories=elastic
 // +kubebuilder:resource:shortName=kb
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.nodeCount,statuspath=.status.availableNodes,selectorpath=.status.selector
 // +kubebuilder:printcolumn:name="health",type="string",JSONPath=".status.health"
 // +kubebuilder:printcolumn:name="nodes",type="integer",JSONPath=".status.availableNodes",description="Available nodes"
 // +kubebuilder:printcolumn:name="version",type="string",JSONPath=".spec.version",description="Kibana version"
@@ -37,7 +37,7 @@ func (in *Kibana) DeepCopyInto(out *Kibana) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -92,10 +92,31 @@ func (in *KibanaList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaPluginStatus) DeepCopyInto(out *KibanaPluginStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaPluginStatus.
+func (in *KibanaPluginStatus) DeepCopy() *KibanaPluginStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaPluginStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KibanaSpec) DeepCopyInto(out *KibanaSpec) {
 	*out = *in
 	out.ElasticsearchRef = in.ElasticsearchRef
+	if in.ExternalElasticsearchRef != nil {
+		in, out := &in.ExternalElasticsearchRef, &out.ExternalElasticsearchRef
+		*out = new(commonv1alpha1.ExternalElasticsearchRef)
+		**out = **in
+	}
 	in.Elasticsearch.DeepCopyInto(&out.Elasticsearch)
 	if in.Config != nil {
 		in, out := &in.Config, &out.Config
@@ -110,6 +131,11 @@ func (in *KibanaSpec) DeepCopyInto(out *KibanaSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SavedObjects != nil {
+		in, out := &in.SavedObjects, &out.SavedObjects
+		*out = make([]SavedObjectsSource, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -127,6 +153,16 @@ func (in *KibanaSpec) DeepCopy() *KibanaSpec {
 func (in *KibanaStatus) DeepCopyInto(out *KibanaStatus) {
 	*out = *in
 	out.ReconcilerStatus = in.ReconcilerStatus
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]KibanaPluginStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.SavedObjects != nil {
+		in, out := &in.SavedObjects, &out.SavedObjects
+		*out = make([]SavedObjectsImportStatus, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -139,3 +175,35 @@ func (in *KibanaStatus) DeepCopy() *KibanaStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SavedObjectsImportStatus) DeepCopyInto(out *SavedObjectsImportStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavedObjectsImportStatus.
+func (in *SavedObjectsImportStatus) DeepCopy() *SavedObjectsImportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SavedObjectsImportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SavedObjectsSource) DeepCopyInto(out *SavedObjectsSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavedObjectsSource.
+func (in *SavedObjectsSource) DeepCopy() *SavedObjectsSource {
+	if in == nil {
+		return nil
+	}
+	out := new(SavedObjectsSource)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,197 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+)
+
+const (
+	KibanaContainerName = "kibana"
+	Kind                = "Kibana"
+)
+
+// KibanaSpec defines the desired state of Kibana
+type KibanaSpec struct {
+	// Version represents the version of Kibana
+	Version string `json:"version,omitempty"`
+
+	// Image represents the docker image that will be used.
+	Image string `json:"image,omitempty"`
+
+	// Count defines how many instances the Kibana deployment must have.
+	Count int32 `json:"count,omitempty"`
+
+	// ElasticsearchRef references an Elasticsearch resource in the Kubernetes cluster.
+	// If the namespace is not specified, the current resource namespace will be used.
+	ElasticsearchRef commonv1alpha1.ObjectSelector `json:"elasticsearchRef,omitempty"`
+
+	// ExternalElasticsearchRef references an Elasticsearch cluster that is not managed by the operator. The operator
+	// creates the Kibana user in that cluster with the given admin credentials. It cannot be used together with
+	// ElasticsearchRef.
+	// +optional
+	ExternalElasticsearchRef *commonv1alpha1.ExternalElasticsearchRef `json:"externalElasticsearchRef,omitempty"`
+
+	// Elasticsearch configures how Kibana connects to Elasticsearch
+	// +optional
+	Elasticsearch BackendElasticsearch `json:"elasticsearch,omitempty"`
+
+	// Config represents Kibana configuration.
+	Config *commonv1alpha1.Config `json:"config,omitempty"`
+
+	// HTTP contains settings for HTTP.
+	HTTP commonv1alpha1.HTTPConfig `json:"http,omitempty"`
+
+	// PodTemplate can be used to propagate configuration to Kibana pods.
+	// This allows specifying custom annotations, labels, environment variables,
+	// affinity, resources, etc. for the pods created from this NodeSpec.
+	// +optional
+	PodTemplate corev1.PodTemplateSpec `json:"podTemplate,omitempty"`
+
+	// SecureSettings references secrets containing secure settings, to be injected
+	// into Kibana keystore on each node.
+	// Each individual key/value entry in the referenced secrets is considered as an
+	// individual secure setting to be injected.
+	// You can use the `entries` and `key` fields to consider only a subset of the secret
+	// entries and the `path` field to change the target path of a secret entry key.
+	// The secret must exist in the same namespace as the Kibana resource.
+	SecureSettings []commonv1alpha1.SecretSource `json:"secureSettings,omitempty"`
+
+	// SavedObjects references ConfigMaps containing saved objects, such as dashboards, visualizations or index
+	// patterns, exported from Kibana in NDJSON format. They are imported into Kibana through its saved objects API.
+	// Without a reference to an Elasticsearch cluster, they are imported with the credentials of the Elasticsearch
+	// backend, which must be granted the Kibana privileges to manage saved objects.
+	// +optional
+	SavedObjects []SavedObjectsSource `json:"savedObjects,omitempty"`
+}
+
+// BackendElasticsearch contains configuration for an Elasticsearch backend for Kibana
+type BackendElasticsearch struct {
+	// ElasticsearchURL is the URL to the target Elasticsearch
+	URL string `json:"url"`
+
+	// Auth configures authentication for Kibana to use.
+	Auth commonv1alpha1.ElasticsearchAuth `json:"auth,omitempty"`
+
+	// CertificateAuthorities names a secret that contains a CA file entry to use.
+	CertificateAuthorities commonv1alpha1.SecretRef `json:"certificateAuthorities,omitempty"`
+}
+
+// SavedObjectsSource references a ConfigMap containing saved objects to import into Kibana.
+type SavedObjectsSource struct {
+	// ConfigMapName is the name of a ConfigMap in the Kibana namespace. Each of its entries contains saved objects
+	// in NDJSON format, as exported by Kibana.
+	ConfigMapName string `json:"configMapName"`
+	// Space is the ID of the Kibana space the saved objects are imported into. The space is created if it does not
+	// exist. Defaults to the default space.
+	// +optional
+	Space string `json:"space,omitempty"`
+	// Overwrite replaces existing saved objects with the same ID. Conflicting saved objects are not imported otherwise.
+	// +optional
+	Overwrite bool `json:"overwrite,omitempty"`
+}
+
+// KibanaHealth expresses the status of the Kibana instances.
+type KibanaHealth string
+
+const (
+	// KibanaRed means no instance is currently available, or Kibana reports a red status.
+	KibanaRed KibanaHealth = "red"
+	// KibanaYellow means Kibana is available but reports a yellow status.
+	KibanaYellow KibanaHealth = "yellow"
+	// KibanaGreen means at least one instance is available and Kibana reports a green status.
+	KibanaGreen KibanaHealth = "green"
+	// KibanaUnknown means the Kibana instances have not been observed yet.
+	KibanaUnknown KibanaHealth = "unknown"
+)
+
+// KibanaStatus defines the observed state of Kibana
+type KibanaStatus struct {
+	commonv1alpha1.ReconcilerStatus
+	Health            KibanaHealth                     `json:"health,omitempty"`
+	AssociationStatus commonv1alpha1.AssociationStatus `json:"associationStatus,omitempty"`
+	// ElasticsearchConnection is the state of the connection from Kibana to Elasticsearch, as reported by Kibana.
+	ElasticsearchConnection ElasticsearchConnectionState `json:"elasticsearchConnection,omitempty"`
+	// Plugins lists the Kibana plugins reporting a red or yellow state.
+	Plugins []KibanaPluginStatus `json:"plugins,omitempty"`
+	// SavedObjects reports the import of the saved objects referenced in the specification.
+	SavedObjects []SavedObjectsImportStatus `json:"savedObjects,omitempty"`
+	// Selector is the label selector of the Kibana pods, used by the scale subresource.
+	Selector string `json:"selector,omitempty"`
+}
+
+// ElasticsearchConnectionState expresses the state of the connection from Kibana to Elasticsearch.
+type ElasticsearchConnectionState string
+
+const (
+	// ElasticsearchConnected means Kibana reports it is connected to Elasticsearch.
+	ElasticsearchConnected ElasticsearchConnectionState = "Connected"
+	// ElasticsearchDisconnected means Kibana reports it cannot use Elasticsearch.
+	ElasticsearchDisconnected ElasticsearchConnectionState = "Disconnected"
+	// ElasticsearchConnectionUnknown means the Kibana status API could not be reached.
+	ElasticsearchConnectionUnknown ElasticsearchConnectionState = "Unknown"
+)
+
+// KibanaPluginStatus is the state of a Kibana plugin as reported by the Kibana status API.
+type KibanaPluginStatus struct {
+	// ID is the identifier of the plugin, such as "plugin:elasticsearch@7.2.0".
+	ID      string       `json:"id"`
+	State   KibanaHealth `json:"state"`
+	Message string       `json:"message,omitempty"`
+}
+
+// SavedObjectsImportStatus is the result of the import of the saved objects from a ConfigMap.
+type SavedObjectsImportStatus struct {
+	ConfigMapName string `json:"configMapName"`
+	Space         string `json:"space,omitempty"`
+	// Hash of the imported saved objects and import options. The saved objects are imported again when it changes.
+	Hash string `json:"hash,omitempty"`
+	// Success is true if all the saved objects were imported.
+	Success bool `json:"success"`
+	// SuccessCount is the number of imported saved objects.
+	SuccessCount int `json:"successCount,omitempty"`
+	// Message describes why the import failed.
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Kibana is the Schema for the kibanas API
+// +k8s:openapi-gen=true
+// +kubebuilder:categories=elastic
+// +kubebuilder:resource:shortName=kb
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.count,statuspath=.status.availableNodes,selectorpath=.status.selector
+// +kubebuilder:printcolumn:name="health",type="string",JSONPath=".status.health"
+// +kubebuilder:printcolumn:name="nodes",type="integer",JSONPath=".status.availableNodes",description="Available nodes"
+// +kubebuilder:printcolumn:name="version",type="string",JSONPath=".spec.version",description="Kibana version"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+type Kibana struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KibanaSpec   `json:"spec,omitempty"`
+	Status KibanaStatus `json:"status,omitempty"`
+}
+
+// Hub marks this version as the one other versions of the Kibana API are converted to and from.
+func (*Kibana) Hub() {}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KibanaList contains a list of Kibana
+type KibanaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Kibana `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Kibana{}, &KibanaList{})
+}
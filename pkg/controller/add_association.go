@@ -5,10 +5,12 @@
 package controller
 
 import (
+	"github.com/elastic/cloud-on-k8s/pkg/controller/association"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
-	"github.com/elastic/cloud-on-k8s/pkg/controller/kibanaassociation"
 )
 
 func init() {
-	Register(operator.NamespaceOperator, kibanaassociation.Add)
+	Register(operator.NamespaceOperator, association.AddKibanaES)
+	Register(operator.NamespaceOperator, association.AddApmES)
+	Register(operator.NamespaceOperator, association.AddApmKibana)
 }
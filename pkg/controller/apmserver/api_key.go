@@ -0,0 +1,355 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package apmserver
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"time"
+
+	apmv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/apmserver/config"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/apmserver/labels"
+	apmname "github.com/elastic/cloud-on-k8s/pkg/controller/apmserver/name"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/finalizer"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// APIKeyKey is the key of the API key agents send to authenticate, encoded as expected in the Authorization
+	// header: the base64 encoding of the API key ID and value joined by a colon.
+	APIKeyKey = "api-key"
+	// APIKeyIDKey is the key of the ID of the API key in Elasticsearch.
+	APIKeyIDKey = "api-key-id"
+	// PreviousAPIKeyIDKey is the key of the ID of the API key replaced by a rotation, which remains valid for the
+	// grace period.
+	PreviousAPIKeyIDKey = "previous-api-key-id"
+
+	// RotateAPIKeyAnnotation requests a rotation of the API key whenever its value changes, for example to the
+	// current date.
+	RotateAPIKeyAnnotation = "apm.k8s.elastic.co/rotate-api-key"
+	// APIKeyGracePeriodAnnotation overrides the duration, such as "30m", during which the previous API key remains
+	// valid after a rotation was requested.
+	APIKeyGracePeriodAnnotation = "apm.k8s.elastic.co/api-key-grace-period"
+
+	// apiKeyRotationAnnotation records on the secret the last rotation request handled.
+	apiKeyRotationAnnotation = "apm.k8s.elastic.co/api-key-rotation"
+	// apiKeyRotationTimeAnnotation records on the secret when the previous API key was replaced.
+	apiKeyRotationTimeAnnotation = "apm.k8s.elastic.co/api-key-rotation-time"
+
+	// apiKeyFinalizerName is the name of the finalizer invalidating the API keys of a deleted APM Server.
+	apiKeyFinalizerName = "api-keys.finalizers.apm.k8s.elastic.co"
+)
+
+// infoRequestVersion is the version used to build the client retrieving the version of the Elasticsearch output, the
+// request being the same for all supported versions.
+var infoRequestVersion = version.MustParse("6.8.0")
+
+// apiKeyAnnotations are the annotations of the API key secret managed by the operator.
+var apiKeyAnnotations = []string{apiKeyRotationAnnotation, apiKeyRotationTimeAnnotation}
+
+// agentRoleDescriptors restrict the API keys of the agents to sending events and source maps, and reading the agent
+// configuration.
+var agentRoleDescriptors = map[string]esclient.Role{
+	"apm-agent": {
+		Applications: []esclient.ApplicationPrivileges{
+			{
+				Application: "apm",
+				Privileges:  []string{"event:write", "sourcemap:write", "config_agent:read"},
+				Resources:   []string{"*"},
+			},
+		},
+	},
+}
+
+// apiKeyName is the name of the API keys created for the agents of the given APM Server. It includes the namespace
+// since API keys are invalidated by name when the APM Server is deleted.
+func apiKeyName(as apmv1alpha1.ApmServer) string {
+	return fmt.Sprintf("%s/%s-apm-agents", as.Namespace, as.Name)
+}
+
+// apiKeyState is the content of the secret holding the API key.
+type apiKeyState struct {
+	Data        map[string][]byte
+	Annotations map[string]string
+	// RequeueAfter is the delay before the previous API key should be invalidated, if a rotation is in progress.
+	RequeueAfter time.Duration
+}
+
+// nextAPIKeyState computes the expected content of the API key secret, given its current data and annotations, and
+// creates or invalidates the API keys in Elasticsearch accordingly.
+//
+// Unlike secret tokens, several API keys can be valid at the same time: when a rotation is requested, a new API key
+// is created and published right away, while the previous one remains valid for the grace period. Once the grace
+// period is over, the previous API key is invalidated. The APM Server instances are not restarted.
+func nextAPIKeyState(
+	esClient esclient.Client,
+	as apmv1alpha1.ApmServer,
+	data map[string][]byte,
+	annotations map[string]string,
+	now time.Time,
+) (apiKeyState, error) {
+	state := apiKeyState{
+		Data:        map[string][]byte{},
+		Annotations: map[string]string{},
+	}
+	for _, key := range []string{APIKeyKey, APIKeyIDKey, PreviousAPIKeyIDKey} {
+		if value, exists := data[key]; exists {
+			state.Data[key] = value
+		}
+	}
+	for _, key := range apiKeyAnnotations {
+		if value, exists := annotations[key]; exists {
+			state.Annotations[key] = value
+		}
+	}
+
+	requested := as.Annotations[RotateAPIKeyAnnotation]
+	currentID, hasCurrent := state.Data[APIKeyIDKey]
+	if !hasCurrent || (requested != "" && requested != state.Annotations[apiKeyRotationAnnotation]) {
+		if previousID, rotating := state.Data[PreviousAPIKeyIDKey]; rotating && hasCurrent {
+			// a new rotation ends the grace period of the API key replaced by the previous one
+			if err := invalidateAPIKeys(esClient, esclient.InvalidateAPIKeysRequest{ID: string(previousID)}); err != nil {
+				return state, err
+			}
+			delete(state.Data, PreviousAPIKeyIDKey)
+		}
+		apiKey, err := createAPIKey(esClient, apiKeyName(as))
+		if err != nil {
+			return state, err
+		}
+		state.Data[APIKeyKey] = []byte(base64.StdEncoding.EncodeToString([]byte(apiKey.ID + ":" + apiKey.APIKey)))
+		state.Data[APIKeyIDKey] = []byte(apiKey.ID)
+		if hasCurrent {
+			state.Data[PreviousAPIKeyIDKey] = currentID
+			state.Annotations[apiKeyRotationTimeAnnotation] = now.Format(time.RFC3339)
+		}
+		if requested != "" {
+			state.Annotations[apiKeyRotationAnnotation] = requested
+		}
+	}
+
+	previousID, rotating := state.Data[PreviousAPIKeyIDKey]
+	if !rotating {
+		return state, nil
+	}
+	rotationTime, err := time.Parse(time.RFC3339, state.Annotations[apiKeyRotationTimeAnnotation])
+	if err != nil {
+		// the rotation time was tampered with, consider the grace period over
+		rotationTime = time.Time{}
+	}
+	remaining := rotationTime.Add(gracePeriod(as.Annotations, APIKeyGracePeriodAnnotation)).Sub(now)
+	if remaining > 0 {
+		state.RequeueAfter = remaining
+		return state, nil
+	}
+	// the grace period is over: the previous API key is invalidated
+	if err := invalidateAPIKeys(esClient, esclient.InvalidateAPIKeysRequest{ID: string(previousID)}); err != nil {
+		return state, err
+	}
+	delete(state.Data, PreviousAPIKeyIDKey)
+	delete(state.Annotations, apiKeyRotationTimeAnnotation)
+	return state, nil
+}
+
+func createAPIKey(esClient esclient.Client, name string) (esclient.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+	defer cancel()
+	return esClient.CreateAPIKey(ctx, esclient.APIKeyRequest{Name: name, RoleDescriptors: agentRoleDescriptors})
+}
+
+// invalidateAPIKeys invalidates the API keys matching the given request, which may already be gone.
+func invalidateAPIKeys(esClient esclient.Client, request esclient.InvalidateAPIKeysRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+	defer cancel()
+	if err := esClient.InvalidateAPIKeys(ctx, request); err != nil && !esclient.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// apiKeysManaged returns true if the operator manages the API keys of the agents of the given APM Server: API key
+// authentication must be enabled, and the API keys are created in the Elasticsearch output.
+func apiKeysManaged(as apmv1alpha1.ApmServer) (bool, error) {
+	if !as.Spec.Elasticsearch.IsConfigured() {
+		return false, nil
+	}
+	return config.APIKeyEnabled(as.Spec.Config)
+}
+
+// reconcileAPIKey reconciles the secret holding the API key of the agents, rotating the API key if requested, or
+// invalidates the API keys and deletes the secret if they are not managed anymore.
+// It returns the delay before the next step of an ongoing rotation, if any.
+func (r *ReconcileApmServer) reconcileAPIKey(as *apmv1alpha1.ApmServer) (time.Duration, error) {
+	key := types.NamespacedName{Namespace: as.Namespace, Name: apmname.APIKey(as.Name)}
+	var existing corev1.Secret
+	if err := r.Get(key, &existing); err != nil && !errors.IsNotFound(err) {
+		return 0, err
+	}
+	managed, err := apiKeysManaged(*as)
+	if err != nil {
+		return 0, err
+	}
+	if !managed {
+		if existing.Name == "" {
+			return 0, nil
+		}
+		if as.Spec.Elasticsearch.IsConfigured() {
+			if err := r.invalidateAllAPIKeys(*as); err != nil {
+				return 0, err
+			}
+		} else {
+			log.Info("Cannot invalidate the API keys of the apm server without Elasticsearch output, they must be invalidated manually", "namespace", as.Namespace, "as_name", as.Name, "api_key_name", apiKeyName(*as))
+		}
+		log.Info("Deleting apm server API key secret", "namespace", existing.Namespace, "secret_name", existing.Name, "as_name", as.Name)
+		if err := r.Delete(&existing); err != nil && !errors.IsNotFound(err) {
+			return 0, err
+		}
+		return 0, nil
+	}
+
+	esClient, err := r.newAPIKeyESClient(*as)
+	if err != nil {
+		return 0, err
+	}
+	defer esClient.Close()
+	keyState, err := nextAPIKeyState(esClient, *as, existing.Data, existing.Annotations, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if _, rotating := keyState.Data[PreviousAPIKeyIDKey]; rotating && !reflect.DeepEqual(keyState.Data[APIKeyIDKey], existing.Data[APIKeyIDKey]) {
+		log.Info("Rotating apm server API key", "namespace", as.Namespace, "as_name", as.Name, "grace_period", gracePeriod(as.Annotations, APIKeyGracePeriodAnnotation))
+	}
+
+	expected := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   key.Namespace,
+			Name:        key.Name,
+			Labels:      labels.NewLabels(as.Name),
+			Annotations: keyState.Annotations,
+		},
+		Data: keyState.Data,
+	}
+	reconciled := &corev1.Secret{}
+	return keyState.RequeueAfter, reconciler.ReconcileResource(
+		reconciler.Params{
+			Client: r.Client,
+			Scheme: r.scheme,
+
+			Owner:      as,
+			Expected:   expected,
+			Reconciled: reconciled,
+
+			NeedsUpdate: func() bool {
+				return !reflect.DeepEqual(reconciled.Labels, expected.Labels) ||
+					!reflect.DeepEqual(reconciled.Data, expected.Data) ||
+					!managedAnnotationsMatch(apiKeyAnnotations, expected.Annotations, reconciled.Annotations)
+			},
+			UpdateReconciled: func() {
+				reconciled.Labels = expected.Labels
+				reconciled.Annotations = setManagedAnnotations(apiKeyAnnotations, expected.Annotations, reconciled.Annotations)
+				reconciled.Data = expected.Data
+			},
+			PreCreate: func() {
+				log.Info("Creating apm server API key secret", "namespace", expected.Namespace, "secret_name", expected.Name, "as_name", as.Name)
+			},
+			PreUpdate: func() {
+				log.Info("Updating apm server API key secret", "namespace", expected.Namespace, "secret_name", expected.Name, "as_name", as.Name)
+			},
+		},
+	)
+}
+
+// invalidateAllAPIKeys invalidates all the API keys created for the agents of the given APM Server, including any
+// created by a rotation that could not be recorded in the secret.
+func (r *ReconcileApmServer) invalidateAllAPIKeys(as apmv1alpha1.ApmServer) error {
+	esClient, err := r.newAPIKeyESClient(as)
+	if err != nil {
+		return err
+	}
+	defer esClient.Close()
+	return invalidateAPIKeys(esClient, esclient.InvalidateAPIKeysRequest{Name: apiKeyName(as)})
+}
+
+// newAPIKeyESClient returns a client for the Elasticsearch output of the given APM Server, authenticated with the
+// APM Server credentials, which own the API keys of the agents.
+func (r *ReconcileApmServer) newAPIKeyESClient(as apmv1alpha1.ApmServer) (esclient.Client, error) {
+	if !as.Spec.Elasticsearch.IsConfigured() {
+		return nil, fmt.Errorf("apm server %s/%s has no Elasticsearch output", as.Namespace, as.Name)
+	}
+	username, password, err := association.ElasticsearchAuthSettings(r.Client, &as)
+	if err != nil {
+		return nil, err
+	}
+	var caCerts []*x509.Certificate
+	if secretName := as.Spec.Elasticsearch.SSL.CertificateAuthorities.SecretName; secretName != "" {
+		var caSecret corev1.Secret
+		if err := r.Get(types.NamespacedName{Namespace: as.Namespace, Name: secretName}, &caSecret); err != nil {
+			return nil, err
+		}
+		caCerts, err = certificates.ParsePEMCerts(caSecret.Data[certificates.CertFileName])
+		if err != nil {
+			return nil, err
+		}
+	}
+	// the operator dialer only reaches clusters through their Kubernetes service
+	var dialer net.Dialer
+	if !as.Spec.ExternalElasticsearchRef.IsDefined() {
+		dialer = r.Dialer
+	}
+	url := as.Spec.Elasticsearch.Hosts[0]
+	user := esclient.UserAuth{Name: username, Password: password}
+
+	// the version of the cluster is needed to use the right API, and may differ from the APM Server version while
+	// either of them is upgraded: retrieve it first
+	infoClient := esclient.NewElasticsearchClient(dialer, url, user, infoRequestVersion, caCerts)
+	defer infoClient.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+	defer cancel()
+	info, err := infoClient.GetClusterInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v, err := version.Parse(info.Version.Number)
+	if err != nil {
+		return nil, err
+	}
+	return esclient.NewElasticsearchClient(dialer, url, user, *v, caCerts), nil
+}
+
+// apiKeyFinalizer invalidates the API keys of the agents when the APM Server is deleted. It is best effort: the
+// Elasticsearch cluster may be deleted at the same time, which must not prevent the deletion of the APM Server.
+func (r *ReconcileApmServer) apiKeyFinalizer(as apmv1alpha1.ApmServer) finalizer.Finalizer {
+	return finalizer.Finalizer{
+		Name: apiKeyFinalizerName,
+		Execute: func() error {
+			var secret corev1.Secret
+			err := r.Get(types.NamespacedName{Namespace: as.Namespace, Name: apmname.APIKey(as.Name)}, &secret)
+			if errors.IsNotFound(err) {
+				// no API key was ever created
+				return nil
+			}
+			if err == nil {
+				err = r.invalidateAllAPIKeys(as)
+			}
+			if err != nil {
+				log.Error(err, "Cannot invalidate the API keys of the apm server, they must be invalidated manually", "namespace", as.Namespace, "as_name", as.Name, "api_key_name", apiKeyName(as))
+			}
+			return nil
+		},
+	}
+}
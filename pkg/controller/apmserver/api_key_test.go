@@ -0,0 +1,112 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package apmserver
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	apmv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeAPIKeys records the API key requests sent to Elasticsearch.
+type fakeAPIKeys struct {
+	created     int
+	invalidated []string
+}
+
+func (f *fakeAPIKeys) client(t *testing.T) esclient.Client {
+	return esclient.NewMockClient(version.MustParse("7.6.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_security/api_key", req.URL.Path)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		switch req.Method {
+		case http.MethodPost:
+			require.Contains(t, string(body), `"name":"ns/as-apm-agents"`)
+			f.created++
+			return esclient.NewMockResponse(200, req, fmt.Sprintf(`{"id":"id-%d","name":"ns/as-apm-agents","api_key":"key-%d"}`, f.created, f.created))
+		case http.MethodDelete:
+			f.invalidated = append(f.invalidated, string(body))
+			return esclient.NewMockResponse(200, req, `{}`)
+		}
+		t.Fatalf("unexpected request %s", req.Method)
+		return nil
+	})
+}
+
+func encodedAPIKey(n int) []byte {
+	return []byte(base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("id-%d:key-%d", n, n))))
+}
+
+func Test_nextAPIKeyState(t *testing.T) {
+	now := time.Date(2019, 9, 1, 12, 0, 0, 0, time.UTC)
+	as := apmv1alpha1.ApmServer{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "as"}}
+	keys := &fakeAPIKeys{}
+	esClient := keys.client(t)
+
+	// an API key is created if none exists
+	state, err := nextAPIKeyState(esClient, as, nil, nil, now)
+	require.NoError(t, err)
+	current := map[string][]byte{APIKeyKey: encodedAPIKey(1), APIKeyIDKey: []byte("id-1")}
+	require.Equal(t, current, state.Data)
+	require.Empty(t, state.Annotations)
+
+	// the existing API key is kept
+	state, err = nextAPIKeyState(esClient, as, current, nil, now)
+	require.NoError(t, err)
+	require.Equal(t, current, state.Data)
+	require.Equal(t, 1, keys.created)
+
+	// a rotation publishes a new API key, the previous one remains valid for the grace period
+	as.Annotations = map[string]string{RotateAPIKeyAnnotation: "2019-09-01", APIKeyGracePeriodAnnotation: "30m"}
+	state, err = nextAPIKeyState(esClient, as, current, nil, now)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{
+		APIKeyKey:           encodedAPIKey(2),
+		APIKeyIDKey:         []byte("id-2"),
+		PreviousAPIKeyIDKey: []byte("id-1"),
+	}, state.Data)
+	require.Equal(t, map[string]string{
+		apiKeyRotationAnnotation:     "2019-09-01",
+		apiKeyRotationTimeAnnotation: "2019-09-01T12:00:00Z",
+	}, state.Annotations)
+	require.Equal(t, 30*time.Minute, state.RequeueAfter)
+	require.Empty(t, keys.invalidated)
+
+	// the rotation is not restarted for the same request
+	inProgress, err := nextAPIKeyState(esClient, as, state.Data, state.Annotations, now.Add(10*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, state.Data, inProgress.Data)
+	require.Equal(t, 20*time.Minute, inProgress.RequeueAfter)
+	require.Equal(t, 2, keys.created)
+
+	// the previous API key is invalidated once the grace period is over
+	done, err := nextAPIKeyState(esClient, as, state.Data, state.Annotations, now.Add(30*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{APIKeyKey: encodedAPIKey(2), APIKeyIDKey: []byte("id-2")}, done.Data)
+	require.Equal(t, map[string]string{apiKeyRotationAnnotation: "2019-09-01"}, done.Annotations)
+	require.Equal(t, time.Duration(0), done.RequeueAfter)
+	require.Equal(t, []string{`{"id":"id-1"}`}, keys.invalidated)
+
+	// a new rotation during the grace period invalidates the API key replaced by the previous rotation
+	as.Annotations[RotateAPIKeyAnnotation] = "2019-09-02"
+	again, err := nextAPIKeyState(esClient, as, state.Data, state.Annotations, now.Add(10*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{
+		APIKeyKey:           encodedAPIKey(3),
+		APIKeyIDKey:         []byte("id-3"),
+		PreviousAPIKeyIDKey: []byte("id-2"),
+	}, again.Data)
+	require.Equal(t, 30*time.Minute, again.RequeueAfter)
+	require.Equal(t, []string{`{"id":"id-1"}`, `{"id":"id-1"}`}, keys.invalidated)
+}
@@ -5,6 +5,7 @@
 package apmserver
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"fmt"
 	"path/filepath"
@@ -39,7 +40,6 @@ import (
 	k8slabels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -50,9 +50,10 @@ import (
 )
 
 const (
-	name                    = "apmserver-controller"
-	esCAChecksumLabelName   = "apm.k8s.elastic.co/es-ca-file-checksum"
-	configChecksumLabelName = "apm.k8s.elastic.co/config-file-checksum"
+	name                      = "apmserver-controller"
+	esCAChecksumLabelName     = "apm.k8s.elastic.co/es-ca-file-checksum"
+	kibanaCAChecksumLabelName = "apm.k8s.elastic.co/kibana-ca-file-checksum"
+	configChecksumLabelName   = "apm.k8s.elastic.co/config-file-checksum"
 
 	// ApmBaseDir is the base directory of the APM server
 	ApmBaseDir = "/usr/share/apm-server"
@@ -183,6 +184,8 @@ func (r *ReconcileApmServer) Reconcile(request reconcile.Request) (reconcile.Res
 	defer func() {
 		log.Info("End reconcile iteration", "iteration", currentIteration, "took", time.Since(iterationStartTime), "namespace", request.Namespace, "as_name", request.Name)
 	}()
+	ctx, span := reconciler.StartReconciliation(name, request)
+	defer span.Finish()
 
 	// Fetch the ApmServer resource
 	as := &apmv1alpha1.ApmServer{}
@@ -192,6 +195,7 @@ func (r *ReconcileApmServer) Reconcile(request reconcile.Request) (reconcile.Res
 		if errors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			certificates.DeleteExpirationMetrics(apmv1alpha1.Kind, request.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -212,6 +216,7 @@ func (r *ReconcileApmServer) Reconcile(request reconcile.Request) (reconcile.Res
 	}
 
 	if as.IsMarkedForDeletion() {
+		certificates.DeleteExpirationMetrics(apmv1alpha1.Kind, k8s.ExtractNamespacedName(as))
 		// APM server will be deleted nothing to do other than run finalizers
 		return reconcile.Result{}, nil
 	}
@@ -237,15 +242,21 @@ func (r *ReconcileApmServer) Reconcile(request reconcile.Request) (reconcile.Res
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-	results := apmcerts.Reconcile(r, *as, []corev1.Service{*svc}, r.CACertRotation)
+	_, step := reconciler.StartStep(ctx, "certificates")
+	results := apmcerts.Reconcile(r, *as, []corev1.Service{*svc}, r.Dialer, r.CACertRotation, r.CertKeyParams)
+	step.EndWithResults(results)
 	if results.HasError() {
 		res, err := results.Aggregate()
+		span.SetError(err)
 		k8s.EmitErrorEvent(r.recorder, err, as, events.EventReconciliationError, "Certificate reconciliation error: %v", err)
 		return res, err
 	}
 
+	_, step = reconciler.StartStep(ctx, "deployment")
 	state, err = r.reconcileApmServerDeployment(state, as)
+	step.End(state.Result, err)
 	if err != nil {
+		span.SetError(err)
 		if errors.IsConflict(err) {
 			log.V(1).Info("Conflict while updating status")
 			return reconcile.Result{Requeue: true}, nil
@@ -256,22 +267,50 @@ func (r *ReconcileApmServer) Reconcile(request reconcile.Request) (reconcile.Res
 
 	state.UpdateApmServerExternalService(*svc)
 
-	return r.updateStatus(state)
+	// the API key does not prevent the APM Server from running, its errors are only reported after the status update
+	_, step = reconciler.StartStep(ctx, "api-key")
+	apiKeyRequeueAfter, apiKeyErr := r.reconcileAPIKey(as)
+	step.End(reconcile.Result{RequeueAfter: apiKeyRequeueAfter}, apiKeyErr)
+	if apiKeyErr != nil {
+		span.SetError(apiKeyErr)
+		k8s.EmitErrorEvent(r.recorder, apiKeyErr, as, events.EventReconciliationError, "API key reconciliation error: %v", apiKeyErr)
+	}
+	if apiKeyRequeueAfter > 0 && (state.Result.RequeueAfter == 0 || apiKeyRequeueAfter < state.Result.RequeueAfter) {
+		// invalidate the previous API key once the rotation grace period is over
+		state.Result = reconcile.Result{RequeueAfter: apiKeyRequeueAfter}
+	}
+
+	res, err := r.updateStatus(state)
+	if err == nil {
+		err = apiKeyErr
+	}
+	return res, err
 }
 
-func (r *ReconcileApmServer) reconcileApmServerSecret(as *apmv1alpha1.ApmServer) (*corev1.Secret, error) {
+// reconcileApmServerSecret reconciles the secret holding the secret token, rotating the token if requested.
+// It returns the delay before the next step of an ongoing rotation, if any.
+func (r *ReconcileApmServer) reconcileApmServerSecret(as *apmv1alpha1.ApmServer) (*corev1.Secret, time.Duration, error) {
+	key := types.NamespacedName{Namespace: as.Namespace, Name: apmname.SecretToken(as.Name)}
+	var existing corev1.Secret
+	if err := r.Get(key, &existing); err != nil && !errors.IsNotFound(err) {
+		return nil, 0, err
+	}
+	tokenState := nextSecretTokenState(as.Annotations, existing.Data, existing.Annotations, time.Now())
+	if _, rotating := tokenState.Data[NextSecretTokenKey]; rotating && !bytes.Equal(tokenState.Data[NextSecretTokenKey], existing.Data[NextSecretTokenKey]) {
+		log.Info("Rotating apm server secret token", "namespace", as.Namespace, "as_name", as.Name, "grace_period", gracePeriod(as.Annotations, SecretTokenGracePeriodAnnotation))
+	}
+
 	expectedApmServerSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: as.Namespace,
-			Name:      apmname.SecretToken(as.Name),
-			Labels:    labels.NewLabels(as.Name),
-		},
-		Data: map[string][]byte{
-			SecretTokenKey: []byte(rand.String(24)),
+			Namespace:   key.Namespace,
+			Name:        key.Name,
+			Labels:      labels.NewLabels(as.Name),
+			Annotations: tokenState.Annotations,
 		},
+		Data: tokenState.Data,
 	}
 	reconciledApmServerSecret := &corev1.Secret{}
-	return reconciledApmServerSecret, reconciler.ReconcileResource(
+	return reconciledApmServerSecret, tokenState.RequeueAfter, reconciler.ReconcileResource(
 		reconciler.Params{
 			Client: r.Client,
 			Scheme: r.scheme,
@@ -281,28 +320,13 @@ func (r *ReconcileApmServer) reconcileApmServerSecret(as *apmv1alpha1.ApmServer)
 			Reconciled: reconciledApmServerSecret,
 
 			NeedsUpdate: func() bool {
-				if !reflect.DeepEqual(reconciledApmServerSecret.Labels, expectedApmServerSecret.Labels) {
-					return true
-				}
-
-				if reconciledApmServerSecret.Data == nil {
-					return true
-				}
-
-				// re-use the secret token key if it exists
-				existingSecretTokenKey, hasExistingSecretTokenKey := reconciledApmServerSecret.Data[SecretTokenKey]
-				if hasExistingSecretTokenKey {
-					expectedApmServerSecret.Data[SecretTokenKey] = existingSecretTokenKey
-				}
-
-				if !reflect.DeepEqual(reconciledApmServerSecret.Data, expectedApmServerSecret.Data) {
-					return true
-				}
-
-				return false
+				return !reflect.DeepEqual(reconciledApmServerSecret.Labels, expectedApmServerSecret.Labels) ||
+					!reflect.DeepEqual(reconciledApmServerSecret.Data, expectedApmServerSecret.Data) ||
+					!managedAnnotationsMatch(secretTokenAnnotations, expectedApmServerSecret.Annotations, reconciledApmServerSecret.Annotations)
 			},
 			UpdateReconciled: func() {
 				reconciledApmServerSecret.Labels = expectedApmServerSecret.Labels
+				reconciledApmServerSecret.Annotations = setManagedAnnotations(secretTokenAnnotations, expectedApmServerSecret.Annotations, reconciledApmServerSecret.Annotations)
 				reconciledApmServerSecret.Data = expectedApmServerSecret.Data
 			},
 			PreCreate: func() {
@@ -329,43 +353,32 @@ func (r *ReconcileApmServer) deploymentParams(
 	if params.keystoreResources != nil {
 		_, _ = configChecksum.Write([]byte(params.keystoreResources.Version))
 	}
+	// the secret token is read from an environment variable: restart the APM Server instances when it changes.
+	// This also restarts the instances created by an operator version that did not include the token in the checksum.
+	_, _ = configChecksum.Write(params.ApmServerSecret.Data[SecretTokenKey])
 
-	esCASecretName := as.Spec.Elasticsearch.SSL.CertificateAuthorities.SecretName
-	if esCASecretName != "" {
-		// TODO: use apmServerCa to generate cert for deployment
-
-		// TODO: this is a little ugly as it reaches into the ES controller bits
-		esCAVolume := volume.NewSecretVolumeWithMountPath(
-			esCASecretName,
-			"elasticsearch-certs",
-			filepath.Join(ApmBaseDir, config.CertificatesDir),
-		)
-
-		// build a checksum of the cert file used by ES, which we can use to cause the Deployment to roll the Apm Server
-		// instances in the deployment when the ca file contents change. this is done because Apm Server do not support
-		// updating the CA file contents without restarting the process.
-		certsChecksum := ""
-		var esPublicCASecret corev1.Secret
-		key := types.NamespacedName{Namespace: as.Namespace, Name: esCASecretName}
-		if err := r.Get(key, &esPublicCASecret); err != nil {
-			return DeploymentParams{}, err
-		}
-		if certPem, ok := esPublicCASecret.Data[certificates.CertFileName]; ok {
-			certsChecksum = fmt.Sprintf("%x", sha256.Sum224(certPem))
-		}
-		// we add the checksum to a label for the deployment and its pods (the important bit is that the pod template
-		// changes, which will trigger a rolling update)
-		podLabels[esCAChecksumLabelName] = certsChecksum
-
-		podSpec.Spec.Volumes = append(podSpec.Spec.Volumes, esCAVolume.Volume())
-
-		for i := range podSpec.Spec.InitContainers {
-			podSpec.Spec.InitContainers[i].VolumeMounts = append(podSpec.Spec.InitContainers[i].VolumeMounts, esCAVolume.VolumeMount())
-		}
-
-		for i := range podSpec.Spec.Containers {
-			podSpec.Spec.Containers[i].VolumeMounts = append(podSpec.Spec.Containers[i].VolumeMounts, esCAVolume.VolumeMount())
-		}
+	// TODO: this is a little ugly as it reaches into the ES controller bits
+	if err := r.mountCASecret(
+		as,
+		&podSpec,
+		podLabels,
+		as.Spec.Elasticsearch.SSL.CertificateAuthorities.SecretName,
+		"elasticsearch-certs",
+		config.CertificatesDir,
+		esCAChecksumLabelName,
+	); err != nil {
+		return DeploymentParams{}, err
+	}
+	if err := r.mountCASecret(
+		as,
+		&podSpec,
+		podLabels,
+		as.Spec.Kibana.SSL.CertificateAuthorities.SecretName,
+		"kibana-certs",
+		config.KibanaCertificatesDir,
+		kibanaCAChecksumLabelName,
+	); err != nil {
+		return DeploymentParams{}, err
 	}
 
 	if as.Spec.HTTP.TLS.Enabled() {
@@ -388,7 +401,6 @@ func (r *ReconcileApmServer) deploymentParams(
 	}
 
 	podLabels[configChecksumLabelName] = fmt.Sprintf("%x", configChecksum.Sum(nil))
-	// TODO: also need to hash secret token?
 
 	deploymentLabels := labels.NewLabels(as.Name)
 	podSpec.Labels = defaults.SetDefaultLabels(podSpec.Labels, podLabels)
@@ -403,14 +415,65 @@ func (r *ReconcileApmServer) deploymentParams(
 	}, nil
 }
 
+// mountCASecret mounts the CA certificates of the given secret, if any, into the containers of the given pod spec.
+// A checksum of the certificates is added to the given pod labels so the APM Server instances are rolled when it
+// changes, since APM Server does not support updating the CA file contents without restarting the process.
+func (r *ReconcileApmServer) mountCASecret(
+	as *apmv1alpha1.ApmServer,
+	podSpec *corev1.PodTemplateSpec,
+	podLabels map[string]string,
+	secretName string,
+	volumeName string,
+	certificatesDir string,
+	checksumLabelName string,
+) error {
+	if secretName == "" {
+		return nil
+	}
+	// TODO: use apmServerCa to generate cert for deployment
+	caVolume := volume.NewSecretVolumeWithMountPath(
+		secretName,
+		volumeName,
+		filepath.Join(ApmBaseDir, certificatesDir),
+	)
+
+	certsChecksum := ""
+	var publicCASecret corev1.Secret
+	key := types.NamespacedName{Namespace: as.Namespace, Name: secretName}
+	if err := r.Get(key, &publicCASecret); err != nil {
+		return err
+	}
+	if certPem, ok := publicCASecret.Data[certificates.CertFileName]; ok {
+		certsChecksum = fmt.Sprintf("%x", sha256.Sum224(certPem))
+	}
+	// we add the checksum to a label for the deployment and its pods (the important bit is that the pod template
+	// changes, which will trigger a rolling update)
+	podLabels[checksumLabelName] = certsChecksum
+
+	podSpec.Spec.Volumes = append(podSpec.Spec.Volumes, caVolume.Volume())
+
+	for i := range podSpec.Spec.InitContainers {
+		podSpec.Spec.InitContainers[i].VolumeMounts = append(podSpec.Spec.InitContainers[i].VolumeMounts, caVolume.VolumeMount())
+	}
+
+	for i := range podSpec.Spec.Containers {
+		podSpec.Spec.Containers[i].VolumeMounts = append(podSpec.Spec.Containers[i].VolumeMounts, caVolume.VolumeMount())
+	}
+	return nil
+}
+
 func (r *ReconcileApmServer) reconcileApmServerDeployment(
 	state State,
 	as *apmv1alpha1.ApmServer,
 ) (State, error) {
-	reconciledApmServerSecret, err := r.reconcileApmServerSecret(as)
+	reconciledApmServerSecret, rotationRequeueAfter, err := r.reconcileApmServerSecret(as)
 	if err != nil {
 		return state, err
 	}
+	if rotationRequeueAfter > 0 {
+		// replace the secret token by the next one once the rotation grace period is over
+		state.Result = reconcile.Result{RequeueAfter: rotationRequeueAfter}
+	}
 	reconciledConfigSecret, err := config.Reconcile(r.Client, r.scheme, as)
 	if err != nil {
 		return state, err
@@ -474,5 +537,6 @@ func (r *ReconcileApmServer) updateStatus(state State) (reconcile.Result, error)
 func (r *ReconcileApmServer) finalizersFor(as apmv1alpha1.ApmServer) []finalizer.Finalizer {
 	return []finalizer.Finalizer{
 		keystore.Finalizer(k8s.ExtractNamespacedName(&as), r.dynamicWatches, as.Kind()),
+		r.apiKeyFinalizer(as),
 	}
 }
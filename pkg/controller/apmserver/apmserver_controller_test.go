@@ -285,6 +285,33 @@ func TestReconcileApmServer_deploymentParams(t *testing.T) {
 			want:    expectedDeploymentParams().withConfigChecksum("1846d1bd30922b6492a1a28bc940fd00efcd2d9bfb00e34e94bf8048"),
 			wantErr: false,
 		},
+		{
+			name: "secret token influences checksum",
+			args: args{
+				as: apmFixture,
+				podSpecParams: func() PodSpecParams {
+					params := defaultPodSpecParams
+					params.ApmServerSecret = corev1.Secret{
+						ObjectMeta: v1.ObjectMeta{
+							Name: "test-apm-server-apm-token",
+						},
+						Data: map[string][]byte{
+							SecretTokenKey: []byte("foo"),
+						},
+					}
+					return params
+				}(),
+				initialObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: v1.ObjectMeta{
+							Name: certSecretName,
+						},
+					},
+				},
+			},
+			want:    expectedDeploymentParams().withConfigChecksum("0808f64e60d58979fcb676c96ec938270dea42445aeefcd3a4e6f8db"),
+			wantErr: false,
+		},
 		{
 			name: "keystore version influences checksum",
 			args: args{
@@ -8,12 +8,15 @@ import (
 	"time"
 
 	"github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/apmserver/config"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/apmserver/labels"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/apmserver/name"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates/http"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/driver"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
 	coverv1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -22,11 +25,14 @@ func Reconcile(
 	driver driver.Interface,
 	apm v1alpha1.ApmServer,
 	services []coverv1.Service,
+	dialer net.Dialer,
 	rotation certificates.RotationParams,
+	keyParams certificates.KeyParams,
 ) reconciler.Results {
 	results := reconciler.Results{}
 	selfSignedCert := apm.Spec.HTTP.TLS.SelfSignedCertificate
 	if selfSignedCert != nil && selfSignedCert.Disabled {
+		certificates.DeleteHTTPExpirationMetrics(v1alpha1.Kind, k8s.ExtractNamespacedName(&apm))
 		return results
 	}
 
@@ -41,14 +47,18 @@ func Reconcile(
 		labels,
 		certificates.HTTPCAType,
 		rotation,
+		keyParams,
+		http.PodsCertificatesIssuedBy(driver.K8sClient(), dialer, apm.Namespace, labels, config.DefaultHTTPPort, apm.Spec.HTTP.TLS),
 	)
 	if err != nil {
 		return *results.WithError(err)
 	}
 
-	// handle CA expiry via requeue
+	certificates.ReportCAExpiration(v1alpha1.Kind, k8s.ExtractNamespacedName(&apm), certificates.HTTPCAType, httpCa)
+
+	// handle CA expiry and rotation via requeue
 	results.WithResult(reconcile.Result{
-		RequeueAfter: certificates.ShouldRotateIn(time.Now(), httpCa.Cert.NotAfter, rotation.RotateBefore),
+		RequeueAfter: certificates.ShouldRotateCAIn(time.Now(), httpCa, rotation),
 	})
 
 	// discover and maybe reconcile for the http certificates to use
@@ -61,10 +71,15 @@ func Reconcile(
 		labels,
 		services,
 		rotation, // todo correct rotation
+		keyParams,
 	)
 	if err != nil {
 		return *results.WithError(err)
 	}
+	// warn again before the certificate expires if it cannot be rotated by the operator
+	results.WithResult(reconcile.Result{
+		RequeueAfter: http.ReportCertificatesExpiration(driver.Recorder(), &apm, v1alpha1.Kind, apm.Spec.HTTP.TLS, *httpCertificates),
+	})
 	// reconcile http public cert secret
 	results.WithError(http.ReconcileHTTPCertsPublicSecret(driver.K8sClient(), driver.Scheme(), &apm, name.APMNamer, httpCertificates))
 	return results
@@ -23,16 +23,84 @@ const (
 	DefaultHTTPPort = 8200
 
 	// Certificates
-	CertificatesDir = "config/elasticsearch-certs"
+	CertificatesDir       = "config/elasticsearch-certs"
+	KibanaCertificatesDir = "config/kibana-certs"
 
 	APMServerHost        = "apm-server.host"
 	APMServerSecretToken = "apm-server.secret_token"
 
+	APMServerAPIKeyEnabled = "apm-server.api_key.enabled"
+
 	APMServerSSLEnabled     = "apm-server.ssl.enabled"
 	APMServerSSLKey         = "apm-server.ssl.key"
 	APMServerSSLCertificate = "apm-server.ssl.certificate"
+
+	OutputElasticsearchHosts                  = "output.elasticsearch.hosts"
+	OutputElasticsearchUsername               = "output.elasticsearch.username"
+	OutputElasticsearchPassword               = "output.elasticsearch.password"
+	OutputElasticsearchCertificateAuthorities = "output.elasticsearch.ssl.certificate_authorities"
+
+	APMServerKibanaEnabled                = "apm-server.kibana.enabled"
+	APMServerKibanaHost                   = "apm-server.kibana.host"
+	APMServerKibanaUsername               = "apm-server.kibana.username"
+	APMServerKibanaPassword               = "apm-server.kibana.password"
+	APMServerKibanaCertificateAuthorities = "apm-server.kibana.ssl.certificate_authorities"
 )
 
+// Blacklist are the settings managed by the operator, which cannot be set by users.
+var Blacklist = []string{
+	APMServerHost,
+	APMServerSecretToken,
+	APMServerSSLEnabled,
+	APMServerSSLKey,
+	APMServerSSLCertificate,
+}
+
+// AssociationBlacklist are the settings managed by the operator when the APM Server references an Elasticsearch
+// cluster, which cannot be set by users in that case.
+var AssociationBlacklist = []string{
+	OutputElasticsearchHosts,
+	OutputElasticsearchUsername,
+	OutputElasticsearchPassword,
+	OutputElasticsearchCertificateAuthorities,
+}
+
+// KibanaAssociationBlacklist are the settings managed by the operator when the APM Server references a Kibana
+// instance, which cannot be set by users in that case.
+var KibanaAssociationBlacklist = []string{
+	APMServerKibanaEnabled,
+	APMServerKibanaHost,
+	APMServerKibanaUsername,
+	APMServerKibanaPassword,
+	APMServerKibanaCertificateAuthorities,
+}
+
+// apiKeySettings are the settings of the API key authentication of the APM Server.
+type apiKeySettings struct {
+	APMServer struct {
+		APIKey struct {
+			Enabled bool `config:"enabled"`
+		} `config:"api_key"`
+	} `config:"apm-server"`
+}
+
+// APIKeyEnabled returns true if the given user configuration enables API key authentication, in which case
+// APM Server validates the API keys sent by the agents against the Elasticsearch output.
+func APIKeyEnabled(specConfig *commonv1alpha1.Config) (bool, error) {
+	if specConfig == nil {
+		return false, nil
+	}
+	cfg, err := settings.NewCanonicalConfigFrom(specConfig.Data)
+	if err != nil {
+		return false, err
+	}
+	var apiKey apiKeySettings
+	if err := cfg.Unpack(&apiKey); err != nil {
+		return false, err
+	}
+	return apiKey.APMServer.APIKey.Enabled, nil
+}
+
 func NewConfigFromSpec(c k8s.Client, as v1alpha1.ApmServer) (*settings.CanonicalConfig, error) {
 	specConfig := as.Spec.Config
 	if specConfig == nil {
@@ -53,15 +121,20 @@ func NewConfigFromSpec(c k8s.Client, as v1alpha1.ApmServer) (*settings.Canonical
 		}
 		outputCfg = settings.MustCanonicalConfig(
 			map[string]interface{}{
-				"output.elasticsearch.hosts":                       as.Spec.Elasticsearch.Hosts,
-				"output.elasticsearch.username":                    username,
-				"output.elasticsearch.password":                    password,
-				"output.elasticsearch.ssl.certificate_authorities": []string{filepath.Join(CertificatesDir, certificates.CertFileName)},
+				OutputElasticsearchHosts:                  as.Spec.Elasticsearch.Hosts,
+				OutputElasticsearchUsername:               username,
+				OutputElasticsearchPassword:               password,
+				OutputElasticsearchCertificateAuthorities: []string{filepath.Join(CertificatesDir, certificates.CertFileName)},
 			},
 		)
 
 	}
 
+	kibanaCfg, err := kibanaSettings(c, as)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create a base configuration.
 
 	cfg := settings.MustCanonicalConfig(map[string]interface{}{
@@ -72,6 +145,7 @@ func NewConfigFromSpec(c k8s.Client, as v1alpha1.ApmServer) (*settings.Canonical
 	// Merge the configuration with userSettings last so they take precedence.
 	err = cfg.MergeWith(
 		outputCfg,
+		kibanaCfg,
 		settings.MustCanonicalConfig(tlsSettings(as)),
 		userSettings,
 	)
@@ -82,6 +156,27 @@ func NewConfigFromSpec(c k8s.Client, as v1alpha1.ApmServer) (*settings.Canonical
 	return cfg, nil
 }
 
+// kibanaSettings returns the settings used by the APM Server to connect to Kibana for agent central configuration.
+func kibanaSettings(c k8s.Client, as v1alpha1.ApmServer) (*settings.CanonicalConfig, error) {
+	if !as.Spec.Kibana.IsConfigured() {
+		return settings.NewCanonicalConfig(), nil
+	}
+	username, password, err := association.AuthSettings(c, as.Namespace, as.Spec.Kibana.Auth)
+	if err != nil {
+		return nil, err
+	}
+	cfg := map[string]interface{}{
+		APMServerKibanaEnabled:  true,
+		APMServerKibanaHost:     as.Spec.Kibana.Host,
+		APMServerKibanaUsername: username,
+		APMServerKibanaPassword: password,
+	}
+	if as.Spec.Kibana.SSL.CertificateAuthorities.SecretName != "" {
+		cfg[APMServerKibanaCertificateAuthorities] = []string{filepath.Join(KibanaCertificatesDir, certificates.CertFileName)}
+	}
+	return settings.MustCanonicalConfig(cfg), nil
+}
+
 func tlsSettings(as v1alpha1.ApmServer) map[string]interface{} {
 	if !as.Spec.HTTP.TLS.Enabled() {
 		return nil
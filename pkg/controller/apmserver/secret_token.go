@@ -0,0 +1,150 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package apmserver
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+const (
+	// NextSecretTokenKey is the key of the secret token that replaces the current one once the rotation grace
+	// period is over. It allows agents to be prepared for the switch.
+	NextSecretTokenKey = "next-secret-token"
+
+	// RotateSecretTokenAnnotation requests a rotation of the secret token whenever its value changes, for example
+	// to the current date.
+	RotateSecretTokenAnnotation = "apm.k8s.elastic.co/rotate-secret-token"
+	// SecretTokenGracePeriodAnnotation overrides the duration, such as "30m", during which the current secret token
+	// remains in use after a rotation was requested.
+	SecretTokenGracePeriodAnnotation = "apm.k8s.elastic.co/secret-token-grace-period"
+	// DefaultGracePeriod is the default duration during which the current secret token remains in use, or the
+	// previous API key remains valid, after a rotation was requested.
+	DefaultGracePeriod = 1 * time.Hour
+
+	// secretTokenRotationAnnotation records on the secret the last rotation request handled.
+	secretTokenRotationAnnotation = "apm.k8s.elastic.co/secret-token-rotation"
+	// secretTokenRotationTimeAnnotation records on the secret when the next secret token was generated.
+	secretTokenRotationTimeAnnotation = "apm.k8s.elastic.co/secret-token-rotation-time"
+
+	secretTokenLength = 24
+)
+
+// secretTokenState is the content of the secret holding the secret token.
+type secretTokenState struct {
+	Data        map[string][]byte
+	Annotations map[string]string
+	// RequeueAfter is the delay before the next secret token should replace the current one, if a rotation is in
+	// progress.
+	RequeueAfter time.Duration
+}
+
+// nextSecretTokenState computes the expected content of the secret token secret, given its current data and
+// annotations, and the annotations of the APM Server.
+//
+// APM Server accepts a single secret token, there is no way to have both the current and the next token accepted at
+// the same time. When a rotation is requested, a new token is generated and published under NextSecretTokenKey
+// while the current token remains in use for the grace period. Once the grace period is over, the new token replaces
+// the current one, which causes the APM Server instances to be restarted: agents using the current token are
+// rejected from then on, as agents using the next token were until then. API keys, managed in nextAPIKeyState, can
+// be rotated without such an interruption.
+func nextSecretTokenState(
+	apmAnnotations map[string]string,
+	data map[string][]byte,
+	annotations map[string]string,
+	now time.Time,
+) secretTokenState {
+	state := secretTokenState{
+		Data:        map[string][]byte{},
+		Annotations: map[string]string{},
+	}
+
+	// re-use the secret token if it exists
+	if token, exists := data[SecretTokenKey]; exists {
+		state.Data[SecretTokenKey] = token
+	} else {
+		state.Data[SecretTokenKey] = []byte(rand.String(secretTokenLength))
+	}
+	if next, exists := data[NextSecretTokenKey]; exists {
+		state.Data[NextSecretTokenKey] = next
+		state.Annotations[secretTokenRotationTimeAnnotation] = annotations[secretTokenRotationTimeAnnotation]
+	}
+	if handled, exists := annotations[secretTokenRotationAnnotation]; exists {
+		state.Annotations[secretTokenRotationAnnotation] = handled
+	}
+
+	// start a new rotation if requested, restarting the grace period if one was already in progress
+	if requested := apmAnnotations[RotateSecretTokenAnnotation]; requested != "" && requested != annotations[secretTokenRotationAnnotation] {
+		state.Data[NextSecretTokenKey] = []byte(rand.String(secretTokenLength))
+		state.Annotations[secretTokenRotationAnnotation] = requested
+		state.Annotations[secretTokenRotationTimeAnnotation] = now.Format(time.RFC3339)
+	}
+
+	if _, inProgress := state.Data[NextSecretTokenKey]; !inProgress {
+		return state
+	}
+	rotationTime, err := time.Parse(time.RFC3339, state.Annotations[secretTokenRotationTimeAnnotation])
+	if err != nil {
+		// the rotation time was tampered with, consider the grace period over
+		rotationTime = time.Time{}
+	}
+	remaining := rotationTime.Add(gracePeriod(apmAnnotations, SecretTokenGracePeriodAnnotation)).Sub(now)
+	if remaining > 0 {
+		state.RequeueAfter = remaining
+		return state
+	}
+	// the grace period is over: the next secret token becomes the current one
+	state.Data[SecretTokenKey] = state.Data[NextSecretTokenKey]
+	delete(state.Data, NextSecretTokenKey)
+	delete(state.Annotations, secretTokenRotationTimeAnnotation)
+	return state
+}
+
+// gracePeriod returns the grace period of a rotation, set by the given annotation of the APM Server.
+func gracePeriod(apmAnnotations map[string]string, annotation string) time.Duration {
+	value, exists := apmAnnotations[annotation]
+	if !exists {
+		return DefaultGracePeriod
+	}
+	period, err := time.ParseDuration(value)
+	if err != nil || period < 0 {
+		log.Info("Ignoring invalid grace period", "annotation", annotation, "value", value)
+		return DefaultGracePeriod
+	}
+	return period
+}
+
+// secretTokenAnnotations are the annotations of the secret token secret managed by the operator.
+var secretTokenAnnotations = []string{secretTokenRotationAnnotation, secretTokenRotationTimeAnnotation}
+
+// managedAnnotationsMatch returns true if the actual annotations contain the expected managed annotations, and none
+// of the other managed annotations.
+func managedAnnotationsMatch(managed []string, expected, actual map[string]string) bool {
+	for _, key := range managed {
+		expectedValue, expectedExists := expected[key]
+		actualValue, actualExists := actual[key]
+		if expectedExists != actualExists || expectedValue != actualValue {
+			return false
+		}
+	}
+	return true
+}
+
+// setManagedAnnotations returns the actual annotations updated with the expected managed annotations, leaving the
+// other annotations untouched.
+func setManagedAnnotations(managed []string, expected, actual map[string]string) map[string]string {
+	updated := make(map[string]string, len(actual)+len(expected))
+	for key, value := range actual {
+		updated[key] = value
+	}
+	for _, key := range managed {
+		delete(updated, key)
+		if value, exists := expected[key]; exists {
+			updated[key] = value
+		}
+	}
+	return updated
+}
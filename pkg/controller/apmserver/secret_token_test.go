@@ -0,0 +1,73 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package apmserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_nextSecretTokenState(t *testing.T) {
+	now := time.Date(2019, 9, 1, 12, 0, 0, 0, time.UTC)
+	current := map[string][]byte{SecretTokenKey: []byte("current")}
+
+	// a secret token is generated if none exists
+	state := nextSecretTokenState(nil, nil, nil, now)
+	require.Len(t, state.Data[SecretTokenKey], secretTokenLength)
+	require.Len(t, state.Data, 1)
+	require.Empty(t, state.Annotations)
+
+	// the existing secret token is kept
+	state = nextSecretTokenState(nil, current, nil, now)
+	require.Equal(t, current, state.Data)
+	require.Equal(t, time.Duration(0), state.RequeueAfter)
+
+	// a rotation publishes the next secret token, the current one remains in use for the grace period
+	rotate := map[string]string{RotateSecretTokenAnnotation: "2019-09-01", SecretTokenGracePeriodAnnotation: "30m"}
+	state = nextSecretTokenState(rotate, current, nil, now)
+	require.Equal(t, "current", string(state.Data[SecretTokenKey]))
+	next := state.Data[NextSecretTokenKey]
+	require.Len(t, next, secretTokenLength)
+	require.Equal(t, map[string]string{
+		secretTokenRotationAnnotation:     "2019-09-01",
+		secretTokenRotationTimeAnnotation: "2019-09-01T12:00:00Z",
+	}, state.Annotations)
+	require.Equal(t, 30*time.Minute, state.RequeueAfter)
+
+	// the rotation is not restarted for the same request
+	inProgress := nextSecretTokenState(rotate, state.Data, state.Annotations, now.Add(10*time.Minute))
+	require.Equal(t, state.Data, inProgress.Data)
+	require.Equal(t, state.Annotations, inProgress.Annotations)
+	require.Equal(t, 20*time.Minute, inProgress.RequeueAfter)
+
+	// the next secret token replaces the current one once the grace period is over
+	done := nextSecretTokenState(rotate, state.Data, state.Annotations, now.Add(30*time.Minute))
+	require.Equal(t, map[string][]byte{SecretTokenKey: next}, done.Data)
+	require.Equal(t, map[string]string{secretTokenRotationAnnotation: "2019-09-01"}, done.Annotations)
+	require.Equal(t, time.Duration(0), done.RequeueAfter)
+
+	// and nothing happens until a new rotation is requested
+	again := nextSecretTokenState(rotate, done.Data, done.Annotations, now.Add(2*time.Hour))
+	require.Equal(t, done, again)
+}
+
+func Test_gracePeriod(t *testing.T) {
+	require.Equal(t, DefaultGracePeriod, gracePeriod(nil, SecretTokenGracePeriodAnnotation))
+	require.Equal(t, 5*time.Minute, gracePeriod(map[string]string{SecretTokenGracePeriodAnnotation: "5m"}, SecretTokenGracePeriodAnnotation))
+	require.Equal(t, time.Duration(0), gracePeriod(map[string]string{SecretTokenGracePeriodAnnotation: "0s"}, SecretTokenGracePeriodAnnotation))
+	require.Equal(t, DefaultGracePeriod, gracePeriod(map[string]string{SecretTokenGracePeriodAnnotation: "invalid"}, SecretTokenGracePeriodAnnotation))
+	require.Equal(t, DefaultGracePeriod, gracePeriod(map[string]string{SecretTokenGracePeriodAnnotation: "5m"}, APIKeyGracePeriodAnnotation))
+}
+
+func Test_setManagedAnnotations(t *testing.T) {
+	actual := map[string]string{"other": "value", secretTokenRotationTimeAnnotation: "2019-09-01T12:00:00Z"}
+	expected := map[string]string{secretTokenRotationAnnotation: "2019-09-01"}
+	require.False(t, managedAnnotationsMatch(secretTokenAnnotations, expected, actual))
+	updated := setManagedAnnotations(secretTokenAnnotations, expected, actual)
+	require.Equal(t, map[string]string{"other": "value", secretTokenRotationAnnotation: "2019-09-01"}, updated)
+	require.True(t, managedAnnotationsMatch(secretTokenAnnotations, expected, updated))
+}
@@ -6,6 +6,7 @@ package apmserver
 
 import (
 	"github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1alpha1"
+	commondeployment "github.com/elastic/cloud-on-k8s/pkg/controller/common/deployment"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -31,6 +32,7 @@ func NewState(request reconcile.Request, as *v1alpha1.ApmServer) State {
 func (s State) UpdateApmServerState(deployment v1.Deployment, apmServerSecret corev1.Secret) {
 	s.ApmServer.Status.SecretTokenSecretName = apmServerSecret.Name
 	s.ApmServer.Status.AvailableNodes = int(deployment.Status.AvailableReplicas) // TODO lossy type conversion
+	s.ApmServer.Status.Selector = commondeployment.Selector(deployment)
 	s.ApmServer.Status.Health = v1alpha1.ApmServerRed
 	for _, c := range deployment.Status.Conditions {
 		if c.Type == v1.DeploymentAvailable && c.Status == corev1.ConditionTrue {
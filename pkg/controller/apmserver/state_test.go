@@ -0,0 +1,35 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package apmserver
+
+import (
+	"testing"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1alpha1"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestState_UpdateApmServerState(t *testing.T) {
+	deployment := appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"apm.k8s.elastic.co/name": "as"}},
+		},
+		Status: appsv1.DeploymentStatus{
+			AvailableReplicas: 2,
+			Conditions:        []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}},
+		},
+	}
+	secret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "as-apm-token"}}
+	as := v1alpha1.ApmServer{}
+	NewState(reconcile.Request{}, &as).UpdateApmServerState(deployment, secret)
+	require.Equal(t, "apm.k8s.elastic.co/name=as", as.Status.Selector)
+	require.Equal(t, 2, as.Status.AvailableNodes)
+	require.Equal(t, v1alpha1.ApmServerGreen, as.Status.Health)
+	require.Equal(t, "as-apm-token", as.Status.SecretTokenSecretName)
+}
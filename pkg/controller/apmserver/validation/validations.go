@@ -0,0 +1,70 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package validation
+
+import (
+	"fmt"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/apmserver/config"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/validation"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esversion "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/version"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// apiKeyMinVersion is the first version supporting API key authentication.
+var apiKeyMinVersion = version.MustParse("7.6.0")
+
+// Validate runs all validations against the given APM Server and returns the failed ones.
+func Validate(c k8s.Client, as v1alpha1.ApmServer) []validation.Result {
+	return validation.ValidateAssociated(c, validation.Associated{
+		Namespace:                as.Namespace,
+		Version:                  as.Spec.Version,
+		LowestVersion:            esversion.SupportedRange.LowestSupportedVersion,
+		HighestVersion:           esversion.SupportedRange.HighestSupportedVersion,
+		Config:                   as.Spec.Config,
+		Blacklist:                blacklist(as),
+		ElasticsearchRef:         as.Spec.ElasticsearchRef,
+		ExternalElasticsearchRef: as.Spec.ExternalElasticsearchRef,
+		TLS:                      as.Spec.HTTP.TLS,
+	},
+		validation.ValidObjectSelector("kibanaRef", as.Spec.KibanaRef),
+		supportedAPIKey(as),
+	)
+}
+
+// blacklist returns the settings managed by the operator, which cannot be overridden in the configuration.
+func blacklist(as v1alpha1.ApmServer) []string {
+	blacklist := config.Blacklist
+	if as.Spec.ElasticsearchRef.IsDefined() || as.Spec.ExternalElasticsearchRef.IsDefined() {
+		blacklist = append(append([]string{}, blacklist...), config.AssociationBlacklist...)
+	}
+	if as.Spec.KibanaRef.IsDefined() {
+		blacklist = append(append([]string{}, blacklist...), config.KibanaAssociationBlacklist...)
+	}
+	return blacklist
+}
+
+// supportedAPIKey checks that API key authentication is only enabled for versions supporting it.
+func supportedAPIKey(as v1alpha1.ApmServer) validation.Result {
+	enabled, err := config.APIKeyEnabled(as.Spec.Config)
+	if err != nil || !enabled {
+		// invalid configurations are reported when checking the blacklisted settings
+		return validation.OK
+	}
+	v, err := version.Parse(as.Spec.Version)
+	if err != nil {
+		// invalid versions are reported when checking the supported versions
+		return validation.OK
+	}
+	if !v.IsSameOrAfter(apiKeyMinVersion) {
+		return validation.Result{
+			Allowed: false,
+			Reason:  fmt.Sprintf("%s requires version %s or later", config.APMServerAPIKeyEnabled, apiKeyMinVersion),
+		}
+	}
+	return validation.OK
+}
@@ -0,0 +1,125 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1alpha1"
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidate(t *testing.T) {
+	apmServer := func(mutate func(as *v1alpha1.ApmServer)) v1alpha1.ApmServer {
+		as := v1alpha1.ApmServer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "apm"},
+			Spec: v1alpha1.ApmServerSpec{
+				Version:          "7.2.0",
+				ElasticsearchRef: commonv1alpha1.ObjectSelector{Name: "es"},
+			},
+		}
+		mutate(&as)
+		return as
+	}
+	tests := []struct {
+		name        string
+		as          v1alpha1.ApmServer
+		wantReasons []string
+	}{
+		{
+			name: "valid",
+			as:   apmServer(func(as *v1alpha1.ApmServer) {}),
+		},
+		{
+			name:        "invalid version",
+			as:          apmServer(func(as *v1alpha1.ApmServer) { as.Spec.Version = "latest" }),
+			wantReasons: []string{"Cannot parse version latest"},
+		},
+		{
+			name: "settings managed by the operator",
+			as: apmServer(func(as *v1alpha1.ApmServer) {
+				as.Spec.Config = &commonv1alpha1.Config{Data: map[string]interface{}{
+					"apm-server.secret_token":       "my-token",
+					"output.elasticsearch.username": "elastic",
+				}}
+			}),
+			wantReasons: []string{"apm-server.secret_token, output.elasticsearch.username is not user configurable"},
+		},
+		{
+			name: "Elasticsearch output can be set without Elasticsearch reference",
+			as: apmServer(func(as *v1alpha1.ApmServer) {
+				as.Spec.ElasticsearchRef = commonv1alpha1.ObjectSelector{}
+				as.Spec.Config = &commonv1alpha1.Config{Data: map[string]interface{}{
+					"output.elasticsearch.username": "elastic",
+				}}
+			}),
+		},
+		{
+			name: "malformed Elasticsearch reference",
+			as: apmServer(func(as *v1alpha1.ApmServer) {
+				as.Spec.ElasticsearchRef = commonv1alpha1.ObjectSelector{Namespace: "other"}
+			}),
+			wantReasons: []string{"elasticsearchRef: name is required when namespace is set"},
+		},
+		{
+			name: "Kibana settings managed by the operator",
+			as: apmServer(func(as *v1alpha1.ApmServer) {
+				as.Spec.KibanaRef = commonv1alpha1.ObjectSelector{Name: "kb"}
+				as.Spec.Config = &commonv1alpha1.Config{Data: map[string]interface{}{
+					"apm-server.kibana.host": "https://kb:5601",
+				}}
+			}),
+			wantReasons: []string{"apm-server.kibana.host is not user configurable"},
+		},
+		{
+			name: "Kibana settings can be set without Kibana reference",
+			as: apmServer(func(as *v1alpha1.ApmServer) {
+				as.Spec.Config = &commonv1alpha1.Config{Data: map[string]interface{}{
+					"apm-server.kibana.host": "https://kb:5601",
+				}}
+			}),
+		},
+		{
+			name: "malformed Kibana reference",
+			as: apmServer(func(as *v1alpha1.ApmServer) {
+				as.Spec.KibanaRef = commonv1alpha1.ObjectSelector{Namespace: "other"}
+			}),
+			wantReasons: []string{"kibanaRef: name is required when namespace is set"},
+		},
+		{
+			name: "API keys with a supported version",
+			as: apmServer(func(as *v1alpha1.ApmServer) {
+				as.Spec.Version = "7.6.0"
+				as.Spec.Config = &commonv1alpha1.Config{Data: map[string]interface{}{
+					"apm-server.api_key.enabled": true,
+				}}
+			}),
+		},
+		{
+			name: "API keys with an unsupported version",
+			as: apmServer(func(as *v1alpha1.ApmServer) {
+				as.Spec.Config = &commonv1alpha1.Config{Data: map[string]interface{}{
+					"apm-server": map[string]interface{}{"api_key": map[string]interface{}{"enabled": true}},
+				}}
+			}),
+			wantReasons: []string{"apm-server.api_key.enabled requires version 7.6.0 or later"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Validate(k8s.WrapClient(fake.NewFakeClient()), tt.as)
+			var reasons []string
+			for _, r := range results {
+				require.False(t, r.Allowed)
+				reasons = append(reasons, r.Reason)
+			}
+			require.Equal(t, tt.wantReasons, reasons)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"reflect"
+
+	apmtype "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1alpha1"
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/apmserver/labels"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// ApmESAssociationLabelName marks resources created by the APM Server-Elasticsearch association for easier
+	// retrieval.
+	ApmESAssociationLabelName = "apmassociation.k8s.elastic.co/name"
+	// ApmESAssociationLabelNamespace marks resources created by the APM Server-Elasticsearch association for easier
+	// retrieval.
+	ApmESAssociationLabelNamespace = "apmassociation.k8s.elastic.co/namespace"
+	// ApmKibanaAssociationLabelName marks resources created by the APM Server-Kibana association for easier
+	// retrieval.
+	ApmKibanaAssociationLabelName = "apmkibanaassociation.k8s.elastic.co/name"
+	// ApmKibanaAssociationLabelNamespace marks resources created by the APM Server-Kibana association for easier
+	// retrieval.
+	ApmKibanaAssociationLabelNamespace = "apmkibanaassociation.k8s.elastic.co/namespace"
+
+	apmUserSuffix           = "apm-user"
+	apmESCASecretSuffix     = "apm-es-ca" // nolint
+	apmKibanaUserSuffix     = "apm-kb-user"
+	apmKibanaCASecretSuffix = "apm-kb-ca" // nolint
+	// apmKibanaUserRole is the role of the user APM Server authenticates with against Kibana for agent central
+	// configuration.
+	apmKibanaUserRole = "kibana_user"
+	// apmExternalRoleName is the role of the APM Server user in the Elasticsearch clusters not managed by the
+	// operator, where it is not granted superuser privileges.
+	apmExternalRoleName = "eck_apm_server"
+)
+
+// apmExternalRoles are created in the Elasticsearch clusters not managed by the operator for the APM Server user.
+// They allow setting up and writing the APM indices, and managing the API keys of the agents.
+var apmExternalRoles = map[string]esclient.Role{
+	apmExternalRoleName: {
+		Cluster: []string{"monitor", "manage_ilm", "manage_index_templates", "manage_pipeline", "manage_api_key"},
+		Indices: []esclient.IndicesPrivileges{
+			{
+				Names:      []string{"apm-*"},
+				Privileges: []string{"create_index", "write", "manage", "view_index_metadata"},
+			},
+		},
+		Applications: []esclient.ApplicationPrivileges{
+			{
+				Application: "apm",
+				Privileges:  []string{"event:write", "sourcemap:write", "config_agent:read"},
+				Resources:   []string{"*"},
+			},
+		},
+	},
+}
+
+// apmESAssociationInfo describes the association of the APM Server with its Elasticsearch output.
+var apmESAssociationInfo = AssociationInfo{
+	AssociationName:         "apm-es",
+	AssociatedObjTemplate:   func() commonv1alpha1.Associated { return &apmtype.ApmServer{} },
+	AssociatedNameLabelName: labels.ApmServerNameLabelName,
+	AssociatedLabels:        labels.NewLabels,
+	AssociationRef: func(associated commonv1alpha1.Associated) commonv1alpha1.ObjectSelector {
+		return associated.ElasticsearchRef()
+	},
+	AssociationStatus: func(associated commonv1alpha1.Associated) commonv1alpha1.AssociationStatus {
+		return associated.(*apmtype.ApmServer).Status.Association
+	},
+	SetAssociationStatus: func(associated commonv1alpha1.Associated, status commonv1alpha1.AssociationStatus) {
+		associated.(*apmtype.ApmServer).Status.Association = status
+	},
+	SetAssociationConf: setApmESConf,
+	Referenced:         elasticsearchReference,
+	ExternalElasticsearchRef: func(associated commonv1alpha1.Associated) *commonv1alpha1.ExternalElasticsearchRef {
+		return associated.(*apmtype.ApmServer).Spec.ExternalElasticsearchRef
+	},
+
+	UserSecretSuffix: apmUserSuffix,
+	UserRoles:        "superuser",
+	ExternalRoles:    apmExternalRoles,
+	CASecretSuffix:   apmESCASecretSuffix,
+
+	AssociationLabelName:      ApmESAssociationLabelName,
+	AssociationLabelNamespace: ApmESAssociationLabelNamespace,
+	WatchFinalizerName:        "dynamic-watches.finalizers.apm.k8s.elastic.co",
+	UserFinalizerName:         user.UserFinalizerName,
+}
+
+// apmKibanaAssociationInfo describes the association of the APM Server with Kibana, for agent central configuration.
+var apmKibanaAssociationInfo = AssociationInfo{
+	AssociationName:         "apm-kb",
+	AssociatedObjTemplate:   func() commonv1alpha1.Associated { return &apmtype.ApmServer{} },
+	AssociatedNameLabelName: labels.ApmServerNameLabelName,
+	AssociatedLabels:        labels.NewLabels,
+	AssociationRef: func(associated commonv1alpha1.Associated) commonv1alpha1.ObjectSelector {
+		return associated.(*apmtype.ApmServer).KibanaRef()
+	},
+	AssociationStatus: func(associated commonv1alpha1.Associated) commonv1alpha1.AssociationStatus {
+		return associated.(*apmtype.ApmServer).Status.KibanaAssociation
+	},
+	SetAssociationStatus: func(associated commonv1alpha1.Associated, status commonv1alpha1.AssociationStatus) {
+		associated.(*apmtype.ApmServer).Status.KibanaAssociation = status
+	},
+	SetAssociationConf: setApmKibanaConf,
+	Referenced:         kibanaReference,
+
+	UserSecretSuffix: apmKibanaUserSuffix,
+	UserRoles:        apmKibanaUserRole,
+	CASecretSuffix:   apmKibanaCASecretSuffix,
+
+	AssociationLabelName:      ApmKibanaAssociationLabelName,
+	AssociationLabelNamespace: ApmKibanaAssociationLabelNamespace,
+	WatchFinalizerName:        "kibana-dynamic-watches.finalizers.apm.k8s.elastic.co",
+	UserFinalizerName:         "kibana-users.finalizers.apm.k8s.elastic.co",
+}
+
+// AddApmES creates a new controller associating APM Server resources with their Elasticsearch output and adds it
+// to the Manager.
+func AddApmES(mgr manager.Manager, params operator.Parameters) error {
+	return AddAssociationController(mgr, params, apmESAssociationInfo)
+}
+
+// AddApmKibana creates a new controller associating APM Server resources with Kibana and adds it to the Manager.
+func AddApmKibana(mgr manager.Manager, params operator.Parameters) error {
+	return AddAssociationController(mgr, params, apmKibanaAssociationInfo)
+}
+
+// setApmESConf sets the Elasticsearch output configuration in the APM Server spec.
+func setApmESConf(associated commonv1alpha1.Associated, conf AssociationConf) bool {
+	apm := associated.(*apmtype.ApmServer)
+	var expected apmtype.ElasticsearchOutput
+	if conf != (AssociationConf{}) {
+		expected.Hosts = []string{conf.URL}
+		expected.SSL.CertificateAuthorities = commonv1alpha1.SecretRef{SecretName: conf.CASecretName}
+		expected.Auth.SecretKeyRef = conf.AuthSecretKeyRef
+	}
+	if reflect.DeepEqual(apm.Spec.Elasticsearch, expected) {
+		return false
+	}
+	apm.Spec.Elasticsearch = expected
+	return true
+}
+
+// setApmKibanaConf sets the Kibana connection configuration in the APM Server spec.
+func setApmKibanaConf(associated commonv1alpha1.Associated, conf AssociationConf) bool {
+	apm := associated.(*apmtype.ApmServer)
+	var expected apmtype.KibanaConnection
+	if conf != (AssociationConf{}) {
+		expected.Host = conf.URL
+		expected.SSL.CertificateAuthorities = commonv1alpha1.SecretRef{SecretName: conf.CASecretName}
+		expected.Auth.SecretKeyRef = conf.AuthSecretKeyRef
+	}
+	if reflect.DeepEqual(apm.Spec.Kibana, expected) {
+		return false
+	}
+	apm.Spec.Kibana = expected
+	return true
+}
@@ -0,0 +1,116 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+
+	apmtype "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1alpha1"
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	estype "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	kbtype "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	commonuser "github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var apmFixture = apmtype.ApmServer{
+	ObjectMeta: metav1.ObjectMeta{Name: "as", Namespace: "apm-ns"},
+	Spec: apmtype.ApmServerSpec{
+		KibanaRef: commonv1alpha1.ObjectSelector{Name: "kb", Namespace: "kb-ns"},
+	},
+}
+
+var kbFixture = kbtype.Kibana{
+	ObjectMeta: metav1.ObjectMeta{Name: "kb", Namespace: "kb-ns"},
+	Spec: kbtype.KibanaSpec{
+		ElasticsearchRef: commonv1alpha1.ObjectSelector{Name: "es", Namespace: "es-ns"},
+	},
+}
+
+var kbESFixture = estype.Elasticsearch{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:        "es",
+		Namespace:   "es-ns",
+		Annotations: map[string]string{association.AllowedNamespacesAnnotation: "kb-ns,apm-ns"},
+	},
+}
+
+var kbCAFixture = corev1.Secret{
+	ObjectMeta: metav1.ObjectMeta{Name: "kb-kb-http-certs-public", Namespace: "kb-ns"},
+	Data:       map[string][]byte{certificates.CertFileName: []byte("cert")},
+}
+
+func TestReconciler_reconcileAssociation_ApmKibana(t *testing.T) {
+	apm := apmFixture.DeepCopy()
+	kb := kbFixture.DeepCopy()
+	r := newTestReconciler(t, apmKibanaAssociationInfo, apm, kb, kbESFixture.DeepCopy(), kbCAFixture.DeepCopy())
+
+	status, err := r.reconcileAssociation(apm)
+	require.NoError(t, err)
+	require.Equal(t, commonv1alpha1.AssociationEstablished, status)
+
+	// the APM Server spec is updated with the Kibana connection
+	var updated apmtype.ApmServer
+	require.NoError(t, r.Get(k8s.ExtractNamespacedName(apm), &updated))
+	require.Equal(t, "https://kb-kb-http.kb-ns.svc:5601", updated.Spec.Kibana.Host)
+	require.Equal(t, "as-apm-kb-ca", updated.Spec.Kibana.SSL.CertificateAuthorities.SecretName)
+	require.Equal(t, "as-apm-kb-user", updated.Spec.Kibana.Auth.SecretKeyRef.Name)
+	require.Equal(t, "apm-ns-as-apm-kb-user", updated.Spec.Kibana.Auth.SecretKeyRef.Key)
+
+	// the Kibana CA is copied in the APM Server namespace
+	var ca corev1.Secret
+	require.NoError(t, r.Get(types.NamespacedName{Namespace: "apm-ns", Name: "as-apm-kb-ca"}, &ca))
+	require.Equal(t, kbCAFixture.Data, ca.Data)
+
+	// the user is created in the namespace of the Elasticsearch cluster referenced by Kibana
+	var user corev1.Secret
+	require.NoError(t, r.Get(types.NamespacedName{Namespace: "es-ns", Name: "apm-ns-as-apm-kb-user"}, &user))
+	require.Equal(t, apmKibanaUserRole, string(user.Data[commonuser.UserRoles]))
+}
+
+func TestReconciler_reconcileAssociation_ApmKibana_Pending(t *testing.T) {
+	tests := []struct {
+		name string
+		objs []runtime.Object
+	}{
+		{
+			name: "Kibana does not exist",
+			objs: []runtime.Object{apmFixture.DeepCopy()},
+		},
+		{
+			name: "Kibana does not reference Elasticsearch",
+			objs: []runtime.Object{apmFixture.DeepCopy(), &kbtype.Kibana{ObjectMeta: kbFixture.ObjectMeta}},
+		},
+		{
+			name: "Elasticsearch does not exist",
+			objs: []runtime.Object{apmFixture.DeepCopy(), kbFixture.DeepCopy()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestReconciler(t, apmKibanaAssociationInfo, tt.objs...)
+			status, err := r.reconcileAssociation(apmFixture.DeepCopy())
+			require.NoError(t, err)
+			require.Equal(t, commonv1alpha1.AssociationPending, status)
+		})
+	}
+}
+
+func TestReconciler_reconcileAssociation_ApmKibana_NoRef(t *testing.T) {
+	apm := apmFixture.DeepCopy()
+	apm.Spec.KibanaRef = commonv1alpha1.ObjectSelector{}
+	r := newTestReconciler(t, apmKibanaAssociationInfo, apm)
+
+	status, err := r.reconcileAssociation(apm)
+	require.NoError(t, err)
+	require.Equal(t, commonv1alpha1.AssociationUnknown, status)
+}
@@ -0,0 +1,518 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"sync/atomic"
+	"time"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	estype "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	commonassociation "github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates/http"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/finalizer"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/name"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+// Association controller
+//
+// An association completes an associated resource, such as Kibana or the APM Server, with the connection details
+// of the resource it references, such as Elasticsearch or Kibana.
+//
+// High-level overview:
+// - watch the associated resources
+// - if an associated resource references another resource, resolve details about the referenced resource
+//   (url, Elasticsearch cluster users are created in), and update the associated resource with connection details
+// - check that the Elasticsearch cluster allows associations from the namespace of the associated resource
+// - create the user of the associated resource in Elasticsearch
+// - copy the referenced resource CA public cert secret into the associated resource namespace
+// - reconcile on any change from watching the associated and referenced resources, users and secrets
+//
+// Each association is described by an AssociationInfo and runs in its own controller: several associations,
+// such as the Elasticsearch output and Kibana of the APM Server, can be set up for the same associated resource
+// as long as they use different labels, suffixes and finalizers.
+//
+// If the associated resource does not reference any resource, the association does nothing but cleaning up.
+
+var (
+	log            = logf.Log.WithName("association")
+	defaultRequeue = reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second}
+)
+
+// AssociationConf holds the connection details an association injects into the associated resource.
+type AssociationConf struct {
+	// URL is the URL of the referenced resource.
+	URL string
+	// CASecretName is the name of the secret holding the CA of the referenced resource, in the associated resource
+	// namespace.
+	CASecretName string
+	// AuthSecretKeyRef references the secret holding the password of the associated resource user.
+	AuthSecretKeyRef *corev1.SecretKeySelector
+}
+
+// ReferencedResource describes a type of resource that can be referenced by an associated resource.
+type ReferencedResource struct {
+	// ObjTemplate returns a new instance of the referenced resource type.
+	ObjTemplate func() runtime.Object
+	// Watches returns the dynamic watches on the referenced resource type.
+	Watches func(w watches.DynamicWatches) *watches.DynamicEnqueueRequest
+	// Namer names the resources of the referenced resource, such as its HTTP certificates.
+	Namer name.Namer
+	// Resolve retrieves the referenced resource and returns its URL and the Elasticsearch cluster the user of the
+	// associated resource is created in. A nil Elasticsearch cluster means that the association cannot be
+	// established yet.
+	Resolve func(c k8s.Client, ref types.NamespacedName) (string, *estype.Elasticsearch, error)
+}
+
+// AssociationInfo describes an association between an associated resource and the resource it references.
+type AssociationInfo struct {
+	// AssociationName identifies the association, for example in the name of its controller.
+	AssociationName string
+	// AssociatedObjTemplate returns a new instance of the associated resource type.
+	AssociatedObjTemplate func() commonv1alpha1.Associated
+	// AssociatedNameLabelName is the label holding the name of the associated resource on its own resources.
+	AssociatedNameLabelName string
+	// AssociatedLabels returns the labels of the resources of the associated resource with the given name.
+	AssociatedLabels func(associatedName string) map[string]string
+	// AssociationRef returns the reference of the associated resource to the referenced resource.
+	AssociationRef func(associated commonv1alpha1.Associated) commonv1alpha1.ObjectSelector
+	// AssociationStatus returns the status of the association stored in the associated resource.
+	AssociationStatus func(associated commonv1alpha1.Associated) commonv1alpha1.AssociationStatus
+	// SetAssociationStatus stores the status of the association in the associated resource.
+	SetAssociationStatus func(associated commonv1alpha1.Associated, status commonv1alpha1.AssociationStatus)
+	// SetAssociationConf injects the connection details in the spec of the associated resource, or removes them if
+	// the given configuration is empty. It returns true if the spec was changed.
+	SetAssociationConf func(associated commonv1alpha1.Associated, conf AssociationConf) bool
+
+	// Referenced describes the type of the referenced resource.
+	Referenced ReferencedResource
+	// ExternalElasticsearchRef returns the reference of the associated resource to an Elasticsearch cluster not
+	// managed by the operator, used instead of AssociationRef if defined. Nil if not supported by the association.
+	ExternalElasticsearchRef func(associated commonv1alpha1.Associated) *commonv1alpha1.ExternalElasticsearchRef
+
+	// UserSecretSuffix is used to suffix the user of the associated resource and its secret.
+	UserSecretSuffix string
+	// UserRoles are the roles of the user of the associated resource, as a comma-separated list.
+	UserRoles string
+	// ExternalRoles are created in the Elasticsearch clusters not managed by the operator and given to the user of
+	// the associated resource instead of UserRoles, to restrict its privileges there. UserRoles are used if empty.
+	ExternalRoles map[string]esclient.Role
+	// AdditionalUsers are created along with the user of the associated resource, for other needs than the
+	// connection to the referenced resource. Their credentials are not injected in the associated resource.
+	AdditionalUsers []AssociationUser
+	// CASecretSuffix is used to suffix the copy of the CA of the referenced resource.
+	CASecretSuffix string
+
+	// AssociationLabelName marks the resources created for the association with the associated resource name.
+	AssociationLabelName string
+	// AssociationLabelNamespace marks the resources created for the association with the associated resource
+	// namespace.
+	AssociationLabelNamespace string
+	// WatchFinalizerName is the name of the finalizer removing the dynamic watches of the association.
+	WatchFinalizerName string
+	// UserFinalizerName is the name of the finalizer removing the users of the association. It must be unique among
+	// the associations of the same associated resource type.
+	UserFinalizerName string
+}
+
+// AssociationUser describes a user created in Elasticsearch for the associated resource.
+type AssociationUser struct {
+	// UserSecretSuffix is used to suffix the user and its secret.
+	UserSecretSuffix string
+	// UserRoles are the roles of the user, as a comma-separated list.
+	UserRoles string
+	// ExternalRoles replace UserRoles in the Elasticsearch clusters not managed by the operator, where they are
+	// created along with the user.
+	ExternalRoles map[string]esclient.Role
+}
+
+// users returns the users created for the associated resource, starting with the user it connects with.
+func (a AssociationInfo) users() []AssociationUser {
+	return append([]AssociationUser{{UserSecretSuffix: a.UserSecretSuffix, UserRoles: a.UserRoles, ExternalRoles: a.ExternalRoles}}, a.AdditionalUsers...)
+}
+
+// controllerName returns the name of the controller of the association.
+func (a AssociationInfo) controllerName() string {
+	return a.AssociationName + "-association-controller"
+}
+
+// AddAssociationController creates a new controller reconciling the given association and adds it to the Manager.
+// The Manager will set fields on the Controller and Start it when the Manager is Started.
+func AddAssociationController(mgr manager.Manager, params operator.Parameters, info AssociationInfo) error {
+	r := newReconciler(mgr, params, info)
+	c, err := controller.New(info.controllerName(), mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	return addWatches(c, r)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, params operator.Parameters, info AssociationInfo) *Reconciler {
+	return &Reconciler{
+		AssociationInfo: info,
+		Client:          k8s.WrapClient(mgr.GetClient()),
+		scheme:          mgr.GetScheme(),
+		watches:         watches.NewDynamicWatches(),
+		recorder:        mgr.GetRecorder(info.controllerName()),
+		Parameters:      params,
+		logger:          log.WithValues("association", info.AssociationName),
+
+		newExternalESClient: newExternalESClient,
+	}
+}
+
+var _ reconcile.Reconciler = &Reconciler{}
+
+// Reconciler reconciles an associated resource for its association with a referenced resource.
+type Reconciler struct {
+	AssociationInfo
+
+	k8s.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	watches  watches.DynamicWatches
+	operator.Parameters
+	logger logr.Logger
+	// newExternalESClient creates the clients of the Elasticsearch clusters not managed by the operator
+	newExternalESClient externalESClientProvider
+	// iteration is the number of times this controller has run its Reconcile method
+	iteration int64
+}
+
+// Reconcile reads the state of the cluster for an associated resource and makes changes based on the state read
+// and the reference in the associated resource spec.
+func (r *Reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// atomically update the iteration to support concurrent runs.
+	currentIteration := atomic.AddInt64(&r.iteration, 1)
+	iterationStartTime := time.Now()
+	r.logger.Info("Start reconcile iteration", "iteration", currentIteration, "namespace", request.Namespace, "name", request.Name)
+	defer func() {
+		r.logger.Info("End reconcile iteration", "iteration", currentIteration, "took", time.Since(iterationStartTime), "namespace", request.Namespace, "name", request.Name)
+	}()
+
+	associated := r.AssociatedObjTemplate()
+	if err := r.Get(request.NamespacedName, associated); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if common.IsPaused(metav1.ObjectMeta{Annotations: associated.GetAnnotations()}) {
+		r.logger.Info("Object is paused. Skipping reconciliation", "namespace", request.Namespace, "name", request.Name, "iteration", currentIteration)
+		return common.PauseRequeue, nil
+	}
+
+	// register or execute watch finalizers
+	h := finalizer.NewHandler(r)
+	err := h.Handle(
+		associated,
+		r.watchFinalizer(request.NamespacedName),
+		r.userFinalizer(associated),
+	)
+	if err != nil {
+		if apierrors.IsConflict(err) {
+			// Conflicts are expected here and should be resolved on next loop
+			r.logger.V(1).Info("Conflict while handling finalizer", "namespace", request.Namespace, "name", request.Name)
+			return reconcile.Result{Requeue: true}, nil
+		}
+		// failed to prepare or run finalizer: retry
+		return defaultRequeue, err
+	}
+
+	// the associated resource is being deleted: short-circuit reconciliation
+	if !associated.GetDeletionTimestamp().IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	selector := labels.Set(map[string]string{r.AssociatedNameLabelName: associated.GetName()}).AsSelector()
+	compat, err := annotation.ReconcileCompatibility(r.Client, associated, selector, r.OperatorInfo.BuildInfo.Version)
+	if err != nil {
+		k8s.EmitErrorEvent(r.recorder, err, associated, events.EventCompatCheckError, "Error during compatibility check: %v", err)
+		return reconcile.Result{}, err
+	}
+	if !compat {
+		// this resource is not able to be reconciled by this version of the controller, so we will skip it and not requeue
+		return reconcile.Result{}, nil
+	}
+
+	if err := annotation.UpdateControllerVersion(r.Client, associated, r.OperatorInfo.BuildInfo.Version); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	newStatus, err := r.reconcileAssociation(associated)
+	if err != nil {
+		k8s.EmitErrorEvent(r.recorder, err, associated, events.EventReconciliationError, "Reconciliation error: %v", err)
+	}
+
+	// maybe update status
+	oldStatus := r.AssociationStatus(associated)
+	if oldStatus != newStatus {
+		r.SetAssociationStatus(associated, newStatus)
+		if err := r.Status().Update(associated); err != nil {
+			if apierrors.IsConflict(err) {
+				// Conflicts are expected and will be resolved on next loop
+				r.logger.V(1).Info("Conflict while updating status", "namespace", request.Namespace, "name", request.Name)
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return defaultRequeue, err
+		}
+		r.recorder.AnnotatedEventf(associated,
+			annotation.ForAssociationStatusChange(oldStatus, newStatus),
+			corev1.EventTypeNormal,
+			events.EventAssociationStatusChange,
+			"Association status changed from [%s] to [%s]", oldStatus, newStatus)
+	}
+	return resultFromStatus(newStatus), err
+}
+
+// userFinalizer removes the users of the association when the associated resource is deleted: the user secrets
+// in the namespace of a cluster managed by the operator, and the users of an external cluster. The latter is best
+// effort since the external cluster may not be reachable anymore, which must not prevent the deletion.
+func (r *Reconciler) userFinalizer(associated commonv1alpha1.Associated) finalizer.Finalizer {
+	associatedKey := k8s.ExtractNamespacedName(associated)
+	f := user.UserFinalizer(r.Client, r.UserFinalizerName, r.newUserLabelSelector(associatedKey))
+	deleteUsers := f.Execute
+	f.Execute = func() error {
+		if err := r.deleteExternalUsers(associated, ""); err != nil {
+			r.logger.Error(err, "Cannot delete users from external Elasticsearch cluster, they must be deleted manually", "namespace", associatedKey.Namespace, "name", associatedKey.Name)
+		}
+		return deleteUsers()
+	}
+	return f
+}
+
+func resultFromStatus(status commonv1alpha1.AssociationStatus) reconcile.Result {
+	switch status {
+	case commonv1alpha1.AssociationPending:
+		return defaultRequeue // retry
+	default:
+		return reconcile.Result{} // we are done or there is not much we can do
+	}
+}
+
+func (r *Reconciler) reconcileAssociation(associated commonv1alpha1.Associated) (commonv1alpha1.AssociationStatus, error) {
+	associatedKey := k8s.ExtractNamespacedName(associated)
+
+	if externalRef := r.externalReference(associated); externalRef != nil {
+		return r.reconcileExternalAssociation(associated, *externalRef)
+	}
+	// stop watching the secrets of any external Elasticsearch cluster previously referenced
+	r.watches.Secrets.RemoveHandlerForKey(externalWatchName(associatedKey))
+
+	ref := r.AssociationRef(associated)
+	if !ref.IsDefined() {
+		// stop watching any resource previously referenced
+		r.removeWatches(associatedKey)
+		// the users created in an external cluster previously referenced are deleted first, their secrets are
+		// kept until then
+		externalErr := r.deleteExternalUsers(associated, "")
+		// garbage collect leftover resources that are not required anymore
+		if err := r.deleteOrphanedResources(associated, ""); err != nil {
+			r.logger.Error(err, "Error while trying to delete orphaned resources. Continuing.", "namespace", associatedKey.Namespace, "name", associatedKey.Name)
+		}
+		return commonv1alpha1.AssociationUnknown, externalErr
+	}
+	if ref.Namespace == "" {
+		// no namespace provided: default to the associated resource namespace
+		ref.Namespace = associated.GetNamespace()
+	}
+	refKey := ref.NamespacedName()
+
+	// watch the referenced resource for future reconciliations
+	if err := r.Referenced.Watches(r.watches).AddHandler(watches.NamedWatch{
+		Name:    referencedResourceWatchName(associatedKey),
+		Watched: []types.NamespacedName{refKey},
+		Watcher: associatedKey,
+	}); err != nil {
+		return commonv1alpha1.AssociationFailed, err
+	}
+
+	url, es, err := r.Referenced.Resolve(r.Client, refKey)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return commonv1alpha1.AssociationFailed, err
+	}
+	if err != nil || es == nil {
+		if err != nil {
+			k8s.EmitErrorEvent(r.recorder, err, associated, events.EventAssociationError, "Failed to find referenced resource %s: %v", refKey, err)
+		}
+		// Referenced resource not found or not ready yet. Several options:
+		// - not created yet: that's ok, we'll reconcile on creation event
+		// - deleted: existing resources will be garbage collected
+		// in any case, since the user explicitly requested a managed association,
+		// remove connection details if they are set
+		if r.SetAssociationConf(associated, AssociationConf{}) {
+			r.logger.Info("Removing connection details from managed association", "namespace", associatedKey.Namespace, "name", associatedKey.Name)
+			if err := r.Update(associated); err != nil {
+				return commonv1alpha1.AssociationPending, err
+			}
+		}
+		return commonv1alpha1.AssociationPending, nil
+	}
+
+	// the Elasticsearch cluster must allow associations from the namespace of the associated resource
+	if !commonassociation.IsAllowed(*es, associatedKey.Namespace) {
+		r.recorder.Eventf(associated, corev1.EventTypeWarning, events.EventAssociationForbidden,
+			"Elasticsearch cluster %s/%s does not allow associations from namespace %s, see the %s annotation",
+			es.Namespace, es.Name, associatedKey.Namespace, commonassociation.AllowedNamespacesAnnotation)
+		// revoke any access previously granted
+		if r.SetAssociationConf(associated, AssociationConf{}) {
+			r.logger.Info("Removing connection details from forbidden association", "namespace", associatedKey.Namespace, "name", associatedKey.Name)
+			if err := r.Update(associated); err != nil {
+				return commonv1alpha1.AssociationFailed, err
+			}
+		}
+		if err := user.DeleteUsers(r.Client, r.newUserLabelSelector(associatedKey)); err != nil {
+			return commonv1alpha1.AssociationFailed, err
+		}
+		return commonv1alpha1.AssociationFailed, nil
+	}
+
+	// watch the user secrets in the Elasticsearch namespace
+	users := r.users()
+	userKeys := make([]types.NamespacedName, 0, len(users))
+	for _, u := range users {
+		userKeys = append(userKeys, commonassociation.UserKeyInNamespace(associated, es.Namespace, u.UserSecretSuffix))
+	}
+	if err := r.watches.Secrets.AddHandler(watches.NamedWatch{
+		Name:    userWatchName(associatedKey),
+		Watched: userKeys,
+		Watcher: associatedKey,
+	}); err != nil {
+		return commonv1alpha1.AssociationFailed, err
+	}
+
+	for _, u := range users {
+		if err := commonassociation.ReconcileEsUser(
+			r.Client,
+			r.scheme,
+			associated,
+			map[string]string{
+				r.AssociationLabelName:      associated.GetName(),
+				r.AssociationLabelNamespace: associated.GetNamespace(),
+			},
+			u.UserRoles,
+			u.UserSecretSuffix,
+			*es,
+		); err != nil { // TODO distinguish conflicts and non-recoverable errors here
+			return commonv1alpha1.AssociationPending, err
+		}
+	}
+
+	caSecretName, err := r.reconcileCA(associated, refKey)
+	if err != nil {
+		return commonv1alpha1.AssociationPending, err // maybe not created yet
+	}
+
+	// update the associated resource with the connection details
+	if r.SetAssociationConf(associated, AssociationConf{
+		URL:              url,
+		CASecretName:     caSecretName,
+		AuthSecretKeyRef: commonassociation.ClearTextSecretKeySelector(associated, r.UserSecretSuffix),
+	}) {
+		r.logger.Info("Updating spec with connection details", "namespace", associatedKey.Namespace, "name", associatedKey.Name)
+		if err := r.Update(associated); err != nil {
+			return commonv1alpha1.AssociationPending, err
+		}
+	}
+
+	// garbage collect leftover resources that are not required anymore, including the users of an external
+	// cluster previously referenced
+	externalErr := r.deleteExternalUsers(associated, "")
+	if err := r.deleteOrphanedResources(associated, es.Namespace); err != nil {
+		r.logger.Error(err, "Error while trying to delete orphaned resources. Continuing.", "namespace", associatedKey.Namespace, "name", associatedKey.Name)
+	}
+
+	return commonv1alpha1.AssociationEstablished, externalErr
+}
+
+// reconcileCA copies the CA of the referenced resource in the associated resource namespace.
+func (r *Reconciler) reconcileCA(associated commonv1alpha1.Associated, ref types.NamespacedName) (string, error) {
+	associatedKey := k8s.ExtractNamespacedName(associated)
+	// watch the CA secret of the referenced resource to reconcile on any change
+	if err := r.watches.Secrets.AddHandler(watches.NamedWatch{
+		Name:    caWatchName(associatedKey),
+		Watched: []types.NamespacedName{http.PublicCertsSecretRef(r.Referenced.Namer, ref)},
+		Watcher: associatedKey,
+	}); err != nil {
+		return "", err
+	}
+	// Build the labels applied on the secret
+	labels := r.AssociatedLabels(associated.GetName())
+	labels[r.AssociationLabelName] = associated.GetName()
+	return commonassociation.ReconcileCASecret(
+		r.Client,
+		r.scheme,
+		associated,
+		r.Referenced.Namer,
+		ref,
+		labels,
+		r.CASecretSuffix,
+	)
+}
+
+// deleteOrphanedResources deletes resources created by this association that are left over from previous
+// reconciliation attempts: all of them if the associated resource does not reference any resource anymore, the
+// users living in another namespace than the one of the Elasticsearch cluster currently in use, for example
+// because the reference was changed to another namespace, or all but the user secrets of the associated resource if
+// it references an external Elasticsearch cluster. User secrets still recording an external cluster are kept until
+// the user is deleted from it, see deleteExternalUsers.
+func (r *Reconciler) deleteOrphanedResources(associated commonv1alpha1.Associated, esNamespace string) error {
+	var secrets corev1.SecretList
+	selector := NewResourceSelector(r.AssociationLabelName, associated.GetName())
+	if err := r.List(&client.ListOptions{LabelSelector: selector}, &secrets); err != nil {
+		return err
+	}
+
+	refDefined := r.AssociationRef(associated).IsDefined()
+	external := r.externalReference(associated) != nil
+	userSecretNames := make(map[string]bool)
+	for _, u := range r.users() {
+		userSecretNames[commonassociation.ClearTextSecretKeySelector(associated, u.UserSecretSuffix).Name] = true
+	}
+	for _, s := range secrets.Items {
+		if !metav1.IsControlledBy(&s, associated) && !r.hasBeenCreatedBy(&s, associated) {
+			continue
+		}
+		if _, pending := s.Annotations[externalClusterAnnotation]; pending {
+			// the secret is needed to delete the user from the external cluster it was created in
+			continue
+		}
+		if external && s.Namespace == associated.GetNamespace() && userSecretNames[s.Name] {
+			continue
+		}
+		if refDefined && (esNamespace == "" || s.Labels[common.TypeLabelName] != user.UserType || s.Namespace == esNamespace) {
+			continue
+		}
+		r.logger.Info("Deleting secret", "namespace", s.Namespace, "secret_name", s.Name, "name", associated.GetName())
+		if err := r.Delete(&s); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,512 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+
+	apmtype "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1alpha1"
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	estype "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	kbtype "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	userName       = "default-kibana-foo-kibana-user"
+	userSecretName = "kibana-foo-kibana-user" // nolint
+)
+
+var esFixture = estype.Elasticsearch{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "es-foo",
+		Namespace: "default",
+		UID:       "f8d564d9-885e-11e9-896d-08002703f062",
+	},
+}
+
+var esRefFixture = metav1.OwnerReference{
+	APIVersion:         "elasticsearch.k8s.elastic.co/v1alpha1",
+	Kind:               "Elasticsearch",
+	Name:               "es-foo",
+	UID:                "f8d564d9-885e-11e9-896d-08002703f062",
+	Controller:         &t,
+	BlockOwnerDeletion: &t,
+}
+
+func setupScheme(t *testing.T) *runtime.Scheme {
+	sc := scheme.Scheme
+	require.NoError(t, apmtype.SchemeBuilder.AddToScheme(sc))
+	require.NoError(t, kbtype.SchemeBuilder.AddToScheme(sc))
+	require.NoError(t, estype.SchemeBuilder.AddToScheme(sc))
+	return sc
+}
+
+func newTestReconciler(t *testing.T, info AssociationInfo, objs ...runtime.Object) *Reconciler {
+	sc := setupScheme(t)
+	w := watches.NewDynamicWatches()
+	require.NoError(t, w.InjectScheme(sc))
+	return &Reconciler{
+		AssociationInfo: info,
+		Client:          k8s.WrapClient(fake.NewFakeClientWithScheme(sc, objs...)),
+		scheme:          sc,
+		watches:         w,
+		recorder:        record.NewFakeRecorder(100),
+		logger:          log,
+	}
+}
+
+var kibanaFixtureUID types.UID = "82257b19-8862-11e9-896d-08002703f062"
+
+var kibanaFixtureObjectMeta = metav1.ObjectMeta{
+	Name:      "kibana-foo",
+	Namespace: "default",
+	UID:       kibanaFixtureUID,
+}
+
+var kibanaFixture = kbtype.Kibana{
+	ObjectMeta: kibanaFixtureObjectMeta,
+	Spec: kbtype.KibanaSpec{
+		ElasticsearchRef: commonv1alpha1.ObjectSelector{
+			Name:      esFixture.Name,
+			Namespace: esFixture.Namespace,
+		},
+	},
+}
+
+var t = true
+var ownerRefFixture = metav1.OwnerReference{
+	APIVersion:         "kibana.k8s.elastic.co/v1alpha1",
+	Kind:               "Kibana",
+	Name:               "foo",
+	UID:                kibanaFixtureUID,
+	Controller:         &t,
+	BlockOwnerDeletion: &t,
+}
+
+func TestReconciler_deleteOrphanedResources(t *testing.T) {
+	tests := []struct {
+		name           string
+		kibana         kbtype.Kibana
+		esNamespace    string
+		initialObjects []runtime.Object
+		postCondition  func(c k8s.Client)
+		wantErr        bool
+	}{
+		{
+			name:        "Do not delete if there's no namespace in the ref",
+			esNamespace: "default",
+			kibana: kbtype.Kibana{
+				ObjectMeta: kibanaFixtureObjectMeta,
+				Spec: kbtype.KibanaSpec{
+					ElasticsearchRef: commonv1alpha1.ObjectSelector{ // ElasticsearchRef without a namespace
+						Name: esFixture.Name,
+						//Namespace: esFixture.Namespace, No namespace on purpose
+					},
+				},
+			},
+			initialObjects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      userSecretName,
+						Namespace: kibanaFixture.Namespace,
+						OwnerReferences: []metav1.OwnerReference{
+							ownerRefFixture,
+						},
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      association.ElasticsearchCACertSecretName(&kibanaFixture, KibanaESCASecretSuffix),
+						Namespace: kibanaFixture.Namespace,
+						OwnerReferences: []metav1.OwnerReference{
+							ownerRefFixture,
+						},
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      userName,
+						Namespace: "default",
+						OwnerReferences: []metav1.OwnerReference{
+							esRefFixture,
+						},
+						Labels: map[string]string{
+							KibanaESAssociationLabelName: kibanaFixture.Name,
+							common.TypeLabelName:         user.UserType,
+						},
+					},
+				},
+			},
+			postCondition: func(c k8s.Client) {
+				assertExpectObjectsExist(t, c) // all objects must be exist
+			},
+			wantErr: false,
+		},
+		{
+			name:        "ES namespace has changed ",
+			esNamespace: "ns2",
+			kibana: kbtype.Kibana{
+				ObjectMeta: kibanaFixtureObjectMeta,
+				Spec: kbtype.KibanaSpec{
+					ElasticsearchRef: commonv1alpha1.ObjectSelector{
+						Name:      esFixture.Name,
+						Namespace: "ns2", // Kibana does not reference the default namespace anymore
+					},
+				},
+			},
+			initialObjects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      userSecretName,
+						Namespace: kibanaFixture.Namespace,
+						OwnerReferences: []metav1.OwnerReference{
+							ownerRefFixture,
+						},
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      association.ElasticsearchCACertSecretName(&kibanaFixture, KibanaESCASecretSuffix),
+						Namespace: kibanaFixture.Namespace,
+						OwnerReferences: []metav1.OwnerReference{
+							ownerRefFixture,
+						},
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      userName,
+						Namespace: "default", // but we still have a user secret in default
+						OwnerReferences: []metav1.OwnerReference{
+							esRefFixture,
+						},
+						Labels: map[string]string{
+							KibanaESAssociationLabelName:      kibanaFixture.Name,
+							KibanaESAssociationLabelNamespace: kibanaFixture.Namespace,
+							common.TypeLabelName:              user.UserType,
+						},
+					},
+				},
+			},
+			postCondition: func(c k8s.Client) {
+				// user CR should be in ES namespace
+				assert.Error(t, c.Get(types.NamespacedName{
+					Namespace: esFixture.Namespace,
+					Name:      userName,
+				}, &corev1.Secret{}),
+					"Previous user secret should have been removed")
+			},
+			wantErr: false,
+		},
+		{
+			name:    "nothing to delete",
+			kibana:  kbtype.Kibana{},
+			wantErr: false,
+		},
+		{
+			name:        "only valid objects",
+			kibana:      kibanaFixture,
+			esNamespace: esFixture.Namespace,
+			initialObjects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      userSecretName,
+						Namespace: kibanaFixture.Namespace,
+						OwnerReferences: []metav1.OwnerReference{
+							ownerRefFixture,
+						},
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      association.ElasticsearchCACertSecretName(&kibanaFixture, KibanaESCASecretSuffix),
+						Namespace: kibanaFixture.Namespace,
+						OwnerReferences: []metav1.OwnerReference{
+							ownerRefFixture,
+						},
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      userName,
+						Namespace: kibanaFixture.Namespace,
+						OwnerReferences: []metav1.OwnerReference{
+							esRefFixture,
+						},
+					},
+				},
+			},
+			postCondition: func(c k8s.Client) {
+				assertExpectObjectsExist(t, c)
+			},
+			wantErr: false,
+		},
+		{
+			name: "No more es ref in Kibana, orphan user & CA for previous es ref exist",
+			kibana: kbtype.Kibana{
+				ObjectMeta: kibanaFixtureObjectMeta,
+			},
+			initialObjects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      userSecretName,
+						Namespace: kibanaFixture.Namespace,
+						Labels: map[string]string{
+							KibanaESAssociationLabelName: kibanaFixture.Name,
+						},
+						OwnerReferences: []metav1.OwnerReference{
+							ownerRefFixture,
+						},
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      userName,
+						Namespace: kibanaFixture.Namespace,
+						Labels: map[string]string{
+							KibanaESAssociationLabelName:      kibanaFixture.Name,
+							KibanaESAssociationLabelNamespace: kibanaFixture.Namespace,
+						},
+						OwnerReferences: []metav1.OwnerReference{
+							esRefFixture,
+						},
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      association.ElasticsearchCACertSecretName(&kibanaFixture, KibanaESCASecretSuffix),
+						Namespace: kibanaFixture.Namespace,
+						Labels: map[string]string{
+							KibanaESAssociationLabelName: kibanaFixture.Name,
+						},
+						OwnerReferences: []metav1.OwnerReference{
+							ownerRefFixture,
+						},
+					},
+				},
+			},
+			postCondition: func(c k8s.Client) {
+				// This works even without labels because mock client currently ignores labels
+				assert.Error(t, c.Get(types.NamespacedName{
+					Namespace: kibanaFixture.Namespace,
+					Name:      userName,
+				}, &corev1.Secret{}))
+				assert.Error(t, c.Get(types.NamespacedName{
+					Namespace: kibanaFixture.Spec.ElasticsearchRef.Namespace,
+					Name:      userSecretName,
+				}, &corev1.Secret{}))
+				assert.Error(t, c.Get(types.NamespacedName{
+					Namespace: kibanaFixture.Spec.ElasticsearchRef.Namespace,
+					Name:      association.ElasticsearchCACertSecretName(&kibanaFixture, KibanaESCASecretSuffix),
+				}, &corev1.Secret{}))
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestReconciler(t, kibanaESAssociationInfo, tt.initialObjects...)
+			if err := r.deleteOrphanedResources(&tt.kibana, tt.esNamespace); (err != nil) != tt.wantErr {
+				t.Errorf("deleteOrphanedResources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.postCondition != nil {
+				tt.postCondition(r.Client)
+			}
+		})
+	}
+}
+
+func TestReconciler_deleteOrphanedResources_ApmServer(t *testing.T) {
+	apm := apmtype.ApmServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "as", Namespace: "apm-ns"},
+		Spec: apmtype.ApmServerSpec{
+			ElasticsearchRef: commonv1alpha1.ObjectSelector{Name: esFixture.Name, Namespace: "es-ns"},
+		},
+	}
+	// labels of the resources created for the association of the APM Server
+	associationLabels := func(namespace string, isUser bool) map[string]string {
+		l := map[string]string{
+			ApmESAssociationLabelName:      apm.Name,
+			ApmESAssociationLabelNamespace: namespace,
+		}
+		if isUser {
+			l[common.TypeLabelName] = user.UserType
+		}
+		return l
+	}
+	secret := func(namespace, name string, labels map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels}}
+	}
+	apmUserSecret := secret(apm.Namespace, "as-apm-user", associationLabels(apm.Namespace, false))
+	apmCASecret := secret(apm.Namespace, association.ElasticsearchCACertSecretName(&apm, apmESCASecretSuffix), associationLabels(apm.Namespace, false))
+	esUser := func(esNamespace string) *corev1.Secret {
+		return secret(esNamespace, "apm-ns-as-apm-user", associationLabels(apm.Namespace, true))
+	}
+	// the user of an APM Server with the same name in another namespace
+	otherESUser := secret("es-ns", "other-ns-as-apm-user", associationLabels("other-ns", true))
+
+	tests := []struct {
+		name        string
+		apm         func() apmtype.ApmServer
+		esNamespace string
+		wantDeleted []*corev1.Secret
+		wantKept    []*corev1.Secret
+	}{
+		{
+			name:        "only valid objects",
+			apm:         func() apmtype.ApmServer { return apm },
+			esNamespace: "es-ns",
+			wantKept:    []*corev1.Secret{apmUserSecret, apmCASecret, esUser("es-ns"), otherESUser},
+		},
+		{
+			name: "ES namespace has changed, the user in the previous ES namespace is deleted",
+			apm: func() apmtype.ApmServer {
+				moved := *apm.DeepCopy()
+				moved.Spec.ElasticsearchRef.Namespace = "es-ns2"
+				return moved
+			},
+			esNamespace: "es-ns2",
+			wantDeleted: []*corev1.Secret{esUser("es-ns")},
+			wantKept:    []*corev1.Secret{apmUserSecret, apmCASecret, esUser("es-ns2"), otherESUser},
+		},
+		{
+			name: "No more ES ref, the user and CA of the association are deleted",
+			apm: func() apmtype.ApmServer {
+				removed := *apm.DeepCopy()
+				removed.Spec.ElasticsearchRef = commonv1alpha1.ObjectSelector{}
+				return removed
+			},
+			wantDeleted: []*corev1.Secret{apmUserSecret, apmCASecret, esUser("es-ns")},
+			wantKept:    []*corev1.Secret{otherESUser},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var initialObjects []runtime.Object
+			for _, s := range append(tt.wantDeleted, tt.wantKept...) {
+				initialObjects = append(initialObjects, s.DeepCopy())
+			}
+			r := newTestReconciler(t, apmESAssociationInfo, initialObjects...)
+			associated := tt.apm()
+			require.NoError(t, r.deleteOrphanedResources(&associated, tt.esNamespace))
+			for _, s := range tt.wantDeleted {
+				err := r.Get(k8s.ExtractNamespacedName(s), &corev1.Secret{})
+				require.True(t, apierrors.IsNotFound(err), "secret %s/%s should be deleted", s.Namespace, s.Name)
+			}
+			for _, s := range tt.wantKept {
+				require.NoError(t, r.Get(k8s.ExtractNamespacedName(s), &corev1.Secret{}), "secret %s/%s should be kept", s.Namespace, s.Name)
+			}
+		})
+	}
+}
+
+func TestReconciler_reconcileAssociation_ReferenceNotFound(t *testing.T) {
+	// Kibana still has the connection details of an Elasticsearch cluster that does not exist anymore
+	kibana := kibanaFixture.DeepCopy()
+	kibana.Spec.Elasticsearch = kbtype.BackendElasticsearch{URL: "https://es-foo-es-http.default.svc:9200"}
+	r := newTestReconciler(t, kibanaESAssociationInfo, kibana)
+
+	status, err := r.reconcileAssociation(kibana)
+	require.NoError(t, err)
+	require.Equal(t, commonv1alpha1.AssociationPending, status)
+
+	// connection details are removed
+	var updated kbtype.Kibana
+	require.NoError(t, r.Get(k8s.ExtractNamespacedName(kibana), &updated))
+	require.Equal(t, kbtype.BackendElasticsearch{}, updated.Spec.Elasticsearch)
+}
+
+func TestReconciler_reconcileAssociation_Forbidden(t *testing.T) {
+	// Kibana in another namespace than Elasticsearch, with connection details and a user from a previous association
+	kibana := kibanaFixture.DeepCopy()
+	kibana.Namespace = "kb-ns"
+	kibana.Spec.Elasticsearch = kbtype.BackendElasticsearch{URL: "https://es-foo-es-http.default.svc:9200"}
+	esUser := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: esFixture.Namespace,
+			Name:      "kb-ns-kibana-foo-kibana-user",
+			Labels: map[string]string{
+				KibanaESAssociationLabelName:      kibana.Name,
+				KibanaESAssociationLabelNamespace: kibana.Namespace,
+				common.TypeLabelName:              user.UserType,
+			},
+		},
+	}
+	r := newTestReconciler(t, kibanaESAssociationInfo, kibana, esFixture.DeepCopy(), &esUser)
+
+	status, err := r.reconcileAssociation(kibana)
+	require.NoError(t, err)
+	require.Equal(t, commonv1alpha1.AssociationFailed, status)
+
+	// connection details and user are removed
+	var updated kbtype.Kibana
+	require.NoError(t, r.Get(k8s.ExtractNamespacedName(kibana), &updated))
+	require.Equal(t, kbtype.BackendElasticsearch{}, updated.Spec.Elasticsearch)
+	err = r.Get(k8s.ExtractNamespacedName(&esUser), &corev1.Secret{})
+	require.True(t, apierrors.IsNotFound(err))
+}
+
+func TestReconciler_reconcileAssociation_KibanaUsers(t *testing.T) {
+	kibana := kibanaFixture.DeepCopy()
+	esCA := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "es-foo-es-http-certs-public", Namespace: esFixture.Namespace},
+		Data:       map[string][]byte{certificates.CertFileName: []byte("cert")},
+	}
+	r := newTestReconciler(t, kibanaESAssociationInfo, kibana, esFixture.DeepCopy(), &esCA)
+
+	status, err := r.reconcileAssociation(kibana)
+	require.NoError(t, err)
+	require.Equal(t, commonv1alpha1.AssociationEstablished, status)
+
+	// Kibana connects to Elasticsearch with the kibana_system user
+	var updated kbtype.Kibana
+	require.NoError(t, r.Get(k8s.ExtractNamespacedName(kibana), &updated))
+	require.Equal(t, userSecretName, updated.Spec.Elasticsearch.Auth.SecretKeyRef.Name)
+
+	// a user with Kibana privileges is created to import saved objects
+	for name, roles := range map[string]string{
+		userName:                            "kibana_system",
+		"default-kibana-foo-kibana-so-user": "kibana_user",
+	} {
+		var esUser corev1.Secret
+		require.NoError(t, r.Get(types.NamespacedName{Namespace: esFixture.Namespace, Name: name}, &esUser))
+		require.Equal(t, roles, string(esUser.Data[user.UserRoles]))
+	}
+	require.NoError(t, r.Get(types.NamespacedName{Namespace: kibana.Namespace, Name: "kibana-foo-kibana-so-user"}, &corev1.Secret{}))
+}
+
+func assertExpectObjectsExist(t *testing.T, c k8s.Client) {
+	// user CR should be in ES namespace
+	assert.NoError(t, c.Get(types.NamespacedName{
+		Namespace: esFixture.Namespace,
+		Name:      userName,
+	}, &corev1.Secret{}))
+	// user secret should be in Kibana namespace
+	assert.NoError(t, c.Get(types.NamespacedName{
+		Namespace: kibanaFixture.Namespace,
+		Name:      userSecretName,
+	}, &corev1.Secret{}))
+	// ca secret should be in Kibana namespace
+	assert.NoError(t, c.Get(types.NamespacedName{
+		Namespace: kibanaFixture.Namespace,
+		Name:      association.ElasticsearchCACertSecretName(&kibanaFixture, KibanaESCASecretSuffix),
+	}, &corev1.Secret{}))
+}
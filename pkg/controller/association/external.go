@@ -0,0 +1,310 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	commonassociation "github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// externalClusterAnnotation records on the user secrets of an association the reference to the external
+// Elasticsearch cluster the user was created in, so it can be deleted from there once not needed anymore.
+const externalClusterAnnotation = "association.k8s.elastic.co/external-elasticsearch"
+
+// infoRequestVersion is the version used to build the client retrieving the version of an external Elasticsearch
+// cluster, the request being the same for all supported versions.
+var infoRequestVersion = version.MustParse("6.8.0")
+
+// externalESClientProvider creates a client for an Elasticsearch cluster not managed by the operator.
+type externalESClientProvider func(url string, user esclient.UserAuth, v version.Version, caCerts []*x509.Certificate) esclient.Client
+
+// newExternalESClient is the default externalESClientProvider. The operator dialer is not used since the external
+// cluster is not reached through a Kubernetes service.
+func newExternalESClient(url string, user esclient.UserAuth, v version.Version, caCerts []*x509.Certificate) esclient.Client {
+	return esclient.NewElasticsearchClient(nil, url, user, v, caCerts)
+}
+
+// externalReferenceKeys returns the keys of the admin and CA secrets referenced by an external Elasticsearch reference.
+func externalReferenceKeys(associated commonv1alpha1.Associated, ref commonv1alpha1.ExternalElasticsearchRef) []types.NamespacedName {
+	keys := []types.NamespacedName{{Namespace: associated.GetNamespace(), Name: ref.AdminSecretName}}
+	if ref.CertificateAuthorities.SecretName != "" {
+		keys = append(keys, types.NamespacedName{Namespace: associated.GetNamespace(), Name: ref.CertificateAuthorities.SecretName})
+	}
+	return keys
+}
+
+// externalReference returns the external Elasticsearch reference of the associated resource, or nil if the
+// association does not support it or the associated resource does not use it.
+func (r *Reconciler) externalReference(associated commonv1alpha1.Associated) *commonv1alpha1.ExternalElasticsearchRef {
+	if r.ExternalElasticsearchRef == nil {
+		return nil
+	}
+	ref := r.ExternalElasticsearchRef(associated)
+	if !ref.IsDefined() {
+		return nil
+	}
+	return ref
+}
+
+// reconcileExternalAssociation establishes the association with an Elasticsearch cluster not managed by the
+// operator: the user of the associated resource is created through the Elasticsearch security API, using the
+// credentials of the admin secret, and the CA provided by the user is used as is. The users created in another
+// external cluster previously referenced are deleted from it.
+func (r *Reconciler) reconcileExternalAssociation(
+	associated commonv1alpha1.Associated,
+	ref commonv1alpha1.ExternalElasticsearchRef,
+) (commonv1alpha1.AssociationStatus, error) {
+	associatedKey := k8s.ExtractNamespacedName(associated)
+
+	// stop watching any resource managed by the operator previously referenced
+	r.Referenced.Watches(r.watches).RemoveHandlerForKey(referencedResourceWatchName(associatedKey))
+	r.watches.Secrets.RemoveHandlerForKey(caWatchName(associatedKey))
+	r.watches.Secrets.RemoveHandlerForKey(userWatchName(associatedKey))
+
+	// watch the admin and CA secrets to reconcile on any change
+	if err := r.watches.Secrets.AddHandler(watches.NamedWatch{
+		Name:    externalWatchName(associatedKey),
+		Watched: externalReferenceKeys(associated, ref),
+		Watcher: associatedKey,
+	}); err != nil {
+		return commonv1alpha1.AssociationFailed, err
+	}
+
+	esClient, err := r.connectExternal(associated.GetNamespace(), ref)
+	if err != nil {
+		k8s.EmitErrorEvent(r.recorder, err, associated, events.EventAssociationError, "Cannot connect to external Elasticsearch cluster %s: %v", ref.URL, err)
+		return commonv1alpha1.AssociationPending, nil
+	}
+	defer esClient.Close()
+
+	if err := r.deleteExternalUsers(associated, ref.URL); err != nil {
+		k8s.EmitErrorEvent(r.recorder, err, associated, events.EventAssociationError, "Cannot delete users from the previous external Elasticsearch cluster, they must be deleted manually: %v", err)
+	}
+
+	for _, u := range r.users() {
+		userName, password, err := commonassociation.ReconcileUserSecret(
+			r.Client,
+			r.scheme,
+			associated,
+			map[string]string{
+				r.AssociationLabelName:      associated.GetName(),
+				r.AssociationLabelNamespace: associated.GetNamespace(),
+			},
+			u.UserSecretSuffix,
+		)
+		if err != nil {
+			return commonv1alpha1.AssociationPending, err
+		}
+		// record the cluster before creating the user, so it can be deleted later on
+		if err := r.recordExternalCluster(associated, u.UserSecretSuffix, ref); err != nil {
+			return commonv1alpha1.AssociationPending, err
+		}
+
+		roles := strings.Split(u.UserRoles, ",")
+		if len(u.ExternalRoles) > 0 {
+			if err := putExternalRoles(esClient, u.ExternalRoles); err != nil {
+				k8s.EmitErrorEvent(r.recorder, err, associated, events.EventAssociationError, "Cannot create roles in external Elasticsearch cluster %s: %v", ref.URL, err)
+				return commonv1alpha1.AssociationPending, nil
+			}
+			roles = roleNames(u.ExternalRoles)
+		}
+		// the user is created or updated on each reconciliation, which also restores it if removed from the cluster
+		if err := putExternalUser(esClient, userName, esclient.User{
+			Password: string(password),
+			Roles:    roles,
+		}); err != nil {
+			k8s.EmitErrorEvent(r.recorder, err, associated, events.EventAssociationError, "Cannot create user %s in external Elasticsearch cluster %s: %v", userName, ref.URL, err)
+			return commonv1alpha1.AssociationPending, nil
+		}
+	}
+
+	// update the associated resource with the connection details
+	if r.SetAssociationConf(associated, AssociationConf{
+		URL:              ref.URL,
+		CASecretName:     ref.CertificateAuthorities.SecretName,
+		AuthSecretKeyRef: commonassociation.ClearTextSecretKeySelector(associated, r.UserSecretSuffix),
+	}) {
+		r.logger.Info("Updating spec with external connection details", "namespace", associatedKey.Namespace, "name", associatedKey.Name)
+		if err := r.Update(associated); err != nil {
+			return commonv1alpha1.AssociationPending, err
+		}
+	}
+
+	// garbage collect the users and the CA copy left over from a previous association with a managed cluster
+	if err := r.deleteOrphanedResources(associated, ""); err != nil {
+		r.logger.Error(err, "Error while trying to delete orphaned resources. Continuing.", "namespace", associatedKey.Namespace, "name", associatedKey.Name)
+	}
+
+	return commonv1alpha1.AssociationEstablished, nil
+}
+
+// putExternalUser creates or updates the given user in an external Elasticsearch cluster.
+func putExternalUser(esClient esclient.Client, userName string, user esclient.User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+	defer cancel()
+	return esClient.PutUser(ctx, userName, user)
+}
+
+// putExternalRoles creates or updates the given roles in an external Elasticsearch cluster.
+func putExternalRoles(esClient esclient.Client, roles map[string]esclient.Role) error {
+	for _, name := range roleNames(roles) {
+		ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+		err := esClient.PutRole(ctx, name, roles[name])
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// roleNames returns the sorted names of the given roles.
+func roleNames(roles map[string]esclient.Role) []string {
+	names := make([]string, 0, len(roles))
+	for name := range roles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// recordExternalCluster records the given external cluster on the secret of the user with the given suffix.
+func (r *Reconciler) recordExternalCluster(
+	associated commonv1alpha1.Associated,
+	userSuffix string,
+	ref commonv1alpha1.ExternalElasticsearchRef,
+) error {
+	value, err := json.Marshal(ref)
+	if err != nil {
+		return err
+	}
+	var secret corev1.Secret
+	key := types.NamespacedName{
+		Namespace: associated.GetNamespace(),
+		Name:      commonassociation.ClearTextSecretKeySelector(associated, userSuffix).Name,
+	}
+	if err := r.Get(key, &secret); err != nil {
+		return err
+	}
+	if secret.Annotations[externalClusterAnnotation] == string(value) {
+		return nil
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[externalClusterAnnotation] = string(value)
+	return r.Update(&secret)
+}
+
+// deleteExternalUsers deletes the users of the associated resource from the external cluster recorded on their
+// secrets, unless it is the cluster with the given URL, and removes the record.
+func (r *Reconciler) deleteExternalUsers(associated commonv1alpha1.Associated, keepURL string) error {
+	for _, u := range r.users() {
+		selector := commonassociation.ClearTextSecretKeySelector(associated, u.UserSecretSuffix)
+		var secret corev1.Secret
+		err := r.Get(types.NamespacedName{Namespace: associated.GetNamespace(), Name: selector.Name}, &secret)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		value, recorded := secret.Annotations[externalClusterAnnotation]
+		if !recorded {
+			continue
+		}
+		var ref commonv1alpha1.ExternalElasticsearchRef
+		if err := json.Unmarshal([]byte(value), &ref); err != nil {
+			return err
+		}
+		if ref.URL == keepURL {
+			continue
+		}
+		r.logger.Info("Deleting user from external Elasticsearch cluster", "namespace", associated.GetNamespace(), "name", associated.GetName(), "user_name", selector.Key, "url", ref.URL)
+		if err := r.deleteExternalUser(associated.GetNamespace(), ref, selector.Key); err != nil {
+			return err
+		}
+		delete(secret.Annotations, externalClusterAnnotation)
+		if err := r.Update(&secret); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteExternalUser deletes the given user from an external Elasticsearch cluster, if it exists.
+func (r *Reconciler) deleteExternalUser(namespace string, ref commonv1alpha1.ExternalElasticsearchRef, userName string) error {
+	esClient, err := r.connectExternal(namespace, ref)
+	if err != nil {
+		return err
+	}
+	defer esClient.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+	defer cancel()
+	if err := esClient.DeleteUser(ctx, userName); err != nil && !esclient.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// connectExternal returns a client for the external Elasticsearch cluster, authenticated with the admin credentials,
+// once the cluster is known to be reachable.
+func (r *Reconciler) connectExternal(namespace string, ref commonv1alpha1.ExternalElasticsearchRef) (esclient.Client, error) {
+	var adminSecret corev1.Secret
+	if err := r.Get(types.NamespacedName{Namespace: namespace, Name: ref.AdminSecretName}, &adminSecret); err != nil {
+		return nil, err
+	}
+	admin := esclient.UserAuth{
+		Name:     string(adminSecret.Data[corev1.BasicAuthUsernameKey]),
+		Password: string(adminSecret.Data[corev1.BasicAuthPasswordKey]),
+	}
+	if admin.Name == "" || admin.Password == "" {
+		return nil, fmt.Errorf("secret %s must contain the %s and %s keys", ref.AdminSecretName, corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey)
+	}
+
+	var caCerts []*x509.Certificate
+	if ref.CertificateAuthorities.SecretName != "" {
+		var caSecret corev1.Secret
+		if err := r.Get(types.NamespacedName{Namespace: namespace, Name: ref.CertificateAuthorities.SecretName}, &caSecret); err != nil {
+			return nil, err
+		}
+		certs, err := certificates.ParsePEMCerts(caSecret.Data[certificates.CertFileName])
+		if err != nil {
+			return nil, err
+		}
+		caCerts = certs
+	}
+
+	// the version of the cluster is needed to use the right API, retrieve it first
+	infoClient := r.newExternalESClient(ref.URL, admin, infoRequestVersion, caCerts)
+	defer infoClient.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+	defer cancel()
+	info, err := infoClient.GetClusterInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v, err := version.Parse(info.Version.Number)
+	if err != nil {
+		return nil, err
+	}
+	return r.newExternalESClient(ref.URL, admin, *v, caCerts), nil
+}
@@ -0,0 +1,262 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	apmtype "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1alpha1"
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	kbtype "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var externalKibanaFixture = kbtype.Kibana{
+	ObjectMeta: kibanaFixtureObjectMeta,
+	Spec: kbtype.KibanaSpec{
+		ExternalElasticsearchRef: &commonv1alpha1.ExternalElasticsearchRef{
+			URL:                    "https://legacy-es:9200",
+			AdminSecretName:        "legacy-es-admin",
+			CertificateAuthorities: commonv1alpha1.SecretRef{SecretName: "legacy-es-ca"},
+		},
+	},
+}
+
+var externalAdminSecretFixture = corev1.Secret{
+	ObjectMeta: metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      "legacy-es-admin",
+	},
+	Data: map[string][]byte{
+		corev1.BasicAuthUsernameKey: []byte("admin"),
+		corev1.BasicAuthPasswordKey: []byte("changeme"),
+	},
+}
+
+func externalCASecret(t *testing.T) *corev1.Secret {
+	ca, err := certificates.NewSelfSignedCA(certificates.CABuilderOptions{})
+	require.NoError(t, err)
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "legacy-es-ca",
+		},
+		Data: map[string][]byte{
+			certificates.CertFileName: ca.TrustedCertsPem(),
+		},
+	}
+}
+
+// fakeExternalES mocks an external Elasticsearch cluster, recording the users and roles created through its API.
+type fakeExternalES struct {
+	t     *testing.T
+	url   string
+	users map[string]esclient.User
+	roles map[string]esclient.Role
+}
+
+func newFakeExternalES(t *testing.T, url string) *fakeExternalES {
+	return &fakeExternalES{t: t, url: url, users: map[string]esclient.User{}, roles: map[string]esclient.Role{}}
+}
+
+// externalESProvider returns a provider of clients for the given external clusters.
+func externalESProvider(clusters ...*fakeExternalES) externalESClientProvider {
+	return func(url string, u esclient.UserAuth, v version.Version, caCerts []*x509.Certificate) esclient.Client {
+		for _, es := range clusters {
+			if es.url == url {
+				return es.client(u, v, caCerts)
+			}
+		}
+		clusters[0].t.Fatalf("unexpected external cluster %s", url)
+		return nil
+	}
+}
+
+func (f *fakeExternalES) client(u esclient.UserAuth, v version.Version, caCerts []*x509.Certificate) esclient.Client {
+	require.Len(f.t, caCerts, 1)
+	return esclient.NewMockClientWithUser(v, u, func(req *http.Request) *http.Response {
+		name, password, ok := req.BasicAuth()
+		if !ok || name != "admin" || password != "changeme" {
+			return esclient.NewMockResponse(401, req, "{}")
+		}
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/":
+			return esclient.NewMockResponse(200, req, `{"cluster_name":"legacy","version":{"number":"7.3.0"}}`)
+		case req.Method == http.MethodPut && strings.HasPrefix(req.URL.Path, "/_security/user/"):
+			var esUser esclient.User
+			require.NoError(f.t, json.NewDecoder(req.Body).Decode(&esUser))
+			f.users[strings.TrimPrefix(req.URL.Path, "/_security/user/")] = esUser
+			return esclient.NewMockResponse(200, req, `{"created":true}`)
+		case req.Method == http.MethodDelete && strings.HasPrefix(req.URL.Path, "/_security/user/"):
+			userName := strings.TrimPrefix(req.URL.Path, "/_security/user/")
+			if _, exists := f.users[userName]; !exists {
+				return esclient.NewMockResponse(404, req, `{"found":false}`)
+			}
+			delete(f.users, userName)
+			return esclient.NewMockResponse(200, req, `{"found":true}`)
+		case req.Method == http.MethodPut && strings.HasPrefix(req.URL.Path, "/_security/role/"):
+			var role esclient.Role
+			require.NoError(f.t, json.NewDecoder(req.Body).Decode(&role))
+			f.roles[strings.TrimPrefix(req.URL.Path, "/_security/role/")] = role
+			return esclient.NewMockResponse(200, req, `{"role":{"created":true}}`)
+		default:
+			return esclient.NewMockResponse(404, req, "{}")
+		}
+	})
+}
+
+func TestReconciler_reconcileAssociation_External(t *testing.T) {
+	// user left over from a previous association with a cluster managed by the operator
+	orphanedUser := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      userName,
+			Labels: map[string]string{
+				KibanaESAssociationLabelName:      kibanaFixture.Name,
+				KibanaESAssociationLabelNamespace: kibanaFixture.Namespace,
+				common.TypeLabelName:              user.UserType,
+			},
+		},
+	}
+	kibana := externalKibanaFixture.DeepCopy()
+	r := newTestReconciler(t, kibanaESAssociationInfo, kibana, &externalAdminSecretFixture, externalCASecret(t), &orphanedUser)
+	es := newFakeExternalES(t, "https://legacy-es:9200")
+	r.newExternalESClient = externalESProvider(es)
+
+	status, err := r.reconcileAssociation(kibana)
+	require.NoError(t, err)
+	require.Equal(t, commonv1alpha1.AssociationEstablished, status)
+
+	// the user is created in the external cluster with the password stored in the Kibana namespace
+	var userSecret corev1.Secret
+	require.NoError(t, r.Get(types.NamespacedName{Namespace: "default", Name: userSecretName}, &userSecret))
+	require.Equal(t, esclient.User{
+		Password: string(userSecret.Data[userName]),
+		Roles:    []string{"kibana_system"},
+	}, es.users[userName])
+	// as well as the user importing saved objects
+	var soUserSecret corev1.Secret
+	require.NoError(t, r.Get(types.NamespacedName{Namespace: "default", Name: "kibana-foo-kibana-so-user"}, &soUserSecret))
+	require.Equal(t, esclient.User{
+		Password: string(soUserSecret.Data["default-kibana-foo-kibana-so-user"]),
+		Roles:    []string{"kibana_user"},
+	}, es.users["default-kibana-foo-kibana-so-user"])
+
+	// Kibana is configured to use the external cluster
+	var updated kbtype.Kibana
+	require.NoError(t, r.Get(k8s.ExtractNamespacedName(kibana), &updated))
+	require.Equal(t, "https://legacy-es:9200", updated.Spec.Elasticsearch.URL)
+	require.Equal(t, "legacy-es-ca", updated.Spec.Elasticsearch.CertificateAuthorities.SecretName)
+	require.Equal(t, userSecretName, updated.Spec.Elasticsearch.Auth.SecretKeyRef.Name)
+
+	// the user of the previous association is removed
+	err = r.Get(k8s.ExtractNamespacedName(&orphanedUser), &corev1.Secret{})
+	require.True(t, apierrors.IsNotFound(err))
+}
+
+func TestReconciler_reconcileAssociation_ExternalMissingAdminSecret(t *testing.T) {
+	kibana := externalKibanaFixture.DeepCopy()
+	r := newTestReconciler(t, kibanaESAssociationInfo, kibana, externalCASecret(t))
+	es := newFakeExternalES(t, "https://legacy-es:9200")
+	r.newExternalESClient = externalESProvider(es)
+
+	status, err := r.reconcileAssociation(kibana)
+	require.NoError(t, err)
+	require.Equal(t, commonv1alpha1.AssociationPending, status)
+	require.Empty(t, es.users)
+
+	// Kibana is not configured
+	var updated kbtype.Kibana
+	require.NoError(t, r.Get(k8s.ExtractNamespacedName(kibana), &updated))
+	require.Equal(t, kbtype.BackendElasticsearch{}, updated.Spec.Elasticsearch)
+}
+
+func TestReconciler_reconcileAssociation_ExternalApmServerRoles(t *testing.T) {
+	apm := &apmtype.ApmServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "as"},
+		Spec: apmtype.ApmServerSpec{
+			ExternalElasticsearchRef: externalKibanaFixture.Spec.ExternalElasticsearchRef.DeepCopy(),
+		},
+	}
+	r := newTestReconciler(t, apmESAssociationInfo, apm, &externalAdminSecretFixture, externalCASecret(t))
+	es := newFakeExternalES(t, "https://legacy-es:9200")
+	r.newExternalESClient = externalESProvider(es)
+
+	status, err := r.reconcileAssociation(apm)
+	require.NoError(t, err)
+	require.Equal(t, commonv1alpha1.AssociationEstablished, status)
+
+	// the APM Server user is given a scoped role instead of superuser
+	require.Equal(t, apmExternalRoles, es.roles)
+	require.Equal(t, []string{apmExternalRoleName}, es.users["default-as-apm-user"].Roles)
+}
+
+func TestReconciler_reconcileAssociation_ExternalUsersDeletion(t *testing.T) {
+	otherCluster := &commonv1alpha1.ExternalElasticsearchRef{
+		URL:                    "https://other-es:9200",
+		AdminSecretName:        "legacy-es-admin",
+		CertificateAuthorities: commonv1alpha1.SecretRef{SecretName: "legacy-es-ca"},
+	}
+	soUserName := "default-kibana-foo-kibana-so-user"
+
+	kibana := externalKibanaFixture.DeepCopy()
+	r := newTestReconciler(t, kibanaESAssociationInfo, kibana, &externalAdminSecretFixture, externalCASecret(t))
+	legacy := newFakeExternalES(t, "https://legacy-es:9200")
+	other := newFakeExternalES(t, otherCluster.URL)
+	r.newExternalESClient = externalESProvider(legacy, other)
+
+	_, err := r.reconcileAssociation(kibana)
+	require.NoError(t, err)
+	require.Contains(t, legacy.users, userName)
+	require.Contains(t, legacy.users, soUserName)
+
+	// the users are moved to the other cluster
+	require.NoError(t, r.Get(k8s.ExtractNamespacedName(kibana), kibana))
+	kibana.Spec.ExternalElasticsearchRef = otherCluster
+	status, err := r.reconcileAssociation(kibana)
+	require.NoError(t, err)
+	require.Equal(t, commonv1alpha1.AssociationEstablished, status)
+	require.Empty(t, legacy.users)
+	require.Contains(t, other.users, userName)
+	require.Contains(t, other.users, soUserName)
+
+	// the users are deleted once the reference is removed, along with their secrets
+	require.NoError(t, r.Get(k8s.ExtractNamespacedName(kibana), kibana))
+	kibana.Spec.ExternalElasticsearchRef = nil
+	status, err = r.reconcileAssociation(kibana)
+	require.NoError(t, err)
+	require.Equal(t, commonv1alpha1.AssociationUnknown, status)
+	require.Empty(t, other.users)
+	err = r.Get(types.NamespacedName{Namespace: "default", Name: userSecretName}, &corev1.Secret{})
+	require.True(t, apierrors.IsNotFound(err))
+}
+
+func TestReconciler_userFinalizer_External(t *testing.T) {
+	kibana := externalKibanaFixture.DeepCopy()
+	r := newTestReconciler(t, kibanaESAssociationInfo, kibana, &externalAdminSecretFixture, externalCASecret(t))
+	es := newFakeExternalES(t, "https://legacy-es:9200")
+	r.newExternalESClient = externalESProvider(es)
+
+	_, err := r.reconcileAssociation(kibana)
+	require.NoError(t, err)
+	require.Len(t, es.users, 2)
+
+	require.NoError(t, r.userFinalizer(kibana).Execute())
+	require.Empty(t, es.users)
+}
@@ -0,0 +1,89 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"reflect"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	kbtype "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
+	elasticsearchuser "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
+	kbes "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/es"
+	kblabel "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// KibanaESAssociationLabelName marks resources created by the Kibana-Elasticsearch association for easier
+	// retrieval.
+	KibanaESAssociationLabelName = "kibanaassociation.k8s.elastic.co/name"
+	// KibanaESAssociationLabelNamespace marks resources created by the Kibana-Elasticsearch association for easier
+	// retrieval.
+	KibanaESAssociationLabelNamespace = "kibanaassociation.k8s.elastic.co/namespace"
+
+	// kibanaUserSuffix is used to suffix user and associated secret resources.
+	kibanaUserSuffix = "kibana-user"
+	// KibanaESCASecretSuffix is used as suffix for the copy of the Elasticsearch CA in the Kibana namespace.
+	KibanaESCASecretSuffix = "kb-es-ca" // nolint
+)
+
+// kibanaESAssociationInfo describes the association of Kibana with its Elasticsearch backend.
+var kibanaESAssociationInfo = AssociationInfo{
+	AssociationName:         "kibana",
+	AssociatedObjTemplate:   func() commonv1alpha1.Associated { return &kbtype.Kibana{} },
+	AssociatedNameLabelName: kblabel.KibanaNameLabelName,
+	AssociatedLabels:        kblabel.NewLabels,
+	AssociationRef: func(associated commonv1alpha1.Associated) commonv1alpha1.ObjectSelector {
+		return associated.ElasticsearchRef()
+	},
+	AssociationStatus: func(associated commonv1alpha1.Associated) commonv1alpha1.AssociationStatus {
+		return associated.(*kbtype.Kibana).Status.AssociationStatus
+	},
+	SetAssociationStatus: func(associated commonv1alpha1.Associated, status commonv1alpha1.AssociationStatus) {
+		associated.(*kbtype.Kibana).Status.AssociationStatus = status
+	},
+	SetAssociationConf: setKibanaESConf,
+
+	Referenced: elasticsearchReference,
+	ExternalElasticsearchRef: func(associated commonv1alpha1.Associated) *commonv1alpha1.ExternalElasticsearchRef {
+		return associated.(*kbtype.Kibana).Spec.ExternalElasticsearchRef
+	},
+
+	UserSecretSuffix: kibanaUserSuffix,
+	UserRoles:        elasticsearchuser.KibanaSystemUserBuiltinRole,
+	AdditionalUsers: []AssociationUser{
+		// used by the operator to import saved objects through the Kibana API
+		{UserSecretSuffix: kbes.SavedObjectsUserSuffix, UserRoles: kbes.SavedObjectsUserRole},
+	},
+	CASecretSuffix: KibanaESCASecretSuffix,
+
+	AssociationLabelName:      KibanaESAssociationLabelName,
+	AssociationLabelNamespace: KibanaESAssociationLabelNamespace,
+	WatchFinalizerName:        "dynamic-watches.finalizers.associations.k8s.elastic.co",
+	UserFinalizerName:         user.UserFinalizerName,
+}
+
+// AddKibanaES creates a new controller associating Kibana resources with Elasticsearch and adds it to the Manager.
+func AddKibanaES(mgr manager.Manager, params operator.Parameters) error {
+	return AddAssociationController(mgr, params, kibanaESAssociationInfo)
+}
+
+// setKibanaESConf sets the Elasticsearch backend configuration in the Kibana spec.
+func setKibanaESConf(associated commonv1alpha1.Associated, conf AssociationConf) bool {
+	kibana := associated.(*kbtype.Kibana)
+	var expected kbtype.BackendElasticsearch
+	if conf != (AssociationConf{}) {
+		expected.URL = conf.URL
+		expected.CertificateAuthorities.SecretName = conf.CASecretName
+		expected.Auth.SecretKeyRef = conf.AuthSecretKeyRef
+	}
+	if reflect.DeepEqual(kibana.Spec.Elasticsearch, expected) {
+		return false
+	}
+	kibana.Spec.Elasticsearch = expected
+	return true
+}
@@ -0,0 +1,44 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// NewResourceSelector selects resources labeled with the given association label as related to the named
+// associated resource.
+func NewResourceSelector(associationLabelName string, name string) labels.Selector {
+	return labels.Set(map[string]string{
+		associationLabelName: name,
+	}).AsSelector()
+}
+
+// hasBeenCreatedBy returns true if the given object is labeled as created for the association of the given
+// associated resource.
+func (r *Reconciler) hasBeenCreatedBy(object metav1.Object, associated metav1.Object) bool {
+	labels := object.GetLabels()
+	if name, ok := labels[r.AssociationLabelName]; !ok || name != associated.GetName() {
+		return false
+	}
+	if ns, ok := labels[r.AssociationLabelNamespace]; !ok || ns != associated.GetNamespace() {
+		return false
+	}
+	return true
+}
+
+// newUserLabelSelector selects the users created for the association of the given associated resource.
+func (r *Reconciler) newUserLabelSelector(associated types.NamespacedName) labels.Selector {
+	return labels.SelectorFromSet(
+		map[string]string{
+			r.AssociationLabelName:      associated.Name,
+			r.AssociationLabelNamespace: associated.Namespace,
+			common.TypeLabelName:        user.UserType,
+		})
+}
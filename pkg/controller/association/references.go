@@ -0,0 +1,70 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"fmt"
+
+	estype "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	kbtype "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	esname "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/name"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/services"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana"
+	kbname "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/name"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// elasticsearchReference describes associations referencing an Elasticsearch cluster, in which the user of the
+// associated resource is created.
+var elasticsearchReference = ReferencedResource{
+	ObjTemplate: func() runtime.Object { return &estype.Elasticsearch{} },
+	Watches: func(w watches.DynamicWatches) *watches.DynamicEnqueueRequest {
+		return w.ElasticsearchClusters
+	},
+	Namer: esname.ESNamer,
+	Resolve: func(c k8s.Client, ref types.NamespacedName) (string, *estype.Elasticsearch, error) {
+		var es estype.Elasticsearch
+		if err := c.Get(ref, &es); err != nil {
+			return "", nil, err
+		}
+		return services.ExternalServiceURL(es), &es, nil
+	},
+}
+
+// kibanaReference describes associations referencing a Kibana instance. Kibana users are Elasticsearch users: the
+// user of the associated resource is created in the Elasticsearch cluster referenced by Kibana.
+var kibanaReference = ReferencedResource{
+	ObjTemplate: func() runtime.Object { return &kbtype.Kibana{} },
+	Watches: func(w watches.DynamicWatches) *watches.DynamicEnqueueRequest {
+		return w.Kibanas
+	},
+	Namer: kbname.KBNamer,
+	Resolve: func(c k8s.Client, ref types.NamespacedName) (string, *estype.Elasticsearch, error) {
+		var kb kbtype.Kibana
+		if err := c.Get(ref, &kb); err != nil {
+			return "", nil, err
+		}
+		if kb.Spec.ExternalElasticsearchRef.IsDefined() {
+			// the user would have to be created in the external Elasticsearch cluster, with the admin secret of Kibana
+			return "", nil, fmt.Errorf("referenced Kibana %s uses an external Elasticsearch cluster, which is not supported", ref)
+		}
+		esRef := kb.Spec.ElasticsearchRef
+		if !esRef.IsDefined() {
+			log.Info("Referenced Kibana does not reference an Elasticsearch cluster", "namespace", ref.Namespace, "kibana_name", ref.Name)
+			return "", nil, nil
+		}
+		if esRef.Namespace == "" {
+			esRef.Namespace = kb.Namespace
+		}
+		var es estype.Elasticsearch
+		if err := c.Get(esRef.NamespacedName(), &es); err != nil {
+			return "", nil, err
+		}
+		return kibana.ExternalServiceURL(kb), &es, nil
+	},
+}
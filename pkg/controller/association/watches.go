@@ -0,0 +1,84 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/finalizer"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+func addWatches(c controller.Controller, r *Reconciler) error {
+	// Watch for changes to the associated resources
+	if err := c.Watch(&source.Kind{Type: r.AssociatedObjTemplate()}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	// Dynamically watch the referenced resources (not all resources of that type)
+	if err := c.Watch(&source.Kind{Type: r.Referenced.ObjTemplate()}, r.Referenced.Watches(r.watches)); err != nil {
+		return err
+	}
+
+	// Dynamically watch the public CA secrets of the referenced resources and the users secrets
+	if err := c.Watch(&source.Kind{Type: &corev1.Secret{}}, r.watches.Secrets); err != nil {
+		return err
+	}
+
+	// Watch Secrets owned by an associated resource
+	if err := c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForOwner{
+		OwnerType:    r.AssociatedObjTemplate(),
+		IsController: true,
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// referencedResourceWatchName returns the name of the watch setup on the resource referenced by the given
+// associated resource.
+func referencedResourceWatchName(associated types.NamespacedName) string {
+	return associated.Namespace + "-" + associated.Name + "-ref-watch"
+}
+
+// caWatchName returns the name of the watch setup on the secret that contains the HTTP certificate chain of the
+// referenced resource.
+func caWatchName(associated types.NamespacedName) string {
+	return associated.Namespace + "-" + associated.Name + "-ca-watch"
+}
+
+// userWatchName returns the name of the watch setup on the user secret of the associated resource.
+func userWatchName(associated types.NamespacedName) string {
+	return associated.Namespace + "-" + associated.Name + "-user-watch"
+}
+
+// externalWatchName returns the name of the watch setup on the admin and CA secrets of the external Elasticsearch
+// cluster referenced by the given associated resource.
+func externalWatchName(associated types.NamespacedName) string {
+	return associated.Namespace + "-" + associated.Name + "-external-watch"
+}
+
+// removeWatches stops watching the resources related to the given associated resource.
+func (r *Reconciler) removeWatches(associated types.NamespacedName) {
+	r.Referenced.Watches(r.watches).RemoveHandlerForKey(referencedResourceWatchName(associated))
+	r.watches.Secrets.RemoveHandlerForKey(caWatchName(associated))
+	r.watches.Secrets.RemoveHandlerForKey(userWatchName(associated))
+	r.watches.Secrets.RemoveHandlerForKey(externalWatchName(associated))
+}
+
+// watchFinalizer ensures that we remove watches for resources that we are no longer interested in
+// because the associated resource has been deleted.
+func (r *Reconciler) watchFinalizer(associated types.NamespacedName) finalizer.Finalizer {
+	return finalizer.Finalizer{
+		Name: r.WatchFinalizerName,
+		Execute: func() error {
+			r.removeWatches(associated)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"strings"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+)
+
+const (
+	// AllowedNamespacesAnnotation is set on an Elasticsearch cluster to list the namespaces, separated by commas,
+	// whose resources are allowed to get a user in the cluster through an association. "*" allows all namespaces.
+	// Resources in the namespace of the cluster are always allowed.
+	AllowedNamespacesAnnotation = "association.k8s.elastic.co/allowed-namespaces"
+
+	allNamespaces = "*"
+)
+
+// IsAllowed returns true if a resource in the given namespace is allowed to get a user in the given Elasticsearch
+// cluster.
+func IsAllowed(es v1alpha1.Elasticsearch, namespace string) bool {
+	if es.Namespace == namespace {
+		return true
+	}
+	for _, allowed := range strings.Split(es.Annotations[AllowedNamespacesAnnotation], ",") {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == allNamespaces || allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
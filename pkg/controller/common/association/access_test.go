@@ -0,0 +1,40 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsAllowed(t *testing.T) {
+	es := func(allowed string) v1alpha1.Elasticsearch {
+		es := v1alpha1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Name: "es", Namespace: "es-ns"}}
+		if allowed != "" {
+			es.Annotations = map[string]string{AllowedNamespacesAnnotation: allowed}
+		}
+		return es
+	}
+	tests := []struct {
+		name      string
+		es        v1alpha1.Elasticsearch
+		namespace string
+		want      bool
+	}{
+		{name: "same namespace", es: es(""), namespace: "es-ns", want: true},
+		{name: "other namespace without annotation", es: es(""), namespace: "kb-ns", want: false},
+		{name: "other namespace allowed", es: es("apm-ns, kb-ns"), namespace: "kb-ns", want: true},
+		{name: "other namespace not allowed", es: es("apm-ns"), namespace: "kb-ns", want: false},
+		{name: "all namespaces allowed", es: es("*"), namespace: "kb-ns", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, IsAllowed(tt.es, tt.namespace))
+		})
+	}
+}
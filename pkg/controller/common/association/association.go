@@ -17,10 +17,15 @@ func ElasticsearchAuthSettings(
 	c k8s.Client,
 	associated v1alpha1.Associated,
 ) (username, password string, err error) {
-	auth := associated.ElasticsearchAuth()
+	return AuthSettings(c, associated.GetNamespace(), associated.ElasticsearchAuth())
+}
+
+// AuthSettings returns the user and the password described by the given auth, resolving them from a secret
+// in the given namespace if needed.
+func AuthSettings(c k8s.Client, namespace string, auth v1alpha1.ElasticsearchAuth) (username, password string, err error) {
 	// if auth is provided via a secret, resolve credentials from it.
 	if auth.SecretKeyRef != nil {
-		secretObjKey := types.NamespacedName{Namespace: associated.GetNamespace(), Name: auth.SecretKeyRef.Name}
+		secretObjKey := types.NamespacedName{Namespace: namespace, Name: auth.SecretKeyRef.Name}
 		var secret v1.Secret
 		if err := c.Get(secretObjKey, &secret); err != nil {
 			return "", "", err
@@ -9,8 +9,8 @@ import (
 
 	"github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates/http"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/name"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
-	esname "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/name"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -20,26 +20,29 @@ import (
 )
 
 // ElasticsearchCACertSecretName returns the name of the secret holding the certificate chain used
-// by the associated resource to establish and validate a secured HTTP connection to Elasticsearch.
+// by the associated resource to establish and validate a secured HTTP connection to Elasticsearch,
+// or to the resource the given suffix is dedicated to.
 func ElasticsearchCACertSecretName(associated v1alpha1.Associated, suffix string) string {
 	return associated.GetName() + "-" + suffix
 }
 
-// ReconcileCASecret keeps in sync a copy of the Elasticsearch CA.
-// It is the responsibility of the controller to set a watch on the ES CA.
+// ReconcileCASecret keeps in sync a copy of the HTTP CA of the target resource, such as Elasticsearch or Kibana,
+// whose resources are named with the given namer.
+// It is the responsibility of the controller to set a watch on the target CA.
 func ReconcileCASecret(
 	client k8s.Client,
 	scheme *runtime.Scheme,
 	associated v1alpha1.Associated,
-	es types.NamespacedName,
+	namer name.Namer,
+	target types.NamespacedName,
 	labels map[string]string,
 	suffix string,
 ) (string, error) {
-	publicESHTTPCertificatesNSN := http.PublicCertsSecretRef(esname.ESNamer, es)
+	publicHTTPCertificatesNSN := http.PublicCertsSecretRef(namer, target)
 
-	// retrieve the HTTP certificates from ES namespace
-	var publicESHTTPCertificatesSecret corev1.Secret
-	if err := client.Get(publicESHTTPCertificatesNSN, &publicESHTTPCertificatesSecret); err != nil {
+	// retrieve the HTTP certificates from the target namespace
+	var publicHTTPCertificatesSecret corev1.Secret
+	if err := client.Get(publicHTTPCertificatesNSN, &publicHTTPCertificatesSecret); err != nil {
 		if errors.IsNotFound(err) {
 			return "", nil // probably not created yet, we'll be notified to reconcile later
 		}
@@ -53,7 +56,7 @@ func ReconcileCASecret(
 			Name:      ElasticsearchCACertSecretName(associated, suffix),
 			Labels:    labels,
 		},
-		Data: publicESHTTPCertificatesSecret.Data,
+		Data: publicHTTPCertificatesSecret.Data,
 	}
 	var reconciledSecret corev1.Secret
 	if err := reconciler.ReconcileResource(reconciler.Params{
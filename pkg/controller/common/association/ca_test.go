@@ -114,6 +114,7 @@ func TestReconcileAssociation_reconcileCASecret(t *testing.T) {
 				tt.client,
 				scheme.Scheme,
 				&tt.kibana,
+				esname.ESNamer,
 				k8s.ExtractNamespacedName(&tt.es),
 				map[string]string{},
 				ElasticsearchCASecretSuffix,
@@ -40,6 +40,12 @@ func UserKey(associated commonv1alpha1.Associated, userSuffix string) types.Name
 		// no namespace given, default to the associated object's one
 		esNamespace = associated.GetNamespace()
 	}
+	return UserKeyInNamespace(associated, esNamespace, userSuffix)
+}
+
+// UserKeyInNamespace is the namespaced name to identify the user resource created by the controller in the
+// namespace of the Elasticsearch cluster it belongs to, which may not be the one referenced by the associated object.
+func UserKeyInNamespace(associated commonv1alpha1.Associated, esNamespace string, userSuffix string) types.NamespacedName {
 	return types.NamespacedName{
 		// user lives in the ES namespace
 		Namespace: esNamespace,
@@ -76,42 +82,14 @@ func ReconcileEsUser(
 	userObjectSuffix string,
 	es v1alpha1.Elasticsearch,
 ) error {
-	pw := commonuser.RandomPasswordBytes()
-
-	secKey := secretKey(associated, userObjectSuffix)
-	usrKey := UserKey(associated, userObjectSuffix)
-	expectedSecret := corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      secKey.Name,
-			Namespace: secKey.Namespace,
-			Labels:    labels,
-		},
-		Data: map[string][]byte{
-			usrKey.Name: pw,
-		},
-	}
+	// the user lives in the namespace of the Elasticsearch cluster it is created in, which may not be the one
+	// referenced by the associated object, for example when associating an ApmServer with a Kibana
+	usrKey := UserKeyInNamespace(associated, es.Namespace, userObjectSuffix)
 
-	reconciledSecret := corev1.Secret{}
-	err := reconciler.ReconcileResource(reconciler.Params{
-		Client:     c,
-		Scheme:     s,
-		Owner:      associated,
-		Expected:   &expectedSecret,
-		Reconciled: &reconciledSecret,
-		NeedsUpdate: func() bool {
-			_, ok := reconciledSecret.Data[usrKey.Name]
-			return !ok || !hasExpectedLabels(&expectedSecret, &reconciledSecret)
-		},
-		UpdateReconciled: func() {
-			setExpectedLabels(&expectedSecret, &reconciledSecret)
-			reconciledSecret.Data = expectedSecret.Data
-		},
-	})
+	_, reconciledPw, err := ReconcileUserSecret(c, s, associated, labels, userObjectSuffix)
 	if err != nil {
 		return err
 	}
-
-	reconciledPw := reconciledSecret.Data[usrKey.Name] // make sure we don't constantly update the password
 	bcryptHash, err := bcrypt.GenerateFromPassword(reconciledPw, bcrypt.DefaultCost)
 	if err != nil {
 		return err
@@ -160,6 +138,51 @@ func ReconcileEsUser(
 	})
 }
 
+// ReconcileUserSecret creates or updates the secret holding the password of the user of the associated object, in
+// the associated object namespace. It returns the name of the user and its password, which is kept across calls.
+func ReconcileUserSecret(
+	c k8s.Client,
+	s *runtime.Scheme,
+	associated commonv1alpha1.Associated,
+	labels map[string]string,
+	userObjectSuffix string,
+) (string, []byte, error) {
+	secKey := secretKey(associated, userObjectSuffix)
+	userName := elasticsearchUserName(associated, userObjectSuffix)
+	expectedSecret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secKey.Name,
+			Namespace: secKey.Namespace,
+			Labels:    labels,
+		},
+		Data: map[string][]byte{
+			userName: commonuser.RandomPasswordBytes(),
+		},
+	}
+
+	reconciledSecret := corev1.Secret{}
+	err := reconciler.ReconcileResource(reconciler.Params{
+		Client:     c,
+		Scheme:     s,
+		Owner:      associated,
+		Expected:   &expectedSecret,
+		Reconciled: &reconciledSecret,
+		NeedsUpdate: func() bool {
+			_, ok := reconciledSecret.Data[userName]
+			return !ok || !hasExpectedLabels(&expectedSecret, &reconciledSecret)
+		},
+		UpdateReconciled: func() {
+			setExpectedLabels(&expectedSecret, &reconciledSecret)
+			reconciledSecret.Data = expectedSecret.Data
+		},
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	// make sure we don't constantly update the password
+	return userName, reconciledSecret.Data[userName], nil
+}
+
 // hasExpectedLabels does a left-biased comparison ensuring all key/value pairs in expected exist in actual.
 func hasExpectedLabels(expected, actual metav1.Object) bool {
 	actualLabels := actual.GetLabels()
@@ -0,0 +1,420 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// MultiNamespacedCacheBuilder returns a function creating a cache restricted to the given namespaces, made of one
+// namespaced cache per namespace. The controller-runtime version in use only supports caches restricted to a
+// single namespace, or watching all of them. Cluster-scoped resources are served by a cluster-wide cache.
+func MultiNamespacedCacheBuilder(namespaces []string) func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		if opts.Scheme == nil {
+			opts.Scheme = scheme.Scheme
+		}
+		if opts.Mapper == nil {
+			mapper, err := apiutil.NewDiscoveryRESTMapper(config)
+			if err != nil {
+				return nil, err
+			}
+			opts.Mapper = mapper
+		}
+
+		caches := make(map[string]cache.Cache, len(namespaces))
+		for _, ns := range namespaces {
+			opts.Namespace = ns
+			c, err := cache.New(config, opts)
+			if err != nil {
+				return nil, err
+			}
+			caches[ns] = c
+		}
+		// informers are only created on first use: this cache only watches the cluster-scoped resources
+		opts.Namespace = ""
+		clusterCache, err := cache.New(config, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &multiNamespaceCache{
+			namespaceToCache: caches,
+			clusterCache:     clusterCache,
+			scheme:           opts.Scheme,
+			mapper:           opts.Mapper,
+		}, nil
+	}
+}
+
+// multiNamespaceCache dispatches reads to the cache of the namespace they target, and merges the results of reads
+// across namespaces. Reads of cluster-scoped resources are dispatched to the cluster-wide cache.
+type multiNamespaceCache struct {
+	namespaceToCache map[string]cache.Cache
+	clusterCache     cache.Cache
+	scheme           *runtime.Scheme
+	mapper           meta.RESTMapper
+}
+
+var _ cache.Cache = &multiNamespaceCache{}
+
+// isClusterScoped returns true if the given object, or the items of the given list, are cluster-scoped.
+func (c *multiNamespaceCache) isClusterScoped(obj runtime.Object) (bool, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return false, err
+	}
+	if meta.IsListType(obj) {
+		gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	}
+	return c.isClusterScopedKind(gvk)
+}
+
+// isClusterScopedKind returns true if the resources of the given kind are cluster-scoped.
+func (c *multiNamespaceCache) isClusterScopedKind(gvk schema.GroupVersionKind) (bool, error) {
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, err
+	}
+	return mapping.Scope.Name() == meta.RESTScopeNameRoot, nil
+}
+
+// GetInformer returns an informer dispatching the event handlers to the informers of all namespaces.
+func (c *multiNamespaceCache) GetInformer(obj runtime.Object) (toolscache.SharedIndexInformer, error) {
+	clusterScoped, err := c.isClusterScoped(obj)
+	if err != nil {
+		return nil, err
+	}
+	if clusterScoped {
+		return c.clusterCache.GetInformer(obj)
+	}
+	informers := make([]toolscache.SharedIndexInformer, 0, len(c.namespaceToCache))
+	for _, nsCache := range c.namespaceToCache {
+		informer, err := nsCache.GetInformer(obj)
+		if err != nil {
+			return nil, err
+		}
+		informers = append(informers, informer)
+	}
+	return newMultiNamespaceInformer(informers), nil
+}
+
+// GetInformerForKind returns an informer dispatching the event handlers to the informers of all namespaces.
+func (c *multiNamespaceCache) GetInformerForKind(gvk schema.GroupVersionKind) (toolscache.SharedIndexInformer, error) {
+	clusterScoped, err := c.isClusterScopedKind(gvk)
+	if err != nil {
+		return nil, err
+	}
+	if clusterScoped {
+		return c.clusterCache.GetInformerForKind(gvk)
+	}
+	informers := make([]toolscache.SharedIndexInformer, 0, len(c.namespaceToCache))
+	for _, nsCache := range c.namespaceToCache {
+		informer, err := nsCache.GetInformerForKind(gvk)
+		if err != nil {
+			return nil, err
+		}
+		informers = append(informers, informer)
+	}
+	return newMultiNamespaceInformer(informers), nil
+}
+
+// Start starts the caches of all namespaces and the cluster-wide cache, and blocks until the stop channel is closed.
+func (c *multiNamespaceCache) Start(stopCh <-chan struct{}) error {
+	errs := make(chan error, len(c.namespaceToCache)+1)
+	go func() {
+		if err := c.clusterCache.Start(stopCh); err != nil {
+			errs <- fmt.Errorf("cluster-wide cache: %v", err)
+		}
+	}()
+	for ns, nsCache := range c.namespaceToCache {
+		go func(ns string, nsCache cache.Cache) {
+			if err := nsCache.Start(stopCh); err != nil {
+				errs <- fmt.Errorf("cache for namespace %s: %v", ns, err)
+			}
+		}(ns, nsCache)
+	}
+	select {
+	case err := <-errs:
+		return err
+	case <-stopCh:
+		return nil
+	}
+}
+
+// WaitForCacheSync waits for the caches of all namespaces and the cluster-wide cache to be synced.
+func (c *multiNamespaceCache) WaitForCacheSync(stop <-chan struct{}) bool {
+	synced := c.clusterCache.WaitForCacheSync(stop)
+	for _, nsCache := range c.namespaceToCache {
+		if s := nsCache.WaitForCacheSync(stop); !s {
+			synced = s
+		}
+	}
+	return synced
+}
+
+// IndexField adds the index to the caches of all namespaces, or to the cluster-wide cache for cluster-scoped resources.
+func (c *multiNamespaceCache) IndexField(obj runtime.Object, field string, extractValue client.IndexerFunc) error {
+	clusterScoped, err := c.isClusterScoped(obj)
+	if err != nil {
+		return err
+	}
+	if clusterScoped {
+		return c.clusterCache.IndexField(obj, field, extractValue)
+	}
+	for _, nsCache := range c.namespaceToCache {
+		if err := nsCache.IndexField(obj, field, extractValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get reads the object from the cache of its namespace, or from the cluster-wide cache if it is cluster-scoped.
+func (c *multiNamespaceCache) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	clusterScoped, err := c.isClusterScoped(obj)
+	if err != nil {
+		return err
+	}
+	if clusterScoped {
+		return c.clusterCache.Get(ctx, key, obj)
+	}
+	nsCache, ok := c.namespaceToCache[key.Namespace]
+	if !ok {
+		return fmt.Errorf("unable to get %v: namespace %q is not watched", key, key.Namespace)
+	}
+	return nsCache.Get(ctx, key, obj)
+}
+
+// List reads the objects from the cache of the namespace given in the options, or from the caches of all namespaces.
+// Cluster-scoped objects are read from the cluster-wide cache.
+func (c *multiNamespaceCache) List(ctx context.Context, opts *client.ListOptions, list runtime.Object) error {
+	clusterScoped, err := c.isClusterScoped(list)
+	if err != nil {
+		return err
+	}
+	if clusterScoped {
+		return c.clusterCache.List(ctx, opts, list)
+	}
+	if opts != nil && opts.Namespace != "" {
+		nsCache, ok := c.namespaceToCache[opts.Namespace]
+		if !ok {
+			return fmt.Errorf("unable to list: namespace %q is not watched", opts.Namespace)
+		}
+		return nsCache.List(ctx, opts, list)
+	}
+
+	var nsOpts client.ListOptions
+	if opts != nil {
+		nsOpts = *opts
+	}
+	var allItems []runtime.Object
+	for ns, nsCache := range c.namespaceToCache {
+		nsOpts.Namespace = ns
+		nsList := list.DeepCopyObject()
+		if err := nsCache.List(ctx, &nsOpts, nsList); err != nil {
+			return err
+		}
+		items, err := meta.ExtractList(nsList)
+		if err != nil {
+			return err
+		}
+		allItems = append(allItems, items...)
+	}
+	return meta.SetList(list, allItems)
+}
+
+// multiNamespaceInformer runs the operations of a shared informer against the informers of all namespaces. The
+// informers are run by the cache of their namespace.
+type multiNamespaceInformer struct {
+	informers []toolscache.SharedIndexInformer
+}
+
+var _ toolscache.SharedIndexInformer = &multiNamespaceInformer{}
+
+func newMultiNamespaceInformer(informers []toolscache.SharedIndexInformer) *multiNamespaceInformer {
+	return &multiNamespaceInformer{informers: informers}
+}
+
+// AddEventHandler adds the handler to the informers of all namespaces.
+func (i *multiNamespaceInformer) AddEventHandler(handler toolscache.ResourceEventHandler) {
+	for _, informer := range i.informers {
+		informer.AddEventHandler(handler)
+	}
+}
+
+// AddEventHandlerWithResyncPeriod adds the handler with a resync period to the informers of all namespaces.
+func (i *multiNamespaceInformer) AddEventHandlerWithResyncPeriod(handler toolscache.ResourceEventHandler, resyncPeriod time.Duration) {
+	for _, informer := range i.informers {
+		informer.AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+	}
+}
+
+// AddIndexers adds the indexers to the informers of all namespaces.
+func (i *multiNamespaceInformer) AddIndexers(indexers toolscache.Indexers) error {
+	for _, informer := range i.informers {
+		if err := informer.AddIndexers(indexers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasSynced returns true if the informers of all namespaces have synced.
+func (i *multiNamespaceInformer) HasSynced() bool {
+	for _, informer := range i.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// Run blocks until the stop channel is closed. The informers of all namespaces are already run by their cache.
+func (i *multiNamespaceInformer) Run(stopCh <-chan struct{}) {
+	<-stopCh
+}
+
+// LastSyncResourceVersion returns an empty string: the resource versions of the watches of several namespaces cannot
+// be reduced to a single one.
+func (i *multiNamespaceInformer) LastSyncResourceVersion() string {
+	return ""
+}
+
+// GetController returns the informer itself, which runs and syncs the informers of all namespaces.
+func (i *multiNamespaceInformer) GetController() toolscache.Controller {
+	return i
+}
+
+// GetStore returns a read-only store merging the stores of the informers of all namespaces.
+func (i *multiNamespaceInformer) GetStore() toolscache.Store {
+	return i.GetIndexer()
+}
+
+// GetIndexer returns a read-only indexer merging the indexers of the informers of all namespaces.
+func (i *multiNamespaceInformer) GetIndexer() toolscache.Indexer {
+	indexers := make([]toolscache.Indexer, 0, len(i.informers))
+	for _, informer := range i.informers {
+		indexers = append(indexers, informer.GetIndexer())
+	}
+	return multiNamespaceIndexer(indexers)
+}
+
+// errReadOnlyIndexer is returned when writing to the indexer of a multiNamespaceInformer, which is owned by the
+// informers of each namespace.
+var errReadOnlyIndexer = errors.New("the indexer of a multi-namespace informer is read-only")
+
+// multiNamespaceIndexer merges the reads of the indexers of several namespaces. Writes are rejected.
+type multiNamespaceIndexer []toolscache.Indexer
+
+var _ toolscache.Indexer = multiNamespaceIndexer{}
+
+func (m multiNamespaceIndexer) Add(interface{}) error                 { return errReadOnlyIndexer }
+func (m multiNamespaceIndexer) Update(interface{}) error              { return errReadOnlyIndexer }
+func (m multiNamespaceIndexer) Delete(interface{}) error              { return errReadOnlyIndexer }
+func (m multiNamespaceIndexer) Replace([]interface{}, string) error   { return errReadOnlyIndexer }
+func (m multiNamespaceIndexer) Resync() error                         { return errReadOnlyIndexer }
+func (m multiNamespaceIndexer) AddIndexers(toolscache.Indexers) error { return errReadOnlyIndexer }
+
+func (m multiNamespaceIndexer) List() []interface{} {
+	var items []interface{}
+	for _, indexer := range m {
+		items = append(items, indexer.List()...)
+	}
+	return items
+}
+
+func (m multiNamespaceIndexer) ListKeys() []string {
+	var keys []string
+	for _, indexer := range m {
+		keys = append(keys, indexer.ListKeys()...)
+	}
+	return keys
+}
+
+func (m multiNamespaceIndexer) Get(obj interface{}) (interface{}, bool, error) {
+	for _, indexer := range m {
+		item, exists, err := indexer.Get(obj)
+		if err != nil || exists {
+			return item, exists, err
+		}
+	}
+	return nil, false, nil
+}
+
+func (m multiNamespaceIndexer) GetByKey(key string) (interface{}, bool, error) {
+	for _, indexer := range m {
+		item, exists, err := indexer.GetByKey(key)
+		if err != nil || exists {
+			return item, exists, err
+		}
+	}
+	return nil, false, nil
+}
+
+func (m multiNamespaceIndexer) Index(indexName string, obj interface{}) ([]interface{}, error) {
+	var items []interface{}
+	for _, indexer := range m {
+		nsItems, err := indexer.Index(indexName, obj)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, nsItems...)
+	}
+	return items, nil
+}
+
+func (m multiNamespaceIndexer) IndexKeys(indexName, indexKey string) ([]string, error) {
+	var keys []string
+	for _, indexer := range m {
+		nsKeys, err := indexer.IndexKeys(indexName, indexKey)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, nsKeys...)
+	}
+	return keys, nil
+}
+
+func (m multiNamespaceIndexer) ListIndexFuncValues(indexName string) []string {
+	var values []string
+	for _, indexer := range m {
+		values = append(values, indexer.ListIndexFuncValues(indexName)...)
+	}
+	return values
+}
+
+func (m multiNamespaceIndexer) ByIndex(indexName, indexKey string) ([]interface{}, error) {
+	var items []interface{}
+	for _, indexer := range m {
+		nsItems, err := indexer.ByIndex(indexName, indexKey)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, nsItems...)
+	}
+	return items, nil
+}
+
+// GetIndexers returns the indexers of the informers, which are added to the informers of all namespaces.
+func (m multiNamespaceIndexer) GetIndexers() toolscache.Indexers {
+	if len(m) == 0 {
+		return toolscache.Indexers{}
+	}
+	return m[0].GetIndexers()
+}
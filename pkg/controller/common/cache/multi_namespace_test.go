@@ -0,0 +1,139 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeCache serves reads from a fake client.
+type fakeCache struct {
+	cache.Cache
+	c client.Client
+}
+
+func (f fakeCache) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	return f.c.Get(ctx, key, obj)
+}
+
+func (f fakeCache) List(ctx context.Context, opts *client.ListOptions, list runtime.Object) error {
+	return f.c.List(ctx, opts, list)
+}
+
+func secret(namespace, name string) *corev1.Secret {
+	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+func newTestCache() *multiNamespaceCache {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Secret"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Node"), meta.RESTScopeRoot)
+	return &multiNamespaceCache{
+		namespaceToCache: map[string]cache.Cache{
+			// the fake client does not filter by namespace: only create objects of the namespace in each cache
+			"ns1": fakeCache{c: fake.NewFakeClient(secret("ns1", "a"), secret("ns1", "b"))},
+			"ns2": fakeCache{c: fake.NewFakeClient(secret("ns2", "c"))},
+		},
+		clusterCache: fakeCache{c: fake.NewFakeClient(node("n1"), node("n2"))},
+		scheme:       scheme.Scheme,
+		mapper:       mapper,
+	}
+}
+
+func node(name string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func TestMultiNamespaceCache_Get(t *testing.T) {
+	c := newTestCache()
+	var s corev1.Secret
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns2", Name: "c"}, &s))
+	require.Equal(t, "c", s.Name)
+	require.Error(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns1", Name: "c"}, &s))
+	require.Error(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns3", Name: "c"}, &s))
+
+	// cluster-scoped
+	var n corev1.Node
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "n1"}, &n))
+	require.Equal(t, "n1", n.Name)
+}
+
+func TestMultiNamespaceCache_List(t *testing.T) {
+	c := newTestCache()
+
+	// all namespaces
+	var all corev1.SecretList
+	require.NoError(t, c.List(context.Background(), &client.ListOptions{}, &all))
+	names := make([]string, 0, len(all.Items))
+	for _, s := range all.Items {
+		names = append(names, s.Namespace+"/"+s.Name)
+	}
+	require.ElementsMatch(t, []string{"ns1/a", "ns1/b", "ns2/c"}, names)
+
+	// nil options
+	var allNilOpts corev1.SecretList
+	require.NoError(t, c.List(context.Background(), nil, &allNilOpts))
+	require.Len(t, allNilOpts.Items, 3)
+
+	// single namespace
+	var ns2 corev1.SecretList
+	require.NoError(t, c.List(context.Background(), &client.ListOptions{Namespace: "ns2"}, &ns2))
+	require.Len(t, ns2.Items, 1)
+
+	// namespace not watched
+	require.Error(t, c.List(context.Background(), &client.ListOptions{Namespace: "ns3"}, &ns2))
+
+	// cluster-scoped
+	var nodes corev1.NodeList
+	require.NoError(t, c.List(context.Background(), &client.ListOptions{}, &nodes))
+	require.Len(t, nodes.Items, 2)
+}
+
+func TestMultiNamespaceIndexer(t *testing.T) {
+	newIndexer := func(objs ...interface{}) toolscache.Indexer {
+		indexer := toolscache.NewIndexer(toolscache.MetaNamespaceKeyFunc, toolscache.Indexers{
+			toolscache.NamespaceIndex: toolscache.MetaNamespaceIndexFunc,
+		})
+		for _, obj := range objs {
+			require.NoError(t, indexer.Add(obj))
+		}
+		return indexer
+	}
+	indexer := multiNamespaceIndexer{
+		newIndexer(secret("ns1", "a"), secret("ns1", "b")),
+		newIndexer(secret("ns2", "c")),
+	}
+
+	require.ElementsMatch(t, []string{"ns1/a", "ns1/b", "ns2/c"}, indexer.ListKeys())
+	require.Len(t, indexer.List(), 3)
+
+	item, exists, err := indexer.GetByKey("ns2/c")
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.Equal(t, "c", item.(*corev1.Secret).Name)
+	_, exists, err = indexer.GetByKey("ns2/a")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	items, err := indexer.ByIndex(toolscache.NamespaceIndex, "ns1")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	// writes are rejected
+	require.Error(t, indexer.Add(secret("ns1", "d")))
+}
@@ -5,8 +5,8 @@
 package certificates
 
 import (
+	"crypto"
 	cryptorand "crypto/rand"
-	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"math/big"
@@ -23,9 +23,38 @@ var (
 // CA is a simple certificate authority
 type CA struct {
 	// PrivateKey is the CA private key
-	PrivateKey *rsa.PrivateKey
+	PrivateKey crypto.Signer
 	// Cert is the certificate used to issue new certificates
 	Cert *x509.Certificate
+	// Staged is a CA certificate staged to replace Cert during a rotation.
+	// It is already trusted, but not used to issue new certificates yet.
+	Staged *x509.Certificate
+	// Previous is the CA certificate replaced by Cert during a rotation.
+	// It is still trusted until all leaf certificates have been reissued.
+	Previous *x509.Certificate
+}
+
+// TrustedCerts returns the CA certificates that should be trusted: the current one, along with
+// any CA certificate being rotated in or out.
+func (c *CA) TrustedCerts() []*x509.Certificate {
+	trusted := []*x509.Certificate{c.Cert}
+	if c.Staged != nil {
+		trusted = append(trusted, c.Staged)
+	}
+	if c.Previous != nil {
+		trusted = append(trusted, c.Previous)
+	}
+	return trusted
+}
+
+// TrustedCertsPem returns the PEM encoded CA certificates that should be trusted.
+func (c *CA) TrustedCertsPem() []byte {
+	trusted := c.TrustedCerts()
+	blocks := make([][]byte, 0, len(trusted))
+	for _, cert := range trusted {
+		blocks = append(blocks, cert.Raw)
+	}
+	return EncodePEMCert(blocks...)
 }
 
 // ValidatedCertificateTemplate is a type alias used to convey that the certificate template has been validated and
@@ -33,7 +62,7 @@ type CA struct {
 type ValidatedCertificateTemplate x509.Certificate
 
 // NewCA returns a ca with the given private key and cert
-func NewCA(privateKey *rsa.PrivateKey, cert *x509.Certificate) *CA {
+func NewCA(privateKey crypto.Signer, cert *x509.Certificate) *CA {
 	return &CA{
 		PrivateKey: privateKey,
 		Cert:       cert,
@@ -45,7 +74,9 @@ type CABuilderOptions struct {
 	// Subject of the CA to build.
 	Subject pkix.Name
 	// PrivateKey to be used for signing certificates (auto-generated if not provided).
-	PrivateKey *rsa.PrivateKey
+	PrivateKey crypto.Signer
+	// KeyParams defines how to generate the private key if not provided (defaults to DefaultKeyParams).
+	KeyParams *KeyParams
 	// ExpireIn defines in how much time will the CA expire (defaults to DefaultCertValidity if not provided).
 	ExpireIn *time.Duration
 }
@@ -60,7 +91,11 @@ func NewSelfSignedCA(options CABuilderOptions) (*CA, error) {
 
 	privateKey := options.PrivateKey
 	if privateKey == nil {
-		privateKey, err = rsa.GenerateKey(cryptorand.Reader, 2048)
+		keyParams := DefaultKeyParams
+		if options.KeyParams != nil {
+			keyParams = *options.KeyParams
+		}
+		privateKey, err = keyParams.GenerateKey()
 		if err != nil {
 			return nil, errors.Wrap(err, "unable to generate the private key")
 		}
@@ -76,7 +111,6 @@ func NewSelfSignedCA(options CABuilderOptions) (*CA, error) {
 		Subject:               options.Subject,
 		NotBefore:             time.Now().Add(-1 * time.Minute),
 		NotAfter:              notAfter,
-		SignatureAlgorithm:    x509.SHA256WithRSA,
 		IsCA:                  true,
 		BasicConstraintsValid: true,
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
@@ -111,6 +145,8 @@ func (c *CA) CreateCertificate(
 	}
 	validatedCertificateTemplate.SerialNumber = serial
 	validatedCertificateTemplate.Issuer = c.Cert.Issuer
+	// the signature algorithm depends on the CA private key, which may differ from the certificate key algorithm
+	validatedCertificateTemplate.SignatureAlgorithm = x509.UnknownSignatureAlgorithm
 
 	certTemplate := x509.Certificate(validatedCertificateTemplate)
 
@@ -31,8 +31,17 @@ const (
 
 const (
 	caInternalSecretSuffix = "ca-internal"
+
+	// stagedCertFileName and stagedKeyFileName hold the CA staged to replace the current one during a rotation.
+	stagedCertFileName = "staged.crt"
+	stagedKeyFileName  = "staged.key"
+	// previousCertFileName holds the CA cert replaced during a rotation, until all leaf certificates are reissued.
+	previousCertFileName = "previous.crt"
 )
 
+// LeafCertificatesCheck returns true if all the leaf certificates in use have been issued by the given CA cert.
+type LeafCertificatesCheck func(caCert *x509.Certificate) (bool, error)
+
 // CAInternalSecretName returns the name of the internal secret containing the CA certs and keys
 func CAInternalSecretName(namer name.Namer, ownerName string, caType CAType) string {
 	return namer.Suffix(ownerName, string(caType), caInternalSecretSuffix)
@@ -43,7 +52,16 @@ func CAInternalSecretName(namer name.Namer, ownerName string, caType CAType) str
 // The CA is persisted across operator restarts in the apiserver as a Secret for the CA certificate and private key:
 // `<clusterName>-<caType>-ca-internal`
 //
-// The CA cert and private key are rotated if they become invalid (or soon to expire).
+// The CA cert and private key are rotated in stages when they are soon to expire, so that clients never have to
+// trust a leaf certificate issued by a CA they don't know yet:
+//
+//  1. a new CA is generated and staged: it is part of the trusted CA certs, but not used to issue certificates.
+//  2. once the staged CA has been trusted for half of the rotation safety margin, it replaces the current CA.
+//     Leaf certificates are then reissued, while the previous CA is still trusted.
+//  3. the previous CA is not trusted anymore once leafCertsRotated reports all leaf certs use the new CA.
+//
+// A staged rotation also happens if the CA private key does not match the given key params anymore.
+// The CA cert and private key are replaced at once if they are invalid or already expired.
 func ReconcileCAForOwner(
 	cl k8s.Client,
 	scheme *runtime.Scheme,
@@ -52,6 +70,8 @@ func ReconcileCAForOwner(
 	labels map[string]string,
 	caType CAType,
 	rotationParams RotationParams,
+	keyParams KeyParams,
+	leafCertsRotated LeafCertificatesCheck,
 ) (*CA, error) {
 
 	// retrieve current CA secret
@@ -66,26 +86,100 @@ func ReconcileCAForOwner(
 	}
 	if apierrors.IsNotFound(err) {
 		log.Info("No internal CA certificate Secret found, creating a new one", "owner_namespace", owner.GetNamespace(), "owner_name", owner.GetName(), "ca_type", caType)
-		return renewCA(cl, namer, owner, labels, rotationParams.Validity, scheme, caType)
+		return renewCA(cl, namer, owner, labels, rotationParams.Validity, keyParams, scheme, caType)
 	}
 
 	// build CA
 	ca := buildCAFromSecret(caInternalSecret)
 	if ca == nil {
 		log.Info("Cannot build CA from secret, creating a new one", "owner_namespace", owner.GetNamespace(), "owner_name", owner.GetName(), "ca_type", caType)
-		return renewCA(cl, namer, owner, labels, rotationParams.Validity, scheme, caType)
+		return renewCA(cl, namer, owner, labels, rotationParams.Validity, keyParams, scheme, caType)
 	}
+	staged := buildStagedCAFromSecret(caInternalSecret)
 
-	// renew if cannot reuse
-	if !canReuseCA(ca, rotationParams.RotateBefore) {
+	// renew at once if cannot reuse at all
+	if !canReuseCA(ca, 0) {
+		if staged != nil && canReuseCA(staged, 0) {
+			log.Info("Cannot reuse existing CA, promoting the staged one", "owner_namespace", owner.GetNamespace(), "owner_name", owner.GetName(), "ca_type", caType)
+			return promoteStagedCA(cl, scheme, namer, owner, labels, caType, ca, staged)
+		}
 		log.Info("Cannot reuse existing CA, creating a new one", "owner_namespace", owner.GetNamespace(), "owner_name", owner.GetName(), "ca_type", caType)
-		return renewCA(cl, namer, owner, labels, rotationParams.Validity, scheme, caType)
+		return renewCA(cl, namer, owner, labels, rotationParams.Validity, keyParams, scheme, caType)
 	}
 
-	// reuse existing CA
+	ca.Previous = buildPreviousCACertFromSecret(caInternalSecret)
+	if ca.Previous != nil {
+		rotated, err := leafCertsRotated(ca.Cert)
+		if err != nil {
+			return nil, err
+		}
+		if rotated {
+			log.Info("All leaf certificates rotated, removing the previous CA", "owner_namespace", owner.GetNamespace(), "owner_name", owner.GetName(), "ca_type", caType)
+			ca.Previous = nil
+			if err := updateCAInternalSecret(cl, scheme, owner, ca, namer, labels, caType, staged); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// reuse existing CA if not soon to expire, and generated with the expected key params
+	if time.Now().Before(ca.Cert.NotAfter.Add(-rotationParams.RotateBefore)) && keyParams.Matches(ca.PrivateKey) {
+		if staged != nil {
+			ca.Staged = staged.Cert
+		}
+		return ca, nil
+	}
+
+	// stage a new CA first, so it gets trusted before being used to issue certificates
+	if staged == nil {
+		log.Info("Existing CA soon to expire or with different key params, staging a new one", "owner_namespace", owner.GetNamespace(), "owner_name", owner.GetName(), "ca_type", caType)
+		return stageCA(cl, namer, owner, labels, rotationParams.Validity, keyParams, scheme, caType, ca)
+	}
+	if time.Now().Before(staged.Cert.NotBefore.Add(stagingDuration(rotationParams))) {
+		ca.Staged = staged.Cert
+		return ca, nil
+	}
+	log.Info("Existing CA soon to expire or with different key params, promoting the staged one", "owner_namespace", owner.GetNamespace(), "owner_name", owner.GetName(), "ca_type", caType)
+	return promoteStagedCA(cl, scheme, namer, owner, labels, caType, ca, staged)
+}
+
+// GetCAForOwner returns the current CA of the given owner and CAType, without applying any change.
+// It returns nil if there is no CA yet, or if the existing one cannot be parsed.
+func GetCAForOwner(cl k8s.Client, namer name.Namer, owner types.NamespacedName, caType CAType) (*CA, error) {
+	caInternalSecret := corev1.Secret{}
+	err := cl.Get(types.NamespacedName{
+		Namespace: owner.Namespace,
+		Name:      CAInternalSecretName(namer, owner.Name, caType),
+	}, &caInternalSecret)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ca := buildCAFromSecret(caInternalSecret)
+	if ca == nil {
+		return nil, nil
+	}
+	ca.Previous = buildPreviousCACertFromSecret(caInternalSecret)
+	if staged := buildStagedCAFromSecret(caInternalSecret); staged != nil {
+		ca.Staged = staged.Cert
+	}
 	return ca, nil
 }
 
+// newCAForOwner creates a new self-signed CA for the given owner and CAType.
+func newCAForOwner(owner v1.Object, caType CAType, expireIn time.Duration, keyParams KeyParams) (*CA, error) {
+	return NewSelfSignedCA(CABuilderOptions{
+		Subject: pkix.Name{
+			CommonName:         owner.GetName() + "-" + string(caType),
+			OrganizationalUnit: []string{owner.GetName()},
+		},
+		ExpireIn:  &expireIn,
+		KeyParams: &keyParams,
+	})
+}
+
 // renewCA creates and stores a new CA to replace one that might exist
 func renewCA(
 	client k8s.Client,
@@ -93,24 +187,80 @@ func renewCA(
 	owner v1.Object,
 	labels map[string]string,
 	expireIn time.Duration,
+	keyParams KeyParams,
 	scheme *runtime.Scheme,
 	caType CAType,
 ) (*CA, error) {
-	ca, err := NewSelfSignedCA(CABuilderOptions{
-		Subject: pkix.Name{
-			CommonName:         owner.GetName() + "-" + string(caType),
-			OrganizationalUnit: []string{owner.GetName()},
-		},
-		ExpireIn: &expireIn,
-	})
+	ca, err := newCAForOwner(owner, caType, expireIn, keyParams)
+	if err != nil {
+		return nil, err
+	}
+	if err := updateCAInternalSecret(client, scheme, owner, ca, namer, labels, caType, nil); err != nil {
+		return nil, err
+	}
+	return ca, nil
+}
+
+// stageCA creates and stores a new CA staged to replace the given current one.
+// The current CA is returned, with the staged CA cert attached.
+func stageCA(
+	client k8s.Client,
+	namer name.Namer,
+	owner v1.Object,
+	labels map[string]string,
+	expireIn time.Duration,
+	keyParams KeyParams,
+	scheme *runtime.Scheme,
+	caType CAType,
+	current *CA,
+) (*CA, error) {
+	staged, err := newCAForOwner(owner, caType, expireIn, keyParams)
 	if err != nil {
 		return nil, err
 	}
-	caInternalSecret := internalSecretForCA(ca, namer, owner, labels, caType)
+	if err := updateCAInternalSecret(client, scheme, owner, current, namer, labels, caType, staged); err != nil {
+		return nil, err
+	}
+	current.Staged = staged.Cert
+	return current, nil
+}
 
-	// create or update internal secret
+// promoteStagedCA stores the staged CA as the current one, keeping the current CA cert as the previous one.
+func promoteStagedCA(
+	client k8s.Client,
+	scheme *runtime.Scheme,
+	namer name.Namer,
+	owner v1.Object,
+	labels map[string]string,
+	caType CAType,
+	current *CA,
+	staged *CA,
+) (*CA, error) {
+	promoted := NewCA(staged.PrivateKey, staged.Cert)
+	promoted.Previous = current.Cert
+	if err := updateCAInternalSecret(client, scheme, owner, promoted, namer, labels, caType, nil); err != nil {
+		return nil, err
+	}
+	return promoted, nil
+}
+
+// updateCAInternalSecret creates or updates the internal CA secret for the given CA, and staged CA if any.
+func updateCAInternalSecret(
+	client k8s.Client,
+	scheme *runtime.Scheme,
+	owner v1.Object,
+	ca *CA,
+	namer name.Namer,
+	labels map[string]string,
+	caType CAType,
+	staged *CA,
+) error {
+	caInternalSecret, err := internalSecretForCA(ca, namer, owner, labels, caType, staged)
+	if err != nil {
+		return err
+	}
 	reconciledCAInternalSecret := corev1.Secret{}
-	if err := reconciler.ReconcileResource(reconciler.Params{
+	return reconciler.ReconcileResource(reconciler.Params{
 		Client:           client,
 		Expected:         &caInternalSecret,
 		NeedsUpdate:      func() bool { return true },
@@ -118,16 +268,12 @@ func renewCA(
 		Reconciled:       &reconciledCAInternalSecret,
 		Scheme:           scheme,
 		UpdateReconciled: func() { reconciledCAInternalSecret.Data = caInternalSecret.Data },
-	}); err != nil {
-		return nil, err
-	}
-
-	return ca, nil
+	})
 }
 
 // canReuseCA returns true if the given CA is valid for reuse
 func canReuseCA(ca *CA, expirationSafetyMargin time.Duration) bool {
-	return PrivateMatchesPublicKey(ca.Cert.PublicKey, *ca.PrivateKey) && certIsValid(*ca.Cert, expirationSafetyMargin)
+	return PrivateMatchesPublicKey(ca.Cert.PublicKey, ca.PrivateKey) && certIsValid(*ca.Cert, expirationSafetyMargin)
 }
 
 // certIsValid returns true if the given cert is valid,
@@ -145,15 +291,20 @@ func certIsValid(cert x509.Certificate, expirationSafetyMargin time.Duration) bo
 	return true
 }
 
-// internalSecretForCA returns a new internal Secret for the given CA.
+// internalSecretForCA returns a new internal Secret for the given CA, along with the CA being rotated in or out if any.
 func internalSecretForCA(
 	ca *CA,
 	namer name.Namer,
 	owner v1.Object,
 	labels map[string]string,
 	caType CAType,
-) corev1.Secret {
-	return corev1.Secret{
+	staged *CA,
+) (corev1.Secret, error) {
+	privateKey, err := EncodePEMPrivateKey(ca.PrivateKey)
+	if err != nil {
+		return corev1.Secret{}, err
+	}
+	secret := corev1.Secret{
 		ObjectMeta: v1.ObjectMeta{
 			Namespace: owner.GetNamespace(),
 			Name:      CAInternalSecretName(namer, owner.GetName(), caType),
@@ -161,18 +312,60 @@ func internalSecretForCA(
 		},
 		Data: map[string][]byte{
 			CertFileName: EncodePEMCert(ca.Cert.Raw),
-			KeyFileName:  EncodePEMPrivateKey(*ca.PrivateKey),
+			KeyFileName:  privateKey,
 		},
 	}
+	if staged != nil {
+		stagedPrivateKey, err := EncodePEMPrivateKey(staged.PrivateKey)
+		if err != nil {
+			return corev1.Secret{}, err
+		}
+		secret.Data[stagedCertFileName] = EncodePEMCert(staged.Cert.Raw)
+		secret.Data[stagedKeyFileName] = stagedPrivateKey
+	}
+	if ca.Previous != nil {
+		secret.Data[previousCertFileName] = EncodePEMCert(ca.Previous.Raw)
+	}
+	return secret, nil
 }
 
 // buildCAFromSecret parses the given secret into a CA.
 // It returns nil if the secrets could not be parsed into a CA.
 func buildCAFromSecret(caInternalSecret corev1.Secret) *CA {
+	return buildCAFromSecretEntries(caInternalSecret, CertFileName, KeyFileName)
+}
+
+// buildStagedCAFromSecret parses the CA staged for rotation in the given secret.
+// It returns nil if there is no staged CA, or if it could not be parsed.
+func buildStagedCAFromSecret(caInternalSecret corev1.Secret) *CA {
+	return buildCAFromSecretEntries(caInternalSecret, stagedCertFileName, stagedKeyFileName)
+}
+
+// buildPreviousCACertFromSecret parses the CA cert replaced during the last rotation in the given secret.
+// It returns nil if there is no previous CA cert, or if it could not be parsed.
+func buildPreviousCACertFromSecret(caInternalSecret corev1.Secret) *x509.Certificate {
+	certBytes, exists := caInternalSecret.Data[previousCertFileName]
+	if !exists || len(certBytes) == 0 {
+		return nil
+	}
+	certs, err := ParsePEMCerts(certBytes)
+	if err != nil {
+		log.Error(err, "Cannot parse previous PEM cert from CA secret, ignoring it", "namespace", caInternalSecret.Namespace, "secret_name", caInternalSecret.Name)
+		return nil
+	}
+	if len(certs) == 0 {
+		return nil
+	}
+	return certs[0]
+}
+
+// buildCAFromSecretEntries parses the given cert and key entries of the secret into a CA.
+// It returns nil if the entries could not be parsed into a CA.
+func buildCAFromSecretEntries(caInternalSecret corev1.Secret, certFileName string, keyFileName string) *CA {
 	if caInternalSecret.Data == nil {
 		return nil
 	}
-	caBytes, exists := caInternalSecret.Data[CertFileName]
+	caBytes, exists := caInternalSecret.Data[certFileName]
 	if !exists || len(caBytes) == 0 {
 		return nil
 	}
@@ -193,7 +386,7 @@ func buildCAFromSecret(caInternalSecret corev1.Secret) *CA {
 	}
 	cert := certs[0]
 
-	privateKeyBytes, exists := caInternalSecret.Data[KeyFileName]
+	privateKeyBytes, exists := caInternalSecret.Data[keyFileName]
 	if !exists || len(privateKeyBytes) == 0 {
 		return nil
 	}
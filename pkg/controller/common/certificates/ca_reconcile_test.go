@@ -153,7 +153,7 @@ func checkCASecrets(
 	expectedExpiration time.Duration,
 ) {
 	// ca cert should be valid
-	require.True(t, certIsValid(*ca.Cert, DefaultRotateBefore))
+	require.True(t, certIsValid(*ca.Cert, 0))
 
 	// expiration date should be correctly set
 	require.True(t, ca.Cert.NotBefore.After(time.Now().Add(-1*time.Hour)))
@@ -162,8 +162,7 @@ func checkCASecrets(
 	// if an expected Ca was passed, it should match ca
 	if expectedCa != nil {
 		require.True(t, ca.Cert.Equal(expectedCa.Cert))
-		require.Equal(t, ca.PrivateKey.E, expectedCa.PrivateKey.E)
-		require.Equal(t, ca.PrivateKey.N, expectedCa.PrivateKey.N)
+		require.True(t, PrivateMatchesPublicKey(expectedCa.PrivateKey.Public(), ca.PrivateKey))
 	}
 
 	// if a not expected Ca was passed, it should not match ca
@@ -186,14 +185,13 @@ func checkCASecrets(
 	require.NotNil(t, parsedCa)
 	// and return the ca
 	require.True(t, ca.Cert.Equal(parsedCa.Cert))
-	require.Equal(t, ca.PrivateKey.E, parsedCa.PrivateKey.E)
-	require.Equal(t, ca.PrivateKey.N, parsedCa.PrivateKey.N)
+	require.True(t, PrivateMatchesPublicKey(parsedCa.PrivateKey.Public(), ca.PrivateKey))
 }
 
 func Test_renewCA(t *testing.T) {
 	testCa, err := NewSelfSignedCA(CABuilderOptions{})
 	require.NoError(t, err)
-	internalCASecret := internalSecretForCA(testCa, testNamer, &testCluster, nil, TransportCAType)
+	internalCASecret := mustInternalSecretForCA(t, testCa, testNamer, &testCluster, nil, TransportCAType, nil)
 
 	err = v1alpha1.AddToScheme(scheme.Scheme)
 	require.NoError(t, err)
@@ -202,26 +200,36 @@ func Test_renewCA(t *testing.T) {
 		name        string
 		client      k8s.Client
 		expireIn    time.Duration
+		keyParams   KeyParams
 		notExpected *CA
 	}{
 		{
-			name:     "create new CA",
-			client:   k8s.WrapClient(fake.NewFakeClient()),
-			expireIn: DefaultCertValidity,
+			name:      "create new CA",
+			client:    k8s.WrapClient(fake.NewFakeClient()),
+			expireIn:  DefaultCertValidity,
+			keyParams: DefaultKeyParams,
 		},
 		{
 			name:        "replace existing CA",
 			client:      k8s.WrapClient(fake.NewFakeClient(&internalCASecret)),
 			expireIn:    DefaultCertValidity,
+			keyParams:   DefaultKeyParams,
 			notExpected: testCa, // existing CA should be replaced
 		},
+		{
+			name:      "create new ECDSA CA",
+			client:    k8s.WrapClient(fake.NewFakeClient()),
+			expireIn:  DefaultCertValidity,
+			keyParams: KeyParams{Algorithm: ECDSAKeyAlgorithm, Size: 384},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ca, err := renewCA(tt.client, testNamer, &testCluster, nil, tt.expireIn, scheme.Scheme, TransportCAType)
+			ca, err := renewCA(tt.client, testNamer, &testCluster, nil, tt.expireIn, tt.keyParams, scheme.Scheme, TransportCAType)
 			require.NoError(t, err)
 			require.NotNil(t, ca)
 			assert.Equal(t, ca.Cert.Issuer.CommonName, testName+"-"+string(TransportCAType))
+			assert.True(t, tt.keyParams.Matches(ca.PrivateKey))
 			checkCASecrets(t, tt.client, testCluster, TransportCAType, ca, nil, tt.notExpected, tt.expireIn)
 		})
 	}
@@ -233,7 +241,7 @@ func TestReconcileCAForCluster(t *testing.T) {
 
 	validCa, err := NewSelfSignedCA(CABuilderOptions{})
 	require.NoError(t, err)
-	internalCASecret := internalSecretForCA(validCa, testNamer, &testCluster, nil, TransportCAType)
+	internalCASecret := mustInternalSecretForCA(t, validCa, testNamer, &testCluster, nil, TransportCAType, nil)
 
 	internalCASecretWithoutPrivateKey := internalCASecret.DeepCopy()
 	delete(internalCASecretWithoutPrivateKey.Data, KeyFileName)
@@ -246,14 +254,15 @@ func TestReconcileCAForCluster(t *testing.T) {
 		ExpireIn: &soonToExpire,
 	})
 	require.NoError(t, err)
-	soonToExpireInternalCASecret := internalSecretForCA(
-		soonToExpireCa, testNamer, &testCluster, nil, TransportCAType,
+	soonToExpireInternalCASecret := mustInternalSecretForCA(
+		t, soonToExpireCa, testNamer, &testCluster, nil, TransportCAType, nil,
 	)
 
 	tests := []struct {
 		name             string
 		cl               k8s.Client
 		caCertValidity   time.Duration
+		keyParams        KeyParams
 		shouldReuseCa    *CA // ca that should be reused
 		shouldNotReuseCa *CA // ca that should not be reused
 	}{
@@ -261,32 +270,43 @@ func TestReconcileCAForCluster(t *testing.T) {
 			name:           "no existing CA cert nor private key",
 			cl:             k8s.WrapClient(fake.NewFakeClient()),
 			caCertValidity: DefaultCertValidity,
+			keyParams:      DefaultKeyParams,
 			shouldReuseCa:  nil, // should create a new one
 		},
 		{
 			name:           "existing CA cert but no private key",
 			cl:             k8s.WrapClient(fake.NewFakeClient(internalCASecretWithoutPrivateKey)),
 			caCertValidity: DefaultCertValidity,
+			keyParams:      DefaultKeyParams,
 			shouldReuseCa:  nil, // should create a new one
 		},
 		{
 			name:           "existing private key cert but no cert",
 			cl:             k8s.WrapClient(fake.NewFakeClient(internalCASecretWithoutCACert)),
 			caCertValidity: DefaultCertValidity,
+			keyParams:      DefaultKeyParams,
 			shouldReuseCa:  nil, // should create a new one
 		},
 		{
 			name:           "existing valid internal secret",
 			cl:             k8s.WrapClient(fake.NewFakeClient(&internalCASecret)),
 			caCertValidity: DefaultCertValidity,
+			keyParams:      DefaultKeyParams,
 			shouldReuseCa:  validCa, // should reuse existing one
 		},
 		{
-			name:             "existing internal cert is soon to expire",
-			cl:               k8s.WrapClient(fake.NewFakeClient(&soonToExpireInternalCASecret)),
-			caCertValidity:   DefaultCertValidity,
-			shouldReuseCa:    nil,            // should create a new one
-			shouldNotReuseCa: soonToExpireCa, // and not reuse existing one
+			name:           "existing internal cert is soon to expire",
+			cl:             k8s.WrapClient(fake.NewFakeClient(&soonToExpireInternalCASecret)),
+			caCertValidity: DefaultCertValidity,
+			keyParams:      DefaultKeyParams,
+			shouldReuseCa:  soonToExpireCa, // should keep using the existing one while a new one is staged
+		},
+		{
+			name:           "existing valid internal secret with a different key algorithm",
+			cl:             k8s.WrapClient(fake.NewFakeClient(&internalCASecret)),
+			caCertValidity: DefaultCertValidity,
+			keyParams:      KeyParams{Algorithm: ECDSAKeyAlgorithm, Size: DefaultECDSAKeySize},
+			shouldReuseCa:  validCa, // should keep using the existing one while a new one is staged
 		},
 	}
 	for _, tt := range tests {
@@ -296,6 +316,8 @@ func TestReconcileCAForCluster(t *testing.T) {
 					Validity:     tt.caCertValidity,
 					RotateBefore: DefaultRotateBefore,
 				},
+				tt.keyParams,
+				leafCertsRotated(true),
 			)
 			require.NoError(t, err)
 			require.NotNil(t, ca)
@@ -306,13 +328,138 @@ func TestReconcileCAForCluster(t *testing.T) {
 	}
 }
 
+func leafCertsRotated(rotated bool) LeafCertificatesCheck {
+	return func(caCert *x509.Certificate) (bool, error) {
+		return rotated, nil
+	}
+}
+
+func TestReconcileCAForOwner_StagedRotation(t *testing.T) {
+	err := v1alpha1.AddToScheme(scheme.Scheme)
+	require.NoError(t, err)
+
+	rotation := RotationParams{
+		Validity:     DefaultCertValidity,
+		RotateBefore: 2 * time.Hour,
+	}
+	getInternalSecret := func(c k8s.Client) corev1.Secret {
+		var secret corev1.Secret
+		require.NoError(t, c.Get(types.NamespacedName{
+			Namespace: testCluster.Namespace,
+			Name:      CAInternalSecretName(testNamer, testCluster.Name, TransportCAType),
+		}, &secret))
+		return secret
+	}
+
+	// CA expiring within the rotation safety margin
+	expireIn := 1 * time.Hour
+	currentCa, err := NewSelfSignedCA(CABuilderOptions{ExpireIn: &expireIn})
+	require.NoError(t, err)
+	cl := k8s.WrapClient(fake.NewFakeClient(ptr(mustInternalSecretForCA(t, currentCa, testNamer, &testCluster, nil, TransportCAType, nil))))
+
+	// a new CA should be staged, the current one still being used
+	ca, err := ReconcileCAForOwner(cl, scheme.Scheme, testNamer, &testCluster, nil, TransportCAType, rotation, DefaultKeyParams, leafCertsRotated(false))
+	require.NoError(t, err)
+	require.True(t, ca.Cert.Equal(currentCa.Cert))
+	require.NotNil(t, ca.Staged)
+	require.Nil(t, ca.Previous)
+	require.Len(t, ca.TrustedCerts(), 2)
+	stagedCert := ca.Staged
+
+	// the staged CA should be kept until it has been trusted long enough
+	ca, err = ReconcileCAForOwner(cl, scheme.Scheme, testNamer, &testCluster, nil, TransportCAType, rotation, DefaultKeyParams, leafCertsRotated(false))
+	require.NoError(t, err)
+	require.True(t, ca.Cert.Equal(currentCa.Cert))
+	require.True(t, ca.Staged.Equal(stagedCert))
+	require.Equal(t, stagingDuration(rotation)-1*time.Minute, ShouldRotateCAIn(stagedCert.NotBefore.Add(1*time.Minute), ca, rotation))
+
+	// simulate the staging duration being over
+	secret := getInternalSecret(cl)
+	staged := buildStagedCAFromSecret(secret)
+	require.NotNil(t, staged)
+	staged.Cert.NotBefore = time.Now().Add(-stagingDuration(rotation) - 1*time.Minute)
+	certData, err := x509.CreateCertificate(cryptorand.Reader, staged.Cert, staged.Cert, staged.PrivateKey.Public(), staged.PrivateKey)
+	require.NoError(t, err)
+	secret.Data[stagedCertFileName] = EncodePEMCert(certData)
+	require.NoError(t, cl.Update(&secret))
+	stagedCert, err = x509.ParseCertificate(certData)
+	require.NoError(t, err)
+
+	// the staged CA should be promoted, the previous one still being trusted
+	ca, err = ReconcileCAForOwner(cl, scheme.Scheme, testNamer, &testCluster, nil, TransportCAType, rotation, DefaultKeyParams, leafCertsRotated(false))
+	require.NoError(t, err)
+	require.True(t, ca.Cert.Equal(stagedCert))
+	require.Nil(t, ca.Staged)
+	require.True(t, ca.Previous.Equal(currentCa.Cert))
+	require.Len(t, ca.TrustedCerts(), 2)
+	require.Equal(t, caRotationCheckInterval, ShouldRotateCAIn(time.Now(), ca, rotation))
+	require.NotContains(t, getInternalSecret(cl).Data, stagedCertFileName)
+
+	// the previous CA should be kept until leaf certs are rotated
+	ca, err = ReconcileCAForOwner(cl, scheme.Scheme, testNamer, &testCluster, nil, TransportCAType, rotation, DefaultKeyParams, leafCertsRotated(false))
+	require.NoError(t, err)
+	require.True(t, ca.Previous.Equal(currentCa.Cert))
+
+	// and removed once they are
+	ca, err = ReconcileCAForOwner(cl, scheme.Scheme, testNamer, &testCluster, nil, TransportCAType, rotation, DefaultKeyParams, leafCertsRotated(true))
+	require.NoError(t, err)
+	require.True(t, ca.Cert.Equal(stagedCert))
+	require.Nil(t, ca.Previous)
+	require.Len(t, ca.TrustedCerts(), 1)
+	require.NotContains(t, getInternalSecret(cl).Data, previousCertFileName)
+}
+
+func TestReconcileCAForOwner_InvalidWithStagedCA(t *testing.T) {
+	err := v1alpha1.AddToScheme(scheme.Scheme)
+	require.NoError(t, err)
+
+	invalidCa, err := NewSelfSignedCA(CABuilderOptions{})
+	require.NoError(t, err)
+	stagedCa, err := NewSelfSignedCA(CABuilderOptions{})
+	require.NoError(t, err)
+	secret := mustInternalSecretForCA(t, invalidCa, testNamer, &testCluster, nil, TransportCAType, stagedCa)
+	// private key does not match the cert anymore
+	otherKey, err := rsa.GenerateKey(cryptorand.Reader, 2048)
+	require.NoError(t, err)
+	secret.Data[KeyFileName], err = EncodePEMPrivateKey(otherKey)
+	require.NoError(t, err)
+	cl := k8s.WrapClient(fake.NewFakeClient(&secret))
+
+	// the staged CA should be promoted at once
+	ca, err := ReconcileCAForOwner(cl, scheme.Scheme, testNamer, &testCluster, nil, TransportCAType, RotationParams{
+		Validity:     DefaultCertValidity,
+		RotateBefore: DefaultRotateBefore,
+	}, DefaultKeyParams, leafCertsRotated(false))
+	require.NoError(t, err)
+	require.True(t, ca.Cert.Equal(stagedCa.Cert))
+	require.True(t, ca.Previous.Equal(invalidCa.Cert))
+}
+
+func mustInternalSecretForCA(
+	t *testing.T,
+	ca *CA,
+	namer name.Namer,
+	owner metav1.Object,
+	labels map[string]string,
+	caType CAType,
+	staged *CA,
+) corev1.Secret {
+	secret, err := internalSecretForCA(ca, namer, owner, labels, caType, staged)
+	require.NoError(t, err)
+	return secret
+}
+
+func ptr(secret corev1.Secret) *corev1.Secret {
+	return &secret
+}
+
 func Test_internalSecretForCA(t *testing.T) {
 	testCa, err := NewSelfSignedCA(CABuilderOptions{})
 	require.NoError(t, err)
 
 	labels := map[string]string{"foo": "bar"}
 
-	internalSecret := internalSecretForCA(testCa, testNamer, &testCluster, labels, TransportCAType)
+	internalSecret := mustInternalSecretForCA(t, testCa, testNamer, &testCluster, labels, TransportCAType, nil)
 
 	assert.Equal(t, testNamespace, internalSecret.Namespace)
 	assert.Equal(t, testName+"-test-transport-ca-internal", internalSecret.Name)
@@ -328,7 +475,7 @@ func Test_buildCAFromSecret(t *testing.T) {
 	testCa, err := NewSelfSignedCA(CABuilderOptions{})
 	require.NoError(t, err)
 
-	internalSecret := internalSecretForCA(testCa, testNamer, &testCluster, nil, TransportCAType)
+	internalSecret := mustInternalSecretForCA(t, testCa, testNamer, &testCluster, nil, TransportCAType, nil)
 
 	internalSecretMissingCert := internalSecret.DeepCopy()
 	delete(internalSecretMissingCert.Data, CertFileName)
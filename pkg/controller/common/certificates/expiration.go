@@ -12,6 +12,8 @@ const (
 	// DefaultRotateBefore defines how long before expiration a certificate
 	// should be re-issued
 	DefaultRotateBefore = 24 * time.Hour
+	// caRotationCheckInterval defines how often leaf certificates are checked while the previous CA is still trusted
+	caRotationCheckInterval = 30 * time.Second
 )
 
 // RotationParams defines validity and a safety margin for certificate rotation.
@@ -35,3 +37,27 @@ func ShouldRotateIn(now time.Time, certExpiration time.Time, caCertRotateBefore
 	}
 	return requeueIn
 }
+
+// stagingDuration is the duration during which a new CA is trusted before being used to issue certificates.
+// It leaves the second half of the rotation safety margin to reissue the leaf certificates.
+func stagingDuration(rotation RotationParams) time.Duration {
+	return rotation.RotateBefore / 2
+}
+
+// ShouldRotateCAIn computes the duration after which the given CA should be reconciled again,
+// in order to move on with its rotation.
+func ShouldRotateCAIn(now time.Time, ca *CA, rotation RotationParams) time.Duration {
+	switch {
+	case ca.Previous != nil:
+		// check again soon whether all leaf certificates were reissued
+		return caRotationCheckInterval
+	case ca.Staged != nil:
+		requeueIn := ca.Staged.NotBefore.Add(stagingDuration(rotation)).Sub(now)
+		if requeueIn <= 0 {
+			requeueIn = caRotationCheckInterval
+		}
+		return requeueIn
+	default:
+		return ShouldRotateIn(now, ca.Cert.NotAfter, rotation.RotateBefore)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package http
+
+import (
+	"time"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// CustomCertificateExpirationWarning defines how long before expiration warning events are emitted for
+	// user-provided certificates, since they cannot be rotated by the operator.
+	CustomCertificateExpirationWarning = 30 * 24 * time.Hour
+	// customCertificateWarningInterval defines how often the warning events are emitted again once the
+	// user-provided certificate is close to expiration.
+	customCertificateWarningInterval = 24 * time.Hour
+)
+
+// ReportCertificatesExpiration exports the expiration date of the HTTP certificate in use by the given owner.
+// A warning event is emitted on the owner if the certificate was provided by the user and is close to expiration.
+// It returns the duration after which the owner should be reconciled to emit the next warning, or 0 if the
+// certificate is rotated by the operator.
+func ReportCertificatesExpiration(
+	recorder record.EventRecorder,
+	owner runtime.Object,
+	ownerKind string,
+	tls v1alpha1.TLSOptions,
+	httpCertificates CertificatesSecret,
+) time.Duration {
+	ownerMeta, err := meta.Accessor(owner)
+	if err != nil {
+		log.Error(err, "Cannot report the HTTP certificate expiration", "owner_kind", ownerKind)
+		return 0
+	}
+	certs, err := certificates.ParsePEMCerts(httpCertificates.CertPem())
+	if err != nil || len(certs) == 0 {
+		log.Error(err, "Cannot report the HTTP certificate expiration",
+			"namespace", ownerMeta.GetNamespace(), "owner_kind", ownerKind, "owner_name", ownerMeta.GetName())
+		return 0
+	}
+	// the first certificate of the chain is the one served
+	notAfter := certs[0].NotAfter
+
+	source := certificates.SelfSignedCertificateSource
+	if tls.Certificate.SecretName != "" {
+		source = certificates.CustomCertificateSource
+	}
+	certificates.ReportExpiration(
+		ownerKind,
+		types.NamespacedName{Namespace: ownerMeta.GetNamespace(), Name: ownerMeta.GetName()},
+		certificates.HTTPCertificateKind,
+		source,
+		notAfter,
+	)
+
+	if source != certificates.CustomCertificateSource {
+		return 0
+	}
+	now := time.Now()
+	warnOnCustomCertificateExpiration(recorder, owner, tls.Certificate.SecretName, notAfter, now)
+	return nextCustomCertificateWarningIn(notAfter, now)
+}
+
+// nextCustomCertificateWarningIn returns the duration after which the next warning event should be emitted for a
+// user-provided certificate expiring at the given date: when entering the warning period, then on a regular basis.
+func nextCustomCertificateWarningIn(notAfter time.Time, now time.Time) time.Duration {
+	if untilWarning := notAfter.Add(-CustomCertificateExpirationWarning).Sub(now); untilWarning > 0 {
+		return untilWarning
+	}
+	if untilExpiration := notAfter.Sub(now); untilExpiration > 0 && untilExpiration < customCertificateWarningInterval {
+		return untilExpiration
+	}
+	return customCertificateWarningInterval
+}
+
+// warnOnCustomCertificateExpiration emits a warning event if the given user-provided certificate expires
+// within CustomCertificateExpirationWarning.
+func warnOnCustomCertificateExpiration(
+	recorder record.EventRecorder,
+	owner runtime.Object,
+	secretName string,
+	notAfter time.Time,
+	now time.Time,
+) {
+	if notAfter.Sub(now) > CustomCertificateExpirationWarning {
+		return
+	}
+	if now.After(notAfter) {
+		recorder.Eventf(owner, corev1.EventTypeWarning, events.EventReasonCertificateExpiration,
+			"Certificate provided in secret %s expired on %s", secretName, notAfter.UTC().Format(time.RFC3339))
+		return
+	}
+	recorder.Eventf(owner, corev1.EventTypeWarning, events.EventReasonCertificateExpiration,
+		"Certificate provided in secret %s expires on %s and must be renewed", secretName, notAfter.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,84 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package http
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+)
+
+func Test_warnOnCustomCertificateExpiration(t *testing.T) {
+	now := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		notAfter  time.Time
+		wantEvent string
+	}{
+		{
+			name:     "certificate far from expiration",
+			notAfter: now.Add(CustomCertificateExpirationWarning + time.Hour),
+		},
+		{
+			name:      "certificate close to expiration",
+			notAfter:  now.Add(24 * time.Hour),
+			wantEvent: "Warning CertificateExpiration Certificate provided in secret my-cert expires on 2019-06-02T00:00:00Z and must be renewed",
+		},
+		{
+			name:      "expired certificate",
+			notAfter:  now.Add(-24 * time.Hour),
+			wantEvent: "Warning CertificateExpiration Certificate provided in secret my-cert expired on 2019-05-31T00:00:00Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			warnOnCustomCertificateExpiration(recorder, &testES, "my-cert", tt.notAfter, now)
+			if tt.wantEvent == "" {
+				require.Empty(t, recorder.Events)
+				return
+			}
+			require.Len(t, recorder.Events, 1)
+			require.Equal(t, tt.wantEvent, <-recorder.Events)
+		})
+	}
+}
+
+func Test_nextCustomCertificateWarningIn(t *testing.T) {
+	now := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		notAfter time.Time
+		want     time.Duration
+	}{
+		{
+			name:     "certificate far from expiration: requeue when entering the warning period",
+			notAfter: now.Add(CustomCertificateExpirationWarning + time.Hour),
+			want:     time.Hour,
+		},
+		{
+			name:     "certificate close to expiration: requeue on a regular basis",
+			notAfter: now.Add(48 * time.Hour),
+			want:     customCertificateWarningInterval,
+		},
+		{
+			name:     "certificate expiring before the next warning: requeue on expiration",
+			notAfter: now.Add(time.Hour),
+			want:     time.Hour,
+		},
+		{
+			name:     "expired certificate: requeue on a regular basis",
+			notAfter: now.Add(-time.Hour),
+			want:     customCertificateWarningInterval,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, nextCustomCertificateWarningIn(tt.notAfter, now))
+		})
+	}
+}
@@ -5,6 +5,7 @@
 package http
 
 import (
+	"crypto/x509"
 	"reflect"
 
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
@@ -20,6 +21,7 @@ import (
 
 // ReconcileHTTPCertsPublicSecret reconciles the Secret containing the HTTP Certificate currently in use, and the CA of
 // the certificate if available.
+// During a CA rotation, both the staged or previous CA and the current one are part of the trusted CA certs.
 func ReconcileHTTPCertsPublicSecret(
 	c k8s.Client,
 	scheme *runtime.Scheme,
@@ -30,9 +32,12 @@ func ReconcileHTTPCertsPublicSecret(
 	expected := &corev1.Secret{
 		ObjectMeta: k8s.ToObjectMeta(PublicCertsSecretRef(namer, k8s.ExtractNamespacedName(owner))),
 		Data: map[string][]byte{
-			certificates.CertFileName: httpCertificates.CertPem(),
+			certificates.CertFileName: publicCertPem(*httpCertificates),
 		},
 	}
+	if caPem := httpCertificates.CAPem(); len(caPem) > 0 {
+		expected.Data[certificates.CAFileName] = caPem
+	}
 
 	reconciled := &corev1.Secret{}
 
@@ -62,6 +67,36 @@ func ReconcileHTTPCertsPublicSecret(
 	})
 }
 
+// publicCertPem returns the certificate chain in use, followed by the trusted CA certs it does not already contain.
+// Clients relying on the certificate chain to trust the CA can then keep working through a CA rotation.
+func publicCertPem(httpCertificates CertificatesSecret) []byte {
+	certPem := httpCertificates.CertPem()
+	caCerts, err := certificates.ParsePEMCerts(httpCertificates.CAPem())
+	if err != nil || len(caCerts) == 0 {
+		return certPem
+	}
+	chain, err := certificates.ParsePEMCerts(certPem)
+	if err != nil {
+		return certPem
+	}
+	result := certPem
+	for _, caCert := range caCerts {
+		if !containsCert(chain, caCert) {
+			result = append(result, certificates.EncodePEMCert(caCert.Raw)...)
+		}
+	}
+	return result
+}
+
+func containsCert(certs []*x509.Certificate, cert *x509.Certificate) bool {
+	for _, c := range certs {
+		if c.Equal(cert) {
+			return true
+		}
+	}
+	return false
+}
+
 // PublicCertsSecretRef returns the NamespacedName for the Secret containing the publicly available HTTP CA.
 func PublicCertsSecretRef(namer name.Namer, es types.NamespacedName) types.NamespacedName {
 	return types.NamespacedName{
@@ -40,6 +40,11 @@ func TestReconcileHTTPCertsPublicSecret(t *testing.T) {
 
 	namespacedSecretName := PublicCertsSecretRef(name.ESNamer, k8s.ExtractNamespacedName(owner))
 
+	// the CA cert is appended to the certificate chain, since the chain does not contain it
+	caCerts, err := certificates.ParsePEMCerts(ca)
+	require.NoError(t, err)
+	publicTLS := append(append([]byte{}, tls...), certificates.EncodePEMCert(caCerts[0].Raw)...)
+
 	mkClient := func(t *testing.T, objs ...runtime.Object) k8s.Client {
 		t.Helper()
 		return k8s.WrapClient(fake.NewFakeClient(objs...))
@@ -50,7 +55,8 @@ func TestReconcileHTTPCertsPublicSecret(t *testing.T) {
 		wantSecret := &corev1.Secret{
 			ObjectMeta: k8s.ToObjectMeta(namespacedSecretName),
 			Data: map[string][]byte{
-				certificates.CertFileName: tls,
+				certificates.CAFileName:   ca,
+				certificates.CertFileName: publicTLS,
 			},
 		}
 
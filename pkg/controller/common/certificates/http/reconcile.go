@@ -5,8 +5,9 @@
 package http
 
 import (
+	"bytes"
+	"crypto"
 	cryptorand "crypto/rand"
-	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"net"
@@ -43,6 +44,7 @@ func ReconcileHTTPCertificates(
 	labels map[string]string,
 	services []corev1.Service,
 	rotationParams certificates.RotationParams,
+	keyParams certificates.KeyParams,
 ) (*CertificatesSecret, error) {
 	ownerNSN := k8s.ExtractNamespacedName(owner)
 	customCertificates, err := GetCustomCertificates(driver.K8sClient(), ownerNSN, tls)
@@ -55,7 +57,7 @@ func ReconcileHTTPCertificates(
 	}
 
 	internalCerts, err := reconcileHTTPInternalCertificatesSecret(
-		driver.K8sClient(), driver.Scheme(), owner, namer, tls, labels, services, customCertificates, ca, rotationParams,
+		driver.K8sClient(), driver.Scheme(), owner, namer, tls, labels, services, customCertificates, ca, rotationParams, keyParams,
 	)
 	if err != nil {
 		return nil, err
@@ -76,6 +78,7 @@ func reconcileHTTPInternalCertificatesSecret(
 	customCertificates *CertificatesSecret,
 	ca *certificates.CA,
 	rotationParams certificates.RotationParams,
+	keyParams certificates.KeyParams,
 ) (*CertificatesSecret, error) {
 	secret := corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -129,7 +132,7 @@ func reconcileHTTPInternalCertificatesSecret(
 		}
 	} else {
 		selfSignedNeedsUpdate, err := ensureInternalSelfSignedCertificateSecretContents(
-			&secret, k8s.ExtractNamespacedName(owner), namer, tls, svcs, ca, rotationParams,
+			&secret, k8s.ExtractNamespacedName(owner), namer, tls, svcs, ca, rotationParams, keyParams,
 		)
 		if err != nil {
 			return nil, err
@@ -155,6 +158,44 @@ func reconcileHTTPInternalCertificatesSecret(
 	return &result, nil
 }
 
+// ShouldReissueHTTPCertificates returns true if reconciling the HTTP certificates of the given owner would
+// issue a new certificate (or use new custom certificates), without applying any change.
+// A nil CA means the CA does not exist yet, in which case a new certificate is always issued.
+func ShouldReissueHTTPCertificates(
+	c k8s.Client,
+	owner types.NamespacedName,
+	namer name.Namer,
+	tls v1alpha1.TLSOptions,
+	svcs []corev1.Service,
+	ca *certificates.CA,
+	rotateBefore time.Duration,
+) (bool, error) {
+	var secret corev1.Secret
+	err := c.Get(types.NamespacedName{Namespace: owner.Namespace, Name: certificates.HTTPCertsInternalSecretName(namer, owner.Name)}, &secret)
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	customCertificates, err := GetCustomCertificates(c, owner, tls)
+	if err != nil {
+		return false, err
+	}
+	if customCertificates != nil {
+		return !bytes.Equal(secret.Data[certificates.CertFileName], customCertificates.CertChain()), nil
+	}
+
+	if ca == nil {
+		return true, nil
+	}
+	if _, err := certificates.ParsePEMPrivateKey(secret.Data[certificates.KeyFileName]); err != nil {
+		return true, nil
+	}
+	return shouldIssueNewHTTPCertificate(owner, namer, tls, &secret, svcs, ca, rotateBefore), nil
+}
+
 // ensureInternalSelfSignedCertificateSecretContents ensures that contents of a secret containing self-signed
 // certificates is valid. The provided secret is updated in-place.
 //
@@ -167,16 +208,19 @@ func ensureInternalSelfSignedCertificateSecretContents(
 	svcs []corev1.Service,
 	ca *certificates.CA,
 	rotationParam certificates.RotationParams,
+	keyParams certificates.KeyParams,
 ) (bool, error) {
 	secretWasChanged := false
 
-	// verify that the secret contains a parsable private key, create if it does not exist
-	var privateKey *rsa.PrivateKey
+	// verify that the secret contains a parsable private key matching the key params, create if it does not exist
+	var privateKey crypto.Signer
 	needsNewPrivateKey := true
 	if privateKeyData, ok := secret.Data[certificates.KeyFileName]; ok {
 		storedPrivateKey, err := certificates.ParsePEMPrivateKey(privateKeyData)
 		if err != nil {
 			log.Error(err, "Unable to parse stored private key", "namespace", secret.Namespace, "secret_name", secret.Name)
+		} else if !keyParams.Matches(storedPrivateKey) {
+			log.Info("Stored private key does not match the key params", "namespace", secret.Namespace, "secret_name", secret.Name)
 		} else {
 			needsNewPrivateKey = false
 			privateKey = storedPrivateKey
@@ -185,18 +229,22 @@ func ensureInternalSelfSignedCertificateSecretContents(
 
 	// if we need a new private key, generate it
 	if needsNewPrivateKey {
-		generatedPrivateKey, err := rsa.GenerateKey(cryptorand.Reader, 2048)
+		generatedPrivateKey, err := keyParams.GenerateKey()
+		if err != nil {
+			return secretWasChanged, err
+		}
+		encodedPrivateKey, err := certificates.EncodePEMPrivateKey(generatedPrivateKey)
 		if err != nil {
 			return secretWasChanged, err
 		}
 
 		privateKey = generatedPrivateKey
 		secretWasChanged = true
-		secret.Data[certificates.KeyFileName] = certificates.EncodePEMPrivateKey(*privateKey)
+		secret.Data[certificates.KeyFileName] = encodedPrivateKey
 	}
 
-	// check if the existing cert should be re-issued
-	if shouldIssueNewHTTPCertificate(owner, namer, tls, secret, svcs, ca, rotationParam.RotateBefore) {
+	// check if the existing cert should be re-issued, which is always the case with a new private key
+	if needsNewPrivateKey || shouldIssueNewHTTPCertificate(owner, namer, tls, secret, svcs, ca, rotationParam.RotateBefore) {
 		log.Info(
 			"Issuing new HTTP certificate",
 			"namespace", secret.Namespace,
@@ -231,6 +279,12 @@ func ensureInternalSelfSignedCertificateSecretContents(
 		secret.Data[certificates.CertFileName] = certificates.EncodePEMCert(certData, ca.Cert.Raw)
 	}
 
+	// store the CA certs to trust, which include any CA being rotated in or out
+	if trusted := ca.TrustedCertsPem(); !bytes.Equal(secret.Data[certificates.CAFileName], trusted) {
+		secretWasChanged = true
+		secret.Data[certificates.CAFileName] = trusted
+	}
+
 	return secretWasChanged, nil
 }
 
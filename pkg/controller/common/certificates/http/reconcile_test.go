@@ -128,6 +128,7 @@ func TestReconcileHTTPCertificates(t *testing.T) {
 			want: func(t *testing.T, cs *CertificatesSecret) {
 				assert.Contains(t, cs.Data, certificates.KeyFileName)
 				assert.Contains(t, cs.Data, certificates.CertFileName)
+				assert.Equal(t, testCA.TrustedCertsPem(), cs.Data[certificates.CAFileName])
 			},
 		},
 		{
@@ -176,6 +177,7 @@ func TestReconcileHTTPCertificates(t *testing.T) {
 					Validity:     certificates.DefaultCertValidity,
 					RotateBefore: certificates.DefaultRotateBefore,
 				},
+				certificates.DefaultKeyParams,
 			)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ReconcileHTTPCertificates() error = %v, wantErr %v", err, tt.wantErr)
@@ -372,3 +374,86 @@ func Test_shouldIssueNewCertificate(t *testing.T) {
 		})
 	}
 }
+
+func TestShouldReissueHTTPCertificates(t *testing.T) {
+	internalSecret := func(cert []byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Namespace: testES.Namespace,
+				Name:      certificates.HTTPCertsInternalSecretName(name.ESNamer, testES.Name),
+			},
+			Data: map[string][]byte{
+				certificates.CertFileName: cert,
+				certificates.KeyFileName:  []byte(testPemPrivateKey),
+			},
+		}
+	}
+	customCerts := &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Namespace: testES.Namespace, Name: "custom-certs"},
+		Data: map[string][]byte{
+			certificates.CertFileName: []byte("custom-cert"),
+			certificates.KeyFileName:  []byte(testPemPrivateKey),
+		},
+	}
+	esWithCustomCerts := testES.DeepCopy()
+	esWithCustomCerts.Spec.HTTP.TLS.Certificate.SecretName = customCerts.Name
+
+	tests := []struct {
+		name string
+		es   v1alpha1.Elasticsearch
+		ca   *certificates.CA
+		c    k8s.Client
+		want bool
+	}{
+		{
+			name: "no certificates yet",
+			es:   testES,
+			ca:   testCA,
+			c:    k8s.WrapClient(fake.NewFakeClient()),
+			want: true,
+		},
+		{
+			name: "no CA yet",
+			es:   testES,
+			ca:   nil,
+			c:    k8s.WrapClient(fake.NewFakeClient(internalSecret(pemCert))),
+			want: true,
+		},
+		{
+			name: "valid certificate",
+			es:   testES,
+			ca:   testCA,
+			c:    k8s.WrapClient(fake.NewFakeClient(internalSecret(pemCert))),
+			want: false,
+		},
+		{
+			name: "custom certificates not in use yet",
+			es:   *esWithCustomCerts,
+			ca:   testCA,
+			c:    k8s.WrapClient(fake.NewFakeClient(internalSecret(pemCert), customCerts)),
+			want: true,
+		},
+		{
+			name: "custom certificates already in use",
+			es:   *esWithCustomCerts,
+			ca:   testCA,
+			c:    k8s.WrapClient(fake.NewFakeClient(internalSecret([]byte("custom-cert")), customCerts)),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ShouldReissueHTTPCertificates(
+				tt.c,
+				k8s.ExtractNamespacedName(&tt.es),
+				name.ESNamer,
+				tt.es.Spec.HTTP.TLS,
+				[]corev1.Service{testSvc},
+				tt.ca,
+				certificates.DefaultRotateBefore,
+			)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package http
+
+import (
+	"crypto/x509"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PodsCertificatesIssuedBy returns a check verifying that all the pods matching the given labels serve,
+// on the given port, an HTTP certificate issued by the given CA cert.
+// The check always succeeds if the HTTP certificates are not issued by the operator CA.
+func PodsCertificatesIssuedBy(
+	c k8s.Client,
+	dialer net.Dialer,
+	namespace string,
+	podLabels map[string]string,
+	port int,
+	tls v1alpha1.TLSOptions,
+) certificates.LeafCertificatesCheck {
+	return func(caCert *x509.Certificate) (bool, error) {
+		if !tls.Enabled() || tls.Certificate.SecretName != "" {
+			// HTTP certificates are not issued by the CA
+			return true, nil
+		}
+		var pods corev1.PodList
+		if err := c.List(&client.ListOptions{
+			LabelSelector: labels.SelectorFromSet(podLabels),
+			Namespace:     namespace,
+		}, &pods); err != nil {
+			return false, err
+		}
+		return certificates.PodsServeCertificatesIssuedBy(dialer, pods.Items, port, caCert), nil
+	}
+}
@@ -5,21 +5,125 @@
 package certificates
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	cryptorand "crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+)
+
+// KeyAlgorithm is the public key algorithm used for generated private keys.
+type KeyAlgorithm string
+
+const (
+	// RSAKeyAlgorithm generates RSA private keys.
+	RSAKeyAlgorithm KeyAlgorithm = "rsa"
+	// ECDSAKeyAlgorithm generates ECDSA private keys on the NIST P-256 or P-384 curves.
+	ECDSAKeyAlgorithm KeyAlgorithm = "ecdsa"
 
-	"github.com/pkg/errors"
+	// DefaultRSAKeySize is the default size in bits of generated RSA keys.
+	DefaultRSAKeySize = 2048
+	// DefaultECDSAKeySize is the default size in bits of generated ECDSA keys (P-256 curve).
+	DefaultECDSAKeySize = 256
 )
 
+// DefaultKeyParams generates 2048 bits RSA private keys.
+var DefaultKeyParams = KeyParams{Algorithm: RSAKeyAlgorithm, Size: DefaultRSAKeySize}
+
+// KeyParams defines how private keys are generated, for CA and leaf certificates.
+type KeyParams struct {
+	// Algorithm is the public key algorithm.
+	Algorithm KeyAlgorithm
+	// Size is the size in bits of RSA keys, or the ECDSA curve size (256 or 384).
+	Size int
+}
+
+// NewKeyParams returns validated KeyParams for the given algorithm and size.
+// The default size of the algorithm is used if size is 0.
+func NewKeyParams(algorithm string, size int) (KeyParams, error) {
+	params := KeyParams{Algorithm: KeyAlgorithm(algorithm), Size: size}
+	if params.Size == 0 {
+		switch params.Algorithm {
+		case RSAKeyAlgorithm:
+			params.Size = DefaultRSAKeySize
+		case ECDSAKeyAlgorithm:
+			params.Size = DefaultECDSAKeySize
+		}
+	}
+	return params, params.Validate()
+}
+
+// Validate returns an error if the key algorithm or size is not supported.
+func (p KeyParams) Validate() error {
+	switch p.Algorithm {
+	case RSAKeyAlgorithm:
+		if p.Size < DefaultRSAKeySize {
+			return fmt.Errorf("RSA key size must be at least %d bits, got %d", DefaultRSAKeySize, p.Size)
+		}
+		return nil
+	case ECDSAKeyAlgorithm:
+		if _, err := p.curve(); err != nil {
+			return err
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported key algorithm %q, must be one of %s, %s", p.Algorithm, RSAKeyAlgorithm, ECDSAKeyAlgorithm)
+	}
+}
+
+// GenerateKey generates a new private key according to the params.
+func (p KeyParams) GenerateKey() (crypto.Signer, error) {
+	switch p.Algorithm {
+	case ECDSAKeyAlgorithm:
+		curve, err := p.curve()
+		if err != nil {
+			return nil, err
+		}
+		return ecdsa.GenerateKey(curve, cryptorand.Reader)
+	case RSAKeyAlgorithm:
+		return rsa.GenerateKey(cryptorand.Reader, p.Size)
+	default:
+		return nil, p.Validate()
+	}
+}
+
+// Matches returns true if the given private key was generated according to the params.
+func (p KeyParams) Matches(privateKey crypto.Signer) bool {
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return p.Algorithm == RSAKeyAlgorithm && key.N.BitLen() == p.Size
+	case *ecdsa.PrivateKey:
+		return p.Algorithm == ECDSAKeyAlgorithm && key.Curve.Params().BitSize == p.Size
+	default:
+		return false
+	}
+}
+
+func (p KeyParams) curve() (elliptic.Curve, error) {
+	switch p.Size {
+	case 256:
+		return elliptic.P256(), nil
+	case 384:
+		return elliptic.P384(), nil
+	default:
+		return nil, fmt.Errorf("ECDSA key size must be 256 or 384, got %d", p.Size)
+	}
+}
+
 // PrivateMatchesPublicKey returns true if the public and private keys correspond to each other.
-func PrivateMatchesPublicKey(publicKey interface{}, privateKey rsa.PrivateKey) bool {
-	pubKey, ok := publicKey.(*rsa.PublicKey)
-	if !ok {
-		log.Error(errors.New("Public key is not an RSA public key"), "")
+func PrivateMatchesPublicKey(publicKey interface{}, privateKey crypto.Signer) bool {
+	expected, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		log.Error(err, "Cannot marshal public key")
 		return false
 	}
-	// check that public and private keys share the same modulus and exponent
-	if pubKey.N.Cmp(privateKey.N) != 0 || pubKey.E != privateKey.E {
+	actual, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		log.Error(err, "Cannot marshal public key of the private key")
 		return false
 	}
-	return true
+	return bytes.Equal(expected, actual)
 }
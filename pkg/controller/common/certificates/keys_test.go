@@ -7,6 +7,9 @@
 package certificates
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	cryptorand "crypto/rand"
 	"crypto/rsa"
 	"testing"
@@ -19,22 +22,36 @@ func Test_PrivateMatchesPublicKey(t *testing.T) {
 	require.NoError(t, err)
 	privateKey2, err := rsa.GenerateKey(cryptorand.Reader, 2048)
 	require.NoError(t, err)
+	ecdsaPrivateKey, err := ecdsa.GenerateKey(elliptic.P256(), cryptorand.Reader)
+	require.NoError(t, err)
 	tests := []struct {
 		name       string
 		publicKey  interface{}
-		privateKey rsa.PrivateKey
+		privateKey crypto.Signer
 		want       bool
 	}{
 		{
 			name:       "with matching public and private keys",
 			publicKey:  privateKey1.Public(),
-			privateKey: *privateKey1,
+			privateKey: privateKey1,
 			want:       true,
 		},
 		{
 			name:       "with non-matching public and private keys",
 			publicKey:  privateKey1.Public(),
-			privateKey: *privateKey2,
+			privateKey: privateKey2,
+			want:       false,
+		},
+		{
+			name:       "with matching ECDSA public and private keys",
+			publicKey:  ecdsaPrivateKey.Public(),
+			privateKey: ecdsaPrivateKey,
+			want:       true,
+		},
+		{
+			name:       "with RSA public key and ECDSA private key",
+			publicKey:  privateKey1.Public(),
+			privateKey: ecdsaPrivateKey,
 			want:       false,
 		},
 	}
@@ -46,3 +63,99 @@ func Test_PrivateMatchesPublicKey(t *testing.T) {
 		})
 	}
 }
+
+func TestNewKeyParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		size      int
+		want      KeyParams
+		wantErr   bool
+	}{
+		{
+			name:      "default RSA size",
+			algorithm: "rsa",
+			want:      KeyParams{Algorithm: RSAKeyAlgorithm, Size: DefaultRSAKeySize},
+		},
+		{
+			name:      "custom RSA size",
+			algorithm: "rsa",
+			size:      4096,
+			want:      KeyParams{Algorithm: RSAKeyAlgorithm, Size: 4096},
+		},
+		{
+			name:      "RSA size too small",
+			algorithm: "rsa",
+			size:      1024,
+			wantErr:   true,
+		},
+		{
+			name:      "default ECDSA size",
+			algorithm: "ecdsa",
+			want:      KeyParams{Algorithm: ECDSAKeyAlgorithm, Size: DefaultECDSAKeySize},
+		},
+		{
+			name:      "P-384 ECDSA",
+			algorithm: "ecdsa",
+			size:      384,
+			want:      KeyParams{Algorithm: ECDSAKeyAlgorithm, Size: 384},
+		},
+		{
+			name:      "unsupported ECDSA size",
+			algorithm: "ecdsa",
+			size:      521,
+			wantErr:   true,
+		},
+		{
+			name:      "unsupported algorithm",
+			algorithm: "dsa",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewKeyParams(tt.algorithm, tt.size)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestKeyParams_GenerateKey(t *testing.T) {
+	for _, params := range []KeyParams{
+		DefaultKeyParams,
+		{Algorithm: ECDSAKeyAlgorithm, Size: 256},
+		{Algorithm: ECDSAKeyAlgorithm, Size: 384},
+	} {
+		t.Run(string(params.Algorithm), func(t *testing.T) {
+			privateKey, err := params.GenerateKey()
+			require.NoError(t, err)
+			require.True(t, params.Matches(privateKey))
+
+			// the generated key should survive a PEM round trip
+			encoded, err := EncodePEMPrivateKey(privateKey)
+			require.NoError(t, err)
+			parsed, err := ParsePEMPrivateKey(encoded)
+			require.NoError(t, err)
+			require.True(t, PrivateMatchesPublicKey(privateKey.Public(), parsed))
+		})
+	}
+}
+
+func TestKeyParams_Matches(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(cryptorand.Reader, 2048)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), cryptorand.Reader)
+	require.NoError(t, err)
+
+	require.True(t, DefaultKeyParams.Matches(rsaKey))
+	require.False(t, DefaultKeyParams.Matches(ecdsaKey))
+	require.False(t, KeyParams{Algorithm: RSAKeyAlgorithm, Size: 4096}.Matches(rsaKey))
+	require.True(t, KeyParams{Algorithm: ECDSAKeyAlgorithm, Size: 256}.Matches(ecdsaKey))
+	require.False(t, KeyParams{Algorithm: ECDSAKeyAlgorithm, Size: 384}.Matches(ecdsaKey))
+	require.False(t, KeyParams{Algorithm: ECDSAKeyAlgorithm, Size: 256}.Matches(rsaKey))
+}
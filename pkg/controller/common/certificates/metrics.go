@@ -0,0 +1,107 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package certificates
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// CertificateKind describes what a certificate is used for, in the expiration metrics.
+type CertificateKind string
+
+const (
+	// HTTPCACertificateKind is the CA issuing HTTP certificates.
+	HTTPCACertificateKind CertificateKind = "http-ca"
+	// TransportCACertificateKind is the CA issuing Elasticsearch transport certificates.
+	TransportCACertificateKind CertificateKind = "transport-ca"
+	// HTTPCertificateKind is the certificate served on the HTTP layer.
+	HTTPCertificateKind CertificateKind = "http"
+	// TransportCertificateKind is the certificate used on the Elasticsearch transport layer.
+	TransportCertificateKind CertificateKind = "transport"
+)
+
+// certificateKind returns the certificate kind of a CA of this type.
+func (t CAType) certificateKind() CertificateKind {
+	if t == TransportCAType {
+		return TransportCACertificateKind
+	}
+	return HTTPCACertificateKind
+}
+
+// CertificateSource describes where a certificate comes from, in the expiration metrics.
+type CertificateSource string
+
+const (
+	// SelfSignedCertificateSource is a certificate issued by the operator.
+	SelfSignedCertificateSource CertificateSource = "self-signed"
+	// CustomCertificateSource is a certificate provided by the user, which cannot be rotated by the operator.
+	CustomCertificateSource CertificateSource = "custom"
+)
+
+var (
+	allCertificateKinds = []CertificateKind{
+		HTTPCACertificateKind, TransportCACertificateKind, HTTPCertificateKind, TransportCertificateKind,
+	}
+	allCertificateSources = []CertificateSource{SelfSignedCertificateSource, CustomCertificateSource}
+
+	expirationGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "elastic",
+		Subsystem: "certificates",
+		Name:      "expiration_timestamp_seconds",
+		Help:      "Expiration date of the certificates used by the managed resources, as a Unix timestamp",
+	}, []string{"namespace", "owner_kind", "owner_name", "kind", "source"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(expirationGauge)
+}
+
+// ReportExpiration exports the expiration date of a certificate of the given kind and source, used by the given owner.
+func ReportExpiration(
+	ownerKind string,
+	owner types.NamespacedName,
+	kind CertificateKind,
+	source CertificateSource,
+	notAfter time.Time,
+) {
+	expirationGauge.
+		WithLabelValues(owner.Namespace, ownerKind, owner.Name, string(kind), string(source)).
+		Set(float64(notAfter.Unix()))
+	// a certificate cannot come from both sources at the same time
+	for _, s := range allCertificateSources {
+		if s != source {
+			expirationGauge.DeleteLabelValues(owner.Namespace, ownerKind, owner.Name, string(kind), string(s))
+		}
+	}
+}
+
+// ReportCAExpiration exports the expiration date of the given CA, issued by the operator for the given owner.
+func ReportCAExpiration(ownerKind string, owner types.NamespacedName, caType CAType, ca *CA) {
+	ReportExpiration(ownerKind, owner, caType.certificateKind(), SelfSignedCertificateSource, ca.Cert.NotAfter)
+}
+
+// DeleteExpirationMetrics stops exporting the expiration date of the certificates used by the given owner,
+// typically once it has been deleted.
+func DeleteExpirationMetrics(ownerKind string, owner types.NamespacedName) {
+	deleteExpirationMetrics(ownerKind, owner, allCertificateKinds...)
+}
+
+// DeleteHTTPExpirationMetrics stops exporting the expiration date of the HTTP certificate and CA used by the given
+// owner, typically once self-signed HTTP certificates have been disabled.
+func DeleteHTTPExpirationMetrics(ownerKind string, owner types.NamespacedName) {
+	deleteExpirationMetrics(ownerKind, owner, HTTPCACertificateKind, HTTPCertificateKind)
+}
+
+func deleteExpirationMetrics(ownerKind string, owner types.NamespacedName, kinds ...CertificateKind) {
+	for _, kind := range kinds {
+		for _, source := range allCertificateSources {
+			expirationGauge.DeleteLabelValues(owner.Namespace, ownerKind, owner.Name, string(kind), string(source))
+		}
+	}
+}
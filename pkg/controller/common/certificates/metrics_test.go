@@ -0,0 +1,75 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package certificates
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// expirationGaugeValue returns the value of the expiration gauge with the given labels, or nil if it does not exist.
+func expirationGaugeValue(t *testing.T, owner types.NamespacedName, kind CertificateKind, source CertificateSource) *float64 {
+	metricsCh := make(chan prometheus.Metric, 100)
+	go func() {
+		expirationGauge.Collect(metricsCh)
+		close(metricsCh)
+	}()
+	var value *float64
+	for m := range metricsCh {
+		var metric dto.Metric
+		require.NoError(t, m.Write(&metric))
+		labels := map[string]string{}
+		for _, l := range metric.Label {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if labels["namespace"] == owner.Namespace && labels["owner_name"] == owner.Name &&
+			labels["kind"] == string(kind) && labels["source"] == string(source) {
+			v := metric.Gauge.GetValue()
+			value = &v
+		}
+	}
+	return value
+}
+
+func TestReportExpiration(t *testing.T) {
+	owner := types.NamespacedName{Namespace: "ns", Name: "metrics-test"}
+	notAfter := time.Now().Add(24 * time.Hour)
+
+	ReportExpiration("Kibana", owner, HTTPCertificateKind, SelfSignedCertificateSource, notAfter)
+	value := expirationGaugeValue(t, owner, HTTPCertificateKind, SelfSignedCertificateSource)
+	require.NotNil(t, value)
+	require.Equal(t, float64(notAfter.Unix()), *value)
+
+	// switching to a custom certificate should remove the self-signed one
+	ReportExpiration("Kibana", owner, HTTPCertificateKind, CustomCertificateSource, notAfter)
+	require.Nil(t, expirationGaugeValue(t, owner, HTTPCertificateKind, SelfSignedCertificateSource))
+	require.NotNil(t, expirationGaugeValue(t, owner, HTTPCertificateKind, CustomCertificateSource))
+
+	ca, err := NewSelfSignedCA(CABuilderOptions{})
+	require.NoError(t, err)
+	ReportCAExpiration("Kibana", owner, HTTPCAType, ca)
+	value = expirationGaugeValue(t, owner, HTTPCACertificateKind, SelfSignedCertificateSource)
+	require.NotNil(t, value)
+	require.Equal(t, float64(ca.Cert.NotAfter.Unix()), *value)
+
+	// disabling self-signed HTTP certificates should only remove the HTTP metrics
+	ReportCAExpiration("Kibana", owner, TransportCAType, ca)
+	DeleteHTTPExpirationMetrics("Kibana", owner)
+	require.Nil(t, expirationGaugeValue(t, owner, HTTPCertificateKind, CustomCertificateSource))
+	require.Nil(t, expirationGaugeValue(t, owner, HTTPCACertificateKind, SelfSignedCertificateSource))
+	require.NotNil(t, expirationGaugeValue(t, owner, TransportCACertificateKind, SelfSignedCertificateSource))
+
+	// all metrics should be removed with the owner
+	ReportCAExpiration("Kibana", owner, HTTPCAType, ca)
+	DeleteExpirationMetrics("Kibana", owner)
+	require.Nil(t, expirationGaugeValue(t, owner, TransportCACertificateKind, SelfSignedCertificateSource))
+	require.Nil(t, expirationGaugeValue(t, owner, HTTPCertificateKind, CustomCertificateSource))
+	require.Nil(t, expirationGaugeValue(t, owner, HTTPCACertificateKind, SelfSignedCertificateSource))
+}
@@ -0,0 +1,88 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package certificates
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	netutil "github.com/elastic/cloud-on-k8s/pkg/utils/net"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// servedCertificateTimeout is the maximum duration to retrieve the certificate served by a pod
+const servedCertificateTimeout = 5 * time.Second
+
+// PodsServeCertificatesIssuedBy returns true if all the given pods serve, on the given port, a TLS certificate
+// issued by the given CA cert.
+// Pods without an IP are ignored, since they will load the current certificates once started.
+// Pods that cannot be reached are considered as not serving the expected certificate yet.
+func PodsServeCertificatesIssuedBy(dialer netutil.Dialer, pods []corev1.Pod, port int, caCert *x509.Certificate) bool {
+	for _, pod := range pods {
+		if pod.Status.PodIP == "" {
+			continue
+		}
+		addr := net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(port))
+		served, err := servedCertificate(dialer, addr)
+		if err != nil {
+			log.V(1).Info("Cannot retrieve the certificate served by pod", "namespace", pod.Namespace, "pod_name", pod.Name, "error", err)
+			return false
+		}
+		if err := served.CheckSignatureFrom(caCert); err != nil {
+			log.V(1).Info("Pod does not serve a certificate issued by the CA yet", "namespace", pod.Namespace, "pod_name", pod.Name, "ca_subject", caCert.Subject)
+			return false
+		}
+	}
+	return true
+}
+
+// servedCertificate returns the leaf certificate presented during a TLS handshake with the given address.
+func servedCertificate(dialer netutil.Dialer, addr string) (*x509.Certificate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), servedCertificateTimeout)
+	defer cancel()
+
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	var served *x509.Certificate
+	tlsConn := tls.Client(conn, &tls.Config{
+		// the served certificate is checked against the expected CA by the caller
+		InsecureSkipVerify: true, // nolint:gosec
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("no certificate served")
+			}
+			cert, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return err
+			}
+			served = cert
+			return nil
+		},
+	})
+	// the handshake may fail once the certificate is received, for example when a client certificate
+	// is required (Elasticsearch transport layer): we only care about the served certificate here
+	if err := tlsConn.Handshake(); err != nil && served == nil {
+		return nil, err
+	}
+	if served == nil {
+		return nil, errors.New("no certificate served")
+	}
+	return served, nil
+}
@@ -0,0 +1,67 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package certificates
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPodsServeCertificatesIssuedBy(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	otherCa, err := NewSelfSignedCA(CABuilderOptions{})
+	require.NoError(t, err)
+
+	podWithIP := corev1.Pod{Status: corev1.PodStatus{PodIP: host}}
+	podWithoutIP := corev1.Pod{}
+
+	tests := []struct {
+		name string
+		pods []corev1.Pod
+		port int
+		want bool
+	}{
+		{
+			name: "no pods",
+			want: true,
+		},
+		{
+			name: "pods without IP are ignored",
+			pods: []corev1.Pod{podWithoutIP},
+			port: port,
+			want: true,
+		},
+		{
+			name: "pod serving a certificate issued by the CA",
+			pods: []corev1.Pod{podWithIP, podWithoutIP},
+			port: port,
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the test server certificate is self-signed
+			got := PodsServeCertificatesIssuedBy(nil, tt.pods, tt.port, server.Certificate())
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("pod serving a certificate issued by another CA", func(t *testing.T) {
+		require.False(t, PodsServeCertificatesIssuedBy(nil, []corev1.Pod{podWithIP}, port, otherCa.Cert))
+	})
+}
@@ -0,0 +1,19 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package common
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DryRunAnnotationName annotation
+	DryRunAnnotationName = "common.k8s.elastic.co/dry-run"
+)
+
+// IsDryRun computes if changes to a given resource should only be planned and reported, not applied.
+func IsDryRun(meta metav1.ObjectMeta) bool {
+	return getBoolFromAnnotation(meta.Annotations, DryRunAnnotationName)
+}
@@ -0,0 +1,31 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsDryRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{name: "no annotations", annotations: nil, want: false},
+		{name: "dry-run enabled", annotations: map[string]string{DryRunAnnotationName: "true"}, want: true},
+		{name: "dry-run disabled", annotations: map[string]string{DryRunAnnotationName: "false"}, want: false},
+		{name: "invalid value", annotations: map[string]string{DryRunAnnotationName: "XXXX"}, want: false},
+		{name: "pause annotation only", annotations: map[string]string{PauseAnnotationName: "true"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, IsDryRun(metav1.ObjectMeta{Annotations: tt.annotations}))
+		})
+	}
+}
@@ -22,6 +22,10 @@ const (
 	EventReasonStateChange = "StateChange"
 	// EventReasonRestart describes events where one or multiple Elasticsearch nodes are scheduled for a restart.
 	EventReasonRestart = "Restart"
+	// EventReasonCertificateExpiration describes events where a certificate that cannot be rotated by the operator is about to expire.
+	EventReasonCertificateExpiration = "CertificateExpiration"
+	// EventReasonPlan describes events reporting the changes planned for a resource in dry-run mode.
+	EventReasonPlan = "Plan"
 )
 
 // Event reasons for Association controllers
@@ -30,6 +34,8 @@ const (
 	EventAssociationError = "AssociationError"
 	// EventAssociationStatusChange describes association status change events.
 	EventAssociationStatusChange = "AssociationStatusChange"
+	// EventAssociationForbidden describes an event fired when the referenced resource does not allow an association.
+	EventAssociationForbidden = "AssociationForbidden"
 )
 
 // Event reasons for common error conditions
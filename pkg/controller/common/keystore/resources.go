@@ -10,9 +10,11 @@ import (
 	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/driver"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/name"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 )
 
@@ -75,3 +77,37 @@ func NewResources(
 		Version:       version,
 	}, nil
 }
+
+// PlanResources returns the resources NewResources would return, without applying any change:
+// the aggregated secure settings secret is neither created nor updated, and no watch is set up.
+// The version of the returned resources is empty if the aggregated secret would be created or updated.
+func PlanResources(
+	c k8s.Client,
+	recorder record.EventRecorder,
+	hasKeystore HasKeystore,
+	namer name.Namer,
+	initContainerParams InitContainerParameters,
+) (*Resources, error) {
+	secretVolume, version, err := plannedSecureSettingsVolume(c, recorder, hasKeystore, namer)
+	if err != nil {
+		return nil, err
+	}
+	if secretVolume == nil {
+		return nil, nil
+	}
+
+	initContainer, err := initContainer(
+		*secretVolume,
+		strings.ToLower(hasKeystore.Kind()),
+		initContainerParams,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Resources{
+		Volume:        secretVolume.Volume(),
+		InitContainer: initContainer,
+		Version:       version,
+	}, nil
+}
@@ -5,6 +5,7 @@
 package keystore
 
 import (
+	"reflect"
 	"testing"
 
 	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
@@ -163,3 +164,72 @@ echo "Keystore initialization successful."
 		})
 	}
 }
+
+func TestPlanResources(t *testing.T) {
+	sc := scheme.Scheme
+	require.NoError(t, v1alpha1.SchemeBuilder.AddToScheme(sc))
+
+	upToDateSecret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "namespace",
+			Name:            "kibana-kb-secure-settings",
+			ResourceVersion: "42",
+		},
+		Data: testSecureSettingsSecret.Data,
+	}
+	outdatedSecret := *upToDateSecret.DeepCopy()
+	outdatedSecret.Data = map[string][]byte{"key1": []byte("outdated")}
+
+	tests := []struct {
+		name        string
+		client      k8s.Client
+		kb          v1alpha1.Kibana
+		wantNil     bool
+		wantVersion string
+	}{
+		{
+			name:    "no secure settings specified: no resources",
+			client:  k8s.WrapClient(fake.NewFakeClient()),
+			kb:      testKibana,
+			wantNil: true,
+		},
+		{
+			name:        "aggregated secret does not exist yet: empty version",
+			client:      k8s.WrapClient(fake.NewFakeClient(&testSecureSettingsSecret)),
+			kb:          testKibanaWithSecureSettings,
+			wantVersion: "",
+		},
+		{
+			name:        "aggregated secret is outdated: empty version",
+			client:      k8s.WrapClient(fake.NewFakeClient(&testSecureSettingsSecret, &outdatedSecret)),
+			kb:          testKibanaWithSecureSettings,
+			wantVersion: "",
+		},
+		{
+			name:        "aggregated secret is up-to-date: existing version",
+			client:      k8s.WrapClient(fake.NewFakeClient(&testSecureSettingsSecret, &upToDateSecret)),
+			kb:          testKibanaWithSecureSettings,
+			wantVersion: "42",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources, err := PlanResources(tt.client, record.NewFakeRecorder(1000), &tt.kb, name.KBNamer, initContainersParameters)
+			require.NoError(t, err)
+			if tt.wantNil {
+				require.Nil(t, resources)
+				return
+			}
+			require.NotNil(t, resources)
+			require.Equal(t, tt.wantVersion, resources.Version)
+			// the aggregated secret should not have been created nor updated
+			var aggregated corev1.Secret
+			err = tt.client.Get(k8s.ExtractNamespacedName(&upToDateSecret), &aggregated)
+			if tt.wantVersion == "" {
+				require.True(t, err != nil || !reflect.DeepEqual(upToDateSecret.Data, aggregated.Data))
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -74,20 +74,61 @@ func secureSettingsVolume(
 	return &secureSettingsVolume, resourceVersion, nil
 }
 
-func reconcileSecureSettings(
+// plannedSecureSettingsVolume returns the volume secureSettingsVolume would create, without applying any change.
+// The returned version is the one of the existing aggregated secret if its content is up-to-date, empty otherwise,
+// since any change in the aggregated secret leads to a new resource version.
+func plannedSecureSettingsVolume(
 	c k8s.Client,
-	scheme *runtime.Scheme,
+	recorder record.EventRecorder,
 	hasKeystore HasKeystore,
-	userSecrets []corev1.Secret,
 	namer name.Namer,
-	labels map[string]string) (*corev1.Secret, error) {
-	aggregatedData := map[string][]byte{}
+) (*volume.SecretVolume, string, error) {
+	secrets, err := retrieveUserSecrets(c, recorder, hasKeystore)
+	if err != nil {
+		return nil, "", err
+	}
+	aggregatedData := aggregateSecretsData(secrets)
+	if len(aggregatedData) == 0 {
+		return nil, "", nil
+	}
+
+	secretName := secureSettingsSecretName(namer, hasKeystore)
+	secureSettingsVolume := volume.NewSecretVolumeWithMountPath(
+		secretName,
+		SecureSettingsVolumeName,
+		SecureSettingsVolumeMountPath,
+	)
+
+	var existing corev1.Secret
+	err = c.Get(types.NamespacedName{Namespace: hasKeystore.GetNamespace(), Name: secretName}, &existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, "", err
+	}
+	if err != nil || !reflect.DeepEqual(aggregatedData, existing.Data) {
+		return &secureSettingsVolume, "", nil
+	}
+	return &secureSettingsVolume, existing.GetResourceVersion(), nil
+}
 
-	for _, s := range userSecrets {
+// aggregateSecretsData merges the data of the given secrets into a single map.
+func aggregateSecretsData(secrets []corev1.Secret) map[string][]byte {
+	aggregatedData := map[string][]byte{}
+	for _, s := range secrets {
 		for k, v := range s.Data {
 			aggregatedData[k] = v
 		}
 	}
+	return aggregatedData
+}
+
+func reconcileSecureSettings(
+	c k8s.Client,
+	scheme *runtime.Scheme,
+	hasKeystore HasKeystore,
+	userSecrets []corev1.Secret,
+	namer name.Namer,
+	labels map[string]string) (*corev1.Secret, error) {
+	aggregatedData := aggregateSecretsData(userSecrets)
 
 	// reconcile our managed secret with the user-provided secret content
 	expected := corev1.Secret{
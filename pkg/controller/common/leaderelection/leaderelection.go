@@ -0,0 +1,195 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package leaderelection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+const (
+	// DefaultLeaseDuration is the duration non-leader candidates wait before trying to acquire the leadership.
+	DefaultLeaseDuration = 15 * time.Second
+	// DefaultRenewDeadline is the duration during which the leader retries refreshing the leadership before giving up.
+	DefaultRenewDeadline = 10 * time.Second
+	// DefaultRetryPeriod is the duration candidates wait between tries of actions.
+	DefaultRetryPeriod = 2 * time.Second
+
+	lockNamePrefix = "elastic-operator-leader"
+)
+
+var log = logf.Log.WithName("leader-election")
+
+// Params defines how operator replicas elect a leader.
+type Params struct {
+	// Namespace in which the lock resource is maintained.
+	Namespace string
+	// LockName is the name of the lock resource, shared by all the replicas of the same operator deployment.
+	LockName string
+	// LeaseDuration is the duration non-leader candidates wait before trying to acquire the leadership.
+	LeaseDuration time.Duration
+	// RenewDeadline is the duration during which the leader retries refreshing the leadership before giving up.
+	RenewDeadline time.Duration
+	// RetryPeriod is the duration candidates wait between tries of actions.
+	RetryPeriod time.Duration
+}
+
+// Validate returns an error if the params cannot be used to elect a leader.
+func (p Params) Validate() error {
+	if p.Namespace == "" {
+		return errors.New("a namespace is required for leader election")
+	}
+	if p.LeaseDuration <= p.RenewDeadline {
+		return fmt.Errorf("lease duration (%s) must be greater than renew deadline (%s)", p.LeaseDuration, p.RenewDeadline)
+	}
+	if p.RenewDeadline <= time.Duration(leaderelection.JitterFactor*float64(p.RetryPeriod)) {
+		return fmt.Errorf("renew deadline (%s) must be greater than %.1f times the retry period (%s)",
+			p.RenewDeadline, leaderelection.JitterFactor, p.RetryPeriod)
+	}
+	return nil
+}
+
+// LockName returns the name of the lock resource for an operator deployment with the given roles,
+// managing resources in the given namespaces (all namespaces if empty).
+// Operators deployed in the same namespace with different roles or managed namespaces do not compete for the same lock.
+func LockName(roles []string, managedNamespaces []string) string {
+	parts := append([]string{lockNamePrefix}, sorted(roles)...)
+	switch len(managedNamespaces) {
+	case 0:
+	case 1:
+		parts = append(parts, managedNamespaces[0])
+	default:
+		// a list of namespaces may not fit in a resource name, use a hash of it instead
+		hash := fnv.New32a()
+		_, _ = hash.Write([]byte(strings.Join(sorted(managedNamespaces), ",")))
+		parts = append(parts, fmt.Sprintf("%x", hash.Sum32()))
+	}
+	return strings.Join(parts, "-")
+}
+
+// sorted returns a sorted copy of the given strings.
+func sorted(s []string) []string {
+	c := make([]string, len(s))
+	copy(c, s)
+	sort.Strings(c)
+	return c
+}
+
+// Runnable is a manager Runnable competing with the other operator replicas for the leadership.
+// The given function is invoked once elected leader, it is expected to start the leader-only components
+// such as controllers. If the leadership is lost, Start returns an error so that the operator restarts as a follower.
+type Runnable struct {
+	clientset        kubernetes.Interface
+	recorder         record.EventRecorder
+	params           Params
+	onStartedLeading func() error
+}
+
+// NewRunnable returns a Runnable running the given function once elected leader.
+func NewRunnable(
+	clientset kubernetes.Interface,
+	recorder record.EventRecorder,
+	params Params,
+	onStartedLeading func() error,
+) *Runnable {
+	return &Runnable{
+		clientset:        clientset,
+		recorder:         recorder,
+		params:           params,
+		onStartedLeading: onStartedLeading,
+	}
+}
+
+// Start competes for the leadership until the given channel is closed or the leadership is lost.
+func (r *Runnable) Start(stop <-chan struct{}) error {
+	identity, err := identity()
+	if err != nil {
+		return err
+	}
+	lock, err := resourcelock.New(
+		resourcelock.ConfigMapsResourceLock,
+		r.params.Namespace,
+		r.params.LockName,
+		r.clientset.CoreV1(),
+		resourcelock.ResourceLockConfig{
+			Identity:      identity,
+			EventRecorder: r.recorder,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	fail := func(err error) {
+		select {
+		case errCh <- err:
+		default:
+			// an error is already reported
+		}
+	}
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: r.params.LeaseDuration,
+		RenewDeadline: r.params.RenewDeadline,
+		RetryPeriod:   r.params.RetryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(_ context.Context) {
+				log.Info("Elected leader", "namespace", r.params.Namespace, "lock_name", r.params.LockName, "identity", identity)
+				if err := r.onStartedLeading(); err != nil {
+					fail(err)
+				}
+			},
+			OnStoppedLeading: func() {
+				if ctx.Err() != nil {
+					// stopped on purpose
+					return
+				}
+				fail(errors.New("leader election lost"))
+			},
+			OnNewLeader: func(leader string) {
+				log.Info("New leader elected", "namespace", r.params.Namespace, "lock_name", r.params.LockName, "identity", leader)
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("Competing for the leadership", "namespace", r.params.Namespace, "lock_name", r.params.LockName, "identity", identity)
+	go elector.Run(ctx)
+
+	select {
+	case <-stop:
+		return nil
+	case err := <-errCh:
+		return err
+	}
+}
+
+// identity returns a unique identity for this operator replica.
+func identity() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+	return hostname + "_" + string(uuid.NewUUID()), nil
+}
@@ -0,0 +1,113 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package leaderelection
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestLockName(t *testing.T) {
+	require.Equal(t, "elastic-operator-leader-all", LockName([]string{"all"}, nil))
+	require.Equal(t, "elastic-operator-leader-global-namespace", LockName([]string{"namespace", "global"}, nil))
+	require.Equal(t, "elastic-operator-leader-namespace-ns1", LockName([]string{"namespace"}, []string{"ns1"}))
+	// the order of the namespaces does not matter, but the list does
+	require.Equal(t,
+		LockName([]string{"namespace"}, []string{"ns1", "ns2"}),
+		LockName([]string{"namespace"}, []string{"ns2", "ns1"}),
+	)
+	require.NotEqual(t,
+		LockName([]string{"namespace"}, []string{"ns1", "ns2"}),
+		LockName([]string{"namespace"}, []string{"ns1", "ns3"}),
+	)
+}
+
+func TestParams_Validate(t *testing.T) {
+	valid := Params{
+		Namespace:     "ns",
+		LockName:      "lock",
+		LeaseDuration: DefaultLeaseDuration,
+		RenewDeadline: DefaultRenewDeadline,
+		RetryPeriod:   DefaultRetryPeriod,
+	}
+	require.NoError(t, valid.Validate())
+
+	noNamespace := valid
+	noNamespace.Namespace = ""
+	require.Error(t, noNamespace.Validate())
+
+	shortLease := valid
+	shortLease.LeaseDuration = DefaultRenewDeadline
+	require.Error(t, shortLease.Validate())
+
+	shortRenewDeadline := valid
+	shortRenewDeadline.RenewDeadline = DefaultRetryPeriod
+	require.Error(t, shortRenewDeadline.Validate())
+}
+
+func testParams() Params {
+	return Params{
+		Namespace:     "ns",
+		LockName:      "lock",
+		LeaseDuration: 3 * time.Second,
+		RenewDeadline: 2 * time.Second,
+		RetryPeriod:   500 * time.Millisecond,
+	}
+}
+
+func TestRunnable_Start(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	elected := make(chan struct{})
+	r := NewRunnable(clientset, record.NewFakeRecorder(10), testParams(), func() error {
+		close(elected)
+		return nil
+	})
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- r.Start(stop)
+	}()
+
+	select {
+	case <-elected:
+	case <-time.After(10 * time.Second):
+		t.Fatal("not elected leader")
+	}
+	// the lock should be maintained in the given namespace
+	_, err := clientset.CoreV1().ConfigMaps("ns").Get("lock", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	close(stop)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("not stopped")
+	}
+}
+
+func TestRunnable_Start_Error(t *testing.T) {
+	r := NewRunnable(fake.NewSimpleClientset(), record.NewFakeRecorder(10), testParams(), func() error {
+		return errors.New("cannot start controllers")
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- r.Start(make(chan struct{}))
+	}()
+	select {
+	case err := <-done:
+		require.EqualError(t, err, "cannot start controllers")
+	case <-time.After(10 * time.Second):
+		t.Fatal("error not returned")
+	}
+}
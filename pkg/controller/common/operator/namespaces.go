@@ -0,0 +1,30 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package operator
+
+import (
+	"github.com/elastic/cloud-on-k8s/pkg/utils/stringsutil"
+)
+
+// WatchedNamespaces returns the namespaces in which the operator watches resources, given the namespaces it manages
+// resources in (all namespaces if empty) and its roles. An empty result stands for all namespaces.
+// The operator namespace is watched along with the managed namespaces for the global role, since the licensing
+// controllers work with the enterprise licenses and the trial status stored there.
+func WatchedNamespaces(managedNamespaces []string, operatorNamespace string, roles []string) []string {
+	if len(managedNamespaces) == 0 {
+		return nil
+	}
+	var watched []string
+	for _, ns := range managedNamespaces {
+		if !stringsutil.StringInSlice(ns, watched) {
+			watched = append(watched, ns)
+		}
+	}
+	global := stringsutil.StringInSlice(All, roles) || stringsutil.StringInSlice(GlobalOperator, roles)
+	if global && !stringsutil.StringInSlice(operatorNamespace, watched) {
+		watched = append(watched, operatorNamespace)
+	}
+	return watched
+}
@@ -0,0 +1,62 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchedNamespaces(t *testing.T) {
+	tests := []struct {
+		name              string
+		managedNamespaces []string
+		roles             []string
+		want              []string
+	}{
+		{
+			name:              "all namespaces",
+			managedNamespaces: nil,
+			roles:             []string{All},
+			want:              nil,
+		},
+		{
+			name:              "single namespace operator",
+			managedNamespaces: []string{"ns1"},
+			roles:             []string{NamespaceOperator},
+			want:              []string{"ns1"},
+		},
+		{
+			name:              "several namespaces, deduplicated",
+			managedNamespaces: []string{"ns1", "ns2", "ns1"},
+			roles:             []string{NamespaceOperator, WebhookServer},
+			want:              []string{"ns1", "ns2"},
+		},
+		{
+			name:              "global role watches the operator namespace",
+			managedNamespaces: []string{"ns1", "ns2"},
+			roles:             []string{GlobalOperator},
+			want:              []string{"ns1", "ns2", "elastic-system"},
+		},
+		{
+			name:              "all roles watch the operator namespace",
+			managedNamespaces: []string{"ns1"},
+			roles:             []string{All},
+			want:              []string{"ns1", "elastic-system"},
+		},
+		{
+			name:              "operator namespace already managed",
+			managedNamespaces: []string{"elastic-system", "ns1"},
+			roles:             []string{All},
+			want:              []string{"elastic-system", "ns1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, WatchedNamespaces(tt.managedNamespaces, "elastic-system", tt.roles))
+		})
+	}
+}
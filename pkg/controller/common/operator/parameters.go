@@ -22,4 +22,6 @@ type Parameters struct {
 	CACertRotation certificates.RotationParams
 	// CertRotation defines the rotation params for non-CA certificates.
 	CertRotation certificates.RotationParams
+	// CertKeyParams defines how private keys are generated for CA and non-CA certificates.
+	CertKeyParams certificates.KeyParams
 }
@@ -25,16 +25,16 @@ var (
 
 // IsPaused computes if a given controller is paused.
 func IsPaused(meta metav1.ObjectMeta) bool {
-	return getBoolFromAnnotation(meta.Annotations)
+	return getBoolFromAnnotation(meta.Annotations, PauseAnnotationName)
 }
 
 // Extract the desired state from the map that contains annotations.
-func getBoolFromAnnotation(annotations map[string]string) bool {
+func getBoolFromAnnotation(annotations map[string]string, annotationName string) bool {
 	if annotations == nil {
 		return false
 	}
 
-	stateAsString, exists := annotations[PauseAnnotationName]
+	stateAsString, exists := annotations[annotationName]
 
 	if !exists {
 		return false
@@ -42,7 +42,7 @@ func getBoolFromAnnotation(annotations map[string]string) bool {
 
 	expectedState, err := strconv.ParseBool(stateAsString)
 	if err != nil {
-		log.Error(err, "Cannot parse %s as a bool, defaulting to %s: \"false\"", annotations[PauseAnnotationName], PauseAnnotationName)
+		log.Error(err, "Cannot parse %s as a bool, defaulting to %s: \"false\"", annotations[annotationName], annotationName)
 		return false
 	}
 
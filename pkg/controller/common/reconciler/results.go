@@ -5,6 +5,8 @@
 package reconciler
 
 import (
+	"context"
+
 	k8serrors "k8s.io/apimachinery/pkg/util/errors"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -13,6 +15,13 @@ import (
 type Results struct {
 	results []reconcile.Result
 	errors  []error
+	// ctx is the context of the reconciliation, used to record and trace the applied steps.
+	ctx context.Context
+}
+
+// NewResults returns empty Results for the reconciliation of the given context.
+func NewResults(ctx context.Context) *Results {
+	return &Results{ctx: ctx}
 }
 
 // HasError returns true if Results contains one or more errors.
@@ -46,7 +55,9 @@ func (r *Results) WithResult(res reconcile.Result) *Results {
 // Apply applies the output of a reconciliation step to the results. The step outcome is implicitly considered
 // recoverable as we just record the results and continue.
 func (r *Results) Apply(step string, recoverableStep func() (reconcile.Result, error)) *Results {
+	_, s := StartStep(r.ctx, step)
 	result, err := recoverableStep()
+	s.End(result, err)
 	if err != nil {
 		log.Info("Recoverable error during step, continuing", "step", step, "error", err)
 	}
@@ -0,0 +1,111 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package reconciler
+
+import (
+	"context"
+	"time"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// unknownController is the controller reported for steps run outside of a reconciliation context.
+const unknownController = "unknown"
+
+var (
+	stepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "elastic",
+		Subsystem: "reconcile",
+		Name:      "step_duration_seconds",
+		Help:      "Duration of the reconciliation steps, per controller and step",
+		Buckets:   []float64{0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"controller", "step"})
+
+	stepErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "elastic",
+		Subsystem: "reconcile",
+		Name:      "step_errors_total",
+		Help:      "Number of reconciliation steps that returned an error, per controller and step",
+	}, []string{"controller", "step"})
+
+	stepRequeues = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "elastic",
+		Subsystem: "reconcile",
+		Name:      "step_requeues_total",
+		Help:      "Number of reconciliation steps that requested a requeue, per controller and step",
+	}, []string{"controller", "step"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(stepDuration, stepErrors, stepRequeues)
+}
+
+type controllerKey struct{}
+
+// StartReconciliation returns the context of a reconciliation of the given request by the given controller.
+// The context carries a root tracing span for the reconciliation, to be finished once done.
+func StartReconciliation(controller string, request reconcile.Request) (context.Context, *tracing.Span) {
+	ctx := context.WithValue(context.Background(), controllerKey{}, controller)
+	ctx, span := tracing.StartSpan(ctx, "reconcile")
+	span.SetAttribute("controller", controller)
+	span.SetAttribute("namespace", request.Namespace)
+	span.SetAttribute("name", request.Name)
+	return ctx, span
+}
+
+// controllerFromContext returns the controller running the reconciliation of the given context.
+func controllerFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return unknownController
+	}
+	if controller, ok := ctx.Value(controllerKey{}).(string); ok {
+		return controller
+	}
+	return unknownController
+}
+
+// Step is a named step of a reconciliation, whose outcome is recorded in the step metrics and traced.
+type Step struct {
+	controller string
+	name       string
+	start      time.Time
+	span       *tracing.Span
+}
+
+// StartStep starts the given step of the reconciliation of the given context. The returned context carries
+// the step span, so that nested steps are traced as its children.
+func StartStep(ctx context.Context, name string) (context.Context, *Step) {
+	ctx, span := tracing.StartSpan(ctx, name)
+	controller := controllerFromContext(ctx)
+	span.SetAttribute("controller", controller)
+	return ctx, &Step{
+		controller: controller,
+		name:       name,
+		start:      time.Now(),
+		span:       span,
+	}
+}
+
+// End records the outcome of the step.
+func (s *Step) End(result reconcile.Result, err error) {
+	stepDuration.WithLabelValues(s.controller, s.name).Observe(time.Since(s.start).Seconds())
+	if err != nil {
+		stepErrors.WithLabelValues(s.controller, s.name).Inc()
+	}
+	if result.Requeue || result.RequeueAfter > 0 {
+		stepRequeues.WithLabelValues(s.controller, s.name).Inc()
+		s.span.SetAttribute("requeue", "true")
+	}
+	s.span.SetError(err)
+	s.span.Finish()
+}
+
+// EndWithResults records the aggregated outcome of the step.
+func (s *Step) EndWithResults(results *Results) {
+	s.End(results.Aggregate())
+}
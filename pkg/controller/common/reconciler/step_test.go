@@ -0,0 +1,77 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package reconciler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// stepMetric returns the metric of the given collector for the given controller and step, or nil if it does not exist.
+func stepMetric(t *testing.T, collector prometheus.Collector, controller string, step string) *dto.Metric {
+	metricsCh := make(chan prometheus.Metric, 100)
+	go func() {
+		collector.Collect(metricsCh)
+		close(metricsCh)
+	}()
+	var found *dto.Metric
+	for m := range metricsCh {
+		var metric dto.Metric
+		require.NoError(t, m.Write(&metric))
+		labels := map[string]string{}
+		for _, l := range metric.Label {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if labels["controller"] == controller && labels["step"] == step {
+			found = &metric
+		}
+	}
+	return found
+}
+
+func TestStartStep(t *testing.T) {
+	ctx, span := StartReconciliation("test-controller", reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: "ns", Name: "name"},
+	})
+	defer span.Finish()
+
+	_, step := StartStep(ctx, "successful-step")
+	step.End(reconcile.Result{}, nil)
+	require.Equal(t, uint64(1), stepMetric(t, stepDuration, "test-controller", "successful-step").Histogram.GetSampleCount())
+	require.Nil(t, stepMetric(t, stepErrors, "test-controller", "successful-step"))
+	require.Nil(t, stepMetric(t, stepRequeues, "test-controller", "successful-step"))
+
+	_, step = StartStep(ctx, "failing-step")
+	step.EndWithResults(NewResults(ctx).WithError(errors.New("failed")).WithResult(reconcile.Result{RequeueAfter: time.Second}))
+	require.Equal(t, uint64(1), stepMetric(t, stepDuration, "test-controller", "failing-step").Histogram.GetSampleCount())
+	require.Equal(t, float64(1), stepMetric(t, stepErrors, "test-controller", "failing-step").Counter.GetValue())
+	require.Equal(t, float64(1), stepMetric(t, stepRequeues, "test-controller", "failing-step").Counter.GetValue())
+}
+
+func TestResults_Apply(t *testing.T) {
+	ctx, span := StartReconciliation("test-controller", reconcile.Request{})
+	defer span.Finish()
+
+	results := NewResults(ctx)
+	results.Apply("applied-step", func() (reconcile.Result, error) {
+		return reconcile.Result{Requeue: true}, errors.New("failed")
+	})
+	require.True(t, results.HasError())
+	require.Equal(t, float64(1), stepMetric(t, stepErrors, "test-controller", "applied-step").Counter.GetValue())
+	require.Equal(t, float64(1), stepMetric(t, stepRequeues, "test-controller", "applied-step").Counter.GetValue())
+
+	// results created without a context are recorded for an unknown controller
+	(&Results{}).Apply("applied-step", func() (reconcile.Result, error) {
+		return reconcile.Result{}, errors.New("failed")
+	})
+	require.Equal(t, float64(1), stepMetric(t, stepErrors, unknownController, "applied-step").Counter.GetValue())
+}
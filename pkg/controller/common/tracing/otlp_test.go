@@ -0,0 +1,64 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package tracing
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPExporter_Export(t *testing.T) {
+	var received otlpRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/traces", r.URL.Path)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	start := time.Unix(1, 0)
+	span := &Span{
+		TraceID:    TraceID{1},
+		SpanID:     SpanID{2},
+		ParentID:   SpanID{3},
+		Name:       "upscale",
+		Start:      start,
+		End:        start.Add(time.Second),
+		Attributes: map[string]string{"controller": "elasticsearch-controller"},
+		Err:        errors.New("failed"),
+	}
+	require.NoError(t, NewOTLPExporter(server.URL, "elastic-operator").Export([]*Span{span}))
+
+	require.Len(t, received.ResourceSpans, 1)
+	require.Equal(t, []otlpAttribute{stringAttribute("service.name", "elastic-operator")},
+		received.ResourceSpans[0].Resource.Attributes)
+	require.Len(t, received.ResourceSpans[0].ScopeSpans, 1)
+	require.Equal(t, []otlpSpan{{
+		TraceID:           "01000000000000000000000000000000",
+		SpanID:            "0200000000000000",
+		ParentSpanID:      "0300000000000000",
+		Name:              "upscale",
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: "1000000000",
+		EndTimeUnixNano:   "2000000000",
+		Attributes:        []otlpAttribute{stringAttribute("controller", "elasticsearch-controller")},
+		Status:            otlpStatus{Code: statusCodeError, Message: "failed"},
+	}}, received.ResourceSpans[0].ScopeSpans[0].Spans)
+}
+
+func TestOTLPExporter_ExportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	require.Error(t, NewOTLPExporter(server.URL+"/v1/traces", "elastic-operator").Export([]*Span{{}}))
+}
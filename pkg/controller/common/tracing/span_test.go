@@ -0,0 +1,74 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeExporter struct {
+	spans chan *Span
+}
+
+func (e *fakeExporter) Export(spans []*Span) error {
+	for _, s := range spans {
+		e.spans <- s
+	}
+	return nil
+}
+
+func TestStartSpan_Disabled(t *testing.T) {
+	SetTracer(nil)
+	ctx, span := StartSpan(context.Background(), "reconcile")
+	require.Nil(t, span)
+	require.Nil(t, SpanFromContext(ctx))
+	// a nil span can be used safely
+	span.SetAttribute("key", "value")
+	span.SetError(errors.New("error"))
+	span.Finish()
+}
+
+func TestStartSpan(t *testing.T) {
+	exporter := &fakeExporter{spans: make(chan *Span, 10)}
+	tracer := NewTracer(exporter)
+	SetTracer(tracer)
+	defer SetTracer(nil)
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- tracer.Start(stop)
+	}()
+
+	ctx, parent := StartSpan(context.Background(), "reconcile")
+	require.Equal(t, parent, SpanFromContext(ctx))
+	_, child := StartSpan(ctx, "certificates")
+	child.SetAttribute("namespace", "ns")
+	child.SetError(errors.New("failed"))
+	child.Finish()
+	// finishing twice is a no-op
+	child.Finish()
+	parent.Finish()
+	close(stop)
+	require.NoError(t, <-done)
+
+	exportedChild := <-exporter.spans
+	exportedParent := <-exporter.spans
+	require.Len(t, exporter.spans, 0)
+
+	require.Equal(t, "certificates", exportedChild.Name)
+	require.Equal(t, exportedParent.TraceID, exportedChild.TraceID)
+	require.Equal(t, exportedParent.SpanID, exportedChild.ParentID)
+	require.Equal(t, map[string]string{"namespace": "ns"}, exportedChild.Attributes)
+	require.EqualError(t, exportedChild.Err, "failed")
+	require.False(t, exportedChild.End.Before(exportedChild.Start))
+
+	require.Equal(t, "reconcile", exportedParent.Name)
+	require.True(t, exportedParent.ParentID.IsZero())
+	require.NoError(t, exportedParent.Err)
+}
@@ -0,0 +1,116 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package tracing
+
+import (
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+const (
+	// DefaultBatchSize is the maximum number of spans exported at once.
+	DefaultBatchSize = 512
+	// DefaultFlushInterval is the maximum duration finished spans are buffered before being exported.
+	DefaultFlushInterval = 5 * time.Second
+	// defaultQueueSize is the number of finished spans buffered before new ones get dropped.
+	defaultQueueSize = 4096
+)
+
+var (
+	log = logf.Log.WithName("tracing")
+
+	currentTracer *Tracer
+	tracerMutex   sync.RWMutex
+)
+
+// Exporter sends finished spans to a tracing backend.
+type Exporter interface {
+	Export(spans []*Span) error
+}
+
+// Tracer buffers finished spans and exports them in batches.
+// It is a manager Runnable: spans are only exported once started.
+type Tracer struct {
+	exporter      Exporter
+	batchSize     int
+	flushInterval time.Duration
+	queue         chan *Span
+}
+
+// NewTracer returns a Tracer exporting finished spans through the given exporter.
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{
+		exporter:      exporter,
+		batchSize:     DefaultBatchSize,
+		flushInterval: DefaultFlushInterval,
+		queue:         make(chan *Span, defaultQueueSize),
+	}
+}
+
+// SetTracer sets the tracer used to create spans. Tracing is disabled if nil.
+func SetTracer(tracer *Tracer) {
+	tracerMutex.Lock()
+	defer tracerMutex.Unlock()
+	currentTracer = tracer
+}
+
+// CurrentTracer returns the tracer used to create spans, nil if tracing is disabled.
+func CurrentTracer() *Tracer {
+	tracerMutex.RLock()
+	defer tracerMutex.RUnlock()
+	return currentTracer
+}
+
+// export queues the given finished span for export, or drops it if the queue is full
+// rather than slowing down reconciliations.
+func (t *Tracer) export(span *Span) {
+	select {
+	case t.queue <- span:
+	default:
+		log.V(1).Info("Dropping span, export queue is full", "name", span.Name)
+	}
+}
+
+// Start exports the finished spans in batches, until the given channel is closed.
+func (t *Tracer) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(t.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, t.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := t.exporter.Export(batch); err != nil {
+			log.Error(err, "Failed to export spans", "count", len(batch))
+		}
+		batch = make([]*Span, 0, t.batchSize)
+	}
+
+	for {
+		select {
+		case span := <-t.queue:
+			batch = append(batch, span)
+			if len(batch) >= t.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-stop:
+			// export the spans already finished before stopping
+			for {
+				select {
+				case span := <-t.queue:
+					batch = append(batch, span)
+				default:
+					flush()
+					return nil
+				}
+			}
+		}
+	}
+}
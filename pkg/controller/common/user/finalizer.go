@@ -13,21 +13,31 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// UserFinalizer ensures that any external user created for an associated object is removed.
-func UserFinalizer(c k8s.Client, selector labels.Selector) finalizer.Finalizer {
+// UserFinalizerName is the name of the user finalizer of the associations registered before each association
+// had its own finalizer. It is kept for the associations already using it.
+const UserFinalizerName = "users.finalizers.associations.k8s.elastic.co"
+
+// UserFinalizer ensures that any external user created for an associated object is removed. Each association of
+// the same object must use its own finalizer name, since a finalizer is only executed once per name.
+func UserFinalizer(c k8s.Client, name string, selector labels.Selector) finalizer.Finalizer {
 	return finalizer.Finalizer{
-		Name: "users.finalizers.associations.k8s.elastic.co",
+		Name: name,
 		Execute: func() error {
-			var secrets corev1.SecretList
-			if err := c.List(&client.ListOptions{LabelSelector: selector}, &secrets); err != nil {
-				return err
-			}
-			for _, s := range secrets.Items {
-				if err := c.Delete(&s); err != nil && !apierrors.IsNotFound(err) {
-					return err
-				}
-			}
-			return nil
+			return DeleteUsers(c, selector)
 		},
 	}
 }
+
+// DeleteUsers deletes the user secrets matching the given selector.
+func DeleteUsers(c k8s.Client, selector labels.Selector) error {
+	var secrets corev1.SecretList
+	if err := c.List(&client.ListOptions{LabelSelector: selector}, &secrets); err != nil {
+		return err
+	}
+	for _, s := range secrets.Items {
+		if err := c.Delete(&s); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
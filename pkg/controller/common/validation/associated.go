@@ -0,0 +1,46 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package validation
+
+import (
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// Associated holds the fields of a resource associated with Elasticsearch, such as Kibana or APM Server, that are
+// validated in the same way for all of them.
+type Associated struct {
+	Namespace string
+	Version   string
+	// LowestVersion and HighestVersion bound the supported versions.
+	LowestVersion  version.Version
+	HighestVersion version.Version
+	Config         *commonv1alpha1.Config
+	// Blacklist lists the settings managed by the operator, which cannot be set in Config.
+	Blacklist                []string
+	ElasticsearchRef         commonv1alpha1.ObjectSelector
+	ExternalElasticsearchRef *commonv1alpha1.ExternalElasticsearchRef
+	TLS                      commonv1alpha1.TLSOptions
+}
+
+// ValidateAssociated runs the validations shared by the resources associated with Elasticsearch, and returns the
+// failed ones followed by the failed additional results.
+func ValidateAssociated(c k8s.Client, a Associated, additional ...Result) []Result {
+	results := append([]Result{
+		SupportedVersion(a.Version, a.LowestVersion, a.HighestVersion),
+		NoBlacklistedSettings(a.Config, a.Blacklist),
+		ValidObjectSelector("elasticsearchRef", a.ElasticsearchRef),
+		ValidExternalElasticsearchRef("externalElasticsearchRef", a.ExternalElasticsearchRef, a.ElasticsearchRef),
+		ValidCertificateSecret(c, a.Namespace, a.TLS),
+	}, additional...)
+	var failed []Result
+	for _, r := range results {
+		if !r.Allowed {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,158 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/settings"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	cfgInvalidMsg = "configuration invalid"
+)
+
+// SupportedVersion checks that the given version is in the given inclusive range.
+func SupportedVersion(v string, lowest version.Version, highest version.Version) Result {
+	parsed, err := version.Parse(v)
+	if err != nil {
+		return Result{Allowed: false, Error: err, Reason: fmt.Sprintf("Cannot parse version %s", v)}
+	}
+	if !parsed.IsSameOrAfter(lowest) || !highest.IsSameOrAfter(*parsed) {
+		return Result{
+			Allowed: false,
+			Reason:  fmt.Sprintf("unsupported version: %s, supported versions are %s to %s", v, lowest, highest),
+		}
+	}
+	return OK
+}
+
+// NoBlacklistedSettings checks that the given configuration does not contain any of the given settings,
+// which are managed by the operator.
+func NoBlacklistedSettings(cfg *commonv1alpha1.Config, blacklist []string) Result {
+	if cfg == nil {
+		return OK
+	}
+	canonical, err := settings.NewCanonicalConfigFrom(cfg.Data)
+	if err != nil {
+		return Result{Allowed: false, Error: err, Reason: cfgInvalidMsg}
+	}
+	forbidden := canonical.HasKeys(blacklist)
+	if len(forbidden) == 0 {
+		return OK
+	}
+	sort.Strings(forbidden)
+	return Result{
+		Allowed: false,
+		Reason:  strings.Join(forbidden, ", ") + " is not user configurable",
+	}
+}
+
+// ValidObjectSelector checks that the given reference, if defined, targets a valid resource name and namespace.
+func ValidObjectSelector(field string, ref commonv1alpha1.ObjectSelector) Result {
+	if ref.Name == "" {
+		if ref.Namespace != "" {
+			return Result{Allowed: false, Reason: fmt.Sprintf("%s: name is required when namespace is set", field)}
+		}
+		return OK
+	}
+	var errs []string
+	for _, msg := range k8svalidation.IsDNS1123Subdomain(ref.Name) {
+		errs = append(errs, fmt.Sprintf("invalid name %s: %s", ref.Name, msg))
+	}
+	if ref.Namespace != "" {
+		for _, msg := range k8svalidation.IsDNS1123Label(ref.Namespace) {
+			errs = append(errs, fmt.Sprintf("invalid namespace %s: %s", ref.Namespace, msg))
+		}
+	}
+	if len(errs) > 0 {
+		return Result{Allowed: false, Reason: fmt.Sprintf("%s: %s", field, strings.Join(errs, ", "))}
+	}
+	return OK
+}
+
+// ValidExternalElasticsearchRef checks that the external Elasticsearch reference, if any, has a valid HTTP(S) URL and
+// an admin secret, and is not used together with a reference to an Elasticsearch resource.
+func ValidExternalElasticsearchRef(field string, ref *commonv1alpha1.ExternalElasticsearchRef, esRef commonv1alpha1.ObjectSelector) Result {
+	if ref == nil {
+		return OK
+	}
+	if esRef.IsDefined() {
+		return Result{Allowed: false, Reason: fmt.Sprintf("%s cannot be used together with elasticsearchRef", field)}
+	}
+	var errs []string
+	// associated resources always connect to Elasticsearch over TLS
+	if u, err := url.Parse(ref.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+		errs = append(errs, fmt.Sprintf("invalid url %q, an https url is expected", ref.URL))
+	}
+	if ref.AdminSecretName == "" {
+		errs = append(errs, "adminSecretName is required")
+	}
+	if ref.CertificateAuthorities.SecretName == "" {
+		errs = append(errs, "certificateAuthorities.secretName is required")
+	}
+	if len(errs) > 0 {
+		return Result{Allowed: false, Reason: fmt.Sprintf("%s: %s", field, strings.Join(errs, ", "))}
+	}
+	return OK
+}
+
+// ValidCertificateSecret checks that the custom certificate secret referenced in the given TLS options, if any,
+// contains a certificate and a private key. A secret that does not exist yet cannot be checked, and is accepted.
+func ValidCertificateSecret(c k8s.Client, namespace string, tls commonv1alpha1.TLSOptions) Result {
+	secretName := tls.Certificate.SecretName
+	if secretName == "" {
+		return OK
+	}
+	var secret corev1.Secret
+	if err := c.Get(types.NamespacedName{Namespace: namespace, Name: secretName}, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return OK
+		}
+		return Result{Allowed: false, Error: err, Reason: fmt.Sprintf("Cannot retrieve certificate secret %s", secretName)}
+	}
+	var missing []string
+	for _, key := range []string{certificates.CertFileName, certificates.KeyFileName} {
+		if len(secret.Data[key]) == 0 {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return Result{
+			Allowed: false,
+			Reason:  fmt.Sprintf("Certificate secret %s is missing %s", secretName, strings.Join(missing, ", ")),
+		}
+	}
+	return OK
+}
+
+// Aggregate merges the given results into a single one, allowed only if all results are allowed.
+func Aggregate(results []Result) Result {
+	response := OK
+	var reasons []string
+	for _, r := range results {
+		if r.Allowed {
+			continue
+		}
+		response.Allowed = false
+		if response.Error == nil {
+			response.Error = r.Error
+		}
+		reasons = append(reasons, r.Reason)
+	}
+	response.Reason = strings.Join(reasons, ". ")
+	return response
+}
@@ -0,0 +1,193 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSupportedVersion(t *testing.T) {
+	lowest := version.MustParse("6.0.0")
+	highest := version.MustParse("7.99.99")
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{version: "6.0.0", want: true},
+		{version: "7.3.1", want: true},
+		{version: "5.6.4", want: false},
+		{version: "8.0.0", want: false},
+		{version: "not-a-version", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			require.Equal(t, tt.want, SupportedVersion(tt.version, lowest, highest).Allowed)
+		})
+	}
+}
+
+func TestNoBlacklistedSettings(t *testing.T) {
+	blacklist := []string{"server.host", "elasticsearch.password"}
+	tests := []struct {
+		name       string
+		cfg        *commonv1alpha1.Config
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "no configuration",
+			cfg:  nil,
+			want: true,
+		},
+		{
+			name: "no blacklisted setting",
+			cfg:  &commonv1alpha1.Config{Data: map[string]interface{}{"server.name": "foo"}},
+			want: true,
+		},
+		{
+			name: "blacklisted settings, in flat and nested forms",
+			cfg: &commonv1alpha1.Config{Data: map[string]interface{}{
+				"elasticsearch": map[string]interface{}{"password": "changeme"},
+				"server.host":   "0.0.0.0",
+			}},
+			want:       false,
+			wantReason: "elasticsearch.password, server.host is not user configurable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NoBlacklistedSettings(tt.cfg, blacklist)
+			require.Equal(t, tt.want, got.Allowed)
+			require.Equal(t, tt.wantReason, got.Reason)
+		})
+	}
+}
+
+func TestValidObjectSelector(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  commonv1alpha1.ObjectSelector
+		want bool
+	}{
+		{name: "undefined", ref: commonv1alpha1.ObjectSelector{}, want: true},
+		{name: "name only", ref: commonv1alpha1.ObjectSelector{Name: "es"}, want: true},
+		{name: "name and namespace", ref: commonv1alpha1.ObjectSelector{Name: "es", Namespace: "ns"}, want: true},
+		{name: "namespace only", ref: commonv1alpha1.ObjectSelector{Namespace: "ns"}, want: false},
+		{name: "invalid name", ref: commonv1alpha1.ObjectSelector{Name: "My_ES"}, want: false},
+		{name: "invalid namespace", ref: commonv1alpha1.ObjectSelector{Name: "es", Namespace: "my.ns"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ValidObjectSelector("elasticsearchRef", tt.ref).Allowed)
+		})
+	}
+}
+
+func TestValidExternalElasticsearchRef(t *testing.T) {
+	valid := commonv1alpha1.ExternalElasticsearchRef{
+		URL:                    "https://legacy-es:9200",
+		AdminSecretName:        "legacy-es-admin",
+		CertificateAuthorities: commonv1alpha1.SecretRef{SecretName: "legacy-es-ca"},
+	}
+	withValid := func(mutate func(ref *commonv1alpha1.ExternalElasticsearchRef)) *commonv1alpha1.ExternalElasticsearchRef {
+		ref := valid
+		mutate(&ref)
+		return &ref
+	}
+	tests := []struct {
+		name  string
+		ref   *commonv1alpha1.ExternalElasticsearchRef
+		esRef commonv1alpha1.ObjectSelector
+		want  bool
+	}{
+		{name: "undefined", want: true},
+		{name: "valid", ref: &valid, want: true},
+		{name: "with an Elasticsearch reference", ref: &valid, esRef: commonv1alpha1.ObjectSelector{Name: "es"}, want: false},
+		{name: "invalid url", ref: withValid(func(ref *commonv1alpha1.ExternalElasticsearchRef) { ref.URL = "legacy-es:9200" }), want: false},
+		{name: "http url", ref: withValid(func(ref *commonv1alpha1.ExternalElasticsearchRef) { ref.URL = "http://legacy-es:9200" }), want: false},
+		{name: "no admin secret", ref: withValid(func(ref *commonv1alpha1.ExternalElasticsearchRef) { ref.AdminSecretName = "" }), want: false},
+		{name: "no CA secret", ref: withValid(func(ref *commonv1alpha1.ExternalElasticsearchRef) {
+			ref.CertificateAuthorities = commonv1alpha1.SecretRef{}
+		}), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ValidExternalElasticsearchRef("externalElasticsearchRef", tt.ref, tt.esRef).Allowed)
+		})
+	}
+}
+
+func TestValidCertificateSecret(t *testing.T) {
+	tls := commonv1alpha1.TLSOptions{Certificate: commonv1alpha1.SecretRef{SecretName: "my-cert"}}
+	secret := func(data map[string][]byte) runtime.Object {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "my-cert"}, Data: data}
+	}
+	tests := []struct {
+		name       string
+		tls        commonv1alpha1.TLSOptions
+		objects    []runtime.Object
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "no custom certificate",
+			tls:  commonv1alpha1.TLSOptions{},
+			want: true,
+		},
+		{
+			name: "secret not created yet",
+			tls:  tls,
+			want: true,
+		},
+		{
+			name:    "valid secret",
+			tls:     tls,
+			objects: []runtime.Object{secret(map[string][]byte{"tls.crt": []byte("crt"), "tls.key": []byte("key")})},
+			want:    true,
+		},
+		{
+			name:       "missing private key",
+			tls:        tls,
+			objects:    []runtime.Object{secret(map[string][]byte{"tls.crt": []byte("crt")})},
+			want:       false,
+			wantReason: "Certificate secret my-cert is missing tls.key",
+		},
+		{
+			name:       "empty secret",
+			tls:        tls,
+			objects:    []runtime.Object{secret(nil)},
+			want:       false,
+			wantReason: "Certificate secret my-cert is missing tls.crt, tls.key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.WrapClient(fake.NewFakeClient(tt.objects...))
+			got := ValidCertificateSecret(c, "ns", tt.tls)
+			require.Equal(t, tt.want, got.Allowed)
+			require.Equal(t, tt.wantReason, got.Reason)
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	err := errors.New("boom")
+	require.Equal(t, OK, Aggregate(nil))
+	require.Equal(t, OK, Aggregate([]Result{OK, OK}))
+	require.Equal(t,
+		Result{Allowed: false, Error: err, Reason: "first. second"},
+		Aggregate([]Result{{Reason: "first", Error: err}, OK, {Reason: "second"}}),
+	)
+}
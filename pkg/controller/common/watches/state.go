@@ -13,6 +13,7 @@ import (
 func NewDynamicWatches() DynamicWatches {
 	return DynamicWatches{
 		Secrets:               NewDynamicEnqueueRequest(),
+		ConfigMaps:            NewDynamicEnqueueRequest(),
 		Pods:                  NewDynamicEnqueueRequest(),
 		ElasticsearchClusters: NewDynamicEnqueueRequest(),
 		Kibanas:               NewDynamicEnqueueRequest(),
@@ -23,6 +24,7 @@ func NewDynamicWatches() DynamicWatches {
 // give each of them an identity.
 type DynamicWatches struct {
 	Secrets               *DynamicEnqueueRequest
+	ConfigMaps            *DynamicEnqueueRequest
 	Pods                  *DynamicEnqueueRequest
 	ElasticsearchClusters *DynamicEnqueueRequest
 	Kibanas               *DynamicEnqueueRequest
@@ -31,7 +33,10 @@ type DynamicWatches struct {
 // InjectScheme is used by the ControllerManager to inject Scheme into Sources, EventHandlers, Predicates, and
 // Reconciles
 func (w DynamicWatches) InjectScheme(scheme *runtime.Scheme) error {
-	return w.Secrets.InjectScheme(scheme)
+	if err := w.Secrets.InjectScheme(scheme); err != nil {
+		return err
+	}
+	return w.ConfigMaps.InjectScheme(scheme)
 }
 
 // DynamicWatches implements inject.Scheme mostly to facilitate testing. In production code injection happens on
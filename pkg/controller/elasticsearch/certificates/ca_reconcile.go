@@ -16,8 +16,11 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/certificates/transport"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/name"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/network"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
 	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -34,8 +37,10 @@ func Reconcile(
 	driver driver.Interface,
 	es v1alpha1.Elasticsearch,
 	services []corev1.Service,
+	dialer net.Dialer,
 	caRotation certificates.RotationParams,
 	certRotation certificates.RotationParams,
+	keyParams certificates.KeyParams,
 ) (*CertificateResources, *reconciler.Results) {
 	results := &reconciler.Results{}
 
@@ -49,14 +54,23 @@ func Reconcile(
 		labels,
 		certificates.HTTPCAType,
 		caRotation,
+		keyParams,
+		http.PodsCertificatesIssuedBy(
+			driver.K8sClient(),
+			dialer,
+			es.Namespace,
+			map[string]string{label.ClusterNameLabelName: es.Name},
+			network.HTTPPort,
+			es.Spec.HTTP.TLS,
+		),
 	)
 	if err != nil {
 		return nil, results.WithError(err)
 	}
 
-	// make sure to requeue before the CA cert expires
+	// make sure to requeue before the CA cert expires, or to move on with its rotation
 	results.WithResult(reconcile.Result{
-		RequeueAfter: certificates.ShouldRotateIn(time.Now(), httpCA.Cert.NotAfter, caRotation.RotateBefore),
+		RequeueAfter: certificates.ShouldRotateCAIn(time.Now(), httpCA, caRotation),
 	})
 
 	// discover and maybe reconcile for the http certificates to use
@@ -69,10 +83,21 @@ func Reconcile(
 		labels,
 		services,
 		caRotation,
+		keyParams,
 	)
 	if err != nil {
 		return nil, results.WithError(err)
 	}
+	if es.Spec.HTTP.TLS.Enabled() {
+		certificates.ReportCAExpiration(v1alpha1.Kind, k8s.ExtractNamespacedName(&es), certificates.HTTPCAType, httpCA)
+		// warn again before the certificate expires if it cannot be rotated by the operator
+		results.WithResult(reconcile.Result{
+			RequeueAfter: http.ReportCertificatesExpiration(driver.Recorder(), &es, v1alpha1.Kind, es.Spec.HTTP.TLS, *httpCertificates),
+		})
+	} else {
+		// the HTTP certificates are still issued but not served
+		certificates.DeleteHTTPExpirationMetrics(v1alpha1.Kind, k8s.ExtractNamespacedName(&es))
+	}
 
 	// reconcile http public certs secret:
 	if err := http.ReconcileHTTPCertsPublicSecret(driver.K8sClient(), driver.Scheme(), &es, name.ESNamer, httpCertificates); err != nil {
@@ -87,13 +112,17 @@ func Reconcile(
 		labels,
 		certificates.TransportCAType,
 		caRotation,
+		keyParams,
+		transportCertificatesIssuedBy(driver.K8sClient(), dialer, es),
 	)
 	if err != nil {
 		return nil, results.WithError(err)
 	}
-	// make sure to requeue before the CA cert expires
+	certificates.ReportCAExpiration(v1alpha1.Kind, k8s.ExtractNamespacedName(&es), certificates.TransportCAType, transportCA)
+
+	// make sure to requeue before the CA cert expires, or to move on with its rotation
 	results.WithResult(reconcile.Result{
-		RequeueAfter: certificates.ShouldRotateIn(time.Now(), transportCA.Cert.NotAfter, caRotation.RotateBefore),
+		RequeueAfter: certificates.ShouldRotateCAIn(time.Now(), transportCA, caRotation),
 	})
 
 	// reconcile transport public certs secret:
@@ -108,12 +137,13 @@ func Reconcile(
 		transportCA,
 		es,
 		certRotation,
+		keyParams,
 	)
 	if results.WithResult(result).WithError(err).HasError() {
 		return nil, results
 	}
 
-	trustedHTTPCertificates, err := certificates.ParsePEMCerts(httpCertificates.CertPem())
+	trustedHTTPCertificates, err := certificates.ParsePEMCerts(httpCertificates.CertChain())
 	if err != nil {
 		return nil, results.WithError(err)
 	}
@@ -123,3 +153,18 @@ func Reconcile(
 		TransportCA:             transportCA,
 	}, results
 }
+
+// transportCertificatesIssuedBy returns a check verifying that all the Elasticsearch pods serve a transport
+// certificate issued by the given CA cert.
+func transportCertificatesIssuedBy(c k8s.Client, dialer net.Dialer, es v1alpha1.Elasticsearch) certificates.LeafCertificatesCheck {
+	return func(caCert *x509.Certificate) (bool, error) {
+		var pods corev1.PodList
+		if err := c.List(&client.ListOptions{
+			LabelSelector: label.NewLabelSelectorForElasticsearch(es),
+			Namespace:     es.Namespace,
+		}, &pods); err != nil {
+			return false, err
+		}
+		return certificates.PodsServeCertificatesIssuedBy(dialer, pods.Items, network.TransportPort, caCert), nil
+	}
+}
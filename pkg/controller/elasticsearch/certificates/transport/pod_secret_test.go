@@ -109,6 +109,7 @@ func Test_ensureTransportCertificatesSecretContentsForPod(t *testing.T) {
 		name       string
 		secret     *corev1.Secret
 		pod        *corev1.Pod
+		keyParams  *certificates.KeyParams
 		assertions func(t *testing.T, before corev1.Secret, after corev1.Secret)
 		wantErr    func(t *testing.T, err error)
 	}{
@@ -131,7 +132,7 @@ func Test_ensureTransportCertificatesSecretContentsForPod(t *testing.T) {
 			name: "no cert in the secret",
 			secret: &corev1.Secret{
 				Data: map[string][]byte{
-					PodKeyFileName(testPod.Name): certificates.EncodePEMPrivateKey(*testRSAPrivateKey),
+					PodKeyFileName(testPod.Name): testEncodedPrivateKey,
 				},
 			},
 			assertions: func(t *testing.T, before corev1.Secret, after corev1.Secret) {
@@ -146,7 +147,7 @@ func Test_ensureTransportCertificatesSecretContentsForPod(t *testing.T) {
 			name: "cert does not belong to the key in the secret",
 			secret: &corev1.Secret{
 				Data: map[string][]byte{
-					PodKeyFileName(testPod.Name):  certificates.EncodePEMPrivateKey(*testRSAPrivateKey),
+					PodKeyFileName(testPod.Name):  testEncodedPrivateKey,
 					PodCertFileName(testPod.Name): certificates.EncodePEMCert(testCA.Cert.Raw),
 				},
 			},
@@ -163,7 +164,7 @@ func Test_ensureTransportCertificatesSecretContentsForPod(t *testing.T) {
 			name: "invalid cert in the secret",
 			secret: &corev1.Secret{
 				Data: map[string][]byte{
-					PodKeyFileName(testPod.Name):  certificates.EncodePEMPrivateKey(*testRSAPrivateKey),
+					PodKeyFileName(testPod.Name):  testEncodedPrivateKey,
 					PodCertFileName(testPod.Name): []byte("invalid"),
 				},
 			},
@@ -180,7 +181,7 @@ func Test_ensureTransportCertificatesSecretContentsForPod(t *testing.T) {
 			name: "valid data should not require updating",
 			secret: &corev1.Secret{
 				Data: map[string][]byte{
-					PodKeyFileName(testPod.Name):  certificates.EncodePEMPrivateKey(*testRSAPrivateKey),
+					PodKeyFileName(testPod.Name):  testEncodedPrivateKey,
 					PodCertFileName(testPod.Name): pemCert,
 				},
 			},
@@ -188,6 +189,24 @@ func Test_ensureTransportCertificatesSecretContentsForPod(t *testing.T) {
 				assert.Equal(t, before, after)
 			},
 		},
+		{
+			name: "key not matching the key params should be regenerated",
+			secret: &corev1.Secret{
+				Data: map[string][]byte{
+					PodKeyFileName(testPod.Name):  testEncodedPrivateKey,
+					PodCertFileName(testPod.Name): pemCert,
+				},
+			},
+			keyParams: &certificates.KeyParams{Algorithm: certificates.ECDSAKeyAlgorithm, Size: 256},
+			assertions: func(t *testing.T, before corev1.Secret, after corev1.Secret) {
+				assert.NotEqual(t, before.Data[PodKeyFileName(testPod.Name)], after.Data[PodKeyFileName(testPod.Name)])
+				assert.NotEqual(t, before.Data[PodCertFileName(testPod.Name)], after.Data[PodCertFileName(testPod.Name)])
+
+				privateKey, err := certificates.ParsePEMPrivateKey(after.Data[PodKeyFileName(testPod.Name)])
+				require.NoError(t, err)
+				assert.True(t, certificates.KeyParams{Algorithm: certificates.ECDSAKeyAlgorithm, Size: 256}.Matches(privateKey))
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -197,6 +216,9 @@ func Test_ensureTransportCertificatesSecretContentsForPod(t *testing.T) {
 			if tt.pod == nil {
 				tt.pod = testPod.DeepCopy()
 			}
+			if tt.keyParams == nil {
+				tt.keyParams = &testKeyParams
+			}
 
 			beforeSecret := tt.secret.DeepCopy()
 
@@ -209,6 +231,7 @@ func Test_ensureTransportCertificatesSecretContentsForPod(t *testing.T) {
 					Validity:     certificates.DefaultCertValidity,
 					RotateBefore: certificates.DefaultRotateBefore,
 				},
+				*tt.keyParams,
 			)
 			if tt.wantErr != nil {
 				tt.wantErr(t, err)
@@ -20,7 +20,7 @@ import (
 )
 
 // ReconcileTransportCertsPublicSecret reconciles the Secret containing the publicly available transport CA
-// information.
+// information, including any CA being rotated in or out.
 func ReconcileTransportCertsPublicSecret(
 	c k8s.Client,
 	scheme *runtime.Scheme,
@@ -34,7 +34,7 @@ func ReconcileTransportCertsPublicSecret(
 	expected := &corev1.Secret{
 		ObjectMeta: meta,
 		Data: map[string][]byte{
-			certificates.CAFileName: certificates.EncodePEMCert(ca.Cert.Raw),
+			certificates.CAFileName: ca.TrustedCertsPem(),
 		},
 	}
 	reconciled := &corev1.Secret{}
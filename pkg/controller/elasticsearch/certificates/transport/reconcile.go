@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
@@ -34,6 +35,7 @@ func ReconcileTransportCertificatesSecrets(
 	ca *certificates.CA,
 	es v1alpha1.Elasticsearch,
 	rotationParams certificates.RotationParams,
+	keyParams certificates.KeyParams,
 ) (reconcile.Result, error) {
 	var pods corev1.PodList
 	if err := c.List(&client.ListOptions{
@@ -57,7 +59,7 @@ func ReconcileTransportCertificatesSecrets(
 		}
 
 		if err := ensureTransportCertificatesSecretContentsForPod(
-			es, secret, pod, ca, rotationParams,
+			es, secret, pod, ca, rotationParams, keyParams,
 		); err != nil {
 			return reconcile.Result{}, err
 		}
@@ -88,7 +90,10 @@ func ReconcileTransportCertificatesSecrets(
 		}
 	}
 
-	caBytes := certificates.EncodePEMCert(ca.Cert.Raw)
+	reportCertificatesExpiration(es, pods.Items, *secret)
+
+	// trust the current CA, along with any CA being rotated in or out
+	caBytes := ca.TrustedCertsPem()
 
 	// compare with current trusted CA certs.
 	if !bytes.Equal(caBytes, secret.Data[certificates.CAFileName]) {
@@ -107,6 +112,30 @@ func ReconcileTransportCertificatesSecrets(
 	return reconcile.Result{}, nil
 }
 
+// reportCertificatesExpiration exports the earliest expiration date of the transport certificates of the given pods.
+func reportCertificatesExpiration(es v1alpha1.Elasticsearch, pods []corev1.Pod, secret corev1.Secret) {
+	var earliest *time.Time
+	for _, pod := range pods {
+		certs, err := certificates.ParsePEMCerts(secret.Data[PodCertFileName(pod.Name)])
+		if err != nil || len(certs) == 0 {
+			continue
+		}
+		if earliest == nil || certs[0].NotAfter.Before(*earliest) {
+			earliest = &certs[0].NotAfter
+		}
+	}
+	if earliest == nil {
+		return
+	}
+	certificates.ReportExpiration(
+		v1alpha1.Kind,
+		k8s.ExtractNamespacedName(&es),
+		certificates.TransportCertificateKind,
+		certificates.SelfSignedCertificateSource,
+		*earliest,
+	)
+}
+
 // ensureTransportCertificatesSecretExists ensures the existence and Labels of the Secret that at a later point
 // in time will contain the transport certificates.
 func ensureTransportCertificatesSecretExists(
@@ -22,6 +22,7 @@ import (
 var (
 	testCA                       *certificates.CA
 	testRSAPrivateKey            *rsa.PrivateKey
+	testEncodedPrivateKey        []byte
 	testCSRBytes                 []byte
 	testCSR                      *x509.CertificateRequest
 	validatedCertificateTemplate *certificates.ValidatedCertificateTemplate
@@ -39,6 +40,8 @@ var (
 			PodIP: testIP,
 		},
 	}
+	// testKeyParams match the 1024 bits test private key
+	testKeyParams = certificates.KeyParams{Algorithm: certificates.RSAKeyAlgorithm, Size: 1024}
 )
 
 const (
@@ -71,6 +74,9 @@ func init() {
 	if testRSAPrivateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
 		panic("Failed to parse private key: " + err.Error())
 	}
+	if testEncodedPrivateKey, err = certificates.EncodePEMPrivateKey(testRSAPrivateKey); err != nil {
+		panic("Failed to encode private key: " + err.Error())
+	}
 
 	if testCA, err = certificates.NewSelfSignedCA(certificates.CABuilderOptions{
 		Subject:    pkix.Name{CommonName: "test-common-name"},
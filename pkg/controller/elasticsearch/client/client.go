@@ -32,25 +32,57 @@ type UserAuth struct {
 	Password string
 }
 
+// User represents a user of the Elasticsearch native realm.
+type User struct {
+	Password string   `json:"password,omitempty"`
+	Roles    []string `json:"roles"`
+}
+
+// APIKeyRequest is a request to create an API key, whose privileges are restricted to the given role descriptors.
+type APIKeyRequest struct {
+	Name            string          `json:"name"`
+	RoleDescriptors map[string]Role `json:"role_descriptors,omitempty"`
+}
+
+// APIKey is an API key created through the security API.
+type APIKey struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	APIKey string `json:"api_key"`
+}
+
+// InvalidateAPIKeysRequest selects the API keys to invalidate by ID or by name.
+type InvalidateAPIKeysRequest struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
 // Role represents an Elasticsearch role.
 type Role struct {
-	Cluster []string `json:"cluster,omitempty"`
-	/*Indices []struct {
-		Names      []string `json:"names,omitempty"`
-		Privileges []string `json:",omitempty"`
-	} `json:"indices,omitempty"`
-	Applications []struct {
-		Application string   `json:"application"`
-		Privileges  []string `json:"privileges"`
-		Resources   []string `json:"resources,omitempty"`
-	} `json:"applications,omitempty"`
-	RunAs    []string `json:"run_as,omitempty"`
-	Metadata *struct {
-		Reserved bool `json:"_reserved"`
-	} `json:"metadata,omitempty"`
-	TransientMetadata *struct {
-		Enabled bool `json:"enabled"`
-	} `json:"transient_metadata,omitempty"`*/
+	Cluster           []string                `json:"cluster,omitempty"`
+	Indices           []IndicesPrivileges     `json:"indices,omitempty"`
+	Applications      []ApplicationPrivileges `json:"applications,omitempty"`
+	RunAs             []string                `json:"run_as,omitempty"`
+	Metadata          map[string]interface{}  `json:"metadata,omitempty"`
+	TransientMetadata *TransientMetadata      `json:"transient_metadata,omitempty"`
+}
+
+// IndicesPrivileges are the privileges of a role on a set of indices.
+type IndicesPrivileges struct {
+	Names      []string `json:"names"`
+	Privileges []string `json:"privileges"`
+}
+
+// ApplicationPrivileges are the privileges of a role on the resources of an application.
+type ApplicationPrivileges struct {
+	Application string   `json:"application"`
+	Privileges  []string `json:"privileges"`
+	Resources   []string `json:"resources,omitempty"`
+}
+
+// TransientMetadata of a role.
+type TransientMetadata struct {
+	Enabled bool `json:"enabled"`
 }
 
 // Client captures the information needed to interact with an Elasticsearch cluster via HTTP
@@ -92,6 +124,16 @@ type Client interface {
 	GetLicense(ctx context.Context) (License, error)
 	// UpdateLicense attempts to update cluster license with the given licenses.
 	UpdateLicense(ctx context.Context, licenses LicenseUpdateRequest) (LicenseUpdateResponse, error)
+	// PutUser creates or updates a user of the native realm.
+	PutUser(ctx context.Context, name string, user User) error
+	// DeleteUser deletes a user of the native realm.
+	DeleteUser(ctx context.Context, name string) error
+	// PutRole creates or updates a role of the native realm.
+	PutRole(ctx context.Context, name string, role Role) error
+	// CreateAPIKey creates an API key owned by the authenticated user.
+	CreateAPIKey(ctx context.Context, request APIKeyRequest) (APIKey, error)
+	// InvalidateAPIKeys invalidates the API keys matching the given request.
+	InvalidateAPIKeys(ctx context.Context, request InvalidateAPIKeysRequest) error
 	// AddVotingConfigExclusions sets the transient and persistent setting of the same name in cluster settings.
 	//
 	// If timeout is the empty string, the default is used.
@@ -384,6 +384,125 @@ func TestClient_Equal(t *testing.T) {
 	}
 }
 
+func TestClient_PutUser(t *testing.T) {
+	tests := []struct {
+		expectedPath string
+		version      version.Version
+	}{
+		{
+			expectedPath: "/_xpack/security/user/kibana-user",
+			version:      version.MustParse("6.8.0"),
+		},
+		{
+			expectedPath: "/_security/user/kibana-user",
+			version:      version.MustParse("7.0.0"),
+		},
+	}
+	for _, tt := range tests {
+		testClient := NewMockClient(tt.version, func(req *http.Request) *http.Response {
+			require.Equal(t, tt.expectedPath, req.URL.Path)
+			require.Equal(t, http.MethodPut, req.Method)
+			body, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"password":"secret","roles":["kibana_system"]}`, string(body))
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"created":true}`)),
+				Header:     make(http.Header),
+				Request:    req,
+			}
+		})
+		err := testClient.PutUser(context.Background(), "kibana-user", User{Password: "secret", Roles: []string{"kibana_system"}})
+		assert.NoError(t, err)
+	}
+}
+
+func TestClient_DeleteUser(t *testing.T) {
+	tests := []struct {
+		expectedPath string
+		version      version.Version
+	}{
+		{
+			expectedPath: "/_xpack/security/user/kibana-user",
+			version:      version.MustParse("6.8.0"),
+		},
+		{
+			expectedPath: "/_security/user/kibana-user",
+			version:      version.MustParse("7.0.0"),
+		},
+	}
+	for _, tt := range tests {
+		testClient := NewMockClient(tt.version, func(req *http.Request) *http.Response {
+			require.Equal(t, tt.expectedPath, req.URL.Path)
+			require.Equal(t, http.MethodDelete, req.Method)
+			return NewMockResponse(200, req, `{"found":true}`)
+		})
+		assert.NoError(t, testClient.DeleteUser(context.Background(), "kibana-user"))
+	}
+}
+
+func TestClient_PutRole(t *testing.T) {
+	tests := []struct {
+		expectedPath string
+		version      version.Version
+	}{
+		{
+			expectedPath: "/_xpack/security/role/apm-role",
+			version:      version.MustParse("6.8.0"),
+		},
+		{
+			expectedPath: "/_security/role/apm-role",
+			version:      version.MustParse("7.0.0"),
+		},
+	}
+	for _, tt := range tests {
+		testClient := NewMockClient(tt.version, func(req *http.Request) *http.Response {
+			require.Equal(t, tt.expectedPath, req.URL.Path)
+			require.Equal(t, http.MethodPut, req.Method)
+			body, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"cluster":["monitor"],"indices":[{"names":["apm-*"],"privileges":["write"]}]}`, string(body))
+			return NewMockResponse(200, req, `{"role":{"created":true}}`)
+		})
+		err := testClient.PutRole(context.Background(), "apm-role", Role{
+			Cluster: []string{"monitor"},
+			Indices: []IndicesPrivileges{{Names: []string{"apm-*"}, Privileges: []string{"write"}}},
+		})
+		assert.NoError(t, err)
+	}
+}
+
+func TestClient_CreateAPIKey(t *testing.T) {
+	testClient := NewMockClient(version.MustParse("7.6.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_security/api_key", req.URL.Path)
+		require.Equal(t, http.MethodPost, req.Method)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"name":"agents","role_descriptors":{"apm":{"applications":[{"application":"apm","privileges":["event:write"],"resources":["*"]}]}}}`, string(body))
+		return NewMockResponse(200, req, `{"id":"key-id","name":"agents","api_key":"key-value"}`)
+	})
+	apiKey, err := testClient.CreateAPIKey(context.Background(), APIKeyRequest{
+		Name: "agents",
+		RoleDescriptors: map[string]Role{
+			"apm": {Applications: []ApplicationPrivileges{{Application: "apm", Privileges: []string{"event:write"}, Resources: []string{"*"}}}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, APIKey{ID: "key-id", Name: "agents", APIKey: "key-value"}, apiKey)
+}
+
+func TestClient_InvalidateAPIKeys(t *testing.T) {
+	testClient := NewMockClient(version.MustParse("7.6.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_security/api_key", req.URL.Path)
+		require.Equal(t, http.MethodDelete, req.Method)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"id":"key-id"}`, string(body))
+		return NewMockResponse(200, req, `{"invalidated_api_keys":["key-id"]}`)
+	})
+	require.NoError(t, testClient.InvalidateAPIKeys(context.Background(), InvalidateAPIKeysRequest{ID: "key-id"}))
+}
+
 func TestClient_UpdateLicense(t *testing.T) {
 	tests := []struct {
 		expectedPath string
@@ -120,6 +120,27 @@ func (c *clientV6) UpdateLicense(ctx context.Context, licenses LicenseUpdateRequ
 	return response, c.post(ctx, "/_xpack/license", licenses, &response)
 }
 
+func (c *clientV6) PutUser(ctx context.Context, name string, user User) error {
+	return c.put(ctx, "/_xpack/security/user/"+url.PathEscape(name), user, nil)
+}
+
+func (c *clientV6) DeleteUser(ctx context.Context, name string) error {
+	return c.delete(ctx, "/_xpack/security/user/"+url.PathEscape(name), nil, nil)
+}
+
+func (c *clientV6) PutRole(ctx context.Context, name string, role Role) error {
+	return c.put(ctx, "/_xpack/security/role/"+url.PathEscape(name), role, nil)
+}
+
+func (c *clientV6) CreateAPIKey(ctx context.Context, request APIKeyRequest) (APIKey, error) {
+	var apiKey APIKey
+	return apiKey, c.post(ctx, "/_security/api_key", request, &apiKey)
+}
+
+func (c *clientV6) InvalidateAPIKeys(ctx context.Context, request InvalidateAPIKeysRequest) error {
+	return c.delete(ctx, "/_security/api_key", request, nil)
+}
+
 func (c *clientV6) AddVotingConfigExclusions(ctx context.Context, nodeNames []string, timeout string) error {
 	return errors.New("Not supported in Elasticsearch 6.x")
 }
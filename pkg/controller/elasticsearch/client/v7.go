@@ -7,6 +7,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -27,6 +28,18 @@ func (c *clientV7) UpdateLicense(ctx context.Context, licenses LicenseUpdateRequ
 	return response, c.post(ctx, "/_license", licenses, &response)
 }
 
+func (c *clientV7) PutUser(ctx context.Context, name string, user User) error {
+	return c.put(ctx, "/_security/user/"+url.PathEscape(name), user, nil)
+}
+
+func (c *clientV7) DeleteUser(ctx context.Context, name string) error {
+	return c.delete(ctx, "/_security/user/"+url.PathEscape(name), nil, nil)
+}
+
+func (c *clientV7) PutRole(ctx context.Context, name string, role Role) error {
+	return c.put(ctx, "/_security/role/"+url.PathEscape(name), role, nil)
+}
+
 func (c *clientV7) AddVotingConfigExclusions(ctx context.Context, nodeNames []string, timeout string) error {
 	if timeout == "" {
 		timeout = DefaultVotingConfigExclusionsTimeout
@@ -0,0 +1,59 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package defaults
+
+import (
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	commondefaults "github.com/elastic/cloud-on-k8s/pkg/controller/common/defaults"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	esvolume "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SetDefaults writes into the given Elasticsearch spec the defaults otherwise applied implicitly by the controller,
+// so that the settings in effect are visible on the resource. Values explicitly set are left untouched, which makes
+// this function idempotent.
+func SetDefaults(es *v1alpha1.Elasticsearch) {
+	if es.Spec.SetVMMaxMapCount == nil {
+		setVMMaxMapCount := true
+		es.Spec.SetVMMaxMapCount = &setVMMaxMapCount
+	}
+
+	for i := range es.Spec.Nodes {
+		claims := VolumeClaimTemplates(es.Spec.Nodes[i])
+		// do not share the default claims with the resource
+		for j := range claims {
+			claims[j] = *claims[j].DeepCopy()
+		}
+		es.Spec.Nodes[i].VolumeClaimTemplates = claims
+	}
+
+	if es.Spec.UpdateStrategy.ChangeBudget == nil {
+		changeBudget := v1alpha1.DefaultChangeBudget
+		es.Spec.UpdateStrategy.ChangeBudget = &changeBudget
+	}
+
+	// an empty budget disables the default one and is left untouched
+	if es.Spec.PodDisruptionBudget == nil {
+		maxUnavailable := commonv1alpha1.DefaultPodDisruptionBudgetMaxUnavailable
+		es.Spec.PodDisruptionBudget = &commonv1alpha1.PodDisruptionBudgetTemplate{}
+		es.Spec.PodDisruptionBudget.Spec.MaxUnavailable = &maxUnavailable
+		es.Spec.PodDisruptionBudget.Spec.Selector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				label.ClusterNameLabelName: es.Name,
+			},
+		}
+	}
+}
+
+// VolumeClaimTemplates returns the volume claim templates of the given node spec, including the default data volume
+// claim unless the pod template already provides a data volume.
+func VolumeClaimTemplates(nodeSpec v1alpha1.NodeSpec) []corev1.PersistentVolumeClaim {
+	return commondefaults.AppendDefaultPVCs(
+		nodeSpec.VolumeClaimTemplates, nodeSpec.PodTemplate.Spec, esvolume.DefaultVolumeClaimTemplates...,
+	)
+}
@@ -0,0 +1,124 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package defaults
+
+import (
+	"testing"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	esvolume "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func pdbSpec(maxUnavailable *intstr.IntOrString, matchLabels map[string]string) v1beta1.PodDisruptionBudgetSpec {
+	return v1beta1.PodDisruptionBudgetSpec{
+		MaxUnavailable: maxUnavailable,
+		Selector:       &metav1.LabelSelector{MatchLabels: matchLabels},
+	}
+}
+
+func TestSetDefaults(t *testing.T) {
+	falseValue := false
+	maxUnavailable := intstr.FromInt(2)
+	defaultMaxUnavailable := commonv1alpha1.DefaultPodDisruptionBudgetMaxUnavailable
+	customClaim := corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "custom"}}
+	emptyDirData := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{{
+				Name:         esvolume.ElasticsearchDataVolumeName,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		es   v1alpha1.Elasticsearch
+		want v1alpha1.ElasticsearchSpec
+	}{
+		{
+			name: "empty spec",
+			es: v1alpha1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Name: "es"},
+				Spec: v1alpha1.ElasticsearchSpec{
+					Nodes: []v1alpha1.NodeSpec{{Name: "default"}},
+				},
+			},
+			want: v1alpha1.ElasticsearchSpec{
+				SetVMMaxMapCount: func() *bool { b := true; return &b }(),
+				Nodes: []v1alpha1.NodeSpec{{
+					Name:                 "default",
+					VolumeClaimTemplates: []corev1.PersistentVolumeClaim{esvolume.DefaultDataVolumeClaim},
+				}},
+				UpdateStrategy: v1alpha1.UpdateStrategy{ChangeBudget: &v1alpha1.DefaultChangeBudget},
+				PodDisruptionBudget: &commonv1alpha1.PodDisruptionBudgetTemplate{
+					Spec: pdbSpec(&defaultMaxUnavailable, map[string]string{label.ClusterNameLabelName: "es"}),
+				},
+			},
+		},
+		{
+			name: "user-provided values are left untouched",
+			es: v1alpha1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Name: "es"},
+				Spec: v1alpha1.ElasticsearchSpec{
+					SetVMMaxMapCount: &falseValue,
+					Nodes: []v1alpha1.NodeSpec{
+						{Name: "custom", VolumeClaimTemplates: []corev1.PersistentVolumeClaim{customClaim}},
+						{Name: "emptydir", PodTemplate: emptyDirData},
+					},
+					UpdateStrategy: v1alpha1.UpdateStrategy{ChangeBudget: &v1alpha1.ChangeBudget{MaxSurge: 3}},
+					// an empty budget disables the default one
+					PodDisruptionBudget: &commonv1alpha1.PodDisruptionBudgetTemplate{},
+				},
+			},
+			want: v1alpha1.ElasticsearchSpec{
+				SetVMMaxMapCount: &falseValue,
+				Nodes: []v1alpha1.NodeSpec{
+					{
+						Name:                 "custom",
+						VolumeClaimTemplates: []corev1.PersistentVolumeClaim{customClaim, esvolume.DefaultDataVolumeClaim},
+					},
+					{Name: "emptydir", PodTemplate: emptyDirData},
+				},
+				UpdateStrategy:      v1alpha1.UpdateStrategy{ChangeBudget: &v1alpha1.ChangeBudget{MaxSurge: 3}},
+				PodDisruptionBudget: &commonv1alpha1.PodDisruptionBudgetTemplate{},
+			},
+		},
+		{
+			name: "custom budget",
+			es: v1alpha1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Name: "es"},
+				Spec: v1alpha1.ElasticsearchSpec{
+					PodDisruptionBudget: &commonv1alpha1.PodDisruptionBudgetTemplate{
+						Spec: pdbSpec(&maxUnavailable, map[string]string{"foo": "bar"}),
+					},
+				},
+			},
+			want: v1alpha1.ElasticsearchSpec{
+				SetVMMaxMapCount: func() *bool { b := true; return &b }(),
+				UpdateStrategy:   v1alpha1.UpdateStrategy{ChangeBudget: &v1alpha1.DefaultChangeBudget},
+				PodDisruptionBudget: &commonv1alpha1.PodDisruptionBudgetTemplate{
+					Spec: pdbSpec(&maxUnavailable, map[string]string{"foo": "bar"}),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetDefaults(&tt.es)
+			require.Equal(t, tt.want, tt.es.Spec)
+			// defaulting is idempotent
+			defaulted := *tt.es.DeepCopy()
+			SetDefaults(&tt.es)
+			require.Equal(t, defaulted, tt.es)
+		})
+	}
+}
@@ -5,6 +5,7 @@
 package driver
 
 import (
+	"context"
 	"crypto/x509"
 	"fmt"
 	"time"
@@ -31,6 +32,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/configmap"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/initcontainer"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/license"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/monitoring"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/observer"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/pdb"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/reconcile"
@@ -49,6 +51,8 @@ var (
 // Its lifecycle is bound to a single reconciliation attempt.
 type Driver interface {
 	Reconcile() *reconciler.Results
+	// Plan computes the changes Reconcile would apply, without applying them.
+	Plan() (*Plan, error)
 }
 
 // NewDefaultDriver returns the default driver implementation.
@@ -59,6 +63,8 @@ func NewDefaultDriver(parameters DefaultDriverParameters) Driver {
 // DefaultDriverParameters contain parameters for this driver.
 // Most of them are persisted across driver creations.
 type DefaultDriverParameters struct {
+	// Context is the context of the reconciliation, used to record and trace the reconciliation steps.
+	Context context.Context
 	// OperatorParameters contain global parameters about the operator.
 	OperatorParameters operator.Parameters
 
@@ -110,7 +116,7 @@ var _ commondriver.Interface = &defaultDriver{}
 
 // Reconcile fulfills the Driver interface and reconciles the cluster resources.
 func (d *defaultDriver) Reconcile() *reconciler.Results {
-	results := &reconciler.Results{}
+	results := reconciler.NewResults(d.Context)
 
 	// garbage collect secrets attached to this cluster that we don't need anymore
 	if err := cleanup.DeleteOrphanedSecrets(d.Client, d.ES); err != nil {
@@ -126,18 +132,24 @@ func (d *defaultDriver) Reconcile() *reconciler.Results {
 		return results.WithError(err)
 	}
 
+	_, step := reconciler.StartStep(d.Context, "certificates")
 	certificateResources, res := certificates.Reconcile(
 		d,
 		d.ES,
 		[]corev1.Service{*externalService},
+		d.OperatorParameters.Dialer,
 		d.OperatorParameters.CACertRotation,
 		d.OperatorParameters.CertRotation,
+		d.OperatorParameters.CertKeyParams,
 	)
+	step.EndWithResults(res)
 	if results.WithResults(res).HasError() {
 		return results
 	}
 
+	_, step = reconciler.StartStep(d.Context, "users")
 	internalUsers, err := user.ReconcileUsers(d.Client, d.Scheme(), d.ES)
+	step.End(controller.Result{}, err)
 	if err != nil {
 		return results.WithError(err)
 	}
@@ -230,13 +242,24 @@ func (d *defaultDriver) Reconcile() *reconciler.Results {
 		return results.WithError(err)
 	}
 
+	// ship monitoring data to the monitoring cluster, if specified by the user
+	_, step = reconciler.StartStep(d.Context, "monitoring")
+	monitoringResources, monitoringStatus, err := monitoring.Reconcile(d, &d.ES)
+	step.End(controller.Result{}, err)
+	d.ReconcileState.UpdateMonitoringAssociation(monitoringStatus)
+	if err != nil {
+		return results.WithError(err)
+	}
+
 	// set an annotation with the ClusterUUID, if bootstrapped
 	if err := ReconcileClusterUUID(d.Client, &d.ES, observedState); err != nil {
 		return results.WithError(err)
 	}
 
 	// reconcile StatefulSets and nodes configuration
-	res = d.reconcileNodeSpecs(esReachable, esClient, d.ReconcileState, observedState, *resourcesState, keystoreResources)
+	ctx, step := reconciler.StartStep(d.Context, "statefulsets")
+	res = d.reconcileNodeSpecs(ctx, esReachable, esClient, d.ReconcileState, observedState, *resourcesState, keystoreResources, monitoringResources)
+	step.EndWithResults(res)
 	if results.WithResults(res).HasError() {
 		return results
 	}
@@ -5,9 +5,12 @@
 package driver
 
 import (
+	"context"
+
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/keystore"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
 	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/monitoring"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/nodespec"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/observer"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/reconcile"
@@ -15,17 +18,20 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/version/zen1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/version/zen2"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	controller "sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func (d *defaultDriver) reconcileNodeSpecs(
+	ctx context.Context,
 	esReachable bool,
 	esClient esclient.Client,
 	reconcileState *reconcile.State,
 	observedState observer.State,
 	resourcesState reconcile.ResourcesState,
 	keystoreResources *keystore.Resources,
+	monitoringResources *monitoring.Resources,
 ) *reconciler.Results {
-	results := &reconciler.Results{}
+	results := reconciler.NewResults(ctx)
 
 	actualStatefulSets, err := sset.RetrieveActualStatefulSets(d.Client, k8s.ExtractNamespacedName(&d.ES))
 	if err != nil {
@@ -41,7 +47,7 @@ func (d *defaultDriver) reconcileNodeSpecs(
 		return results.WithResult(defaultRequeue)
 	}
 
-	expectedResources, err := nodespec.BuildExpectedResources(d.ES, keystoreResources)
+	expectedResources, err := nodespec.BuildExpectedResources(d.ES, keystoreResources, monitoringResources)
 	if err != nil {
 		return results.WithError(err)
 	}
@@ -57,7 +63,10 @@ func (d *defaultDriver) reconcileNodeSpecs(
 		esState:             esState,
 		upscaleStateBuilder: &upscaleStateBuilder{},
 	}
-	if err := HandleUpscaleAndSpecChanges(upscaleCtx, actualStatefulSets, expectedResources); err != nil {
+	_, step := reconciler.StartStep(ctx, "upscale")
+	err = HandleUpscaleAndSpecChanges(upscaleCtx, actualStatefulSets, expectedResources)
+	step.End(controller.Result{}, err)
+	if err != nil {
 		return results.WithError(err)
 	}
 
@@ -97,7 +106,9 @@ func (d *defaultDriver) reconcileNodeSpecs(
 		es:             d.ES,
 		expectations:   d.Expectations,
 	}
+	_, step = reconciler.StartStep(ctx, "downscale")
 	downscaleRes := HandleDownscale(downscaleCtx, expectedResources.StatefulSets(), actualStatefulSets)
+	step.EndWithResults(downscaleRes)
 	results.WithResults(downscaleRes)
 	if downscaleRes.HasError() {
 		return results
@@ -105,7 +116,9 @@ func (d *defaultDriver) reconcileNodeSpecs(
 
 	// Phase 3: handle rolling upgrades.
 	// Control nodes restart (upgrade) by manually decrementing rollingUpdate.Partition.
+	_, step = reconciler.StartStep(ctx, "upgrade")
 	rollingUpgradesRes := d.handleRollingUpgrades(esClient, esState, actualStatefulSets)
+	step.EndWithResults(rollingUpgradesRes)
 	results.WithResults(rollingUpgradesRes)
 	if rollingUpgradesRes.HasError() {
 		return results
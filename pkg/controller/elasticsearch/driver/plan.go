@@ -0,0 +1,274 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package driver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates/http"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/hash"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/keystore"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/initcontainer"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/monitoring"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/name"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/nodespec"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/services"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/diff"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// configKeysAdjustedOnReconcile are the configuration keys set according to the current master nodes
+// during the reconciliation, which are not part of the expected configuration.
+var configKeysAdjustedOnReconcile = []string{
+	settings.DiscoveryZenMinimumMasterNodes,
+	settings.ClusterInitialMasterNodes,
+}
+
+// ReplicasChange describes a change in the replicas of a StatefulSet.
+type ReplicasChange struct {
+	StatefulSet string
+	From        int32
+	To          int32
+	// LeavingNodes are the nodes leaving the cluster as a result of the change, if any.
+	LeavingNodes []string
+}
+
+// RollingRestart describes a StatefulSet whose pods are restarted to apply a specification change.
+type RollingRestart struct {
+	StatefulSet string
+	// Pods is the number of existing pods restarted.
+	Pods int32
+}
+
+// ConfigChange describes a change in the Elasticsearch configuration of a StatefulSet.
+type ConfigChange struct {
+	StatefulSet string
+	// Keys are the flattened configuration keys that change.
+	Keys []string
+	// Diff is a detailed diff between the current and the expected configuration.
+	Diff string
+}
+
+// Plan describes the changes a reconciliation of the cluster would apply, computed from the current state
+// without applying any change.
+type Plan struct {
+	CreatedStatefulSets []ReplicasChange
+	ScaleUps            []ReplicasChange
+	ScaleDowns          []ReplicasChange
+	RemovedStatefulSets []ReplicasChange
+	RollingRestarts     []RollingRestart
+	// DataMigrations are the data nodes whose data is migrated away before they leave the cluster.
+	DataMigrations []string
+	ConfigChanges  []ConfigChange
+	// HTTPCertificatesReissued is true if a new HTTP certificate is issued.
+	HTTPCertificatesReissued bool
+}
+
+// IsEmpty returns true if the plan does not contain any change.
+func (p Plan) IsEmpty() bool {
+	return len(p.CreatedStatefulSets) == 0 &&
+		len(p.ScaleUps) == 0 &&
+		len(p.ScaleDowns) == 0 &&
+		len(p.RemovedStatefulSets) == 0 &&
+		len(p.RollingRestarts) == 0 &&
+		len(p.DataMigrations) == 0 &&
+		len(p.ConfigChanges) == 0 &&
+		!p.HTTPCertificatesReissued
+}
+
+// RestartedPods returns the total number of existing pods restarted.
+func (p Plan) RestartedPods() int32 {
+	var count int32
+	for _, r := range p.RollingRestarts {
+		count += r.Pods
+	}
+	return count
+}
+
+// String returns a human-readable summary of the plan, without the detailed configuration diffs.
+func (p Plan) String() string {
+	if p.IsEmpty() {
+		return "No changes"
+	}
+	var lines []string
+	for _, c := range p.CreatedStatefulSets {
+		lines = append(lines, fmt.Sprintf("create StatefulSet %s with %d replicas", c.StatefulSet, c.To))
+	}
+	for _, c := range p.ScaleUps {
+		lines = append(lines, fmt.Sprintf("scale up StatefulSet %s from %d to %d replicas", c.StatefulSet, c.From, c.To))
+	}
+	for _, c := range p.ScaleDowns {
+		lines = append(lines, fmt.Sprintf("scale down StatefulSet %s from %d to %d replicas, removing nodes %s",
+			c.StatefulSet, c.From, c.To, strings.Join(c.LeavingNodes, ", ")))
+	}
+	for _, c := range p.RemovedStatefulSets {
+		lines = append(lines, fmt.Sprintf("remove StatefulSet %s with %d replicas", c.StatefulSet, c.From))
+	}
+	if len(p.DataMigrations) > 0 {
+		lines = append(lines, fmt.Sprintf("migrate data away from nodes %s", strings.Join(p.DataMigrations, ", ")))
+	}
+	for _, r := range p.RollingRestarts {
+		lines = append(lines, fmt.Sprintf("rolling restart of StatefulSet %s: %d pods restarted", r.StatefulSet, r.Pods))
+	}
+	for _, c := range p.ConfigChanges {
+		lines = append(lines, fmt.Sprintf("update configuration of StatefulSet %s: %s", c.StatefulSet, strings.Join(c.Keys, ", ")))
+	}
+	if p.HTTPCertificatesReissued {
+		lines = append(lines, "reissue HTTP certificates")
+	}
+	return strings.Join(lines, "; ")
+}
+
+// Plan fulfills the Driver interface and computes the changes a reconciliation would apply, without applying them.
+func (d *defaultDriver) Plan() (*Plan, error) {
+	esKey := k8s.ExtractNamespacedName(&d.ES)
+
+	keystoreResources, err := keystore.PlanResources(d.Client, d.Recorder(), &d.ES, name.ESNamer, initcontainer.KeystoreParams)
+	if err != nil {
+		return nil, err
+	}
+	monitoringResources, err := monitoring.Plan(d.Client, d.ES)
+	if err != nil {
+		return nil, err
+	}
+	expectedResources, err := nodespec.BuildExpectedResources(d.ES, keystoreResources, monitoringResources)
+	if err != nil {
+		return nil, err
+	}
+
+	actualStatefulSets, err := sset.RetrieveActualStatefulSets(d.Client, esKey)
+	if err != nil {
+		return nil, err
+	}
+	actualConfigs := make(map[string]settings.CanonicalConfig, len(actualStatefulSets))
+	for _, actual := range actualStatefulSets {
+		cfg, err := settings.GetESConfigContent(d.Client, actual.Namespace, actual.Name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		actualConfigs[actual.Name] = cfg
+	}
+
+	plan := computePlan(expectedResources, actualStatefulSets, actualConfigs)
+
+	httpCA, err := certificates.GetCAForOwner(d.Client, name.ESNamer, esKey, certificates.HTTPCAType)
+	if err != nil {
+		return nil, err
+	}
+	plan.HTTPCertificatesReissued, err = http.ShouldReissueHTTPCertificates(
+		d.Client,
+		esKey,
+		name.ESNamer,
+		d.ES.Spec.HTTP.TLS,
+		[]corev1.Service{*services.NewExternalService(d.ES)},
+		httpCA,
+		d.OperatorParameters.CACertRotation.RotateBefore,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+// computePlan compares the expected resources with the actual StatefulSets and their configuration
+// to compute the changes a reconciliation would apply.
+func computePlan(
+	expectedResources nodespec.ResourcesList,
+	actualStatefulSets sset.StatefulSetList,
+	actualConfigs map[string]settings.CanonicalConfig,
+) Plan {
+	var plan Plan
+	for _, res := range expectedResources {
+		expected := res.StatefulSet
+		actual, exists := actualStatefulSets.GetByName(expected.Name)
+		if !exists {
+			plan.CreatedStatefulSets = append(plan.CreatedStatefulSets, ReplicasChange{
+				StatefulSet: expected.Name,
+				To:          sset.GetReplicas(expected),
+			})
+			continue
+		}
+		if isReplicaIncrease(actual, expected) {
+			plan.ScaleUps = append(plan.ScaleUps, ReplicasChange{
+				StatefulSet: expected.Name,
+				From:        sset.GetReplicas(actual),
+				To:          sset.GetReplicas(expected),
+			})
+		}
+		if specChanged(actual, expected) {
+			restarted := sset.GetReplicas(actual)
+			if sset.GetReplicas(expected) < restarted {
+				// leaving nodes are removed, not restarted
+				restarted = sset.GetReplicas(expected)
+			}
+			plan.RollingRestarts = append(plan.RollingRestarts, RollingRestart{StatefulSet: expected.Name, Pods: restarted})
+		}
+		if actualConfig, exists := actualConfigs[expected.Name]; exists {
+			if change := configChange(expected.Name, actualConfig, res.Config); change != nil {
+				plan.ConfigChanges = append(plan.ConfigChanges, *change)
+			}
+		}
+	}
+
+	for _, downscale := range calculateDownscales(expectedResources.StatefulSets(), actualStatefulSets) {
+		change := ReplicasChange{
+			StatefulSet:  downscale.statefulSet.Name,
+			From:         downscale.initialReplicas,
+			To:           downscale.targetReplicas,
+			LeavingNodes: downscale.leavingNodeNames(),
+		}
+		if _, stillExpected := expectedResources.StatefulSets().GetByName(change.StatefulSet); stillExpected {
+			plan.ScaleDowns = append(plan.ScaleDowns, change)
+		} else {
+			plan.RemovedStatefulSets = append(plan.RemovedStatefulSets, change)
+		}
+		if label.NodeTypesDataLabelName.HasValue(true, downscale.statefulSet.Spec.Template.Labels) {
+			plan.DataMigrations = append(plan.DataMigrations, change.LeavingNodes...)
+		}
+	}
+
+	return plan
+}
+
+// specChanged returns true if the expected StatefulSet specification differs from the actual one,
+// regardless of replicas and rolling update partition which are handled separately.
+func specChanged(actual appsv1.StatefulSet, expected appsv1.StatefulSet) bool {
+	normalized := *expected.DeepCopy()
+	nodespec.UpdateReplicas(&normalized, actual.Spec.Replicas)
+	if actual.Spec.UpdateStrategy.RollingUpdate != nil {
+		nodespec.UpdatePartition(&normalized, actual.Spec.UpdateStrategy.RollingUpdate.Partition)
+	}
+	return hash.GetTemplateHashLabel(normalized.Labels) != hash.GetTemplateHashLabel(actual.Labels)
+}
+
+// configChange returns the change between the actual and expected configuration of the given StatefulSet,
+// or nil if they do not differ.
+func configChange(ssetName string, actual settings.CanonicalConfig, expected settings.CanonicalConfig) *ConfigChange {
+	keys := actual.Diff(expected.CanonicalConfig, configKeysAdjustedOnReconcile)
+	if len(keys) == 0 {
+		return nil
+	}
+	change := ConfigChange{StatefulSet: ssetName, Keys: keys}
+	actualContent, actualErr := actual.Render()
+	expectedContent, expectedErr := expected.Render()
+	if actualErr == nil && expectedErr == nil {
+		if err := diff.NewDiffAsError(string(expectedContent), string(actualContent)); err != nil {
+			change.Diff = err.Error()
+		}
+	}
+	return &change
+}
@@ -0,0 +1,160 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package driver
+
+import (
+	"testing"
+
+	commonsettings "github.com/elastic/cloud-on-k8s/pkg/controller/common/settings"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/nodespec"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
+	"github.com/stretchr/testify/require"
+)
+
+func expectedResourcesFor(statefulSets ...sset.TestSset) nodespec.ResourcesList {
+	resources := make(nodespec.ResourcesList, 0, len(statefulSets))
+	for _, s := range statefulSets {
+		resources = append(resources, nodespec.Resources{
+			StatefulSet: s.Build(),
+			Config: settings.CanonicalConfig{CanonicalConfig: commonsettings.MustCanonicalConfig(map[string]string{
+				"node.name":                    "${POD_NAME}",
+				"discovery.zen.hosts_provider": "file",
+			})},
+		})
+	}
+	return resources
+}
+
+func Test_computePlan(t *testing.T) {
+	data := sset.TestSset{Name: "data", Version: "7.2.0", Replicas: 3, Partition: 3, Data: true}
+	master := sset.TestSset{Name: "master", Version: "7.2.0", Replicas: 3, Partition: 3, Master: true}
+	withReplicas := func(s sset.TestSset, replicas int32) sset.TestSset {
+		s.Replicas = replicas
+		s.Partition = replicas
+		return s
+	}
+	withVersion := func(s sset.TestSset, version string) sset.TestSset {
+		s.Version = version
+		return s
+	}
+
+	tests := []struct {
+		name          string
+		expected      nodespec.ResourcesList
+		actual        sset.StatefulSetList
+		actualConfigs map[string]settings.CanonicalConfig
+		want          Plan
+	}{
+		{
+			name:     "no change",
+			expected: expectedResourcesFor(data, master),
+			actual:   sset.StatefulSetList{data.Build(), master.Build()},
+			want:     Plan{},
+		},
+		{
+			name:     "no change but an ongoing rolling upgrade partition",
+			expected: expectedResourcesFor(data),
+			actual:   sset.StatefulSetList{sset.TestSset{Name: "data", Version: "7.2.0", Replicas: 3, Partition: 1, Data: true}.Build()},
+			want:     Plan{},
+		},
+		{
+			name:     "new StatefulSet",
+			expected: expectedResourcesFor(data, master),
+			actual:   sset.StatefulSetList{master.Build()},
+			want: Plan{
+				CreatedStatefulSets: []ReplicasChange{{StatefulSet: "data", To: 3}},
+			},
+		},
+		{
+			name:     "scale up",
+			expected: expectedResourcesFor(withReplicas(data, 5)),
+			actual:   sset.StatefulSetList{data.Build()},
+			want: Plan{
+				ScaleUps: []ReplicasChange{{StatefulSet: "data", From: 3, To: 5}},
+			},
+		},
+		{
+			name:     "data nodes scale down: data migration",
+			expected: expectedResourcesFor(withReplicas(data, 1)),
+			actual:   sset.StatefulSetList{data.Build()},
+			want: Plan{
+				ScaleDowns:     []ReplicasChange{{StatefulSet: "data", From: 3, To: 1, LeavingNodes: []string{"data-2", "data-1"}}},
+				DataMigrations: []string{"data-2", "data-1"},
+			},
+		},
+		{
+			name:     "master nodes StatefulSet removal: no data migration",
+			expected: expectedResourcesFor(data),
+			actual:   sset.StatefulSetList{data.Build(), master.Build()},
+			want: Plan{
+				RemovedStatefulSets: []ReplicasChange{{StatefulSet: "master", From: 3, To: 0, LeavingNodes: []string{"master-2", "master-1", "master-0"}}},
+			},
+		},
+		{
+			name:     "version upgrade: rolling restart",
+			expected: expectedResourcesFor(withVersion(data, "7.3.0")),
+			actual:   sset.StatefulSetList{data.Build()},
+			want: Plan{
+				RollingRestarts: []RollingRestart{{StatefulSet: "data", Pods: 3}},
+			},
+		},
+		{
+			name:     "version upgrade and scale down: leaving nodes are not restarted",
+			expected: expectedResourcesFor(withReplicas(withVersion(data, "7.3.0"), 2)),
+			actual:   sset.StatefulSetList{data.Build()},
+			want: Plan{
+				ScaleDowns:      []ReplicasChange{{StatefulSet: "data", From: 3, To: 2, LeavingNodes: []string{"data-2"}}},
+				RollingRestarts: []RollingRestart{{StatefulSet: "data", Pods: 2}},
+				DataMigrations:  []string{"data-2"},
+			},
+		},
+		{
+			name:     "configuration change, ignoring the master nodes settings",
+			expected: expectedResourcesFor(data),
+			actual:   sset.StatefulSetList{data.Build()},
+			actualConfigs: map[string]settings.CanonicalConfig{
+				"data": {CanonicalConfig: commonsettings.MustCanonicalConfig(map[string]interface{}{
+					"node.name":                          "data-node",
+					"discovery.zen.hosts_provider":       "file",
+					"discovery.zen.minimum_master_nodes": 2,
+				})},
+			},
+			want: Plan{
+				ConfigChanges: []ConfigChange{{StatefulSet: "data", Keys: []string{"node.name"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computePlan(tt.expected, tt.actual, tt.actualConfigs)
+			// detailed diffs are meant for humans
+			for i := range got.ConfigChanges {
+				require.NotEmpty(t, got.ConfigChanges[i].Diff)
+				got.ConfigChanges[i].Diff = ""
+			}
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.want.IsEmpty(), got.IsEmpty())
+		})
+	}
+}
+
+func TestPlan_String(t *testing.T) {
+	require.Equal(t, "No changes", Plan{}.String())
+	plan := Plan{
+		ScaleDowns:               []ReplicasChange{{StatefulSet: "data", From: 3, To: 2, LeavingNodes: []string{"data-2"}}},
+		RollingRestarts:          []RollingRestart{{StatefulSet: "data", Pods: 2}},
+		DataMigrations:           []string{"data-2"},
+		HTTPCertificatesReissued: true,
+	}
+	require.Equal(t,
+		"scale down StatefulSet data from 3 to 2 replicas, removing nodes data-2; "+
+			"migrate data away from nodes data-2; "+
+			"rolling restart of StatefulSet data: 2 pods restarted; "+
+			"reissue HTTP certificates",
+		plan.String(),
+	)
+	require.Equal(t, int32(2), plan.RestartedPods())
+}
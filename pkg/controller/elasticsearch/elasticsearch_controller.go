@@ -5,6 +5,7 @@
 package elasticsearch
 
 import (
+	"context"
 	"fmt"
 	"sync/atomic"
 	"time"
@@ -28,6 +29,7 @@ import (
 	elasticsearchv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates/http"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/finalizer"
@@ -38,6 +40,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/driver"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/monitoring"
 	esname "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/name"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/observer"
 	esreconcile "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/reconcile"
@@ -92,6 +95,13 @@ func addWatches(c controller.Controller, r *ReconcileElasticsearch) error {
 		return err
 	}
 
+	// Dynamically watch referenced monitoring clusters
+	if err := c.Watch(
+		&source.Kind{Type: &elasticsearchv1alpha1.Elasticsearch{}}, r.dynamicWatches.ElasticsearchClusters,
+	); err != nil {
+		return err
+	}
+
 	// Watch StatefulSets
 	if err := c.Watch(
 		&source.Kind{Type: &appsv1.StatefulSet{}}, &handler.EnqueueRequestForOwner{
@@ -187,6 +197,8 @@ func (r *ReconcileElasticsearch) Reconcile(request reconcile.Request) (reconcile
 	defer func() {
 		log.Info("End reconcile iteration", "iteration", currentIteration, "took", time.Since(iterationStartTime), "namespace", request.Namespace, "es_name", request.Name)
 	}()
+	ctx, span := reconciler.StartReconciliation(name, request)
+	defer span.Finish()
 
 	// Fetch the Elasticsearch instance
 	es := elasticsearchv1alpha1.Elasticsearch{}
@@ -195,6 +207,7 @@ func (r *ReconcileElasticsearch) Reconcile(request reconcile.Request) (reconcile
 		if apierrors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			certificates.DeleteExpirationMetrics(elasticsearchv1alpha1.Kind, request.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -223,7 +236,7 @@ func (r *ReconcileElasticsearch) Reconcile(request reconcile.Request) (reconcile
 	}
 
 	state := esreconcile.NewState(es)
-	results := r.internalReconcile(es, state)
+	results := r.internalReconcile(ctx, es, state)
 	err = r.updateStatus(es, state)
 	if err != nil {
 		if apierrors.IsConflict(err) {
@@ -232,20 +245,24 @@ func (r *ReconcileElasticsearch) Reconcile(request reconcile.Request) (reconcile
 		}
 		k8s.EmitErrorEvent(r.recorder, err, &es, events.EventReconciliationError, "Reconciliation error: %v", err)
 	}
-	return results.WithError(err).Aggregate()
+	result, err := results.WithError(err).Aggregate()
+	span.SetError(err)
+	return result, err
 }
 
 func (r *ReconcileElasticsearch) internalReconcile(
+	ctx context.Context,
 	es elasticsearchv1alpha1.Elasticsearch,
 	reconcileState *esreconcile.State,
 ) *reconciler.Results {
-	results := &reconciler.Results{}
+	results := reconciler.NewResults(ctx)
 
 	if err := r.finalizers.Handle(&es, r.finalizersFor(es)...); err != nil {
 		return results.WithError(err)
 	}
 
 	if es.IsMarkedForDeletion() {
+		certificates.DeleteExpirationMetrics(elasticsearchv1alpha1.Kind, k8s.ExtractNamespacedName(&es))
 		// resource will be deleted, nothing to reconcile
 		// pre-delete operations are handled by finalizers
 		return results
@@ -269,7 +286,8 @@ func (r *ReconcileElasticsearch) internalReconcile(
 		return results.WithError(fmt.Errorf("unsupported version: %s", ver))
 	}
 
-	return driver.NewDefaultDriver(driver.DefaultDriverParameters{
+	d := driver.NewDefaultDriver(driver.DefaultDriverParameters{
+		Context:            ctx,
 		OperatorParameters: r.Parameters,
 		ES:                 es,
 		ReconcileState:     reconcileState,
@@ -281,7 +299,24 @@ func (r *ReconcileElasticsearch) internalReconcile(
 		Observers:          r.esObservers,
 		DynamicWatches:     r.dynamicWatches,
 		SupportedVersions:  *supported,
-	}).Reconcile()
+	})
+
+	if common.IsDryRun(es.ObjectMeta) {
+		// only report the changes that would be applied
+		plan, err := d.Plan()
+		if err != nil {
+			return results.WithError(err)
+		}
+		log.Info("Dry-run mode, skipping reconciliation", "namespace", es.Namespace, "es_name", es.Name, "plan", plan.String())
+		for _, change := range plan.ConfigChanges {
+			log.V(1).Info("Planned configuration change", "namespace", es.Namespace, "es_name", es.Name,
+				"statefulset_name", change.StatefulSet, "diff", change.Diff)
+		}
+		r.recorder.Event(&es, corev1.EventTypeNormal, events.EventReasonPlan, plan.String())
+		return results
+	}
+
+	return d.Reconcile()
 }
 
 func (r *ReconcileElasticsearch) updateStatus(
@@ -309,5 +344,6 @@ func (r *ReconcileElasticsearch) finalizersFor(
 		r.esObservers.Finalizer(clusterName),
 		keystore.Finalizer(k8s.ExtractNamespacedName(&es), r.dynamicWatches, es.Kind()),
 		http.DynamicWatchesFinalizer(r.dynamicWatches, es.Name, esname.ESNamer),
+		monitoring.Finalizer(r.Client, r.dynamicWatches, clusterName),
 	}
 }
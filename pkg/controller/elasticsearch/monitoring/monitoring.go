@@ -0,0 +1,330 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package monitoring
+
+import (
+	"encoding/json"
+	"path"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates/http"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/driver"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	commonsettings "github.com/elastic/cloud-on-k8s/pkg/controller/common/settings"
+	commonuser "github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/volume"
+	esname "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/name"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/services"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
+	esvolume "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("monitoring")
+
+const (
+	// AssociationLabelName marks resources created for the monitoring of the named cluster.
+	AssociationLabelName = "monitoringassociation.k8s.elastic.co/name"
+	// AssociationLabelNamespace marks resources created for the monitoring of the named cluster.
+	AssociationLabelNamespace = "monitoringassociation.k8s.elastic.co/namespace"
+
+	// ExporterName is the name of the monitoring exporter shipping data to the monitoring cluster.
+	ExporterName = "elastic-monitoring"
+	// PasswordEnvVarName is the environment variable holding the password of the monitoring user.
+	PasswordEnvVarName = "MONITORING_ES_PASSWORD"
+
+	// userSuffix is used to name the monitoring user and the secret holding its password.
+	userSuffix = "monitoring-user"
+	// caSecretSuffix is used to name the secret holding a copy of the monitoring cluster CA.
+	caSecretSuffix = "monitoring-es-ca"
+
+	// LastAppliedExporterAnnotation records the monitoring cluster the exporter settings of a cluster point to.
+	LastAppliedExporterAnnotation = "elasticsearch.k8s.elastic.co/monitoring-exporter"
+)
+
+// Resources holds the resources needed by Elasticsearch nodes to ship monitoring data to the monitoring cluster.
+type Resources struct {
+	// Config contains the monitoring exporter settings.
+	Config *commonsettings.CanonicalConfig
+	// CAVolume contains the CA of the monitoring cluster, nil if the monitoring cluster does not use TLS.
+	CAVolume *volume.SecretVolume
+	// PasswordEnvVar exposes the password of the monitoring user, referenced in the exporter settings.
+	PasswordEnvVar corev1.EnvVar
+}
+
+// NewLabels returns the labels of the resources created for the monitoring of the given cluster.
+func NewLabels(es types.NamespacedName) map[string]string {
+	return map[string]string{
+		AssociationLabelName:      es.Name,
+		AssociationLabelNamespace: es.Namespace,
+	}
+}
+
+// NewUserLabelSelector selects the users created in the monitoring cluster for the given cluster.
+func NewUserLabelSelector(es types.NamespacedName) labels.Selector {
+	selector := NewLabels(es)
+	selector[common.TypeLabelName] = commonuser.UserType
+	return labels.SelectorFromSet(selector)
+}
+
+// Reconcile associates the given cluster with the monitoring cluster referenced in its spec, if any:
+// a user allowed to ship monitoring data is created in the monitoring cluster and the monitoring cluster CA
+// is copied into the namespace of the given cluster.
+// It returns the resources to include in the Elasticsearch pods, nil if there is no monitoring cluster
+// to ship data to, along with the status of the association.
+// As long as the monitoring cluster is not available, the exporter settings last applied are returned: changing them
+// would restart all nodes of the given cluster, which cannot be monitored in the meantime anyway.
+func Reconcile(d driver.Interface, es *v1alpha1.Elasticsearch) (*Resources, commonv1alpha1.AssociationStatus, error) {
+	esKey := k8s.ExtractNamespacedName(es)
+
+	if err := deleteOrphanedResources(d.K8sClient(), *es); err != nil {
+		return nil, commonv1alpha1.AssociationFailed, err
+	}
+
+	if !es.Spec.Monitoring.ElasticsearchRef.IsDefined() {
+		// stop watching any monitoring cluster previously referenced
+		removeWatches(d.DynamicWatches(), esKey)
+		return nil, commonv1alpha1.AssociationUnknown, updateLastAppliedExporter(d.K8sClient(), es, nil)
+	}
+
+	lastApplied, err := lastAppliedResources(*es)
+	if err != nil {
+		return nil, commonv1alpha1.AssociationFailed, err
+	}
+
+	monitoringKey := monitoringClusterKey(*es)
+	if err := addWatches(d.DynamicWatches(), esKey, monitoringKey, association.UserKey(es, userSuffix)); err != nil {
+		return lastApplied, commonv1alpha1.AssociationFailed, err
+	}
+
+	var monitoringES v1alpha1.Elasticsearch
+	if err := d.K8sClient().Get(monitoringKey, &monitoringES); err != nil {
+		if apierrors.IsNotFound(err) {
+			// not created yet, we'll be notified to reconcile once it is
+			log.Info("Monitoring cluster not found", "namespace", es.Namespace, "es_name", es.Name, "monitoring_cluster", monitoringKey)
+			d.Recorder().Eventf(es, corev1.EventTypeWarning, events.EventAssociationError, "Monitoring cluster %s not found", monitoringKey)
+			return lastApplied, commonv1alpha1.AssociationPending, nil
+		}
+		return lastApplied, commonv1alpha1.AssociationFailed, err
+	}
+
+	if !association.IsAllowed(monitoringES, es.Namespace) {
+		d.Recorder().Eventf(es, corev1.EventTypeWarning, events.EventAssociationForbidden,
+			"Monitoring cluster %s does not allow associations from namespace %s, see the %s annotation",
+			monitoringKey, es.Namespace, association.AllowedNamespacesAnnotation)
+		// revoke any access previously granted, the exporter keeps running without being able to authenticate
+		return lastApplied, commonv1alpha1.AssociationFailed, commonuser.DeleteUsers(d.K8sClient(), NewUserLabelSelector(esKey))
+	}
+
+	associationLabels := NewLabels(esKey)
+	if err := association.ReconcileEsUser(
+		d.K8sClient(),
+		d.Scheme(),
+		es,
+		associationLabels,
+		user.RemoteMonitoringAgentBuiltinRole,
+		userSuffix,
+		monitoringES,
+	); err != nil {
+		return lastApplied, commonv1alpha1.AssociationPending, err
+	}
+
+	caSecretName, err := association.ReconcileCASecret(d.K8sClient(), d.Scheme(), es, esname.ESNamer, monitoringKey, associationLabels, caSecretSuffix)
+	if err != nil {
+		return lastApplied, commonv1alpha1.AssociationPending, err
+	}
+	if monitoringES.Spec.HTTP.TLS.Enabled() && caSecretName == "" {
+		// the monitoring cluster CA is not there yet, we'll be notified to reconcile once it is
+		d.Recorder().Eventf(es, corev1.EventTypeWarning, events.EventAssociationError, "CA of monitoring cluster %s not found", monitoringKey)
+		return lastApplied, commonv1alpha1.AssociationPending, nil
+	}
+
+	exp := &exporter{URL: services.ExternalServiceURL(monitoringES), CASecretName: caSecretName}
+	if err := updateLastAppliedExporter(d.K8sClient(), es, exp); err != nil {
+		return lastApplied, commonv1alpha1.AssociationPending, err
+	}
+	return newResources(*es, *exp), commonv1alpha1.AssociationEstablished, nil
+}
+
+// Plan returns the resources Reconcile would return for the given cluster, without applying any change:
+// neither the monitoring user nor the copy of the monitoring cluster CA are created or updated.
+func Plan(c k8s.Client, es v1alpha1.Elasticsearch) (*Resources, error) {
+	if !es.Spec.Monitoring.ElasticsearchRef.IsDefined() {
+		return nil, nil
+	}
+
+	lastApplied, err := lastAppliedResources(es)
+	if err != nil {
+		return nil, err
+	}
+
+	monitoringKey := monitoringClusterKey(es)
+	var monitoringES v1alpha1.Elasticsearch
+	if err := c.Get(monitoringKey, &monitoringES); err != nil {
+		if apierrors.IsNotFound(err) {
+			return lastApplied, nil
+		}
+		return nil, err
+	}
+	if !association.IsAllowed(monitoringES, es.Namespace) {
+		return lastApplied, nil
+	}
+
+	// the CA is copied as soon as the monitoring cluster public CA secret exists
+	caSecretName := association.ElasticsearchCACertSecretName(&es, caSecretSuffix)
+	var monitoringCA corev1.Secret
+	if err := c.Get(http.PublicCertsSecretRef(esname.ESNamer, monitoringKey), &monitoringCA); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		caSecretName = ""
+	}
+	if monitoringES.Spec.HTTP.TLS.Enabled() && caSecretName == "" {
+		return lastApplied, nil
+	}
+	return newResources(es, exporter{URL: services.ExternalServiceURL(monitoringES), CASecretName: caSecretName}), nil
+}
+
+// monitoringClusterKey returns the namespaced name of the monitoring cluster of the given cluster,
+// which defaults to the namespace of the given cluster.
+func monitoringClusterKey(es v1alpha1.Elasticsearch) types.NamespacedName {
+	ref := es.Spec.Monitoring.ElasticsearchRef
+	if ref.Namespace == "" {
+		ref.Namespace = es.Namespace
+	}
+	return ref.NamespacedName()
+}
+
+// exporter holds what the exporter settings depend on, recorded in the LastAppliedExporterAnnotation of the
+// monitored cluster to keep them while the monitoring cluster is not available.
+type exporter struct {
+	// URL of the monitoring cluster.
+	URL string `json:"url"`
+	// CASecretName is the name of the copy of the monitoring cluster CA, empty if the monitoring cluster does not use TLS.
+	CASecretName string `json:"caSecretName,omitempty"`
+}
+
+// lastAppliedResources returns the resources last returned by Reconcile for the given cluster, nil if none.
+func lastAppliedResources(es v1alpha1.Elasticsearch) (*Resources, error) {
+	serialized, exists := es.Annotations[LastAppliedExporterAnnotation]
+	if !exists {
+		return nil, nil
+	}
+	var exp exporter
+	if err := json.Unmarshal([]byte(serialized), &exp); err != nil {
+		return nil, errors.Wrapf(err, "cannot parse annotation %s", LastAppliedExporterAnnotation)
+	}
+	return newResources(es, exp), nil
+}
+
+// updateLastAppliedExporter records the given exporter in the annotations of the given cluster, or removes the
+// annotation if the exporter is nil.
+// Only the annotations of the latest version of the cluster are updated, so that concurrent spec changes are not
+// overwritten with the in-memory copy being reconciled.
+func updateLastAppliedExporter(c k8s.Client, es *v1alpha1.Elasticsearch, exp *exporter) error {
+	var serialized []byte
+	if exp != nil {
+		var err error
+		if serialized, err = json.Marshal(exp); err != nil {
+			return err
+		}
+	}
+	current, exists := es.Annotations[LastAppliedExporterAnnotation]
+	if (exp == nil && !exists) || (exp != nil && exists && current == string(serialized)) {
+		return nil
+	}
+
+	var latest v1alpha1.Elasticsearch
+	if err := c.Get(k8s.ExtractNamespacedName(es), &latest); err != nil {
+		return err
+	}
+	if exp == nil {
+		delete(latest.Annotations, LastAppliedExporterAnnotation)
+	} else {
+		if latest.Annotations == nil {
+			latest.Annotations = make(map[string]string)
+		}
+		latest.Annotations[LastAppliedExporterAnnotation] = string(serialized)
+	}
+	if err := c.Update(&latest); err != nil {
+		return err
+	}
+	// keep the in-memory copy consistent with the updated metadata for the next updates
+	es.Annotations = latest.Annotations
+	es.ResourceVersion = latest.ResourceVersion
+	return nil
+}
+
+// newResources builds the exporter settings, CA volume and password environment variable
+// allowing the given cluster to ship monitoring data with the given exporter.
+func newResources(es v1alpha1.Elasticsearch, exp exporter) *Resources {
+	exporterPrefix := settings.XPackMonitoringExporters + "." + ExporterName + "."
+	cfg := map[string]interface{}{
+		settings.XPackMonitoringCollectionEnabled: true,
+		exporterPrefix + "type":                   "http",
+		exporterPrefix + "host":                   []string{exp.URL},
+		exporterPrefix + "auth.username":          association.UserKey(&es, userSuffix).Name,
+		// resolved by Elasticsearch from the environment, to keep the password out of the configuration
+		exporterPrefix + "auth.password": "${" + PasswordEnvVarName + "}",
+	}
+
+	var caVolume *volume.SecretVolume
+	if exp.CASecretName != "" {
+		secretVolume := volume.NewSecretVolumeWithMountPath(
+			exp.CASecretName,
+			esvolume.MonitoringCASecretVolumeName,
+			esvolume.MonitoringCASecretVolumeMountPath,
+		)
+		caVolume = &secretVolume
+		cfg[exporterPrefix+"ssl.certificate_authorities"] = []string{
+			path.Join(esvolume.MonitoringCASecretVolumeMountPath, certificates.CertFileName),
+		}
+	}
+
+	return &Resources{
+		Config:   commonsettings.MustCanonicalConfig(cfg),
+		CAVolume: caVolume,
+		PasswordEnvVar: corev1.EnvVar{
+			Name: PasswordEnvVarName,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: association.ClearTextSecretKeySelector(&es, userSuffix),
+			},
+		},
+	}
+}
+
+// deleteOrphanedResources deletes the resources created for the monitoring of the given cluster that are not needed
+// anymore, either because the monitoring cluster reference was removed or because it now targets another namespace.
+func deleteOrphanedResources(c k8s.Client, es v1alpha1.Elasticsearch) error {
+	var secrets corev1.SecretList
+	selector := labels.SelectorFromSet(NewLabels(k8s.ExtractNamespacedName(&es)))
+	if err := c.List(&client.ListOptions{LabelSelector: selector}, &secrets); err != nil {
+		return err
+	}
+
+	monitoringNamespace := monitoringClusterKey(es).Namespace
+	for _, s := range secrets.Items {
+		isUser := s.Labels[common.TypeLabelName] == commonuser.UserType
+		if !es.Spec.Monitoring.ElasticsearchRef.IsDefined() || (isUser && s.Namespace != monitoringNamespace) {
+			log.Info("Deleting secret", "namespace", s.Namespace, "secret_name", s.Name, "es_name", es.Name)
+			if err := c.Delete(&s); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package monitoring
+
+import (
+	"testing"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/driver"
+	commonsettings "github.com/elastic/cloud-on-k8s/pkg/controller/common/settings"
+	commonuser "github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var (
+	monitoredES = v1alpha1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "production"},
+		Spec: v1alpha1.ElasticsearchSpec{
+			Monitoring: v1alpha1.MonitoringSpec{
+				ElasticsearchRef: commonv1alpha1.ObjectSelector{Namespace: "monitoring-ns", Name: "monitoring"},
+			},
+		},
+	}
+	monitoringES = v1alpha1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "monitoring-ns",
+			Name:        "monitoring",
+			Annotations: map[string]string{association.AllowedNamespacesAnnotation: "ns"},
+		},
+	}
+	// forbiddenMonitoringES does not allow associations from the namespace of the monitored cluster
+	forbiddenMonitoringES = v1alpha1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "monitoring-ns", Name: "monitoring"},
+	}
+	monitoringCA = corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "monitoring-ns", Name: "monitoring-es-http-certs-public"},
+		Data:       map[string][]byte{"tls.crt": []byte("ca")},
+	}
+	monitoringUserKey = types.NamespacedName{Namespace: "monitoring-ns", Name: "ns-production-monitoring-user"}
+)
+
+// monitoringUser returns the user created in the monitoring cluster for the monitored cluster.
+func monitoringUser() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: monitoringUserKey.Namespace,
+			Name:      monitoringUserKey.Name,
+			Labels: map[string]string{
+				AssociationLabelName:      "production",
+				AssociationLabelNamespace: "ns",
+				common.TypeLabelName:      commonuser.UserType,
+			},
+		},
+	}
+}
+
+func withoutMonitoring(es v1alpha1.Elasticsearch) v1alpha1.Elasticsearch {
+	es.Spec.Monitoring = v1alpha1.MonitoringSpec{}
+	return es
+}
+
+// withLastAppliedExporter returns the given cluster, annotated as if it was already shipping data to the monitoring cluster.
+func withLastAppliedExporter(es v1alpha1.Elasticsearch) v1alpha1.Elasticsearch {
+	es.Annotations = map[string]string{LastAppliedExporterAnnotation: lastAppliedExporter}
+	return es
+}
+
+const lastAppliedExporter = `{"url":"https://monitoring-es-http.monitoring-ns.svc:9200","caSecretName":"production-monitoring-es-ca"}`
+
+func TestReconcile(t *testing.T) {
+	require.NoError(t, v1alpha1.AddToScheme(scheme.Scheme))
+
+	expectedConfig := commonsettings.MustCanonicalConfig(map[string]interface{}{
+		"xpack.monitoring.collection.enabled": true,
+		"xpack.monitoring.exporters.elastic-monitoring": map[string]interface{}{
+			"type":                        "http",
+			"host":                        []string{"https://monitoring-es-http.monitoring-ns.svc:9200"},
+			"auth.username":               "ns-production-monitoring-user",
+			"auth.password":               "${MONITORING_ES_PASSWORD}",
+			"ssl.certificate_authorities": []string{"/usr/share/elasticsearch/config/monitoring-es-ca/tls.crt"},
+		},
+	})
+
+	tests := []struct {
+		name           string
+		es             v1alpha1.Elasticsearch
+		initialObjects []runtime.Object
+		wantResources  bool
+		wantUser       bool
+		wantStatus     commonv1alpha1.AssociationStatus
+	}{
+		{
+			name:           "no monitoring cluster referenced",
+			es:             withoutMonitoring(monitoredES),
+			initialObjects: []runtime.Object{&monitoringES, &monitoringCA},
+			wantResources:  false,
+			wantUser:       false,
+			wantStatus:     commonv1alpha1.AssociationUnknown,
+		},
+		{
+			name:           "monitoring cluster not found",
+			es:             monitoredES,
+			initialObjects: nil,
+			wantResources:  false,
+			wantUser:       false,
+			wantStatus:     commonv1alpha1.AssociationPending,
+		},
+		{
+			name:           "monitoring cluster not found anymore: keep the last applied exporter",
+			es:             withLastAppliedExporter(monitoredES),
+			initialObjects: nil,
+			wantResources:  true,
+			wantUser:       false,
+			wantStatus:     commonv1alpha1.AssociationPending,
+		},
+		{
+			name:           "monitoring cluster CA not created yet",
+			es:             monitoredES,
+			initialObjects: []runtime.Object{&monitoringES},
+			wantResources:  false,
+			wantUser:       true,
+			wantStatus:     commonv1alpha1.AssociationPending,
+		},
+		{
+			name:           "monitoring cluster CA not found anymore: keep the last applied exporter",
+			es:             withLastAppliedExporter(monitoredES),
+			initialObjects: []runtime.Object{&monitoringES},
+			wantResources:  true,
+			wantUser:       true,
+			wantStatus:     commonv1alpha1.AssociationPending,
+		},
+		{
+			name:           "monitoring cluster referenced",
+			es:             monitoredES,
+			initialObjects: []runtime.Object{&monitoringES, &monitoringCA},
+			wantResources:  true,
+			wantUser:       true,
+			wantStatus:     commonv1alpha1.AssociationEstablished,
+		},
+		{
+			name:           "monitoring cluster reference removed: delete the monitoring user",
+			es:             withoutMonitoring(withLastAppliedExporter(monitoredES)),
+			initialObjects: []runtime.Object{&monitoringES, &monitoringCA, monitoringUser()},
+			wantResources:  false,
+			wantUser:       false,
+			wantStatus:     commonv1alpha1.AssociationUnknown,
+		},
+		{
+			name:           "monitoring cluster does not allow the namespace: delete the monitoring user",
+			es:             monitoredES,
+			initialObjects: []runtime.Object{&forbiddenMonitoringES, &monitoringCA, monitoringUser()},
+			wantResources:  false,
+			wantUser:       false,
+			wantStatus:     commonv1alpha1.AssociationFailed,
+		},
+		{
+			name:           "monitoring cluster does not allow the namespace anymore: keep the last applied exporter",
+			es:             withLastAppliedExporter(monitoredES),
+			initialObjects: []runtime.Object{&forbiddenMonitoringES, &monitoringCA, monitoringUser()},
+			wantResources:  true,
+			wantUser:       false,
+			wantStatus:     commonv1alpha1.AssociationFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := tt.es.DeepCopy()
+			c := k8s.WrapClient(fake.NewFakeClient(append(tt.initialObjects, es.DeepCopy())...))
+			testDriver := driver.TestDriver{
+				Client:        c,
+				RuntimeScheme: scheme.Scheme,
+				Watches:       watches.NewDynamicWatches(),
+				FakeRecorder:  record.NewFakeRecorder(100),
+			}
+			require.NoError(t, testDriver.Watches.InjectScheme(scheme.Scheme))
+
+			resources, status, err := Reconcile(testDriver, es)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatus, status)
+
+			var user corev1.Secret
+			err = c.Get(monitoringUserKey, &user)
+			if tt.wantUser {
+				require.NoError(t, err)
+				require.Equal(t, "remote_monitoring_agent", string(user.Data[commonuser.UserRoles]))
+			} else {
+				require.Error(t, err)
+			}
+
+			// the exporter is recorded once applied, and forgotten once the monitoring cluster reference is removed
+			var updated v1alpha1.Elasticsearch
+			require.NoError(t, c.Get(k8s.ExtractNamespacedName(es), &updated))
+			if tt.wantResources {
+				require.Equal(t, lastAppliedExporter, updated.Annotations[LastAppliedExporterAnnotation])
+			} else {
+				require.NotContains(t, updated.Annotations, LastAppliedExporterAnnotation)
+			}
+
+			if !tt.wantResources {
+				require.Nil(t, resources)
+				return
+			}
+			require.NotNil(t, resources)
+			expected, err := expectedConfig.Render()
+			require.NoError(t, err)
+			actual, err := resources.Config.Render()
+			require.NoError(t, err)
+			require.Equal(t, string(expected), string(actual))
+			require.NotNil(t, resources.CAVolume)
+			require.Equal(t, "production-monitoring-es-ca", resources.CAVolume.Volume().Secret.SecretName)
+			require.Equal(t, PasswordEnvVarName, resources.PasswordEnvVar.Name)
+			require.Equal(t, "production-monitoring-user", resources.PasswordEnvVar.ValueFrom.SecretKeyRef.Name)
+			require.Equal(t, "ns-production-monitoring-user", resources.PasswordEnvVar.ValueFrom.SecretKeyRef.Key)
+		})
+	}
+}
+
+func Test_updateLastAppliedExporter(t *testing.T) {
+	// the spec of the cluster was changed since it was read for this reconciliation
+	stored := monitoredES.DeepCopy()
+	stored.Spec.Version = "7.4.0"
+	c := k8s.WrapClient(fake.NewFakeClient(stored))
+	es := monitoredES.DeepCopy()
+	es.Spec.Version = "7.3.0"
+
+	exp := &exporter{URL: "https://monitoring-es-http.monitoring-ns.svc:9200", CASecretName: "production-monitoring-es-ca"}
+	require.NoError(t, updateLastAppliedExporter(c, es, exp))
+	require.Equal(t, lastAppliedExporter, es.Annotations[LastAppliedExporterAnnotation])
+
+	// only the annotation is updated
+	var updated v1alpha1.Elasticsearch
+	require.NoError(t, c.Get(k8s.ExtractNamespacedName(es), &updated))
+	require.Equal(t, lastAppliedExporter, updated.Annotations[LastAppliedExporterAnnotation])
+	require.Equal(t, "7.4.0", updated.Spec.Version)
+
+	require.NoError(t, updateLastAppliedExporter(c, es, nil))
+	require.NotContains(t, es.Annotations, LastAppliedExporterAnnotation)
+	require.NoError(t, c.Get(k8s.ExtractNamespacedName(es), &updated))
+	require.NotContains(t, updated.Annotations, LastAppliedExporterAnnotation)
+	require.Equal(t, "7.4.0", updated.Spec.Version)
+}
+
+func TestPlan(t *testing.T) {
+	require.NoError(t, v1alpha1.AddToScheme(scheme.Scheme))
+
+	tests := []struct {
+		name           string
+		es             v1alpha1.Elasticsearch
+		initialObjects []runtime.Object
+		wantResources  bool
+	}{
+		{
+			name:           "no monitoring cluster referenced",
+			es:             withoutMonitoring(monitoredES),
+			initialObjects: []runtime.Object{&monitoringES, &monitoringCA},
+			wantResources:  false,
+		},
+		{
+			name:           "monitoring cluster not found",
+			es:             monitoredES,
+			initialObjects: nil,
+			wantResources:  false,
+		},
+		{
+			name:           "monitoring cluster CA not created yet",
+			es:             monitoredES,
+			initialObjects: []runtime.Object{&monitoringES},
+			wantResources:  false,
+		},
+		{
+			name:           "monitoring cluster referenced",
+			es:             monitoredES,
+			initialObjects: []runtime.Object{&monitoringES, &monitoringCA},
+			wantResources:  true,
+		},
+		{
+			name:           "monitoring cluster does not allow the namespace",
+			es:             monitoredES,
+			initialObjects: []runtime.Object{&forbiddenMonitoringES, &monitoringCA},
+			wantResources:  false,
+		},
+		{
+			name:           "monitoring cluster not found anymore",
+			es:             withLastAppliedExporter(monitoredES),
+			initialObjects: nil,
+			wantResources:  true,
+		},
+		{
+			name:           "monitoring cluster does not allow the namespace anymore",
+			es:             withLastAppliedExporter(monitoredES),
+			initialObjects: []runtime.Object{&forbiddenMonitoringES, &monitoringCA},
+			wantResources:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := tt.es.DeepCopy()
+			c := k8s.WrapClient(fake.NewFakeClient(append(tt.initialObjects, es.DeepCopy())...))
+			resources, err := Plan(c, *es)
+			require.NoError(t, err)
+
+			// no resource should be created
+			var user corev1.Secret
+			require.Error(t, c.Get(monitoringUserKey, &user))
+
+			if !tt.wantResources {
+				require.Nil(t, resources)
+				return
+			}
+			// resources should match the reconciled ones
+			testDriver := driver.TestDriver{
+				Client:        c,
+				RuntimeScheme: scheme.Scheme,
+				Watches:       watches.NewDynamicWatches(),
+				FakeRecorder:  record.NewFakeRecorder(100),
+			}
+			require.NoError(t, testDriver.Watches.InjectScheme(scheme.Scheme))
+			reconciled, _, err := Reconcile(testDriver, es)
+			require.NoError(t, err)
+			require.NotNil(t, reconciled)
+			expected, err := reconciled.Config.Render()
+			require.NoError(t, err)
+			actual, err := resources.Config.Render()
+			require.NoError(t, err)
+			require.Equal(t, string(expected), string(actual))
+			require.Equal(t, reconciled.CAVolume, resources.CAVolume)
+			require.Equal(t, reconciled.PasswordEnvVar, resources.PasswordEnvVar)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package monitoring
+
+import (
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates/http"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/finalizer"
+	commonuser "github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	esname "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/name"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// monitoringClusterWatchName returns the name of the watch set up on the monitoring cluster of the given cluster.
+func monitoringClusterWatchName(es types.NamespacedName) string {
+	return es.Namespace + "-" + es.Name + "-monitoring-es-watch"
+}
+
+// monitoringSecretsWatchName returns the name of the watch set up on the monitoring cluster CA and on the
+// monitoring user of the given cluster.
+func monitoringSecretsWatchName(es types.NamespacedName) string {
+	return es.Namespace + "-" + es.Name + "-monitoring-secrets-watch"
+}
+
+// addWatches watches the monitoring cluster, its CA and the monitoring user created for the given cluster,
+// to reconcile the given cluster on any change.
+func addWatches(w watches.DynamicWatches, es types.NamespacedName, monitoringES types.NamespacedName, user types.NamespacedName) error {
+	if err := w.ElasticsearchClusters.AddHandler(watches.NamedWatch{
+		Name:    monitoringClusterWatchName(es),
+		Watched: []types.NamespacedName{monitoringES},
+		Watcher: es,
+	}); err != nil {
+		return err
+	}
+	return w.Secrets.AddHandler(watches.NamedWatch{
+		Name:    monitoringSecretsWatchName(es),
+		Watched: []types.NamespacedName{http.PublicCertsSecretRef(esname.ESNamer, monitoringES), user},
+		Watcher: es,
+	})
+}
+
+// removeWatches removes the watches set up for the monitoring of the given cluster.
+func removeWatches(w watches.DynamicWatches, es types.NamespacedName) {
+	w.ElasticsearchClusters.RemoveHandlerForKey(monitoringClusterWatchName(es))
+	w.Secrets.RemoveHandlerForKey(monitoringSecretsWatchName(es))
+}
+
+// Finalizer removes the watches set up for the monitoring of the given cluster, and the user created
+// for it in the monitoring cluster, which is not garbage collected along with the given cluster.
+func Finalizer(c k8s.Client, w watches.DynamicWatches, es types.NamespacedName) finalizer.Finalizer {
+	return finalizer.Finalizer{
+		Name: "monitoring.finalizers.elasticsearch.k8s.elastic.co",
+		Execute: func() error {
+			removeWatches(w, es)
+			return commonuser.DeleteUsers(c, NewUserLabelSelector(es))
+		},
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/volume"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/initcontainer"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/monitoring"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/name"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
 	esvolume "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
@@ -31,8 +32,9 @@ func BuildPodTemplateSpec(
 	nodeSpec v1alpha1.NodeSpec,
 	cfg settings.CanonicalConfig,
 	keystoreResources *keystore.Resources,
+	monitoringResources *monitoring.Resources,
 ) (corev1.PodTemplateSpec, error) {
-	volumes, volumeMounts := buildVolumes(es.Name, nodeSpec, keystoreResources)
+	volumes, volumeMounts := buildVolumes(es.Name, nodeSpec, keystoreResources, monitoringResources)
 	labels, err := buildLabels(es, cfg, nodeSpec, keystoreResources)
 	if err != nil {
 		return corev1.PodTemplateSpec{}, err
@@ -52,13 +54,18 @@ func BuildPodTemplateSpec(
 		return corev1.PodTemplateSpec{}, err
 	}
 
+	envVars := DefaultEnvVars(es.Spec.HTTP)
+	if monitoringResources != nil {
+		envVars = append(envVars, monitoringResources.PasswordEnvVar)
+	}
+
 	builder = builder.
 		WithResources(DefaultResources).
 		WithTerminationGracePeriod(DefaultTerminationGracePeriodSeconds).
 		WithPorts(DefaultContainerPorts).
 		WithReadinessProbe(*NewReadinessProbe()).
 		WithAffinity(DefaultAffinity(es.Name)).
-		WithEnv(DefaultEnvVars(es.Spec.HTTP)...).
+		WithEnv(envVars...).
 		WithVolumes(volumes...).
 		WithVolumeMounts(volumeMounts...).
 		WithLabels(labels).
@@ -12,6 +12,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/keystore"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/monitoring"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
@@ -34,7 +35,11 @@ func (l ResourcesList) StatefulSets() sset.StatefulSetList {
 	return ssetList
 }
 
-func BuildExpectedResources(es v1alpha1.Elasticsearch, keystoreResources *keystore.Resources) (ResourcesList, error) {
+func BuildExpectedResources(
+	es v1alpha1.Elasticsearch,
+	keystoreResources *keystore.Resources,
+	monitoringResources *monitoring.Resources,
+) (ResourcesList, error) {
 	nodesResources := make(ResourcesList, 0, len(es.Spec.Nodes))
 
 	for _, nodeSpec := range es.Spec.Nodes {
@@ -47,9 +52,15 @@ func BuildExpectedResources(es v1alpha1.Elasticsearch, keystoreResources *keysto
 		if err != nil {
 			return nil, err
 		}
+		if monitoringResources != nil {
+			// ship monitoring data to the monitoring cluster
+			if err := cfg.MergeWith(monitoringResources.Config); err != nil {
+				return nil, err
+			}
+		}
 
 		// build stateful set and associated headless service
-		statefulSet, err := BuildStatefulSet(es, nodeSpec, cfg, keystoreResources)
+		statefulSet, err := BuildStatefulSet(es, nodeSpec, cfg, keystoreResources, monitoringResources)
 		if err != nil {
 			return nil, err
 		}
@@ -6,13 +6,12 @@ package nodespec
 
 import (
 	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
-	"github.com/elastic/cloud-on-k8s/pkg/controller/common/defaults"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/hash"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/keystore"
+	esdefaults "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/defaults"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/name"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
-	esvolume "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -48,6 +47,7 @@ func BuildStatefulSet(
 	nodeSpec v1alpha1.NodeSpec,
 	cfg settings.CanonicalConfig,
 	keystoreResources *keystore.Resources,
+	monitoringResources *monitoring.Resources,
 ) (appsv1.StatefulSet, error) {
 	statefulSetName := name.StatefulSet(es.Name, nodeSpec.Name)
 
@@ -55,11 +55,9 @@ func BuildStatefulSet(
 	ssetSelector := label.NewStatefulSetLabels(k8s.ExtractNamespacedName(&es), statefulSetName)
 
 	// add default PVCs to the node spec
-	nodeSpec.VolumeClaimTemplates = defaults.AppendDefaultPVCs(
-		nodeSpec.VolumeClaimTemplates, nodeSpec.PodTemplate.Spec, esvolume.DefaultVolumeClaimTemplates...,
-	)
+	nodeSpec.VolumeClaimTemplates = esdefaults.VolumeClaimTemplates(nodeSpec)
 	// build pod template
-	podTemplate, err := BuildPodTemplateSpec(es, nodeSpec, cfg, keystoreResources)
+	podTemplate, err := BuildPodTemplateSpec(es, nodeSpec, cfg, keystoreResources, monitoringResources)
 	if err != nil {
 		return appsv1.StatefulSet{}, err
 	}
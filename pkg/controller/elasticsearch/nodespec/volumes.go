@@ -12,13 +12,19 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/keystore"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/volume"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/initcontainer"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/monitoring"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/name"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
 	esvolume "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
 )
 
-func buildVolumes(esName string, nodeSpec v1alpha1.NodeSpec, keystoreResources *keystore.Resources) ([]corev1.Volume, []corev1.VolumeMount) {
+func buildVolumes(
+	esName string,
+	nodeSpec v1alpha1.NodeSpec,
+	keystoreResources *keystore.Resources,
+	monitoringResources *monitoring.Resources,
+) ([]corev1.Volume, []corev1.VolumeMount) {
 
 	configVolume := settings.ConfigSecretVolume(name.StatefulSet(esName, nodeSpec.Name))
 	probeSecret := volume.NewSelectiveSecretVolumeWithMountPath(
@@ -75,6 +81,9 @@ func buildVolumes(esName string, nodeSpec v1alpha1.NodeSpec, keystoreResources *
 	if keystoreResources != nil {
 		volumes = append(volumes, keystoreResources.Volume)
 	}
+	if monitoringResources != nil && monitoringResources.CAVolume != nil {
+		volumes = append(volumes, monitoringResources.CAVolume.Volume())
+	}
 
 	volumeMounts := append(
 		initcontainer.PluginVolumes.EsContainerVolumeMounts(),
@@ -88,6 +97,9 @@ func buildVolumes(esName string, nodeSpec v1alpha1.NodeSpec, keystoreResources *
 		scriptsVolume.VolumeMount(),
 		configVolume.VolumeMount(),
 	)
+	if monitoringResources != nil && monitoringResources.CAVolume != nil {
+		volumeMounts = append(volumeMounts, monitoringResources.CAVolume.VolumeMount())
+	}
 
 	return volumes, volumeMounts
 }
@@ -21,11 +21,13 @@ type Manager struct {
 
 // NewManager returns a new manager
 func NewManager(settings Settings) *Manager {
-	return &Manager{
+	m := &Manager{
 		observers: make(map[types.NamespacedName]*Observer),
 		lock:      sync.RWMutex{},
 		settings:  settings,
 	}
+	m.listeners = []OnObservation{m.reportObservationMetrics}
+	return m
 }
 
 // ObservedStateResolver returns the last known state of the given cluster,
@@ -57,10 +59,10 @@ func (m *Manager) Observe(cluster types.NamespacedName, esClient client.Client)
 // and create/replace its entry in the observers map
 func (m *Manager) createObserver(cluster types.NamespacedName, esClient client.Client) *Observer {
 	observer := NewObserver(cluster, esClient, m.settings, m.notifyListeners)
-	observer.Start()
 	m.lock.Lock()
 	m.observers[cluster] = observer
 	m.lock.Unlock()
+	observer.Start()
 	return observer
 }
 
@@ -77,6 +79,7 @@ func (m *Manager) StopObserving(cluster types.NamespacedName) {
 	m.lock.Lock()
 	delete(m.observers, cluster)
 	m.lock.Unlock()
+	deleteObservationMetrics(cluster, observer.LastState())
 }
 
 // List returns the names of clusters currently observed
@@ -100,6 +103,18 @@ func (m *Manager) AddObservationListener(listener OnObservation) {
 	m.listeners = append(m.listeners, listener)
 }
 
+// reportObservationMetrics is an OnObservation listener exporting metrics for the observed clusters.
+func (m *Manager) reportObservationMetrics(cluster types.NamespacedName, previousState State, newState State) {
+	m.lock.RLock()
+	_, observed := m.observers[cluster]
+	m.lock.RUnlock()
+	if !observed {
+		// metrics have been deleted along with the observer
+		return
+	}
+	reportObservationMetrics(cluster, previousState, newState)
+}
+
 // notifyListeners notifies all listeners that an observation occurred.
 func (m *Manager) notifyListeners(cluster types.NamespacedName, previousState State, newState State) {
 	wg := sync.WaitGroup{}
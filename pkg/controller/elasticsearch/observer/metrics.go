@@ -0,0 +1,180 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package observer
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "elastic"
+	metricsSubsystem = "elasticsearch"
+
+	// UnknownHealth is the health reported for clusters whose health could not be observed.
+	UnknownHealth = "unknown"
+
+	// requests performed during an observation, as reported in the errors metric
+	clusterStateRequest = "cluster_state"
+	healthRequest       = "health"
+	licenseRequest      = "license"
+)
+
+var (
+	allHealths     = []string{"green", "yellow", "red", UnknownHealth}
+	allShardStates = []string{"active", "relocating", "initializing", "unassigned"}
+	allRequests    = []string{clusterStateRequest, healthRequest, licenseRequest}
+
+	healthGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "cluster_health",
+		Help:      "Observed health of the Elasticsearch cluster: 1 for the current health status, 0 for the others",
+	}, []string{"namespace", "name", "status"})
+
+	nodesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "cluster_nodes",
+		Help:      "Observed number of nodes in the Elasticsearch cluster",
+	}, []string{"namespace", "name"})
+
+	shardsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "cluster_shards",
+		Help:      "Observed number of shards in the Elasticsearch cluster, by state",
+	}, []string{"namespace", "name", "state"})
+
+	masterNodeGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "cluster_master_node",
+		Help:      "Observed master node of the Elasticsearch cluster, always 1",
+	}, []string{"namespace", "name", "node"})
+
+	healthTransitionsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "cluster_health_transitions_total",
+		Help:      "Number of observed health transitions of the Elasticsearch cluster",
+	}, []string{"namespace", "name", "from", "to"})
+
+	observationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "observation_duration_seconds",
+		Help:      "Duration of the observations of the Elasticsearch cluster",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"namespace", "name"})
+
+	observationErrorsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "observation_errors_total",
+		Help:      "Number of failed requests while observing the Elasticsearch cluster, by request",
+	}, []string{"namespace", "name", "request"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		healthGauge,
+		nodesGauge,
+		shardsGauge,
+		masterNodeGauge,
+		healthTransitionsCounter,
+		observationDuration,
+		observationErrorsCounter,
+	)
+}
+
+// health returns the observed health status, or UnknownHealth if not observed.
+func health(state State) string {
+	if state.ClusterHealth == nil || state.ClusterHealth.Status == "" {
+		return UnknownHealth
+	}
+	return state.ClusterHealth.Status
+}
+
+// masterNode returns the name of the observed master node, or an empty string if not observed.
+func masterNode(state State) string {
+	if state.ClusterState == nil {
+		return ""
+	}
+	return state.ClusterState.MasterNodeName()
+}
+
+// reportObservationMetrics exports the observed state of the cluster, and records its health transitions.
+func reportObservationMetrics(cluster types.NamespacedName, previous State, new State) {
+	previousHealth, newHealth := health(previous), health(new)
+	for _, h := range allHealths {
+		value := 0.0
+		if h == newHealth {
+			value = 1
+		}
+		healthGauge.WithLabelValues(cluster.Namespace, cluster.Name, h).Set(value)
+	}
+	if previousHealth != newHealth {
+		log.Info("Cluster health changed",
+			"namespace", cluster.Namespace, "es_name", cluster.Name, "from", previousHealth, "to", newHealth)
+		healthTransitionsCounter.WithLabelValues(cluster.Namespace, cluster.Name, previousHealth, newHealth).Inc()
+	}
+
+	if h := new.ClusterHealth; h != nil {
+		nodesGauge.WithLabelValues(cluster.Namespace, cluster.Name).Set(float64(h.NumberOfNodes))
+		shardsGauge.WithLabelValues(cluster.Namespace, cluster.Name, "active").Set(float64(h.ActiveShards))
+		shardsGauge.WithLabelValues(cluster.Namespace, cluster.Name, "relocating").Set(float64(h.RelocatingShards))
+		shardsGauge.WithLabelValues(cluster.Namespace, cluster.Name, "initializing").Set(float64(h.InitializingShards))
+		shardsGauge.WithLabelValues(cluster.Namespace, cluster.Name, "unassigned").Set(float64(h.UnassignedShards))
+	} else {
+		// do not report stale values
+		nodesGauge.DeleteLabelValues(cluster.Namespace, cluster.Name)
+		for _, s := range allShardStates {
+			shardsGauge.DeleteLabelValues(cluster.Namespace, cluster.Name, s)
+		}
+	}
+
+	previousMaster, newMaster := masterNode(previous), masterNode(new)
+	if previousMaster != "" && previousMaster != newMaster {
+		masterNodeGauge.DeleteLabelValues(cluster.Namespace, cluster.Name, previousMaster)
+	}
+	if newMaster != "" {
+		masterNodeGauge.WithLabelValues(cluster.Namespace, cluster.Name, newMaster).Set(1)
+	}
+}
+
+// reportObservationDuration exports the duration of an observation of the given cluster.
+func reportObservationDuration(cluster types.NamespacedName, duration time.Duration) {
+	observationDuration.WithLabelValues(cluster.Namespace, cluster.Name).Observe(duration.Seconds())
+}
+
+// reportObservationError counts a failed request while observing the given cluster.
+func reportObservationError(cluster types.NamespacedName, request string) {
+	observationErrorsCounter.WithLabelValues(cluster.Namespace, cluster.Name, request).Inc()
+}
+
+// deleteObservationMetrics stops exporting the metrics of the given cluster, once it is not observed anymore.
+func deleteObservationMetrics(cluster types.NamespacedName, lastState State) {
+	for _, h := range allHealths {
+		healthGauge.DeleteLabelValues(cluster.Namespace, cluster.Name, h)
+		for _, to := range allHealths {
+			healthTransitionsCounter.DeleteLabelValues(cluster.Namespace, cluster.Name, h, to)
+		}
+	}
+	nodesGauge.DeleteLabelValues(cluster.Namespace, cluster.Name)
+	for _, s := range allShardStates {
+		shardsGauge.DeleteLabelValues(cluster.Namespace, cluster.Name, s)
+	}
+	if master := masterNode(lastState); master != "" {
+		masterNodeGauge.DeleteLabelValues(cluster.Namespace, cluster.Name, master)
+	}
+	observationDuration.DeleteLabelValues(cluster.Namespace, cluster.Name)
+	for _, r := range allRequests {
+		observationErrorsCounter.DeleteLabelValues(cluster.Namespace, cluster.Name, r)
+	}
+}
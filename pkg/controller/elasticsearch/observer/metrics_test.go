@@ -0,0 +1,107 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package observer
+
+import (
+	"testing"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// metricValues returns the values of the given collector for the given cluster, indexed by the value of the given label.
+func metricValues(t *testing.T, collector prometheus.Collector, cluster types.NamespacedName, label string) map[string]float64 {
+	metricsCh := make(chan prometheus.Metric, 100)
+	go func() {
+		collector.Collect(metricsCh)
+		close(metricsCh)
+	}()
+	values := map[string]float64{}
+	for m := range metricsCh {
+		var metric dto.Metric
+		require.NoError(t, m.Write(&metric))
+		labels := map[string]string{}
+		for _, l := range metric.Label {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if labels["namespace"] != cluster.Namespace || labels["name"] != cluster.Name {
+			continue
+		}
+		switch {
+		case metric.Gauge != nil:
+			values[labels[label]] = metric.Gauge.GetValue()
+		case metric.Counter != nil:
+			values[labels[label]] = metric.Counter.GetValue()
+		}
+	}
+	return values
+}
+
+func stateWith(status string, master string) State {
+	return State{
+		ClusterHealth: &client.Health{
+			Status:             status,
+			NumberOfNodes:      3,
+			ActiveShards:       10,
+			RelocatingShards:   1,
+			InitializingShards: 2,
+			UnassignedShards:   3,
+		},
+		ClusterState: &client.ClusterState{
+			MasterNode: "id-" + master,
+			Nodes: map[string]client.ClusterStateNode{
+				"id-" + master: {Name: master},
+			},
+		},
+	}
+}
+
+func Test_reportObservationMetrics(t *testing.T) {
+	es := cluster("metrics-test")
+
+	// first observation
+	reportObservationMetrics(es, State{}, stateWith("green", "node-0"))
+	require.Equal(t, map[string]float64{"green": 1, "yellow": 0, "red": 0, UnknownHealth: 0},
+		metricValues(t, healthGauge, es, "status"))
+	require.Equal(t, map[string]float64{"": 3}, metricValues(t, nodesGauge, es, ""))
+	require.Equal(t, map[string]float64{"active": 10, "relocating": 1, "initializing": 2, "unassigned": 3},
+		metricValues(t, shardsGauge, es, "state"))
+	require.Equal(t, map[string]float64{"node-0": 1}, metricValues(t, masterNodeGauge, es, "node"))
+	require.Equal(t, map[string]float64{"green": 1}, metricValues(t, healthTransitionsCounter, es, "to"))
+
+	// health and master change
+	reportObservationMetrics(es, stateWith("green", "node-0"), stateWith("yellow", "node-1"))
+	require.Equal(t, map[string]float64{"green": 0, "yellow": 1, "red": 0, UnknownHealth: 0},
+		metricValues(t, healthGauge, es, "status"))
+	require.Equal(t, map[string]float64{"node-1": 1}, metricValues(t, masterNodeGauge, es, "node"))
+	require.Equal(t, map[string]float64{"green": 1, "yellow": 1}, metricValues(t, healthTransitionsCounter, es, "to"))
+
+	// same health: no new transition
+	reportObservationMetrics(es, stateWith("yellow", "node-1"), stateWith("yellow", "node-1"))
+	require.Equal(t, map[string]float64{"green": 1, "yellow": 1}, metricValues(t, healthTransitionsCounter, es, "to"))
+
+	// cluster unreachable: no stale values
+	reportObservationMetrics(es, stateWith("yellow", "node-1"), State{})
+	require.Equal(t, map[string]float64{"green": 0, "yellow": 0, "red": 0, UnknownHealth: 1},
+		metricValues(t, healthGauge, es, "status"))
+	require.Empty(t, metricValues(t, nodesGauge, es, ""))
+	require.Empty(t, metricValues(t, shardsGauge, es, "state"))
+	require.Empty(t, metricValues(t, masterNodeGauge, es, "node"))
+	require.Equal(t, float64(1), metricValues(t, healthTransitionsCounter, es, "to")[UnknownHealth])
+
+	// cluster not observed anymore
+	reportObservationMetrics(es, State{}, stateWith("green", "node-0"))
+	reportObservationError(es, healthRequest)
+	deleteObservationMetrics(es, stateWith("green", "node-0"))
+	require.Empty(t, metricValues(t, healthGauge, es, "status"))
+	require.Empty(t, metricValues(t, nodesGauge, es, ""))
+	require.Empty(t, metricValues(t, shardsGauge, es, "state"))
+	require.Empty(t, metricValues(t, masterNodeGauge, es, "node"))
+	require.Empty(t, metricValues(t, healthTransitionsCounter, es, "to"))
+	require.Empty(t, metricValues(t, observationErrorsCounter, es, "request"))
+}
@@ -131,7 +131,9 @@ func (o *Observer) retrieveState(ctx context.Context) {
 	timeoutCtx, cancel := context.WithTimeout(ctx, o.settings.RequestTimeout)
 	defer cancel()
 
+	start := time.Now()
 	newState := RetrieveState(timeoutCtx, o.cluster, o.esClient)
+	reportObservationDuration(o.cluster, time.Since(start))
 
 	if o.onObservation != nil {
 		o.onObservation(o.cluster, o.LastState(), newState)
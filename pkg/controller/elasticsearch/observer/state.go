@@ -37,6 +37,7 @@ func RetrieveState(ctx context.Context, cluster types.NamespacedName, esClient e
 		if err != nil {
 			// This is expected to happen from time to time
 			log.V(1).Info("Unable to retrieve cluster state", "error", err, "namespace", cluster.Namespace, "es_name", cluster.Name)
+			reportObservationError(cluster, clusterStateRequest)
 			clusterStateChan <- nil
 			return
 		}
@@ -47,6 +48,7 @@ func RetrieveState(ctx context.Context, cluster types.NamespacedName, esClient e
 		health, err := esClient.GetClusterHealth(ctx)
 		if err != nil {
 			log.V(1).Info("Unable to retrieve cluster health", "error", err, "namespace", cluster.Namespace, "es_name", cluster.Name)
+			reportObservationError(cluster, healthRequest)
 			healthChan <- nil
 			return
 		}
@@ -57,6 +59,7 @@ func RetrieveState(ctx context.Context, cluster types.NamespacedName, esClient e
 		license, err := esClient.GetLicense(ctx)
 		if err != nil {
 			log.V(1).Info("Unable to retrieve cluster license", "error", err, "namespace", cluster.Namespace, "es_name", cluster.Name)
+			reportObservationError(cluster, licenseRequest)
 			licenseChan <- nil
 			return
 		}
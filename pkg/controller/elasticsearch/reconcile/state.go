@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"reflect"
 
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
 	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/validation"
@@ -58,6 +59,9 @@ func (s *State) updateWithPhase(
 	if observedState.ClusterHealth != nil && observedState.ClusterHealth.Status != "" {
 		s.status.Health = v1alpha1.ElasticsearchHealth(observedState.ClusterHealth.Status)
 	}
+	if observedState.ClusterHealth != nil {
+		s.status.ActivePrimaryShards = observedState.ClusterHealth.ActivePrimaryShards
+	}
 	return s
 }
 
@@ -111,6 +115,11 @@ func (s *State) GetZen1MinimumMasterNodes() int {
 	return s.status.ZenDiscovery.MinimumMasterNodes
 }
 
+// UpdateMonitoringAssociation updates the status of the association with the monitoring cluster.
+func (s *State) UpdateMonitoringAssociation(status commonv1alpha1.AssociationStatus) {
+	s.status.MonitoringAssociation = status
+}
+
 // Apply takes the current Elasticsearch status, compares it to the previous status, and updates the status accordingly.
 // It returns the events to emit and an updated version of the Elasticsearch cluster resource with
 // the current status applied to its status sub-resource.
@@ -408,6 +408,29 @@ func TestState_UpdateElasticsearchState(t *testing.T) {
 
 			},
 		},
+		{
+			name: "active primary shards are kept if Elasticsearch is not reachable",
+			cluster: v1alpha1.Elasticsearch{
+				Status: v1alpha1.ElasticsearchStatus{ActivePrimaryShards: 5},
+			},
+			stateAssertions: func(s *State) {
+				assert.Equal(t, 5, s.status.ActivePrimaryShards)
+			},
+		},
+		{
+			name: "active primary shards are set if returned by Elasticsearch",
+			cluster: v1alpha1.Elasticsearch{
+				Status: v1alpha1.ElasticsearchStatus{ActivePrimaryShards: 5},
+			},
+			args: args{
+				observedState: observer.State{
+					ClusterHealth: &client.Health{Status: "green", ActivePrimaryShards: 10},
+				},
+			},
+			stateAssertions: func(s *State) {
+				assert.Equal(t, 10, s.status.ActivePrimaryShards)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -19,6 +19,9 @@ const (
 	PathData = "path.data"
 	PathLogs = "path.logs"
 
+	XPackMonitoringCollectionEnabled = "xpack.monitoring.collection.enabled"
+	XPackMonitoringExporters         = "xpack.monitoring.exporters"
+
 	XPackSecurityAuthcReservedRealmEnabled          = "xpack.security.authc.reserved_realm.enabled"
 	XPackSecurityEnabled                            = "xpack.security.enabled"
 	XPackSecurityHttpSslCertificate                 = "xpack.security.http.ssl.certificate"
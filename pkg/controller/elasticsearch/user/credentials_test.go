@@ -145,3 +145,27 @@ func TestNeedsUpdate(t *testing.T) {
 		assert.Equal(t, tt.needsUpdate, tt.subject1.NeedsUpdate(tt.subject2.Secret()), tt.desc)
 	}
 }
+
+func Test_getRolesFileBytes(t *testing.T) {
+	roles := map[string]client.Role{
+		"probe": {Cluster: []string{"monitor"}},
+		"reader": {
+			Indices: []client.IndicesPrivileges{{Names: []string{"logs-*"}, Privileges: []string{"read"}}},
+			RunAs:   []string{"other"},
+		},
+	}
+	bytes, err := getRolesFileBytes(roles)
+	assert.NoError(t, err)
+	assert.Equal(t, `probe:
+  cluster:
+  - monitor
+reader:
+  indices:
+  - names:
+    - logs-*
+    privileges:
+    - read
+  run_as:
+  - other
+`, string(bytes))
+}
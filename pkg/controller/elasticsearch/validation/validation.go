@@ -12,13 +12,17 @@ import (
 )
 
 const (
-	cfgInvalidMsg            = "configuration invalid"
-	masterRequiredMsg        = "Elasticsearch needs to have at least one master node"
-	parseVersionErrMsg       = "Cannot parse Elasticsearch version"
-	parseStoredVersionErrMsg = "Cannot parse current Elasticsearch version"
-	invalidSanIPErrMsg       = "invalid SAN IP address"
-	pvcImmutableMsg          = "Volume claim templates cannot be modified"
-	invalidNamesErrMsg       = "Elasticsearch configuration would generate resources with invalid names"
+	cfgInvalidMsg             = "configuration invalid"
+	masterRequiredMsg         = "Elasticsearch needs to have at least one master node"
+	masterQuorumLossMsg       = "Cannot remove more than half of the master nodes in a single update"
+	masterRenameMsg           = "Master node specs cannot be renamed"
+	dataRequiredMsg           = "Elasticsearch needs to have at least one data node while indices exist"
+	parseVersionErrMsg        = "Cannot parse Elasticsearch version"
+	parseStoredVersionErrMsg  = "Cannot parse current Elasticsearch version"
+	invalidSanIPErrMsg        = "invalid SAN IP address"
+	invalidChangeBudgetErrMsg = "invalid change budget"
+	pvcImmutableMsg           = "Volume claim templates cannot be modified"
+	invalidNamesErrMsg        = "Elasticsearch configuration would generate resources with invalid names"
 )
 
 // Validation is a function from a currently stored Elasticsearch spec and proposed new spec
@@ -15,6 +15,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
 	common "github.com/elastic/cloud-on-k8s/pkg/controller/common/settings"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/validation"
+	esdefaults "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/defaults"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/name"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
 	esversion "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/version"
@@ -26,11 +27,15 @@ import (
 var Validations = []Validation{
 	validName,
 	hasMaster,
+	noMasterQuorumLoss,
+	noMasterRename,
+	noDataNodesRemoval,
 	supportedVersion,
 	noDowngrades,
 	validUpgradePath,
 	noBlacklistedSettings,
 	validSanIP,
+	validChangeBudget,
 	pvcModification,
 }
 
@@ -69,6 +74,115 @@ func hasMaster(ctx Context) validation.Result {
 	return validation.Result{Reason: masterRequiredMsg}
 }
 
+// noMasterQuorumLoss checks that an update does not remove more than half of the current master-eligible nodes at once,
+// which could leave the cluster without a quorum of masters.
+func noMasterQuorumLoss(ctx Context) validation.Result {
+	if ctx.isCreate() {
+		return validation.OK
+	}
+	current, err := countNodes(ctx.Current.Elasticsearch, isMaster)
+	if err != nil {
+		// nothing to compare against, invalid configurations are reported by other validations
+		return validation.OK
+	}
+	proposed, err := countNodes(ctx.Proposed.Elasticsearch, isMaster)
+	if err != nil {
+		return validation.OK
+	}
+	if removed := current - proposed; 2*removed > current {
+		return validation.Result{
+			Reason: fmt.Sprintf("%s: %d out of %d", masterQuorumLossMsg, removed, current),
+		}
+	}
+	return validation.OK
+}
+
+// noMasterRename checks that master node specs are not renamed: the nodes of the renamed node spec would all be
+// replaced in a single update.
+func noMasterRename(ctx Context) validation.Result {
+	if ctx.isCreate() {
+		return validation.OK
+	}
+	currentMasters, err := masterNodeSpecNames(ctx.Current.Elasticsearch)
+	if err != nil {
+		return validation.OK
+	}
+	proposedMasters, err := masterNodeSpecNames(ctx.Proposed.Elasticsearch)
+	if err != nil {
+		return validation.OK
+	}
+	var removed, added []string
+	for _, name := range currentMasters {
+		if getNode(name, ctx.Proposed.Elasticsearch) == nil {
+			removed = append(removed, name)
+		}
+	}
+	for _, name := range proposedMasters {
+		if getNode(name, ctx.Current.Elasticsearch) == nil {
+			added = append(added, name)
+		}
+	}
+	if len(removed) > 0 && len(added) > 0 {
+		return validation.Result{
+			Reason: fmt.Sprintf("%s: %s to %s", masterRenameMsg, strings.Join(removed, ", "), strings.Join(added, ", ")),
+		}
+	}
+	return validation.OK
+}
+
+// noDataNodesRemoval checks that an update does not remove all data nodes of a cluster holding indices.
+func noDataNodesRemoval(ctx Context) validation.Result {
+	if ctx.isCreate() || ctx.Current.Elasticsearch.Status.ActivePrimaryShards == 0 {
+		return validation.OK
+	}
+	proposed, err := countNodes(ctx.Proposed.Elasticsearch, isData)
+	if err != nil {
+		return validation.OK
+	}
+	if proposed == 0 {
+		return validation.Result{Reason: dataRequiredMsg}
+	}
+	return validation.OK
+}
+
+func isMaster(cfg v1alpha1.ElasticsearchSettings) bool {
+	return cfg.Node.Master
+}
+
+func isData(cfg v1alpha1.ElasticsearchSettings) bool {
+	return cfg.Node.Data
+}
+
+// countNodes returns the number of nodes of the given cluster whose configuration matches the given predicate.
+func countNodes(es v1alpha1.Elasticsearch, predicate func(cfg v1alpha1.ElasticsearchSettings) bool) (int32, error) {
+	var count int32
+	for _, n := range es.Spec.Nodes {
+		cfg, err := v1alpha1.UnpackConfig(n.Config)
+		if err != nil {
+			return 0, err
+		}
+		if predicate(cfg) {
+			count += n.NodeCount
+		}
+	}
+	return count, nil
+}
+
+// masterNodeSpecNames returns the names of the node specs of the given cluster with at least one master node.
+func masterNodeSpecNames(es v1alpha1.Elasticsearch) ([]string, error) {
+	var names []string
+	for _, n := range es.Spec.Nodes {
+		cfg, err := v1alpha1.UnpackConfig(n.Config)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Node.Master && n.NodeCount > 0 {
+			names = append(names, n.Name)
+		}
+	}
+	return names, nil
+}
+
 func noBlacklistedSettings(ctx Context) validation.Result {
 	violations := make(map[int]set.StringSet)
 	for i, n := range ctx.Proposed.Elasticsearch.Spec.Nodes {
@@ -141,6 +255,20 @@ func validSanIP(ctx Context) validation.Result {
 	return validation.OK
 }
 
+// validChangeBudget checks that the change budget, possibly set in percentages through v1beta1, can be resolved
+// against the number of nodes.
+func validChangeBudget(ctx Context) validation.Result {
+	if _, err := ctx.Proposed.Elasticsearch.ResolveChangeBudget(); err != nil {
+		msg := fmt.Sprintf("%s: %s", invalidChangeBudgetErrMsg, err)
+		return validation.Result{
+			Error:   errors.New(msg),
+			Reason:  msg,
+			Allowed: false,
+		}
+	}
+	return validation.OK
+}
+
 // pvcModification ensures no PVCs are changed, as volume claim templates are immutable in stateful sets
 func pvcModification(ctx Context) validation.Result {
 	if ctx.Current == nil {
@@ -155,7 +283,8 @@ func pvcModification(ctx Context) validation.Result {
 
 		// ssets do not allow modifications to fields other than 'replicas', 'template', and 'updateStrategy'
 		// reflection isn't ideal, but okay here since the ES object does not have the status of the claims
-		if !reflect.DeepEqual(node.VolumeClaimTemplates, currNode.VolumeClaimTemplates) {
+		// claims are compared with defaults applied, since resources stored before defaulting may lack them
+		if !reflect.DeepEqual(esdefaults.VolumeClaimTemplates(node), esdefaults.VolumeClaimTemplates(*currNode)) {
 			return validation.Result{
 				Allowed: false,
 				Reason:  pvcImmutableMsg,
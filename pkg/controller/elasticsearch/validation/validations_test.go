@@ -21,6 +21,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/validation"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -445,6 +446,59 @@ func Test_validSanIP(t *testing.T) {
 	}
 }
 
+func Test_validChangeBudget(t *testing.T) {
+	withAnnotation := func(value string) estype.Elasticsearch {
+		return estype.Elasticsearch{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{estype.ChangeBudgetAnnotation: value},
+			},
+			Spec: estype.ElasticsearchSpec{
+				Version: "7.2.0",
+				Nodes:   []estype.NodeSpec{{Name: "default", NodeCount: 3}},
+			},
+		}
+	}
+	tests := []struct {
+		name        string
+		esCluster   estype.Elasticsearch
+		wantAllowed bool
+	}{
+		{
+			name: "no change budget: OK",
+			esCluster: estype.Elasticsearch{
+				Spec: estype.ElasticsearchSpec{Version: "7.2.0"},
+			},
+			wantAllowed: true,
+		},
+		{
+			name:        "percentages: OK",
+			esCluster:   withAnnotation(`{"maxUnavailable":"50%","maxSurge":"10%"}`),
+			wantAllowed: true,
+		},
+		{
+			name:        "invalid percentage: NOT OK",
+			esCluster:   withAnnotation(`{"maxUnavailable":"half"}`),
+			wantAllowed: false,
+		},
+		{
+			name:        "invalid annotation: NOT OK",
+			esCluster:   withAnnotation(`not json`),
+			wantAllowed: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := NewValidationContext(nil, tt.esCluster)
+			require.NoError(t, err)
+			got := validChangeBudget(*ctx)
+			require.Equal(t, tt.wantAllowed, got.Allowed)
+			if !tt.wantAllowed {
+				require.Contains(t, got.Reason, invalidChangeBudgetErrMsg)
+			}
+		})
+	}
+}
+
 func Test_pvcModified(t *testing.T) {
 	failedValidation := validation.Result{Allowed: false, Reason: pvcImmutableMsg}
 	current := getEsCluster()
@@ -639,17 +693,232 @@ func Test_pvcModified(t *testing.T) {
 			proposed: *current,
 			want:     validation.OK,
 		},
+
+		{
+			name: "defaulted claim accepted on a resource stored without it",
+			current: &v1alpha1.Elasticsearch{
+				Spec: v1alpha1.ElasticsearchSpec{
+					Version: "7.2.0",
+					Nodes:   []v1alpha1.NodeSpec{{Name: "master"}},
+				},
+			},
+			proposed: v1alpha1.Elasticsearch{
+				Spec: v1alpha1.ElasticsearchSpec{
+					Version: "7.2.0",
+					Nodes: []v1alpha1.NodeSpec{
+						{
+							Name:                 "master",
+							VolumeClaimTemplates: []corev1.PersistentVolumeClaim{volume.DefaultDataVolumeClaim},
+						},
+					},
+				},
+			},
+			want: validation.OK,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ctx, err := NewValidationContext(current, tt.proposed)
+			ctx, err := NewValidationContext(tt.current, tt.proposed)
 			require.NoError(t, err)
 			require.Equal(t, tt.want, pvcModification(*ctx))
 		})
 	}
 }
 
+// withNodes returns a 7.2.0 cluster test fixture with the given node specs.
+func withNodes(nodes ...v1alpha1.NodeSpec) *v1alpha1.Elasticsearch {
+	return &v1alpha1.Elasticsearch{
+		Spec: v1alpha1.ElasticsearchSpec{
+			Version: "7.2.0",
+			Nodes:   nodes,
+		},
+	}
+}
+
+// nodeSpec returns a node spec test fixture with the given roles.
+func nodeSpec(name string, count int32, master bool, data bool) v1alpha1.NodeSpec {
+	return v1alpha1.NodeSpec{
+		Name:      name,
+		NodeCount: count,
+		Config: &common.Config{
+			Data: map[string]interface{}{
+				v1alpha1.NodeMaster: master,
+				v1alpha1.NodeData:   data,
+			},
+		},
+	}
+}
+
+func Test_noMasterQuorumLoss(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  *v1alpha1.Elasticsearch
+		proposed *v1alpha1.Elasticsearch
+		want     validation.Result
+	}{
+		{
+			name:     "new instance accepted",
+			current:  nil,
+			proposed: withNodes(nodeSpec("master", 1, true, true)),
+			want:     validation.OK,
+		},
+		{
+			name:     "3 to 1 masters rejected",
+			current:  withNodes(nodeSpec("master", 3, true, false), nodeSpec("data", 3, false, true)),
+			proposed: withNodes(nodeSpec("master", 1, true, false), nodeSpec("data", 3, false, true)),
+			want:     validation.Result{Reason: masterQuorumLossMsg + ": 2 out of 3"},
+		},
+		{
+			name:     "3 to 2 masters accepted",
+			current:  withNodes(nodeSpec("master", 3, true, false)),
+			proposed: withNodes(nodeSpec("master", 2, true, false)),
+			want:     validation.OK,
+		},
+		{
+			name:     "half of the masters removed accepted",
+			current:  withNodes(nodeSpec("master", 4, true, false)),
+			proposed: withNodes(nodeSpec("master", 2, true, false)),
+			want:     validation.OK,
+		},
+		{
+			name:     "masters removed across node specs rejected",
+			current:  withNodes(nodeSpec("zone-a", 2, true, true), nodeSpec("zone-b", 2, true, true), nodeSpec("zone-c", 1, true, true)),
+			proposed: withNodes(nodeSpec("zone-a", 2, true, true), nodeSpec("zone-b", 2, false, true)),
+			want:     validation.Result{Reason: masterQuorumLossMsg + ": 3 out of 5"},
+		},
+		{
+			name:     "scale up accepted",
+			current:  withNodes(nodeSpec("master", 1, true, true)),
+			proposed: withNodes(nodeSpec("master", 5, true, true)),
+			want:     validation.OK,
+		},
+		{
+			name:     "data nodes removal ignored",
+			current:  withNodes(nodeSpec("master", 3, true, false), nodeSpec("data", 10, false, true)),
+			proposed: withNodes(nodeSpec("master", 3, true, false), nodeSpec("data", 1, false, true)),
+			want:     validation.OK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := NewValidationContext(tt.current, *tt.proposed)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, noMasterQuorumLoss(*ctx))
+		})
+	}
+}
+
+func Test_noMasterRename(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  *v1alpha1.Elasticsearch
+		proposed *v1alpha1.Elasticsearch
+		want     validation.Result
+	}{
+		{
+			name:     "new instance accepted",
+			current:  nil,
+			proposed: withNodes(nodeSpec("master", 3, true, true)),
+			want:     validation.OK,
+		},
+		{
+			name:     "rename of the only master node spec rejected",
+			current:  withNodes(nodeSpec("master", 3, true, true)),
+			proposed: withNodes(nodeSpec("masters", 3, true, true)),
+			want:     validation.Result{Reason: masterRenameMsg + ": master to masters"},
+		},
+		{
+			name:     "rename of a master node spec among others rejected",
+			current:  withNodes(nodeSpec("master-a", 3, true, false), nodeSpec("master-b", 3, true, false)),
+			proposed: withNodes(nodeSpec("master-a", 3, true, false), nodeSpec("master-c", 3, true, false)),
+			want:     validation.Result{Reason: masterRenameMsg + ": master-b to master-c"},
+		},
+		{
+			name:     "new master node spec accepted",
+			current:  withNodes(nodeSpec("master-a", 3, true, false)),
+			proposed: withNodes(nodeSpec("master-a", 3, true, false), nodeSpec("master-b", 3, true, false)),
+			want:     validation.OK,
+		},
+		{
+			name:     "data node spec rename accepted",
+			current:  withNodes(nodeSpec("master", 3, true, false), nodeSpec("data", 3, false, true)),
+			proposed: withNodes(nodeSpec("master", 3, true, false), nodeSpec("hot", 3, false, true)),
+			want:     validation.OK,
+		},
+		{
+			name:     "master node spec turned into a data node spec accepted",
+			current:  withNodes(nodeSpec("master-a", 3, true, true), nodeSpec("master-b", 3, true, true)),
+			proposed: withNodes(nodeSpec("master-a", 3, true, true), nodeSpec("master-b", 3, false, true)),
+			want:     validation.OK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := NewValidationContext(tt.current, *tt.proposed)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, noMasterRename(*ctx))
+		})
+	}
+}
+
+func Test_noDataNodesRemoval(t *testing.T) {
+	withIndices := func(es *v1alpha1.Elasticsearch) *v1alpha1.Elasticsearch {
+		es.Status.ActivePrimaryShards = 5
+		return es
+	}
+	tests := []struct {
+		name     string
+		current  *v1alpha1.Elasticsearch
+		proposed *v1alpha1.Elasticsearch
+		want     validation.Result
+	}{
+		{
+			name:     "new instance without data nodes accepted",
+			current:  nil,
+			proposed: withNodes(nodeSpec("master", 3, true, false)),
+			want:     validation.OK,
+		},
+		{
+			name:     "removing all data nodes without indices accepted",
+			current:  withNodes(nodeSpec("master", 3, true, false), nodeSpec("data", 3, false, true)),
+			proposed: withNodes(nodeSpec("master", 3, true, false), nodeSpec("data", 0, false, true)),
+			want:     validation.OK,
+		},
+		{
+			name:     "scaling data nodes to zero with indices rejected",
+			current:  withIndices(withNodes(nodeSpec("master", 3, true, false), nodeSpec("data", 3, false, true))),
+			proposed: withNodes(nodeSpec("master", 3, true, false), nodeSpec("data", 0, false, true)),
+			want:     validation.Result{Reason: dataRequiredMsg},
+		},
+		{
+			name:     "removing the data node spec with indices rejected",
+			current:  withIndices(withNodes(nodeSpec("master", 3, true, false), nodeSpec("data", 3, false, true))),
+			proposed: withNodes(nodeSpec("master", 3, true, false)),
+			want:     validation.Result{Reason: dataRequiredMsg},
+		},
+		{
+			name:     "scaling data nodes down with indices accepted",
+			current:  withIndices(withNodes(nodeSpec("master", 3, true, false), nodeSpec("data", 3, false, true))),
+			proposed: withNodes(nodeSpec("master", 3, true, false), nodeSpec("data", 1, false, true)),
+			want:     validation.OK,
+		},
+		{
+			name:     "data role moved to the master nodes with indices accepted",
+			current:  withIndices(withNodes(nodeSpec("master", 3, true, false), nodeSpec("data", 3, false, true))),
+			proposed: withNodes(nodeSpec("master", 3, true, true)),
+			want:     validation.OK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := NewValidationContext(tt.current, *tt.proposed)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, noDataNodesRemoval(*ctx))
+		})
+	}
+}
+
 // getEsCluster returns a ES cluster test fixture
 func getEsCluster() *v1alpha1.Elasticsearch {
 	return &v1alpha1.Elasticsearch{
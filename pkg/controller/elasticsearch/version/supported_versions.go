@@ -20,6 +20,13 @@ type LowestHighestSupportedVersions struct {
 	HighestSupportedVersion version.Version
 }
 
+// SupportedRange bounds all the Elasticsearch versions supported by the operator. The other applications of the
+// Elastic Stack, such as Kibana and APM Server, are supported within the same bounds.
+var SupportedRange = LowestHighestSupportedVersions{
+	LowestSupportedVersion:  version.MustParse("6.7.0"),
+	HighestSupportedVersion: version.MustParse("7.99.99"),
+}
+
 func SupportedVersions(v version.Version) *LowestHighestSupportedVersions {
 	switch v.Major {
 	case 6:
@@ -20,6 +20,9 @@ const (
 	HTTPCertificatesSecretVolumeName      = "elastic-internal-http-certificates"
 	HTTPCertificatesSecretVolumeMountPath = "/usr/share/elasticsearch/config/http-certs"
 
+	MonitoringCASecretVolumeName      = "elastic-internal-monitoring-es-ca"
+	MonitoringCASecretVolumeMountPath = "/usr/share/elasticsearch/config/monitoring-es-ca"
+
 	XPackFileRealmVolumeName      = "elastic-internal-xpack-file-realm"
 	XPackFileRealmVolumeMountPath = "/mnt/elastic-internal/xpack-file-realm"
 
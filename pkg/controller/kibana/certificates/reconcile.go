@@ -14,6 +14,9 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/name"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/pod"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
 	coverv1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -22,10 +25,13 @@ func Reconcile(
 	d driver.Interface,
 	kb v1alpha1.Kibana,
 	services []coverv1.Service,
+	dialer net.Dialer,
 	rotation certificates.RotationParams,
+	keyParams certificates.KeyParams,
 ) *reconciler.Results {
 	selfSignedCert := kb.Spec.HTTP.TLS.SelfSignedCertificate
 	if selfSignedCert != nil && selfSignedCert.Disabled {
+		certificates.DeleteHTTPExpirationMetrics(v1alpha1.Kind, k8s.ExtractNamespacedName(&kb))
 		return nil
 	}
 	results := reconciler.Results{}
@@ -41,14 +47,18 @@ func Reconcile(
 		labels,
 		certificates.HTTPCAType,
 		rotation,
+		keyParams,
+		http.PodsCertificatesIssuedBy(d.K8sClient(), dialer, kb.Namespace, labels, pod.HTTPPort, kb.Spec.HTTP.TLS),
 	)
 	if err != nil {
 		return results.WithError(err)
 	}
 
-	// handle CA expiry via requeue
+	certificates.ReportCAExpiration(v1alpha1.Kind, k8s.ExtractNamespacedName(&kb), certificates.HTTPCAType, httpCa)
+
+	// handle CA expiry and rotation via requeue
 	results.WithResult(reconcile.Result{
-		RequeueAfter: certificates.ShouldRotateIn(time.Now(), httpCa.Cert.NotAfter, rotation.RotateBefore),
+		RequeueAfter: certificates.ShouldRotateCAIn(time.Now(), httpCa, rotation),
 	})
 
 	// discover and maybe reconcile for the http certificates to use
@@ -61,10 +71,15 @@ func Reconcile(
 		labels,
 		services,
 		rotation, // todo correct rotation
+		keyParams,
 	)
 	if err != nil {
 		return results.WithError(err)
 	}
+	// warn again before the certificate expires if it cannot be rotated by the operator
+	results.WithResult(reconcile.Result{
+		RequeueAfter: http.ReportCertificatesExpiration(d.Recorder(), &kb, v1alpha1.Kind, kb.Spec.HTTP.TLS, *httpCertificates),
+	})
 	// reconcile http public cert secret
 	results.WithError(http.ReconcileHTTPCertsPublicSecret(d.K8sClient(), d.Scheme(), &kb, name.KBNamer, httpCertificates))
 	return &results
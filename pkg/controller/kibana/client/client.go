@@ -0,0 +1,236 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/elastic/cloud-on-k8s/pkg/utils/cryptutil"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/stringsutil"
+)
+
+const (
+	// StatusPath is the path of the Kibana status API.
+	StatusPath = "/api/status"
+	// SpacesPath is the path of the Kibana spaces API.
+	SpacesPath = "/api/spaces/space"
+	// ImportSavedObjectsPath is the path of the Kibana saved objects import API, relative to a space.
+	ImportSavedObjectsPath = "/api/saved_objects/_import"
+
+	// DefaultSpace is the ID of the Kibana default space.
+	DefaultSpace = "default"
+)
+
+// APIError is an error response of the Kibana API.
+type APIError struct {
+	StatusCode int
+	msg        string
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return e.msg
+}
+
+// IsNotFound returns true if the given error is an APIError with a 404 status code.
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsForbidden returns true if the given error is an APIError with a 403 status code.
+func IsForbidden(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusForbidden
+}
+
+// UserAuth is authentication information for the Kibana client.
+type UserAuth struct {
+	Name     string
+	Password string
+}
+
+// Client is a Kibana HTTP client.
+type Client interface {
+	// GetStatus calls the Kibana status API.
+	GetStatus(ctx context.Context) (Status, error)
+	// GetSpace retrieves the Kibana space with the given ID.
+	GetSpace(ctx context.Context, id string) (Space, error)
+	// CreateSpace creates the given Kibana space.
+	CreateSpace(ctx context.Context, space Space) error
+	// ImportSavedObjects imports saved objects in NDJSON format into the given space.
+	ImportSavedObjects(ctx context.Context, space string, objects []byte, overwrite bool) (ImportResponse, error)
+	// Equal returns true if the given client targets the same endpoint with the same credentials and certificates.
+	Equal(c2 Client) bool
+	// Close releases the idle connections of the client.
+	Close()
+}
+
+// NewKibanaClient creates a new client for the given Kibana endpoint, trusting the given certificates.
+//
+// If dialer is not nil, it will be used to create new TCP connections
+func NewKibanaClient(dialer net.Dialer, url string, user UserAuth, caCerts []*x509.Certificate) Client {
+	certPool := x509.NewCertPool()
+	for _, c := range caCerts {
+		certPool.AddCert(c)
+	}
+
+	transportConfig := http.Transport{
+		TLSClientConfig: &tls.Config{
+			RootCAs: certPool,
+			// certificates are verified in VerifyPeerCertificate, without validating DNS names or IP addresses
+			InsecureSkipVerify: true,
+		},
+	}
+	transportConfig.TLSClientConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if verifiedChains != nil {
+			return errors.New("tls: non-nil verifiedChains argument breaks crypto/tls.Config.VerifyPeerCertificate contract")
+		}
+		_, _, err := cryptutil.VerifyCertificateExceptServerName(rawCerts, transportConfig.TLSClientConfig)
+		return err
+	}
+
+	// use the custom dialer if provided
+	if dialer != nil {
+		transportConfig.DialContext = dialer.DialContext
+	}
+
+	return &client{
+		endpoint:  url,
+		user:      user,
+		caCerts:   caCerts,
+		transport: &transportConfig,
+		http:      &http.Client{Transport: &transportConfig},
+	}
+}
+
+type client struct {
+	endpoint  string
+	user      UserAuth
+	caCerts   []*x509.Certificate
+	transport *http.Transport
+	http      *http.Client
+}
+
+// GetStatus calls the Kibana status API.
+func (c *client) GetStatus(ctx context.Context) (Status, error) {
+	var status Status
+	err := c.request(ctx, http.MethodGet, StatusPath, "", nil, &status)
+	return status, err
+}
+
+// GetSpace retrieves the Kibana space with the given ID, returning an APIError with a 404 status code if it does not exist.
+func (c *client) GetSpace(ctx context.Context, id string) (Space, error) {
+	var space Space
+	err := c.request(ctx, http.MethodGet, stringsutil.Concat(SpacesPath, "/", url.PathEscape(id)), "", nil, &space)
+	return space, err
+}
+
+// CreateSpace creates the given Kibana space.
+func (c *client) CreateSpace(ctx context.Context, space Space) error {
+	body, err := json.Marshal(space)
+	if err != nil {
+		return err
+	}
+	return c.request(ctx, http.MethodPost, SpacesPath, "application/json", bytes.NewReader(body), nil)
+}
+
+// ImportSavedObjects imports the given saved objects in NDJSON format into the given space, or into the default space
+// if empty. Existing saved objects with the same ID are overwritten if overwrite is true.
+func (c *client) ImportSavedObjects(ctx context.Context, space string, objects []byte, overwrite bool) (ImportResponse, error) {
+	var response ImportResponse
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	// Kibana only accepts files with the ndjson extension
+	file, err := form.CreateFormFile("file", "export.ndjson")
+	if err != nil {
+		return response, err
+	}
+	if _, err := file.Write(objects); err != nil {
+		return response, err
+	}
+	if err := form.Close(); err != nil {
+		return response, err
+	}
+	path := stringsutil.Concat(spacePrefix(space), ImportSavedObjectsPath, "?overwrite=", strconv.FormatBool(overwrite))
+	err = c.request(ctx, http.MethodPost, path, form.FormDataContentType(), &body, &response)
+	return response, err
+}
+
+// spacePrefix returns the prefix of the API paths targeting the given space.
+func spacePrefix(space string) string {
+	if space == "" || space == DefaultSpace {
+		return ""
+	}
+	return stringsutil.Concat("/s/", url.PathEscape(space))
+}
+
+// request sends a request to the given Kibana API path and decodes the JSON response into out, if not nil.
+func (c *client) request(ctx context.Context, method string, path string, contentType string, body io.Reader, out interface{}) error {
+	request, err := http.NewRequest(method, stringsutil.Concat(c.endpoint, path), body)
+	if err != nil {
+		return err
+	}
+	request = request.WithContext(ctx)
+	if c.user != (UserAuth{}) {
+		request.SetBasicAuth(c.user.Name, c.user.Password)
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	// Kibana rejects requests modifying its state without this header, as a protection against CSRF attacks
+	request.Header.Set("kbn-xsrf", "true")
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		// Kibana answers 503 while it is not ready yet, for example during saved objects migrations
+		return &APIError{StatusCode: response.StatusCode, msg: fmt.Sprintf("%s %s: %s", method, path, response.Status)}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(out)
+}
+
+// Equal returns true if the given client targets the same endpoint with the same credentials and certificates.
+func (c *client) Equal(c2 Client) bool {
+	other, ok := c2.(*client)
+	if !ok || other == nil {
+		return false
+	}
+	if len(c.caCerts) != len(other.caCerts) {
+		return false
+	}
+	for i := range c.caCerts {
+		if !c.caCerts[i].Equal(other.caCerts[i]) {
+			return false
+		}
+	}
+	return c.endpoint == other.endpoint && c.user == other.user
+}
+
+// Close releases the idle connections of the client.
+func (c *client) Close() {
+	if c.transport != nil {
+		// the client is recreated frequently, avoid leaking the goroutines handling keep-alive connections
+		c.transport.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const sampleStatus = `{
+  "name": "kibana",
+  "version": {"number": "7.2.0"},
+  "status": {
+    "overall": {"state": "yellow", "title": "Yellow"},
+    "statuses": [
+      {"id": "plugin:kibana@7.2.0", "state": "green", "message": "Ready"},
+      {"id": "plugin:elasticsearch@7.2.0", "state": "green", "message": "Ready"},
+      {"id": "plugin:reporting@7.2.0", "state": "yellow", "message": "Waiting for Elasticsearch"}
+    ]
+  }
+}`
+
+func TestClient_GetStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		switch {
+		case r.URL.Path != StatusPath:
+			w.WriteHeader(http.StatusNotFound)
+		case !ok || user != "kibana" || password != "secret":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			_, _ = w.Write([]byte(sampleStatus))
+		}
+	}))
+	defer server.Close()
+
+	c := NewKibanaClient(nil, server.URL, UserAuth{Name: "kibana", Password: "secret"}, nil)
+	defer c.Close()
+	status, err := c.GetStatus(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "7.2.0", status.Version.Number)
+	require.Equal(t, YellowState, status.Status.Overall.State)
+	require.Len(t, status.Status.Statuses, 3)
+	es, exists := status.Elasticsearch()
+	require.True(t, exists)
+	require.Equal(t, PluginStatus{ID: "plugin:elasticsearch@7.2.0", State: GreenState, Message: "Ready"}, es)
+
+	// wrong credentials
+	_, err = NewKibanaClient(nil, server.URL, UserAuth{Name: "kibana", Password: "wrong"}, nil).GetStatus(context.Background())
+	require.Error(t, err)
+}
+
+func TestClient_GetStatus_NotReady(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("Kibana server is not ready yet"))
+	}))
+	defer server.Close()
+
+	_, err := NewKibanaClient(nil, server.URL, UserAuth{}, nil).GetStatus(context.Background())
+	require.Error(t, err)
+}
+
+func TestClient_Spaces(t *testing.T) {
+	spaces := map[string]Space{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == SpacesPath:
+			if r.Header.Get("kbn-xsrf") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			var space Space
+			if err := json.NewDecoder(r.Body).Decode(&space); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			spaces[space.ID] = space
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, SpacesPath+"/"):
+			space, exists := spaces[strings.TrimPrefix(r.URL.Path, SpacesPath+"/")]
+			if !exists {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(space)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := NewKibanaClient(nil, server.URL, UserAuth{}, nil)
+	_, err := c.GetSpace(context.Background(), "marketing")
+	require.True(t, IsNotFound(err))
+	require.NoError(t, c.CreateSpace(context.Background(), Space{ID: "marketing", Name: "Marketing"}))
+	space, err := c.GetSpace(context.Background(), "marketing")
+	require.NoError(t, err)
+	require.Equal(t, Space{ID: "marketing", Name: "Marketing"}, space)
+}
+
+func TestClient_ImportSavedObjects(t *testing.T) {
+	objects := []byte(`{"id":"logs","type":"index-pattern","attributes":{"title":"logs-*"}}` + "\n")
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.String())
+		file, header, err := r.FormFile("file")
+		if err != nil || r.Header.Get("kbn-xsrf") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		content, err := ioutil.ReadAll(file)
+		if err != nil || !bytes.Equal(objects, content) || !strings.HasSuffix(header.Filename, ".ndjson") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true,"successCount":1}`))
+	}))
+	defer server.Close()
+
+	c := NewKibanaClient(nil, server.URL, UserAuth{}, nil)
+	response, err := c.ImportSavedObjects(context.Background(), "", objects, true)
+	require.NoError(t, err)
+	require.Equal(t, ImportResponse{Success: true, SuccessCount: 1}, response)
+	_, err = c.ImportSavedObjects(context.Background(), "marketing", objects, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"/api/saved_objects/_import?overwrite=true",
+		"/s/marketing/api/saved_objects/_import?overwrite=false",
+	}, paths)
+}
+
+func TestClient_ImportSavedObjects_Forbidden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// users without Kibana privileges, such as users with the kibana_system role only
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	_, err := NewKibanaClient(nil, server.URL, UserAuth{Name: "kibana", Password: "secret"}, nil).
+		ImportSavedObjects(context.Background(), "", []byte("{}"), false)
+	require.Error(t, err)
+	require.True(t, IsForbidden(err))
+	require.False(t, IsNotFound(err))
+	require.False(t, IsForbidden(errors.New("forbidden")))
+}
+
+func TestClient_Equal(t *testing.T) {
+	user := UserAuth{Name: "kibana", Password: "secret"}
+	cert := &x509.Certificate{Raw: []byte("cert")}
+	otherCert := &x509.Certificate{Raw: []byte("other")}
+	c := NewKibanaClient(nil, "https://kb:5601", user, []*x509.Certificate{cert})
+
+	require.True(t, c.Equal(NewKibanaClient(nil, "https://kb:5601", user, []*x509.Certificate{cert})))
+	require.False(t, c.Equal(NewKibanaClient(nil, "http://kb:5601", user, []*x509.Certificate{cert})))
+	require.False(t, c.Equal(NewKibanaClient(nil, "https://kb:5601", UserAuth{Name: "kibana"}, []*x509.Certificate{cert})))
+	require.False(t, c.Equal(NewKibanaClient(nil, "https://kb:5601", user, []*x509.Certificate{otherCert})))
+	require.False(t, c.Equal(NewKibanaClient(nil, "https://kb:5601", user, nil)))
+}
+
+func TestStatus_Elasticsearch(t *testing.T) {
+	_, exists := Status{}.Elasticsearch()
+	require.False(t, exists)
+}
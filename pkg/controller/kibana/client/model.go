@@ -0,0 +1,76 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package client
+
+import "strings"
+
+// These are the states reported by Kibana for itself and its plugins.
+const (
+	GreenState  = "green"
+	YellowState = "yellow"
+	RedState    = "red"
+)
+
+// elasticsearchPluginPrefix prefixes the ID of the plugin handling the connection to Elasticsearch.
+const elasticsearchPluginPrefix = "plugin:elasticsearch@"
+
+// Status is the response of the Kibana status API.
+type Status struct {
+	Name    string `json:"name"`
+	Version struct {
+		Number string `json:"number"`
+	} `json:"version"`
+	Status struct {
+		Overall  OverallStatus  `json:"overall"`
+		Statuses []PluginStatus `json:"statuses"`
+	} `json:"status"`
+}
+
+// OverallStatus is the overall state of Kibana.
+type OverallStatus struct {
+	State string `json:"state"`
+	Title string `json:"title"`
+}
+
+// PluginStatus is the state of a Kibana plugin.
+type PluginStatus struct {
+	ID      string `json:"id"`
+	State   string `json:"state"`
+	Message string `json:"message"`
+}
+
+// Elasticsearch returns the status of the plugin handling the connection to Elasticsearch, if reported.
+func (s Status) Elasticsearch() (PluginStatus, bool) {
+	for _, p := range s.Status.Statuses {
+		if strings.HasPrefix(p.ID, elasticsearchPluginPrefix) {
+			return p, true
+		}
+	}
+	return PluginStatus{}, false
+}
+
+// Space is a Kibana space.
+type Space struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// ImportResponse is the response of the Kibana saved objects import API.
+type ImportResponse struct {
+	Success      bool          `json:"success"`
+	SuccessCount int           `json:"successCount"`
+	Errors       []ImportError `json:"errors,omitempty"`
+}
+
+// ImportError describes a saved object that could not be imported.
+type ImportError struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Error struct {
+		Type string `json:"type"`
+	} `json:"error"`
+}
@@ -0,0 +1,89 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package config
+
+import (
+	"reflect"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
+	kbname "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/name"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+const (
+	encryptionKeysSecretSuffix = "encryption-keys"
+	// encryptionKeyLength is the length of the generated keys, Kibana requires at least 32 characters.
+	encryptionKeyLength = 64
+)
+
+// EncryptionKeys are the settings of the keys Kibana uses to encrypt sessions and reports. They must be the same
+// on all the instances of a Kibana deployment and survive restarts, otherwise each instance generates its own.
+var EncryptionKeys = []string{
+	XpackSecurityEncryptionKey,
+	XpackReportingEncryptionKey,
+}
+
+// EncryptionKeysSecretName is the name of the secret that holds the encryption keys for the given Kibana resource.
+func EncryptionKeysSecretName(kb v1alpha1.Kibana) string {
+	return kbname.KBNamer.Suffix(kb.Name, encryptionKeysSecretSuffix)
+}
+
+// ReconcileEncryptionKeysSecret reconciles the secret holding the encryption keys of the given Kibana resource.
+// Keys are generated once and then reused from the existing secret.
+func ReconcileEncryptionKeysSecret(client k8s.Client, kb v1alpha1.Kibana) (corev1.Secret, error) {
+	expected := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: kb.Namespace,
+			Name:      EncryptionKeysSecretName(kb),
+			Labels: map[string]string{
+				label.KibanaNameLabelName: kb.Name,
+			},
+		},
+		Data: make(map[string][]byte, len(EncryptionKeys)),
+	}
+	for _, key := range EncryptionKeys {
+		expected.Data[key] = []byte(rand.String(encryptionKeyLength))
+	}
+	reconciled := corev1.Secret{}
+	err := reconciler.ReconcileResource(reconciler.Params{
+		Client:     client,
+		Scheme:     scheme.Scheme,
+		Owner:      &kb,
+		Expected:   &expected,
+		Reconciled: &reconciled,
+		NeedsUpdate: func() bool {
+			// re-use the existing keys
+			for key, value := range reconciled.Data {
+				if _, isEncryptionKey := expected.Data[key]; isEncryptionKey && len(value) > 0 {
+					expected.Data[key] = value
+				}
+			}
+			return !reflect.DeepEqual(reconciled.Labels, expected.Labels) ||
+				!reflect.DeepEqual(reconciled.Data, expected.Data)
+		},
+		UpdateReconciled: func() {
+			reconciled.Labels = expected.Labels
+			reconciled.Data = expected.Data
+		},
+	})
+	return reconciled, err
+}
+
+// encryptionKeysSettings returns the encryption keys settings stored in the given secret.
+func encryptionKeysSettings(secret corev1.Secret) map[string]interface{} {
+	cfg := make(map[string]interface{}, len(EncryptionKeys))
+	for _, key := range EncryptionKeys {
+		if value, exists := secret.Data[key]; exists {
+			cfg[key] = string(value)
+		}
+	}
+	return cfg
+}
@@ -0,0 +1,90 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileEncryptionKeysSecret(t *testing.T) {
+	require.NoError(t, v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme))
+	existingKey := []byte("existing-security-key-with-at-least-32-characters")
+	secretMeta := metav1.ObjectMeta{Namespace: "test-ns", Name: "test-kb-encryption-keys"}
+
+	tests := []struct {
+		name           string
+		initialObjects []runtime.Object
+		assertions     func(t *testing.T, reconciled corev1.Secret)
+	}{
+		{
+			name: "keys are generated",
+			assertions: func(t *testing.T, reconciled corev1.Secret) {
+				require.Equal(t, map[string]string{label.KibanaNameLabelName: "test"}, reconciled.Labels)
+				require.Len(t, reconciled.Data, 2)
+				for _, key := range EncryptionKeys {
+					require.Len(t, reconciled.Data[key], encryptionKeyLength)
+				}
+				require.NotEqual(t, reconciled.Data[XpackSecurityEncryptionKey], reconciled.Data[XpackReportingEncryptionKey])
+			},
+		},
+		{
+			name: "existing keys are reused",
+			initialObjects: []runtime.Object{&corev1.Secret{
+				ObjectMeta: secretMeta,
+				Data: map[string][]byte{
+					XpackSecurityEncryptionKey:  existingKey,
+					XpackReportingEncryptionKey: existingKey,
+				},
+			}},
+			assertions: func(t *testing.T, reconciled corev1.Secret) {
+				require.Equal(t, existingKey, reconciled.Data[XpackSecurityEncryptionKey])
+				require.Equal(t, existingKey, reconciled.Data[XpackReportingEncryptionKey])
+			},
+		},
+		{
+			name: "missing keys are generated",
+			initialObjects: []runtime.Object{&corev1.Secret{
+				ObjectMeta: secretMeta,
+				Data: map[string][]byte{
+					XpackSecurityEncryptionKey: existingKey,
+					"unexpected":               []byte("value"),
+				},
+			}},
+			assertions: func(t *testing.T, reconciled corev1.Secret) {
+				require.Len(t, reconciled.Data, 2)
+				require.Equal(t, existingKey, reconciled.Data[XpackSecurityEncryptionKey])
+				require.Len(t, reconciled.Data[XpackReportingEncryptionKey], encryptionKeyLength)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.WrapClient(fake.NewFakeClientWithScheme(scheme.Scheme, tt.initialObjects...))
+
+			reconciled, err := ReconcileEncryptionKeysSecret(c, defaultKibana)
+			require.NoError(t, err)
+			tt.assertions(t, reconciled)
+
+			// keys are persisted and stable across reconciliations
+			var stored corev1.Secret
+			require.NoError(t, c.Get(types.NamespacedName{Namespace: "test-ns", Name: "test-kb-encryption-keys"}, &stored))
+			require.Equal(t, reconciled.Data, stored.Data)
+			again, err := ReconcileEncryptionKeysSecret(c, defaultKibana)
+			require.NoError(t, err)
+			require.Equal(t, reconciled.Data, again.Data)
+		})
+	}
+}
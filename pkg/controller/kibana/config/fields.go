@@ -23,4 +23,25 @@ const (
 	ServerSSLEnabled     = "server.ssl.enabled"
 	ServerSSLCertificate = "server.ssl.certificate"
 	ServerSSLKey         = "server.ssl.key"
+
+	XpackSecurityEncryptionKey  = "xpack.security.encryptionKey"
+	XpackReportingEncryptionKey = "xpack.reporting.encryptionKey"
 )
+
+// Blacklist are the settings managed by the operator, which cannot be set by users.
+var Blacklist = []string{
+	ServerHost,
+	ServerSSLEnabled,
+	ServerSSLCertificate,
+	ServerSSLKey,
+}
+
+// AssociationBlacklist are the settings managed by the operator when Kibana references an Elasticsearch cluster,
+// which cannot be set by users in that case.
+var AssociationBlacklist = []string{
+	ElasticsearchURL,
+	ElasticsearchHosts,
+	ElasticsearchUsername,
+	ElasticsearchPassword,
+	ElasticsearchSslCertificateAuthorities,
+}
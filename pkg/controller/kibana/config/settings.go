@@ -15,6 +15,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/settings"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/es"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // Kibana configuration settings file
@@ -26,8 +27,9 @@ type CanonicalConfig struct {
 	*settings.CanonicalConfig
 }
 
-// NewConfigSettings returns the Kibana configuration settings for the given Kibana resource.
-func NewConfigSettings(client k8s.Client, kb v1alpha1.Kibana) (CanonicalConfig, error) {
+// NewConfigSettings returns the Kibana configuration settings for the given Kibana resource,
+// including the encryption keys stored in the given secret.
+func NewConfigSettings(client k8s.Client, kb v1alpha1.Kibana, encryptionKeys corev1.Secret) (CanonicalConfig, error) {
 	specConfig := kb.Spec.Config
 	if specConfig == nil {
 		specConfig = &commonv1alpha1.Config{}
@@ -49,6 +51,7 @@ func NewConfigSettings(client k8s.Client, kb v1alpha1.Kibana) (CanonicalConfig,
 	err = cfg.MergeWith(
 		settings.MustCanonicalConfig(kibanaTLSSettings(kb)),
 		settings.MustCanonicalConfig(elasticsearchTLSSettings(kb)),
+		settings.MustCanonicalConfig(encryptionKeysSettings(encryptionKeys)),
 		settings.MustCanonicalConfig(
 			map[string]interface{}{
 				ElasticsearchUsername: username,
@@ -15,6 +15,7 @@ import (
 	uyaml "github.com/elastic/go-ucfg/yaml"
 	"github.com/go-test/deep"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 )
 
 var defaultConfig = []byte(`
@@ -43,8 +44,9 @@ xpack:
 
 func TestNewConfigSettings(t *testing.T) {
 	type args struct {
-		client k8s.Client
-		kb     v1alpha1.Kibana
+		client         k8s.Client
+		kb             v1alpha1.Kibana
+		encryptionKeys corev1.Secret
 	}
 	tests := []struct {
 		name    string
@@ -101,10 +103,52 @@ func TestNewConfigSettings(t *testing.T) {
 			},
 			want: append(defaultConfig, []byte(`foo: bar`)...),
 		},
+		{
+			name: "with encryption keys",
+			args: args{
+				kb: v1alpha1.Kibana{},
+				encryptionKeys: corev1.Secret{
+					Data: map[string][]byte{
+						XpackSecurityEncryptionKey:  []byte("security-key"),
+						XpackReportingEncryptionKey: []byte("reporting-key"),
+					},
+				},
+			},
+			want: append(defaultConfig, []byte(`
+  security:
+    encryptionKey: security-key
+  reporting:
+    encryptionKey: reporting-key`)...),
+		},
+		{
+			name: "with encryption keys overridden by the user",
+			args: args{
+				kb: v1alpha1.Kibana{
+					Spec: v1alpha1.KibanaSpec{
+						Config: &commonv1alpha1.Config{
+							Data: map[string]interface{}{
+								XpackSecurityEncryptionKey: "user-key",
+							},
+						},
+					},
+				},
+				encryptionKeys: corev1.Secret{
+					Data: map[string][]byte{
+						XpackSecurityEncryptionKey:  []byte("security-key"),
+						XpackReportingEncryptionKey: []byte("reporting-key"),
+					},
+				},
+			},
+			want: append(defaultConfig, []byte(`
+  security:
+    encryptionKey: user-key
+  reporting:
+    encryptionKey: reporting-key`)...),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewConfigSettings(tt.args.client, tt.args.kb)
+			got, err := NewConfigSettings(tt.args.client, tt.args.kb, tt.args.encryptionKeys)
 			if tt.wantErr {
 				require.NotNil(t, err)
 			}
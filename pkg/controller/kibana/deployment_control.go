@@ -26,6 +26,8 @@ type DeploymentParams struct {
 	Labels          map[string]string
 	Replicas        int32
 	PodTemplateSpec corev1.PodTemplateSpec
+	// Strategy is the strategy used to replace existing pods, defaults to a rolling update if empty.
+	Strategy appsv1.DeploymentStrategy
 }
 
 // NewDeployment creates a Deployment API struct with the given PodSpec.
@@ -43,6 +45,7 @@ func NewDeployment(params DeploymentParams) appsv1.Deployment {
 			},
 			Template: params.PodTemplateSpec,
 			Replicas: &params.Replicas,
+			Strategy: params.Strategy,
 		},
 	})
 }
@@ -5,11 +5,16 @@
 package kibana
 
 import (
+	"context"
 	"crypto/sha256"
+	"crypto/x509"
 	"fmt"
+	"sort"
 
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
 	kbtype "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates/http"
 	driver2 "github.com/elastic/cloud-on-k8s/pkg/controller/common/driver"
@@ -22,19 +27,27 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
 	kbcerts "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/certificates"
+	kbclient "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/client"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/config"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/es"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
 	kbname "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/name"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/observer"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/pod"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/savedobjects"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/version/version6"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/version/version7"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/volume"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // initContainersParameters is used to generate the init container that will load the secure settings into a keystore
@@ -51,6 +64,7 @@ type driver struct {
 	settingsFactory func(kb kbtype.Kibana) map[string]interface{}
 	dynamicWatches  watches.DynamicWatches
 	recorder        record.EventRecorder
+	observers       *observer.Manager
 }
 
 func (d *driver) DynamicWatches() watches.DynamicWatches {
@@ -168,11 +182,10 @@ func (d *driver) deploymentParams(kb *kbtype.Kibana) (*DeploymentParams, error)
 	if kb.Spec.HTTP.TLS.Enabled() {
 		// fetch the secret to calculate the checksum
 		var httpCerts corev1.Secret
-		err := d.client.Get(types.NamespacedName{
+		if err := d.client.Get(types.NamespacedName{
 			Namespace: kb.Namespace,
 			Name:      certificates.HTTPCertsInternalSecretName(kbname.KBNamer, kb.Name),
-		}, &httpCerts)
-		if err != nil {
+		}, &httpCerts); err != nil {
 			return nil, err
 		}
 		if httpCert, ok := httpCerts.Data[certificates.CertFileName]; ok {
@@ -198,6 +211,8 @@ func (d *driver) deploymentParams(kb *kbtype.Kibana) (*DeploymentParams, error)
 	// add the checksum to a label for the deployment and its pods (the important bit is that the pod template
 	// changes, which will trigger a rolling update)
 	kibanaPodSpec.Labels[configChecksumLabel] = fmt.Sprintf("%x", configChecksum.Sum(nil))
+	// add the version to a label for the pods, to know which version is running during upgrades
+	kibanaPodSpec.Labels[label.KibanaVersionLabelName] = kb.Spec.Version
 
 	return &DeploymentParams{
 		Name:            kbname.KBNamer.Suffix(kb.Name),
@@ -210,15 +225,16 @@ func (d *driver) deploymentParams(kb *kbtype.Kibana) (*DeploymentParams, error)
 }
 
 func (d *driver) Reconcile(
+	ctx context.Context,
 	state *State,
 	kb *kbtype.Kibana,
 	params operator.Parameters,
 ) *reconciler.Results {
-	results := reconciler.Results{}
+	results := reconciler.NewResults(ctx)
 	if !kb.Spec.Elasticsearch.IsConfigured() {
 		d.recorder.Event(kb, corev1.EventTypeWarning, events.EventAssociationError, "Elasticsearch backend is not configured")
 		log.Info("Aborting Kibana deployment reconciliation as no Elasticsearch backend is configured", "namespace", kb.Namespace, "kibana_name", kb.Name)
-		return &results
+		return results
 	}
 
 	svc, err := common.ReconcileService(d.client, d.scheme, NewService(*kb), kb)
@@ -227,37 +243,165 @@ func (d *driver) Reconcile(
 		return results.WithError(err)
 	}
 
-	results.WithResults(kbcerts.Reconcile(d, *kb, []corev1.Service{*svc}, params.CACertRotation))
-	if results.HasError() {
-		return &results
+	_, step := reconciler.StartStep(ctx, "certificates")
+	res := kbcerts.Reconcile(d, *kb, []corev1.Service{*svc}, params.Dialer, params.CACertRotation, params.CertKeyParams)
+	step.EndWithResults(res)
+	if results.WithResults(res).HasError() {
+		return results
+	}
+
+	// the configuration and the deployment are built for the version to deploy, which may lag behind the spec
+	deployed, delayed, err := d.pinVersion(kb)
+	if err != nil {
+		return results.WithError(err)
+	}
+	if delayed {
+		results.WithResult(reconcile.Result{RequeueAfter: versionUpgradeRequeueAfter})
 	}
 
-	kbSettings, err := config.NewConfigSettings(d.client, *kb)
+	encryptionKeys, err := config.ReconcileEncryptionKeysSecret(d.client, *deployed)
+	if err != nil {
+		return results.WithError(err)
+	}
+	kbSettings, err := config.NewConfigSettings(d.client, *deployed, encryptionKeys)
 	if err != nil {
 		return results.WithError(err)
 	}
 	err = kbSettings.MergeWith(
-		settings.MustCanonicalConfig(d.settingsFactory(*kb)),
+		settings.MustCanonicalConfig(d.settingsFactory(*deployed)),
 	)
 	if err != nil {
 		return results.WithError(err)
 	}
-	err = config.ReconcileConfigSecret(d.client, *kb, kbSettings, params.OperatorInfo)
+	err = config.ReconcileConfigSecret(d.client, *deployed, kbSettings, params.OperatorInfo)
 	if err != nil {
 		return results.WithError(err)
 	}
 
-	deploymentParams, err := d.deploymentParams(kb)
+	deploymentParams, err := d.deploymentParams(deployed)
 	if err != nil {
 		return results.WithError(err)
 	}
-	expectedDp := NewDeployment(*deploymentParams)
-	reconciledDp, err := ReconcileDeployment(d.client, d.scheme, expectedDp, kb)
+	_, step = reconciler.StartStep(ctx, "deployment")
+	reconciledDp, err := d.reconcileDeployment(deployed, *deploymentParams)
+	step.End(reconcile.Result{}, err)
 	if err != nil {
 		return results.WithError(err)
 	}
-	state.UpdateKibanaState(reconciledDp)
-	return &results
+
+	podClients, err := d.newPodClients(*kb, params.Dialer)
+	if err != nil {
+		return results.WithError(err)
+	}
+	observedState := d.observers.ObservedStateResolver(k8s.ExtractNamespacedName(kb), podClients)
+
+	state.UpdateKibanaState(reconciledDp, observedState)
+
+	if err := savedobjects.ReconcileWatches(d.dynamicWatches, *kb); err != nil {
+		return results.WithError(err)
+	}
+	if observedState.Status == nil {
+		// the Kibana API cannot be reached yet, saved objects are imported on the next status change
+		return results
+	}
+	if len(kb.Spec.SavedObjects) == 0 {
+		state.Kibana.Status.SavedObjects = nil
+		return results
+	}
+	kbClient, err := d.newSavedObjectsClient(*kb, params.Dialer)
+	if apierrors.IsNotFound(err) {
+		// the user is created by the association with Elasticsearch, it may not exist yet
+		d.recorder.Eventf(kb, corev1.EventTypeWarning, events.EventReasonUnexpected, "Cannot import saved objects: %v", err)
+		return results.WithResult(reconcile.Result{RequeueAfter: savedobjects.RetryPeriod})
+	}
+	if err != nil {
+		return results.WithError(err)
+	}
+	defer kbClient.Close()
+	_, step = reconciler.StartStep(ctx, "saved-objects")
+	importStatuses, err := savedobjects.Import(ctx, d.client, kbClient, *kb)
+	step.End(reconcile.Result{}, err)
+	if err != nil {
+		return results.WithError(err)
+	}
+	state.Kibana.Status.SavedObjects = importStatuses
+	if !savedobjects.AllImported(importStatuses) {
+		results.WithResult(reconcile.Result{RequeueAfter: savedobjects.RetryPeriod})
+	}
+	return results
+}
+
+// newSavedObjectsClient creates a client for the Kibana API of the given Kibana, through its service. With an
+// association with Elasticsearch, it is authenticated with the user created to import saved objects: the credentials
+// Kibana uses to connect to Elasticsearch cannot be used, since the kibana_system role is not granted any Kibana
+// privilege. Otherwise, it is authenticated with the credentials of the Elasticsearch backend given in the spec.
+func (d *driver) newSavedObjectsClient(kb kbtype.Kibana, dialer net.Dialer) (kbclient.Client, error) {
+	user, trustedCerts, err := d.kibanaClientSettings(kb)
+	if err != nil {
+		return nil, err
+	}
+	if kb.Spec.ElasticsearchRef.IsDefined() || kb.Spec.ExternalElasticsearchRef.IsDefined() {
+		username, password, err := association.AuthSettings(d.client, kb.Namespace, commonv1alpha1.ElasticsearchAuth{
+			SecretKeyRef: association.ClearTextSecretKeySelector(&kb, es.SavedObjectsUserSuffix),
+		})
+		if err != nil {
+			return nil, err
+		}
+		user = kbclient.UserAuth{Name: username, Password: password}
+	}
+	return kbclient.NewKibanaClient(dialer, ExternalServiceURL(kb), user, trustedCerts), nil
+}
+
+// newPodClients creates a client for the Kibana API of each running pod of the given Kibana, sorted by pod name,
+// authenticated with the credentials Kibana uses to connect to Elasticsearch.
+// Pods are requested directly since the service does not route to pods that are not ready.
+func (d *driver) newPodClients(kb kbtype.Kibana, dialer net.Dialer) ([]kbclient.Client, error) {
+	user, trustedCerts, err := d.kibanaClientSettings(kb)
+	if err != nil {
+		return nil, err
+	}
+	var pods corev1.PodList
+	if err := d.client.List(&client.ListOptions{
+		Namespace:     kb.Namespace,
+		LabelSelector: labels.SelectorFromSet(label.NewLabels(kb.Name)),
+	}, &pods); err != nil {
+		return nil, err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+	var kbClients []kbclient.Client
+	for _, p := range pods.Items {
+		if p.Status.Phase != corev1.PodRunning || p.Status.PodIP == "" || !p.DeletionTimestamp.IsZero() {
+			continue
+		}
+		kbClients = append(kbClients, kbclient.NewKibanaClient(dialer, PodURL(kb, p), user, trustedCerts))
+	}
+	return kbClients, nil
+}
+
+// kibanaClientSettings returns the credentials Kibana uses to connect to Elasticsearch, and the certificates
+// to trust to reach Kibana.
+func (d *driver) kibanaClientSettings(kb kbtype.Kibana) (kbclient.UserAuth, []*x509.Certificate, error) {
+	username, password, err := association.ElasticsearchAuthSettings(d.client, &kb)
+	if err != nil {
+		return kbclient.UserAuth{}, nil, err
+	}
+	var trustedCerts []*x509.Certificate
+	if kb.Spec.HTTP.TLS.Enabled() {
+		var httpCerts corev1.Secret
+		if err := d.client.Get(types.NamespacedName{
+			Namespace: kb.Namespace,
+			Name:      certificates.HTTPCertsInternalSecretName(kbname.KBNamer, kb.Name),
+		}, &httpCerts); err != nil {
+			return kbclient.UserAuth{}, nil, err
+		}
+		trustedCerts, err = certificates.ParsePEMCerts(http.CertificatesSecret(httpCerts).CertChain())
+		if err != nil {
+			return kbclient.UserAuth{}, nil, err
+		}
+	}
+	return kbclient.UserAuth{Name: username, Password: password}, trustedCerts, nil
 }
 
 func newDriver(
@@ -266,12 +410,14 @@ func newDriver(
 	version version.Version,
 	watches watches.DynamicWatches,
 	recorder record.EventRecorder,
+	observers *observer.Manager,
 ) (*driver, error) {
 	d := driver{
 		client:         client,
 		scheme:         scheme,
 		dynamicWatches: watches,
 		recorder:       recorder,
+		observers:      observers,
 	}
 	switch version.Major {
 	case 6:
@@ -13,15 +13,19 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates/http"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	kbclient "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/observer"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/pod"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/volume"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 	"github.com/go-test/deep"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -41,6 +45,7 @@ func expectedDeploymentParams() *DeploymentParams {
 					"common.k8s.elastic.co/type":            "kibana",
 					"kibana.k8s.elastic.co/name":            "test",
 					"kibana.k8s.elastic.co/config-checksum": "c530a02188193a560326ce91e34fc62dcbd5722b45534a3f60957663",
+					"kibana.k8s.elastic.co/version":         "7.0.0",
 				},
 			},
 			Spec: corev1.PodSpec{
@@ -107,6 +112,19 @@ func expectedDeploymentParams() *DeploymentParams {
 					Ports: []corev1.ContainerPort{
 						{Name: "http", ContainerPort: int32(5601), Protocol: corev1.ProtocolTCP},
 					},
+					Env: []corev1.EnvVar{
+						{
+							Name: pod.EnvProbePassword,
+							ValueFrom: &corev1.EnvVarSource{
+								SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "test-auth"},
+									Key:                  "kibana-user",
+								},
+							},
+						},
+						{Name: pod.EnvProbeUsername, Value: "kibana-user"},
+						{Name: pod.EnvReadinessProbeProtocol, Value: "https"},
+					},
 					ReadinessProbe: &corev1.Probe{
 						FailureThreshold:    3,
 						InitialDelaySeconds: 10,
@@ -114,10 +132,8 @@ func expectedDeploymentParams() *DeploymentParams {
 						SuccessThreshold:    1,
 						TimeoutSeconds:      5,
 						Handler: corev1.Handler{
-							HTTPGet: &corev1.HTTPGetAction{
-								Port:   intstr.FromInt(5601),
-								Path:   "/login",
-								Scheme: corev1.URISchemeHTTPS,
+							Exec: &corev1.ExecAction{
+								Command: []string{"bash", "-c", pod.ReadinessProbeScript},
 							},
 						},
 					},
@@ -264,7 +280,7 @@ func Test_driver_deploymentParams(t *testing.T) {
 				params := expectedDeploymentParams()
 				params.PodTemplateSpec.Spec.Volumes = params.PodTemplateSpec.Spec.Volumes[:3]
 				params.PodTemplateSpec.Spec.Containers[0].VolumeMounts = params.PodTemplateSpec.Spec.Containers[0].VolumeMounts[:3]
-				params.PodTemplateSpec.Spec.Containers[0].ReadinessProbe.Handler.HTTPGet.Scheme = corev1.URISchemeHTTP
+				params.PodTemplateSpec.Spec.Containers[0].Env[2].Value = "http"
 				return params
 			}(),
 			wantErr: false,
@@ -336,6 +352,7 @@ func Test_driver_deploymentParams(t *testing.T) {
 					"common.k8s.elastic.co/type":            "kibana",
 					"kibana.k8s.elastic.co/name":            "test",
 					"kibana.k8s.elastic.co/config-checksum": "c5496152d789682387b90ea9b94efcd82a2c6f572f40c016fb86c0d7",
+					"kibana.k8s.elastic.co/version":         "7.0.0",
 				}
 				return p
 			}(),
@@ -353,6 +370,7 @@ func Test_driver_deploymentParams(t *testing.T) {
 			},
 			want: func() *DeploymentParams {
 				p := expectedDeploymentParams()
+				p.PodTemplateSpec.Labels["kibana.k8s.elastic.co/version"] = "6.5.0"
 				return p
 			}(),
 			wantErr: false,
@@ -367,7 +385,11 @@ func Test_driver_deploymentParams(t *testing.T) {
 				}(),
 				initialObjects: defaultInitialObjs,
 			},
-			want:    expectedDeploymentParams(),
+			want: func() *DeploymentParams {
+				p := expectedDeploymentParams()
+				p.PodTemplateSpec.Labels["kibana.k8s.elastic.co/version"] = "6.6.0"
+				return p
+			}(),
 			wantErr: false,
 		},
 	}
@@ -379,7 +401,7 @@ func Test_driver_deploymentParams(t *testing.T) {
 			assert.NoError(t, err)
 			kbVersion, err := version.Parse(tt.args.kb.Spec.Version)
 			assert.NoError(t, err)
-			d, err := newDriver(client, s, *kbVersion, w, record.NewFakeRecorder(100))
+			d, err := newDriver(client, s, *kbVersion, w, record.NewFakeRecorder(100), observer.NewManager(observer.DefaultSettings))
 			assert.NoError(t, err)
 
 			got, err := d.deploymentParams(tt.args.kb)
@@ -396,3 +418,104 @@ func Test_driver_deploymentParams(t *testing.T) {
 		})
 	}
 }
+
+func Test_driver_newPodClients(t *testing.T) {
+	kb := kbtype.Kibana{
+		ObjectMeta: metav1.ObjectMeta{Name: "kb", Namespace: "ns"},
+		Spec: kbtype.KibanaSpec{
+			HTTP: v1alpha1.HTTPConfig{TLS: v1alpha1.TLSOptions{
+				SelfSignedCertificate: &v1alpha1.SelfSignedCertificate{Disabled: true},
+			}},
+		},
+	}
+	kbPod := func(name string, phase corev1.PodPhase, ip string) runtime.Object {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: label.NewLabels("kb")},
+			Status:     corev1.PodStatus{Phase: phase, PodIP: ip},
+		}
+	}
+	c := k8s.WrapClient(fake.NewFakeClient(
+		kbPod("kb-b", corev1.PodRunning, "10.0.0.2"),
+		kbPod("kb-a", corev1.PodRunning, "10.0.0.1"),
+		kbPod("kb-pending", corev1.PodPending, ""),
+		kbPod("kb-no-ip", corev1.PodRunning, ""),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "ns"}, Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.3"}},
+	))
+	d := &driver{client: c}
+
+	kbClients, err := d.newPodClients(kb, nil)
+	require.NoError(t, err)
+	// pods are requested directly, whether they are ready or not
+	require.Len(t, kbClients, 2)
+	require.True(t, kbClients[0].Equal(kbclient.NewKibanaClient(nil, "http://10.0.0.1:5601", kbclient.UserAuth{}, nil)))
+	require.True(t, kbClients[1].Equal(kbclient.NewKibanaClient(nil, "http://10.0.0.2:5601", kbclient.UserAuth{}, nil)))
+}
+
+func Test_driver_newSavedObjectsClient(t *testing.T) {
+	secret := func(name, key, value string) runtime.Object {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Data:       map[string][]byte{key: []byte(value)},
+		}
+	}
+	backend := kbtype.BackendElasticsearch{
+		URL: "https://es:9200",
+		Auth: v1alpha1.ElasticsearchAuth{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "kb-es-user"},
+			Key:                  "kb-user",
+		}},
+	}
+	kibana := func(esRef v1alpha1.ObjectSelector) kbtype.Kibana {
+		return kbtype.Kibana{
+			ObjectMeta: metav1.ObjectMeta{Name: "kb", Namespace: "ns"},
+			Spec: kbtype.KibanaSpec{
+				ElasticsearchRef: esRef,
+				Elasticsearch:    backend,
+				HTTP: v1alpha1.HTTPConfig{TLS: v1alpha1.TLSOptions{
+					SelfSignedCertificate: &v1alpha1.SelfSignedCertificate{Disabled: true},
+				}},
+			},
+		}
+	}
+	tests := []struct {
+		name     string
+		kb       kbtype.Kibana
+		objects  []runtime.Object
+		wantUser kbclient.UserAuth
+		wantErr  bool
+	}{
+		{
+			name:     "manual Elasticsearch backend: use its credentials",
+			kb:       kibana(v1alpha1.ObjectSelector{}),
+			objects:  []runtime.Object{secret("kb-es-user", "kb-user", "kb-password")},
+			wantUser: kbclient.UserAuth{Name: "kb-user", Password: "kb-password"},
+		},
+		{
+			name: "association with Elasticsearch: use the saved objects user",
+			kb:   kibana(v1alpha1.ObjectSelector{Name: "es"}),
+			objects: []runtime.Object{
+				secret("kb-es-user", "kb-user", "kb-password"),
+				secret("kb-kibana-so-user", "ns-kb-kibana-so-user", "so-password"),
+			},
+			wantUser: kbclient.UserAuth{Name: "ns-kb-kibana-so-user", Password: "so-password"},
+		},
+		{
+			name:    "association with Elasticsearch: the saved objects user does not exist yet",
+			kb:      kibana(v1alpha1.ObjectSelector{Name: "es"}),
+			objects: []runtime.Object{secret("kb-es-user", "kb-user", "kb-password")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &driver{client: k8s.WrapClient(fake.NewFakeClient(tt.objects...))}
+			got, err := d.newSavedObjectsClient(tt.kb, nil)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, got.Equal(kbclient.NewKibanaClient(nil, ExternalServiceURL(tt.kb), tt.wantUser, nil)))
+		})
+	}
+}
@@ -15,6 +15,14 @@ import (
 
 var eSCertsVolumeMountPath = "/usr/share/kibana/config/elasticsearch-certs"
 
+const (
+	// SavedObjectsUserSuffix is used to suffix the user importing saved objects into Kibana, and its secret.
+	SavedObjectsUserSuffix = "kibana-so-user"
+	// SavedObjectsUserRole is the role of the user importing saved objects. The user Kibana connects to Elasticsearch
+	// with only has the kibana_system role, which is not granted the Kibana privileges needed by the import API.
+	SavedObjectsUserRole = "kibana_user"
+)
+
 // CaCertSecretVolume returns a SecretVolume to hold the Elasticsearch CA certs for the given Kibana resource.
 func CaCertSecretVolume(kb v1alpha1.Kibana) volume.SecretVolume {
 	// TODO: this is a little ugly as it reaches into the ES controller bits
@@ -12,13 +12,17 @@ import (
 	kibanav1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/finalizer"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/keystore"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/observer"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/savedobjects"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -61,6 +65,7 @@ func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileKi
 		recorder:       mgr.GetRecorder(name),
 		dynamicWatches: watches.NewDynamicWatches(),
 		finalizers:     finalizer.NewHandler(client),
+		observers:      observer.NewManager(observer.DefaultSettings),
 		params:         params,
 	}
 }
@@ -106,6 +111,16 @@ func addWatches(c controller.Controller, r *ReconcileKibana) error {
 		return err
 	}
 
+	// dynamically watch referenced ConfigMaps containing saved objects
+	if err := c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, r.dynamicWatches.ConfigMaps); err != nil {
+		return err
+	}
+
+	// trigger a reconciliation when the state reported by Kibana changes
+	if err := c.Watch(observer.WatchStatusChange(r.observers), reconciler.GenericEventHandler()); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -119,6 +134,7 @@ type ReconcileKibana struct {
 
 	finalizers     finalizer.Handler
 	dynamicWatches watches.DynamicWatches
+	observers      *observer.Manager
 
 	params operator.Parameters
 
@@ -136,6 +152,8 @@ func (r *ReconcileKibana) Reconcile(request reconcile.Request) (reconcile.Result
 	defer func() {
 		log.Info("End reconcile iteration", "iteration", currentIteration, "took", time.Since(iterationStartTime), "namespace", request.Namespace, "kibana_name", request.Name)
 	}()
+	ctx, span := reconciler.StartReconciliation(name, request)
+	defer span.Finish()
 
 	// Fetch the Kibana instance
 	kb := &kibanav1alpha1.Kibana{}
@@ -144,6 +162,7 @@ func (r *ReconcileKibana) Reconcile(request reconcile.Request) (reconcile.Result
 		if errors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			certificates.DeleteExpirationMetrics(kibanav1alpha1.Kind, request.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -176,6 +195,7 @@ func (r *ReconcileKibana) Reconcile(request reconcile.Request) (reconcile.Result
 	}
 
 	if kb.IsMarkedForDeletion() {
+		certificates.DeleteExpirationMetrics(kibanav1alpha1.Kind, k8s.ExtractNamespacedName(kb))
 		// Kibana will be deleted nothing to do other than run finalizers
 		return reconcile.Result{}, nil
 	}
@@ -192,12 +212,12 @@ func (r *ReconcileKibana) Reconcile(request reconcile.Request) (reconcile.Result
 	}
 
 	state := NewState(request, kb)
-	driver, err := newDriver(r, r.scheme, *ver, r.dynamicWatches, r.recorder)
+	driver, err := newDriver(r, r.scheme, *ver, r.dynamicWatches, r.recorder, r.observers)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 	// version specific reconcile
-	results := driver.Reconcile(&state, kb, r.params)
+	results := driver.Reconcile(ctx, &state, kb, r.params)
 
 	// update status
 	err = r.updateStatus(state)
@@ -207,6 +227,7 @@ func (r *ReconcileKibana) Reconcile(request reconcile.Request) (reconcile.Result
 	}
 
 	res, err := results.WithError(err).Aggregate()
+	span.SetError(err)
 	k8s.EmitErrorEvent(r.recorder, err, kb, events.EventReconciliationError, "Reconciliation error: %v", err)
 	return res, err
 }
@@ -228,5 +249,7 @@ func (r *ReconcileKibana) finalizersFor(kb kibanav1alpha1.Kibana) []finalizer.Fi
 	return []finalizer.Finalizer{
 		secretWatchFinalizer(kb, r.dynamicWatches),
 		keystore.Finalizer(k8s.ExtractNamespacedName(&kb), r.dynamicWatches, kb.Kind()),
+		r.observers.Finalizer(k8s.ExtractNamespacedName(&kb)),
+		savedobjects.Finalizer(k8s.ExtractNamespacedName(&kb), r.dynamicWatches),
 	}
 }
@@ -9,6 +9,8 @@ import "github.com/elastic/cloud-on-k8s/pkg/controller/common"
 const (
 	// KibanaNameLabelName used to represent a Kibana in k8s resources
 	KibanaNameLabelName = "kibana.k8s.elastic.co/name"
+	// KibanaVersionLabelName used to store the Kibana version of the resource
+	KibanaVersionLabelName = "kibana.k8s.elastic.co/version"
 
 	// Type represents the Kibana type
 	Type = "kibana"
@@ -0,0 +1,93 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package observer
+
+import (
+	"sync"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/client"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Manager for a set of observers
+type Manager struct {
+	observers map[types.NamespacedName]*Observer
+	listeners []OnObservation // invoked on each observation event
+	lock      sync.RWMutex
+	settings  Settings
+}
+
+// NewManager returns a new manager
+func NewManager(settings Settings) *Manager {
+	return &Manager{
+		observers: make(map[types.NamespacedName]*Observer),
+		settings:  settings,
+	}
+}
+
+// ObservedStateResolver returns the last known state of the given Kibana
+func (m *Manager) ObservedStateResolver(kibana types.NamespacedName, kbClients []client.Client) State {
+	return m.Observe(kibana, kbClients).LastState()
+}
+
+// Observe gets or create an observer for the given Kibana, requesting each Kibana instance with one of the given clients.
+// In case something has changed in the given clients (eg. different pods or certificates), the clients of the
+// observer are replaced accordingly, without losing the last observed state.
+// The manager takes ownership of the given clients, and closes them once they are not used anymore.
+func (m *Manager) Observe(kibana types.NamespacedName, kbClients []client.Client) *Observer {
+	m.lock.RLock()
+	observer, exists := m.observers[kibana]
+	m.lock.RUnlock()
+
+	switch {
+	case !exists:
+		return m.createObserver(kibana, kbClients)
+	case !equalClients(observer.clients(), kbClients):
+		log.Info("Replacing observer HTTP clients", "namespace", kibana.Namespace, "kibana_name", kibana.Name)
+		observer.setClients(kbClients)
+		return observer
+	default:
+		closeAll(kbClients)
+		return observer
+	}
+}
+
+// createObserver creates a new observer and creates or replaces its entry in the observers map
+func (m *Manager) createObserver(kibana types.NamespacedName, kbClients []client.Client) *Observer {
+	observer := NewObserver(kibana, kbClients, m.settings, m.notifyListeners)
+	m.lock.Lock()
+	m.observers[kibana] = observer
+	m.lock.Unlock()
+	observer.Start()
+	return observer
+}
+
+// StopObserving stops and deletes the observer for the given Kibana
+func (m *Manager) StopObserving(kibana types.NamespacedName) {
+	m.lock.Lock()
+	observer, exists := m.observers[kibana]
+	delete(m.observers, kibana)
+	m.lock.Unlock()
+	if exists {
+		observer.Stop()
+	}
+}
+
+// AddObservationListener adds the given listener to the list of listeners notified on every observation.
+func (m *Manager) AddObservationListener(listener OnObservation) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.listeners = append(m.listeners, listener)
+}
+
+// notifyListeners notifies all listeners that an observation occurred.
+func (m *Manager) notifyListeners(kibana types.NamespacedName, previousState State, newState State) {
+	m.lock.RLock()
+	listeners := m.listeners
+	m.lock.RUnlock()
+	for _, l := range listeners {
+		l(kibana, previousState, newState)
+	}
+}
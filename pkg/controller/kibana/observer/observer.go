@@ -0,0 +1,163 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package observer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/client"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("kibana-observer")
+
+// Settings for the Observer configuration
+type Settings struct {
+	ObservationInterval time.Duration
+	RequestTimeout      time.Duration
+}
+
+// Default values: the status API is requested every 10 seconds, and should answer quickly.
+const (
+	DefaultObservationInterval = 10 * time.Second
+	DefaultRequestTimeout      = 5 * time.Second
+)
+
+// DefaultSettings is an observer's Params with default values
+var DefaultSettings = Settings{
+	ObservationInterval: DefaultObservationInterval,
+	RequestTimeout:      DefaultRequestTimeout,
+}
+
+// OnObservation is a function that gets executed when a new state is observed
+type OnObservation func(kibana types.NamespacedName, previousState State, newState State)
+
+// Observer regularly requests the status API of each Kibana instance, in a thread-safe way
+type Observer struct {
+	kibana types.NamespacedName
+	// kbClients target the Kibana pods directly: the service only routes to ready pods, and pods reporting a red
+	// state are not ready
+	kbClients []client.Client
+
+	settings Settings
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+
+	onObservation OnObservation
+
+	lastState State
+	mutex     sync.RWMutex
+}
+
+// NewObserver creates an Observer
+func NewObserver(kibana types.NamespacedName, kbClients []client.Client, settings Settings, onObservation OnObservation) *Observer {
+	log.Info("Creating observer for Kibana", "namespace", kibana.Namespace, "kibana_name", kibana.Name)
+	return &Observer{
+		kibana:        kibana,
+		kbClients:     kbClients,
+		settings:      settings,
+		stopChan:      make(chan struct{}),
+		onObservation: onObservation,
+	}
+}
+
+// Start the observer in a separate goroutine
+func (o *Observer) Start() {
+	go o.runPeriodically()
+}
+
+// Stop the observer loop
+func (o *Observer) Stop() {
+	o.stopOnce.Do(func() {
+		close(o.stopChan)
+		closeAll(o.clients())
+	})
+}
+
+// clients returns the clients of the observed Kibana instances
+func (o *Observer) clients() []client.Client {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+	return o.kbClients
+}
+
+// setClients replaces the clients of the observed Kibana instances, keeping the last observed state
+func (o *Observer) setClients(kbClients []client.Client) {
+	o.mutex.Lock()
+	previous := o.kbClients
+	o.kbClients = kbClients
+	o.mutex.Unlock()
+	closeAll(previous)
+}
+
+// LastState returns the last observed state
+func (o *Observer) LastState() State {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+	return o.lastState
+}
+
+// runPeriodically triggers a state retrieval every tick, until stopped
+func (o *Observer) runPeriodically() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-o.stopChan
+		cancel()
+	}()
+
+	o.retrieveState(ctx)
+	ticker := time.NewTicker(o.settings.ObservationInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			o.retrieveState(ctx)
+		case <-ctx.Done():
+			log.Info("Stopping observer for Kibana", "namespace", o.kibana.Namespace, "kibana_name", o.kibana.Name)
+			return
+		}
+	}
+}
+
+// retrieveState retrieves the current Kibana status, executes onObservation, and stores the new state
+func (o *Observer) retrieveState(ctx context.Context) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, o.settings.RequestTimeout)
+	defer cancel()
+
+	newState := RetrieveState(timeoutCtx, o.kibana, o.clients())
+
+	if o.onObservation != nil {
+		o.onObservation(o.kibana, o.LastState(), newState)
+	}
+
+	o.mutex.Lock()
+	o.lastState = newState
+	o.mutex.Unlock()
+}
+
+// closeAll closes the given clients
+func closeAll(kbClients []client.Client) {
+	for _, c := range kbClients {
+		c.Close()
+	}
+}
+
+// equalClients returns true if both slices contain equal clients, in the same order
+func equalClients(c1, c2 []client.Client) bool {
+	if len(c1) != len(c2) {
+		return false
+	}
+	for i := range c1 {
+		if !c1[i].Equal(c2[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package observer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/client"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var kibana = types.NamespacedName{Namespace: "ns", Name: "kb"}
+
+type fakeClient struct {
+	client.Client
+	endpoint string
+	status   *client.Status
+}
+
+func (f *fakeClient) GetStatus(_ context.Context) (client.Status, error) {
+	if f.status == nil {
+		return client.Status{}, errors.New("not ready")
+	}
+	return *f.status, nil
+}
+
+func (f *fakeClient) Equal(c2 client.Client) bool {
+	other, ok := c2.(*fakeClient)
+	return ok && other.endpoint == f.endpoint
+}
+
+func (f *fakeClient) Close() {}
+
+func statusWithState(state string) *client.Status {
+	status := client.Status{}
+	status.Status.Overall.State = state
+	return &status
+}
+
+func fastSettings() Settings {
+	return Settings{ObservationInterval: 10 * time.Millisecond, RequestTimeout: time.Second}
+}
+
+func clients(cs ...*fakeClient) []client.Client {
+	kbClients := make([]client.Client, len(cs))
+	for i := range cs {
+		kbClients[i] = cs[i]
+	}
+	return kbClients
+}
+
+func TestRetrieveState(t *testing.T) {
+	green := statusWithState(client.GreenState)
+	yellow := statusWithState(client.YellowState)
+	red := statusWithState(client.RedState)
+	tests := []struct {
+		name    string
+		clients []client.Client
+		want    State
+	}{
+		{
+			name:    "no instance",
+			clients: nil,
+			want:    State{Observed: true},
+		},
+		{
+			name:    "unreachable instance",
+			clients: clients(&fakeClient{}),
+			want:    State{Observed: true},
+		},
+		{
+			name:    "unreachable instances are ignored",
+			clients: clients(&fakeClient{}, &fakeClient{status: green}),
+			want:    State{Observed: true, Status: green},
+		},
+		{
+			name:    "worst status of the instances",
+			clients: clients(&fakeClient{status: green}, &fakeClient{status: red}, &fakeClient{status: yellow}),
+			want:    State{Observed: true, Status: red},
+		},
+		{
+			name:    "yellow is worse than green",
+			clients: clients(&fakeClient{status: green}, &fakeClient{status: yellow}, &fakeClient{status: green}),
+			want:    State{Observed: true, Status: yellow},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, RetrieveState(context.Background(), kibana, tt.clients))
+		})
+	}
+}
+
+func TestObserver(t *testing.T) {
+	observations := make(chan State, 100)
+	o := NewObserver(kibana, clients(&fakeClient{status: statusWithState(client.GreenState)}), fastSettings(),
+		func(_ types.NamespacedName, _ State, newState State) {
+			observations <- newState
+		})
+	o.Start()
+	defer o.Stop()
+
+	select {
+	case state := <-observations:
+		require.Equal(t, client.GreenState, state.overallState())
+	case <-time.After(5 * time.Second):
+		t.Fatal("no observation")
+	}
+	// the state is stored once listeners are notified
+	deadline := time.Now().Add(5 * time.Second)
+	for o.LastState().overallState() != client.GreenState {
+		require.True(t, time.Now().Before(deadline), "state not stored")
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestManager_Observe(t *testing.T) {
+	m := NewManager(fastSettings())
+	defer m.StopObserving(kibana)
+
+	first := m.Observe(kibana, clients(&fakeClient{endpoint: "https://10.0.0.1:5601"}))
+	require.Equal(t, first, m.Observe(kibana, clients(&fakeClient{endpoint: "https://10.0.0.1:5601"})))
+
+	// the clients of the existing observer are replaced when the pods change
+	updated := clients(&fakeClient{endpoint: "https://10.0.0.1:5601"}, &fakeClient{endpoint: "https://10.0.0.2:5601"})
+	require.Equal(t, first, m.Observe(kibana, updated))
+	require.Equal(t, updated, first.clients())
+
+	m.StopObserving(kibana)
+	require.Empty(t, m.observers)
+}
+
+func Test_statusChangeListener(t *testing.T) {
+	events := make(chan event.GenericEvent, 10)
+	listener := statusChangeListener(events)
+
+	listener(kibana, State{}, State{})
+	listener(kibana, State{Observed: true}, State{Observed: true})
+	listener(kibana, State{Observed: true, Status: statusWithState(client.GreenState)}, State{Observed: true, Status: statusWithState(client.GreenState)})
+	require.Empty(t, events)
+
+	listener(kibana, State{}, State{Observed: true})
+	listener(kibana, State{Observed: true}, State{Observed: true, Status: statusWithState(client.GreenState)})
+	listener(kibana, State{Observed: true, Status: statusWithState(client.GreenState)}, State{Observed: true, Status: statusWithState(client.RedState)})
+	listener(kibana, State{Observed: true, Status: statusWithState(client.RedState)}, State{Observed: true})
+	require.Len(t, events, 4)
+	evt := <-events
+	require.Equal(t, kibana.Name, evt.Meta.GetName())
+	require.Equal(t, kibana.Namespace, evt.Meta.GetNamespace())
+}
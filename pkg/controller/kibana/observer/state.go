@@ -0,0 +1,58 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package observer
+
+import (
+	"context"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/client"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// State contains information about an observed state of Kibana.
+type State struct {
+	// Observed is true once the Kibana instances have been requested, whether they answered or not.
+	Observed bool
+	// Status is the worst status reported by the status API of the Kibana instances, nil if none could be retrieved.
+	Status *client.Status
+}
+
+// RetrieveState returns the current Kibana state, from the worst status reported by the given Kibana instances.
+// Instances that cannot be reached are ignored, they are expected to be starting.
+func RetrieveState(ctx context.Context, kibana types.NamespacedName, kbClients []client.Client) State {
+	state := State{Observed: true}
+	for _, kbClient := range kbClients {
+		status, err := kbClient.GetStatus(ctx)
+		if err != nil {
+			// expected while Kibana is starting
+			log.V(1).Info("Unable to retrieve Kibana status", "error", err, "namespace", kibana.Namespace, "kibana_name", kibana.Name)
+			continue
+		}
+		if state.Status == nil || severity(status.Status.Overall.State) > severity(state.overallState()) {
+			state.Status = &status
+		}
+	}
+	return state
+}
+
+// severity orders the overall states reported by Kibana, from green to red. Unknown states are considered red.
+func severity(state string) int {
+	switch state {
+	case client.GreenState:
+		return 0
+	case client.YellowState:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// overallState returns the overall state reported by Kibana, or an empty string if unknown.
+func (s State) overallState() string {
+	if s.Status == nil {
+		return ""
+	}
+	return s.Status.Status.Overall.State
+}
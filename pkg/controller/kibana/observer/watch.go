@@ -0,0 +1,37 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package observer
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// WatchStatusChange returns a Source fed with generic events targeting Kibana resources
+// whose overall state has changed between 2 observations, or which are observed for the first time.
+// Aimed to be used for triggering a reconciliation.
+func WatchStatusChange(m *Manager) *source.Channel {
+	evtChan := make(chan event.GenericEvent)
+	m.AddObservationListener(statusChangeListener(evtChan))
+	return &source.Channel{Source: evtChan}
+}
+
+// statusChangeListener returns an OnObservation listener that feeds a generic
+// event when Kibana is observed for the first time or when its observed overall state has changed.
+func statusChangeListener(reconciliation chan event.GenericEvent) OnObservation {
+	return func(kibana types.NamespacedName, previous State, new State) {
+		if previous.Observed == new.Observed && previous.overallState() == new.overallState() {
+			return
+		}
+		reconciliation <- event.GenericEvent{
+			Meta: &metav1.ObjectMeta{
+				Namespace: kibana.Namespace,
+				Name:      kibana.Name,
+			},
+		}
+	}
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/utils/stringsutil"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
@@ -28,12 +27,37 @@ var ports = []corev1.ContainerPort{
 	{Name: "http", ContainerPort: int32(HTTPPort), Protocol: corev1.ProtocolTCP},
 }
 
+// Environment variables used by the readiness probe
+const (
+	EnvProbeUsername          = "PROBE_USERNAME"
+	EnvProbePassword          = "PROBE_PASSWORD"
+	EnvReadinessProbeProtocol = "READINESS_PROBE_PROTOCOL"
+)
+
+// ReadinessProbeScript considers Kibana ready if its status API answers with an overall state that is not red.
+// The status API answers 503 while Kibana is not ready yet, for example during saved objects migrations.
+const ReadinessProbeScript = `
+#!/usr/bin/env bash
+CURL_TIMEOUT=3
+
+# setup basic auth if credentials are available
+BASIC_AUTH=()
+if [ -n "${PROBE_USERNAME}" ] && [ -n "${PROBE_PASSWORD}" ]; then
+  BASIC_AUTH=(-u "${PROBE_USERNAME}:${PROBE_PASSWORD}")
+fi
+
+# request the Kibana status API, failing on HTTP errors
+status=$(curl --fail --max-time $CURL_TIMEOUT -XGET -s -k "${BASIC_AUTH[@]}" ${READINESS_PROBE_PROTOCOL:-https}://127.0.0.1:5601/api/status) || exit 1
+
+# not ready if the overall state is red
+if [[ $status == *'"overall":{"state":"red"'* ]]; then
+  exit 1
+fi
+exit 0
+`
+
 // readinessProbe is the readiness probe for the Kibana container
-func readinessProbe(useTLS bool) corev1.Probe {
-	scheme := corev1.URISchemeHTTP
-	if useTLS {
-		scheme = corev1.URISchemeHTTPS
-	}
+func readinessProbe() corev1.Probe {
 	return corev1.Probe{
 		FailureThreshold:    3,
 		InitialDelaySeconds: 10,
@@ -41,15 +65,28 @@ func readinessProbe(useTLS bool) corev1.Probe {
 		SuccessThreshold:    1,
 		TimeoutSeconds:      5,
 		Handler: corev1.Handler{
-			HTTPGet: &corev1.HTTPGetAction{
-				Port:   intstr.FromInt(HTTPPort),
-				Path:   "/login",
-				Scheme: scheme,
+			Exec: &corev1.ExecAction{
+				Command: []string{"bash", "-c", ReadinessProbeScript},
 			},
 		},
 	}
 }
 
+// readinessProbeEnv returns the environment variables used by the readiness probe: the protocol to use, and the
+// credentials Kibana uses to connect to Elasticsearch, if any.
+func readinessProbeEnv(kb v1alpha1.Kibana) []corev1.EnvVar {
+	env := []corev1.EnvVar{
+		{Name: EnvReadinessProbeProtocol, Value: kb.Spec.HTTP.Scheme()},
+	}
+	if ref := kb.Spec.Elasticsearch.Auth.SecretKeyRef; ref != nil {
+		env = append(env,
+			corev1.EnvVar{Name: EnvProbeUsername, Value: ref.Key},
+			corev1.EnvVar{Name: EnvProbePassword, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: ref.DeepCopy()}},
+		)
+	}
+	return env
+}
+
 func imageWithVersion(image string, version string) string {
 	return stringsutil.Concat(image, ":", version)
 }
@@ -58,7 +95,8 @@ func NewPodTemplateSpec(kb v1alpha1.Kibana, keystore *keystore.Resources) corev1
 	builder := defaults.NewPodTemplateBuilder(kb.Spec.PodTemplate, v1alpha1.KibanaContainerName).
 		WithLabels(label.NewLabels(kb.Name)).
 		WithDockerImage(kb.Spec.Image, imageWithVersion(defaultImageRepositoryAndName, kb.Spec.Version)).
-		WithReadinessProbe(readinessProbe(kb.Spec.HTTP.TLS.Enabled())).
+		WithReadinessProbe(readinessProbe()).
+		WithEnv(readinessProbeEnv(kb)...).
 		WithPorts(ports).
 		WithVolumes(volume.KibanaDataVolume.Volume()).
 		WithVolumeMounts(volume.KibanaDataVolume.VolumeMount())
@@ -7,6 +7,7 @@ package pod
 import (
 	"testing"
 
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
 	"github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/keystore"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
@@ -162,7 +163,9 @@ func TestNewPodTemplateSpec(t *testing.T) {
 				},
 			}},
 			assertions: func(pod corev1.PodTemplateSpec) {
-				assert.Len(t, GetKibanaContainer(pod.Spec).Env, 1)
+				env := GetKibanaContainer(pod.Spec).Env
+				assert.Len(t, env, 2)
+				assert.Contains(t, env, corev1.EnvVar{Name: "user-env", Value: "user-env-value"})
 			},
 		},
 		{
@@ -193,6 +196,39 @@ func TestNewPodTemplateSpec(t *testing.T) {
 				assert.Len(t, GetKibanaContainer(pod.Spec).VolumeMounts, 2)
 			},
 		},
+		{
+			name: "with Elasticsearch credentials for the readiness probe",
+			kb: v1alpha1.Kibana{Spec: v1alpha1.KibanaSpec{
+				Elasticsearch: v1alpha1.BackendElasticsearch{
+					Auth: commonv1alpha1.ElasticsearchAuth{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "kb-user"},
+							Key:                  "ns-kb-kibana-user",
+						},
+					},
+				},
+				HTTP: commonv1alpha1.HTTPConfig{
+					TLS: commonv1alpha1.TLSOptions{
+						SelfSignedCertificate: &commonv1alpha1.SelfSignedCertificate{Disabled: true},
+					},
+				},
+			}},
+			assertions: func(pod corev1.PodTemplateSpec) {
+				kibanaContainer := GetKibanaContainer(pod.Spec)
+				assert.Equal(t, []corev1.EnvVar{
+					{
+						Name: EnvProbePassword,
+						ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "kb-user"},
+							Key:                  "ns-kb-kibana-user",
+						}},
+					},
+					{Name: EnvProbeUsername, Value: "ns-kb-kibana-user"},
+					{Name: EnvReadinessProbeProtocol, Value: "http"},
+				}, kibanaContainer.Env)
+				assert.Equal(t, []string{"bash", "-c", ReadinessProbeScript}, kibanaContainer.ReadinessProbe.Exec.Command)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,197 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package savedobjects
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/finalizer"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/hash"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+const (
+	// RetryPeriod is the delay before retrying the imports that failed.
+	RetryPeriod = 1 * time.Minute
+
+	// requestTimeout is the timeout of each request to the Kibana API.
+	requestTimeout = 30 * time.Second
+)
+
+var log = logf.Log.WithName("kibana-saved-objects")
+
+// watchName returns the name of the watch of the ConfigMaps referenced by the given Kibana.
+func watchName(kb types.NamespacedName) string {
+	return fmt.Sprintf("%s-%s-saved-objects", kb.Namespace, kb.Name)
+}
+
+// ReconcileWatches watches the ConfigMaps referenced by the given Kibana, to import their saved objects again
+// when they change.
+func ReconcileWatches(w watches.DynamicWatches, kb v1alpha1.Kibana) error {
+	kbName := k8s.ExtractNamespacedName(&kb)
+	if len(kb.Spec.SavedObjects) == 0 {
+		w.ConfigMaps.RemoveHandlerForKey(watchName(kbName))
+		return nil
+	}
+	watched := make([]types.NamespacedName, 0, len(kb.Spec.SavedObjects))
+	for _, source := range kb.Spec.SavedObjects {
+		watched = append(watched, types.NamespacedName{Namespace: kb.Namespace, Name: source.ConfigMapName})
+	}
+	return w.ConfigMaps.AddHandler(watches.NamedWatch{
+		Name:    watchName(kbName),
+		Watched: watched,
+		Watcher: kbName,
+	})
+}
+
+// Finalizer removes the watch of the ConfigMaps referenced by the given Kibana.
+func Finalizer(kb types.NamespacedName, w watches.DynamicWatches) finalizer.Finalizer {
+	return finalizer.Finalizer{
+		Name: "saved-objects.finalizers.kibana.k8s.elastic.co",
+		Execute: func() error {
+			w.ConfigMaps.RemoveHandlerForKey(watchName(kb))
+			return nil
+		},
+	}
+}
+
+// Import imports into Kibana the saved objects of the ConfigMaps referenced by the given Kibana, and returns the
+// import status of each of them. Saved objects successfully imported with the same content and options, according
+// to the current Kibana status, are not imported again.
+func Import(ctx context.Context, c k8s.Client, kbClient client.Client, kb v1alpha1.Kibana) ([]v1alpha1.SavedObjectsImportStatus, error) {
+	var statuses []v1alpha1.SavedObjectsImportStatus
+	for _, source := range kb.Spec.SavedObjects {
+		var configMap corev1.ConfigMap
+		err := c.Get(types.NamespacedName{Namespace: kb.Namespace, Name: source.ConfigMapName}, &configMap)
+		if apierrors.IsNotFound(err) {
+			statuses = append(statuses, v1alpha1.SavedObjectsImportStatus{
+				ConfigMapName: source.ConfigMapName,
+				Space:         source.Space,
+				Message:       "ConfigMap not found",
+			})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		contentHash := hash.HashObject(struct {
+			Source v1alpha1.SavedObjectsSource
+			Data   map[string]string
+		}{Source: source, Data: configMap.Data})
+		if previous, exists := find(kb.Status.SavedObjects, source); exists && previous.Success && previous.Hash == contentHash {
+			statuses = append(statuses, previous)
+			continue
+		}
+
+		status := importSource(ctx, kbClient, source, configMap)
+		status.Hash = contentHash
+		if status.Success {
+			log.Info("Saved objects imported", "namespace", kb.Namespace, "kibana_name", kb.Name,
+				"configmap", source.ConfigMapName, "space", source.Space, "count", status.SuccessCount)
+		} else {
+			log.Info("Failed to import saved objects", "namespace", kb.Namespace, "kibana_name", kb.Name,
+				"configmap", source.ConfigMapName, "space", source.Space, "error", status.Message)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// AllImported returns true if all the saved objects of the given import statuses were imported.
+func AllImported(statuses []v1alpha1.SavedObjectsImportStatus) bool {
+	for _, s := range statuses {
+		if !s.Success {
+			return false
+		}
+	}
+	return true
+}
+
+// find returns the import status of the given source, if any.
+func find(statuses []v1alpha1.SavedObjectsImportStatus, source v1alpha1.SavedObjectsSource) (v1alpha1.SavedObjectsImportStatus, bool) {
+	for _, s := range statuses {
+		if s.ConfigMapName == source.ConfigMapName && s.Space == source.Space {
+			return s, true
+		}
+	}
+	return v1alpha1.SavedObjectsImportStatus{}, false
+}
+
+// importSource imports the saved objects of each entry of the given ConfigMap into the space of the given source.
+func importSource(
+	ctx context.Context,
+	kbClient client.Client,
+	source v1alpha1.SavedObjectsSource,
+	configMap corev1.ConfigMap,
+) v1alpha1.SavedObjectsImportStatus {
+	status := v1alpha1.SavedObjectsImportStatus{ConfigMapName: source.ConfigMapName, Space: source.Space}
+	if err := ensureSpace(ctx, kbClient, source.Space); err != nil {
+		status.Message = fmt.Sprintf("failed to create space %s: %s", source.Space, errorMessage(err))
+		return status
+	}
+
+	// import the entries in a predictable order
+	keys := make([]string, 0, len(configMap.Data))
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var failures []string
+	for _, key := range keys {
+		response, err := importObjects(ctx, kbClient, source, []byte(configMap.Data[key]))
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", key, errorMessage(err)))
+			continue
+		}
+		status.SuccessCount += response.SuccessCount
+		for _, e := range response.Errors {
+			failures = append(failures, fmt.Sprintf("%s: %s %s: %s", key, e.Type, e.ID, e.Error.Type))
+		}
+	}
+	status.Success = len(failures) == 0
+	status.Message = strings.Join(failures, "; ")
+	return status
+}
+
+// errorMessage describes the given error of the Kibana API.
+func errorMessage(err error) string {
+	if client.IsForbidden(err) {
+		return "forbidden: the user importing saved objects is not granted the required Kibana privileges"
+	}
+	return err.Error()
+}
+
+func importObjects(ctx context.Context, kbClient client.Client, source v1alpha1.SavedObjectsSource, objects []byte) (client.ImportResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	return kbClient.ImportSavedObjects(ctx, source.Space, objects, source.Overwrite)
+}
+
+// ensureSpace creates the Kibana space with the given ID if it does not exist yet.
+func ensureSpace(ctx context.Context, kbClient client.Client, space string) error {
+	if space == "" || space == client.DefaultSpace {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	_, err := kbClient.GetSpace(ctx, space)
+	if !client.IsNotFound(err) {
+		return err
+	}
+	return kbClient.CreateSpace(ctx, client.Space{ID: space, Name: space})
+}
@@ -0,0 +1,161 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package savedobjects
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const indexPattern = `{"id":"logs","type":"index-pattern","attributes":{"title":"logs-*"}}`
+
+type importRequest struct {
+	space     string
+	objects   string
+	overwrite bool
+}
+
+// fakeClient records the requests to the Kibana API.
+type fakeClient struct {
+	client.Client
+	spaces    map[string]client.Space
+	imports   []importRequest
+	importErr error
+	errors    []client.ImportError
+}
+
+func (f *fakeClient) GetSpace(_ context.Context, id string) (client.Space, error) {
+	space, exists := f.spaces[id]
+	if !exists {
+		return client.Space{}, &client.APIError{StatusCode: http.StatusNotFound}
+	}
+	return space, nil
+}
+
+func (f *fakeClient) CreateSpace(_ context.Context, space client.Space) error {
+	if f.spaces == nil {
+		f.spaces = map[string]client.Space{}
+	}
+	f.spaces[space.ID] = space
+	return nil
+}
+
+func (f *fakeClient) ImportSavedObjects(_ context.Context, space string, objects []byte, overwrite bool) (client.ImportResponse, error) {
+	if f.importErr != nil {
+		return client.ImportResponse{}, f.importErr
+	}
+	f.imports = append(f.imports, importRequest{space: space, objects: string(objects), overwrite: overwrite})
+	return client.ImportResponse{Success: len(f.errors) == 0, SuccessCount: 1, Errors: f.errors}, nil
+}
+
+func configMap(name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+		Data:       data,
+	}
+}
+
+func kibana(sources ...v1alpha1.SavedObjectsSource) v1alpha1.Kibana {
+	return v1alpha1.Kibana{
+		ObjectMeta: metav1.ObjectMeta{Name: "kb", Namespace: "ns"},
+		Spec:       v1alpha1.KibanaSpec{SavedObjects: sources},
+	}
+}
+
+func TestImport(t *testing.T) {
+	objects := []runtime.Object{
+		configMap("index-patterns", map[string]string{"logs.ndjson": indexPattern}),
+		configMap("dashboards", map[string]string{"b.ndjson": "b", "a.ndjson": "a"}),
+	}
+	c := k8s.WrapClient(fake.NewFakeClient(objects...))
+	kbClient := &fakeClient{}
+	kb := kibana(
+		v1alpha1.SavedObjectsSource{ConfigMapName: "index-patterns"},
+		v1alpha1.SavedObjectsSource{ConfigMapName: "dashboards", Space: "marketing", Overwrite: true},
+	)
+
+	statuses, err := Import(context.Background(), c, kbClient, kb)
+	require.NoError(t, err)
+	require.True(t, AllImported(statuses))
+	require.Len(t, statuses, 2)
+	require.Equal(t, 1, statuses[0].SuccessCount)
+	require.Equal(t, 2, statuses[1].SuccessCount)
+	require.Equal(t, []importRequest{
+		{space: "", objects: indexPattern},
+		{space: "marketing", objects: "a", overwrite: true},
+		{space: "marketing", objects: "b", overwrite: true},
+	}, kbClient.imports)
+	require.Equal(t, map[string]client.Space{"marketing": {ID: "marketing", Name: "marketing"}}, kbClient.spaces)
+
+	// nothing is imported again if the ConfigMaps did not change
+	kb.Status.SavedObjects = statuses
+	kbClient.imports = nil
+	unchanged, err := Import(context.Background(), c, kbClient, kb)
+	require.NoError(t, err)
+	require.Equal(t, statuses, unchanged)
+	require.Empty(t, kbClient.imports)
+
+	// saved objects are imported again when the ConfigMap changes
+	require.NoError(t, c.Update(configMap("dashboards", map[string]string{"a.ndjson": "a2"})))
+	updated, err := Import(context.Background(), c, kbClient, kb)
+	require.NoError(t, err)
+	require.Equal(t, statuses[0], updated[0])
+	require.NotEqual(t, statuses[1].Hash, updated[1].Hash)
+	require.Equal(t, []importRequest{{space: "marketing", objects: "a2", overwrite: true}}, kbClient.imports)
+
+	// or when the import options change
+	kb.Spec.SavedObjects[0].Overwrite = true
+	kbClient.imports = nil
+	_, err = Import(context.Background(), c, kbClient, kb)
+	require.NoError(t, err)
+	require.Equal(t, []importRequest{{space: "", objects: indexPattern, overwrite: true}}, kbClient.imports)
+}
+
+func TestImport_Failures(t *testing.T) {
+	c := k8s.WrapClient(fake.NewFakeClient(configMap("index-patterns", map[string]string{"logs.ndjson": indexPattern})))
+
+	// missing ConfigMap
+	statuses, err := Import(context.Background(), c, &fakeClient{}, kibana(v1alpha1.SavedObjectsSource{ConfigMapName: "missing"}))
+	require.NoError(t, err)
+	require.False(t, AllImported(statuses))
+	require.Equal(t, []v1alpha1.SavedObjectsImportStatus{{ConfigMapName: "missing", Message: "ConfigMap not found"}}, statuses)
+
+	// Kibana API error
+	kb := kibana(v1alpha1.SavedObjectsSource{ConfigMapName: "index-patterns"})
+	statuses, err = Import(context.Background(), c, &fakeClient{importErr: errors.New("unavailable")}, kb)
+	require.NoError(t, err)
+	require.False(t, AllImported(statuses))
+	require.Equal(t, "logs.ndjson: unavailable", statuses[0].Message)
+
+	// user without Kibana privileges
+	statuses, err = Import(context.Background(), c, &fakeClient{importErr: &client.APIError{StatusCode: http.StatusForbidden}}, kb)
+	require.NoError(t, err)
+	require.False(t, AllImported(statuses))
+	require.Equal(t, "logs.ndjson: forbidden: the user importing saved objects is not granted the required Kibana privileges", statuses[0].Message)
+
+	// saved objects conflicts are imported again even if the ConfigMap did not change
+	conflict := client.ImportError{ID: "logs", Type: "index-pattern"}
+	conflict.Error.Type = "conflict"
+	kbClient := &fakeClient{errors: []client.ImportError{conflict}}
+	statuses, err = Import(context.Background(), c, kbClient, kb)
+	require.NoError(t, err)
+	require.False(t, AllImported(statuses))
+	require.Equal(t, "logs.ndjson: index-pattern logs: conflict", statuses[0].Message)
+	kb.Status.SavedObjects = statuses
+	_, err = Import(context.Background(), c, kbClient, kb)
+	require.NoError(t, err)
+	require.Len(t, kbClient.imports, 2)
+}
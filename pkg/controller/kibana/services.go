@@ -5,6 +5,9 @@
 package kibana
 
 import (
+	"net"
+	"strconv"
+
 	corev1 "k8s.io/api/core/v1"
 
 	kibanav1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
@@ -12,8 +15,19 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
 	kbname "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/name"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/pod"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/stringsutil"
 )
 
+// ExternalServiceURL returns the URL used to reach Kibana from within the Kubernetes cluster.
+func ExternalServiceURL(kb kibanav1alpha1.Kibana) string {
+	return stringsutil.Concat(kb.Spec.HTTP.Scheme(), "://", kbname.HTTPService(kb.Name), ".", kb.Namespace, ".svc:", strconv.Itoa(pod.HTTPPort))
+}
+
+// PodURL returns the URL used to reach the given Kibana pod directly, from within the Kubernetes cluster.
+func PodURL(kb kibanav1alpha1.Kibana, p corev1.Pod) string {
+	return stringsutil.Concat(kb.Spec.HTTP.Scheme(), "://", net.JoinHostPort(p.Status.PodIP, strconv.Itoa(pod.HTTPPort)))
+}
+
 func NewService(kb kibanav1alpha1.Kibana) *corev1.Service {
 	svc := corev1.Service{
 		ObjectMeta: kb.Spec.HTTP.Service.ObjectMeta,
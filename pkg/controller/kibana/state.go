@@ -6,6 +6,9 @@ package kibana
 
 import (
 	"github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	commondeployment "github.com/elastic/cloud-on-k8s/pkg/controller/common/deployment"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/observer"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -26,13 +29,55 @@ func NewState(request reconcile.Request, kb *v1alpha1.Kibana) State {
 	return State{Request: request, Kibana: kb, originalKibana: kb.DeepCopy()}
 }
 
-// UpdateKibanaState updates the Kibana status based on the given deployment.
-func (s State) UpdateKibanaState(deployment v1.Deployment) {
+// UpdateKibanaState updates the Kibana status based on the given deployment and on the state reported by Kibana.
+// Kibana is green only if the deployment is available and Kibana reports a green state through its status API.
+// The health is unknown until the Kibana instances are observed.
+func (s State) UpdateKibanaState(deployment v1.Deployment, observed observer.State) {
 	s.Kibana.Status.AvailableNodes = int(deployment.Status.AvailableReplicas) // TODO lossy type conversion
+	s.Kibana.Status.Selector = commondeployment.Selector(deployment)
 	s.Kibana.Status.Health = v1alpha1.KibanaRed
+	s.Kibana.Status.ElasticsearchConnection = v1alpha1.ElasticsearchConnectionUnknown
+	s.Kibana.Status.Plugins = nil
+
+	if !observed.Observed {
+		s.Kibana.Status.Health = v1alpha1.KibanaUnknown
+		return
+	}
+	if observed.Status == nil {
+		// the status API cannot be reached, Kibana may be starting or stuck
+		return
+	}
+	if es, exists := observed.Status.Elasticsearch(); exists {
+		s.Kibana.Status.ElasticsearchConnection = v1alpha1.ElasticsearchDisconnected
+		if es.State == client.GreenState {
+			s.Kibana.Status.ElasticsearchConnection = v1alpha1.ElasticsearchConnected
+		}
+	}
+	for _, p := range observed.Status.Status.Statuses {
+		if p.State == client.RedState || p.State == client.YellowState {
+			s.Kibana.Status.Plugins = append(s.Kibana.Status.Plugins, v1alpha1.KibanaPluginStatus{
+				ID:      p.ID,
+				State:   v1alpha1.KibanaHealth(p.State),
+				Message: p.Message,
+			})
+		}
+	}
+
 	for _, c := range deployment.Status.Conditions {
 		if c.Type == v1.DeploymentAvailable && c.Status == corev1.ConditionTrue {
-			s.Kibana.Status.Health = v1alpha1.KibanaGreen
+			s.Kibana.Status.Health = health(observed.Status.Status.Overall.State)
 		}
 	}
 }
+
+// health converts the overall state reported by Kibana to a KibanaHealth.
+func health(state string) v1alpha1.KibanaHealth {
+	switch state {
+	case client.GreenState:
+		return v1alpha1.KibanaGreen
+	case client.YellowState:
+		return v1alpha1.KibanaYellow
+	default:
+		return v1alpha1.KibanaRed
+	}
+}
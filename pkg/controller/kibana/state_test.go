@@ -0,0 +1,130 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibana
+
+import (
+	"testing"
+
+	"github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/observer"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func kibanaStatus(overall string, plugins ...client.PluginStatus) *client.Status {
+	status := client.Status{}
+	status.Status.Overall.State = overall
+	status.Status.Statuses = plugins
+	return &status
+}
+
+func TestState_UpdateKibanaState(t *testing.T) {
+	available := appsv1.Deployment{Status: appsv1.DeploymentStatus{
+		AvailableReplicas: 2,
+		Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+		},
+	}}
+	unavailable := appsv1.Deployment{Status: appsv1.DeploymentStatus{
+		Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse},
+		},
+	}}
+	esGreen := client.PluginStatus{ID: "plugin:elasticsearch@7.2.0", State: client.GreenState, Message: "Ready"}
+	esRed := client.PluginStatus{ID: "plugin:elasticsearch@7.2.0", State: client.RedState, Message: "Unable to connect to Elasticsearch."}
+	reportingYellow := client.PluginStatus{ID: "plugin:reporting@7.2.0", State: client.YellowState, Message: "Waiting for Elasticsearch"}
+
+	tests := []struct {
+		name       string
+		deployment appsv1.Deployment
+		observed   observer.State
+		want       v1alpha1.KibanaStatus
+	}{
+		{
+			name:       "not observed yet",
+			deployment: available,
+			observed:   observer.State{},
+			want: v1alpha1.KibanaStatus{
+				Health:                  v1alpha1.KibanaUnknown,
+				ElasticsearchConnection: v1alpha1.ElasticsearchConnectionUnknown,
+			},
+		},
+		{
+			name:       "status API not reachable",
+			deployment: available,
+			observed:   observer.State{Observed: true},
+			want: v1alpha1.KibanaStatus{
+				Health:                  v1alpha1.KibanaRed,
+				ElasticsearchConnection: v1alpha1.ElasticsearchConnectionUnknown,
+			},
+		},
+		{
+			name:       "green",
+			deployment: available,
+			observed:   observer.State{Observed: true, Status: kibanaStatus(client.GreenState, esGreen)},
+			want: v1alpha1.KibanaStatus{
+				Health:                  v1alpha1.KibanaGreen,
+				ElasticsearchConnection: v1alpha1.ElasticsearchConnected,
+			},
+		},
+		{
+			name:       "yellow plugin",
+			deployment: available,
+			observed:   observer.State{Observed: true, Status: kibanaStatus(client.YellowState, esGreen, reportingYellow)},
+			want: v1alpha1.KibanaStatus{
+				Health:                  v1alpha1.KibanaYellow,
+				ElasticsearchConnection: v1alpha1.ElasticsearchConnected,
+				Plugins: []v1alpha1.KibanaPluginStatus{
+					{ID: reportingYellow.ID, State: v1alpha1.KibanaYellow, Message: reportingYellow.Message},
+				},
+			},
+		},
+		{
+			name:       "Elasticsearch not reachable from Kibana",
+			deployment: available,
+			observed:   observer.State{Observed: true, Status: kibanaStatus(client.RedState, esRed)},
+			want: v1alpha1.KibanaStatus{
+				Health:                  v1alpha1.KibanaRed,
+				ElasticsearchConnection: v1alpha1.ElasticsearchDisconnected,
+				Plugins: []v1alpha1.KibanaPluginStatus{
+					{ID: esRed.ID, State: v1alpha1.KibanaRed, Message: esRed.Message},
+				},
+			},
+		},
+		{
+			name:       "deployment not available",
+			deployment: unavailable,
+			observed:   observer.State{Observed: true, Status: kibanaStatus(client.GreenState, esGreen)},
+			want: v1alpha1.KibanaStatus{
+				Health:                  v1alpha1.KibanaRed,
+				ElasticsearchConnection: v1alpha1.ElasticsearchConnected,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb := v1alpha1.Kibana{Status: v1alpha1.KibanaStatus{
+				Plugins: []v1alpha1.KibanaPluginStatus{{ID: "previous", State: v1alpha1.KibanaRed}},
+			}}
+			state := NewState(reconcile.Request{}, &kb)
+			state.UpdateKibanaState(tt.deployment, tt.observed)
+			tt.want.AvailableNodes = int(tt.deployment.Status.AvailableReplicas)
+			require.Equal(t, tt.want, kb.Status)
+		})
+	}
+}
+
+func TestState_UpdateKibanaState_Selector(t *testing.T) {
+	deployment := appsv1.Deployment{Spec: appsv1.DeploymentSpec{
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"kibana.k8s.elastic.co/name": "kb"}},
+	}}
+	kb := v1alpha1.Kibana{}
+	NewState(reconcile.Request{}, &kb).UpdateKibanaState(deployment, observer.State{})
+	require.Equal(t, "kibana.k8s.elastic.co/name=kb", kb.Status.Selector)
+}
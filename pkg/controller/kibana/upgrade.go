@@ -0,0 +1,194 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibana
+
+import (
+	"strings"
+	"time"
+
+	kbtype "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	eslabel "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	esversion "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/version"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
+	kbname "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/name"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/pod"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// versionUpgradeRequeueAfter is the delay before checking again whether a delayed version change can be rolled out.
+const versionUpgradeRequeueAfter = 10 * time.Second
+
+// pinVersion returns the Kibana to deploy. A version change is not applied while the associated Elasticsearch cluster
+// runs nodes in an incompatible version: the returned Kibana is then a copy of the given one pinned to the version and
+// image of the existing deployment, along with true, so that other changes are still applied.
+func (d *driver) pinVersion(kb *kbtype.Kibana) (*kbtype.Kibana, bool, error) {
+	actual, err := d.actualDeployment(*kb)
+	if err != nil {
+		return nil, false, err
+	}
+	// version of the pods specified by the existing deployment, empty if there is no deployment yet
+	actualVersion := podVersion(actual.Spec.Template.Labels, actual.Spec.Template.Spec)
+
+	delay, err := d.shouldDelayVersionChange(kb, actualVersion)
+	if err != nil {
+		return nil, false, err
+	}
+	if !delay {
+		return kb, false, nil
+	}
+	pinned := kb.DeepCopy()
+	pinned.Spec.Version = actualVersion
+	if container := pod.GetKibanaContainer(actual.Spec.Template.Spec); container != nil {
+		pinned.Spec.Image = container.Image
+	}
+	return pinned, true, nil
+}
+
+// reconcileDeployment reconciles the Kibana deployment built from the given parameters, with a strategy depending on
+// whether the version of the pods changes.
+func (d *driver) reconcileDeployment(kb *kbtype.Kibana, params DeploymentParams) (appsv1.Deployment, error) {
+	actual, err := d.actualDeployment(*kb)
+	if err != nil {
+		return appsv1.Deployment{}, err
+	}
+	actualVersion := podVersion(actual.Spec.Template.Labels, actual.Spec.Template.Spec)
+	params.Strategy, err = updateStrategy(d.client, *kb, actualVersion)
+	if err != nil {
+		return appsv1.Deployment{}, err
+	}
+	return ReconcileDeployment(d.client, d.scheme, NewDeployment(params), kb)
+}
+
+// actualDeployment returns the existing deployment of the given Kibana, empty if there is none yet.
+func (d *driver) actualDeployment(kb kbtype.Kibana) (appsv1.Deployment, error) {
+	var actual appsv1.Deployment
+	err := d.client.Get(types.NamespacedName{Namespace: kb.Namespace, Name: kbname.KBNamer.Suffix(kb.Name)}, &actual)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return appsv1.Deployment{}, err
+	}
+	return actual, nil
+}
+
+// shouldDelayVersionChange returns true if Kibana should not be moved from the given running version to the expected
+// one yet, because some nodes of the associated Elasticsearch cluster still run an older, incompatible version.
+// This typically happens when Kibana and Elasticsearch are upgraded together: Kibana refuses to start against
+// older Elasticsearch nodes, it must wait for the Elasticsearch rolling upgrade to be over.
+func (d *driver) shouldDelayVersionChange(kb *kbtype.Kibana, actualVersion string) (bool, error) {
+	if actualVersion == "" || actualVersion == kb.Spec.Version {
+		return false, nil
+	}
+	expectedVersion, err := version.Parse(kb.Spec.Version)
+	if err != nil {
+		return false, err
+	}
+	esVersion, err := minElasticsearchVersion(d.client, *kb)
+	if err != nil {
+		return false, err
+	}
+	if esVersion == nil || isCompatible(*expectedVersion, *esVersion) {
+		return false, nil
+	}
+	log.Info(
+		"Delaying Kibana version change until all Elasticsearch nodes run a compatible version",
+		"namespace", kb.Namespace,
+		"kibana_name", kb.Name,
+		"current_version", actualVersion,
+		"expected_version", kb.Spec.Version,
+		"elasticsearch_version", esVersion.String(),
+	)
+	d.recorder.Eventf(kb, corev1.EventTypeNormal, events.EventReasonDelayed,
+		"Delaying version change to %s until all Elasticsearch nodes run version %d.%d or later",
+		kb.Spec.Version, expectedVersion.Major, expectedVersion.Minor)
+	return true, nil
+}
+
+// isCompatible returns true if Kibana in the given version can run against Elasticsearch nodes in the given version,
+// which must be on the same or a later minor version.
+func isCompatible(kbVersion version.Version, esVersion version.Version) bool {
+	return esVersion.IsSameOrAfter(version.Version{Major: kbVersion.Major, Minor: kbVersion.Minor})
+}
+
+// minElasticsearchVersion returns the lowest version run by the nodes of the Elasticsearch cluster referenced by the
+// given Kibana, or nil if Kibana does not reference any Elasticsearch cluster or if it does not have any node yet.
+func minElasticsearchVersion(c k8s.Client, kb kbtype.Kibana) (*version.Version, error) {
+	if !kb.Spec.ElasticsearchRef.IsDefined() {
+		return nil, nil
+	}
+	es := kb.Spec.ElasticsearchRef.NamespacedName()
+	if es.Namespace == "" {
+		es.Namespace = kb.Namespace
+	}
+	var pods corev1.PodList
+	if err := c.List(&client.ListOptions{
+		Namespace:     es.Namespace,
+		LabelSelector: eslabel.NewLabelSelectorForElasticsearchClusterName(es.Name),
+	}, &pods); err != nil {
+		return nil, err
+	}
+	return esversion.MinVersion(pods.Items)
+}
+
+// updateStrategy returns the strategy to replace the Kibana pods. Kibana instances in different versions cannot
+// run against the same Kibana index: on version changes, all existing pods are deleted before pods in the new
+// version are created, until no pod in another version is left. Other changes are rolled out progressively.
+func updateStrategy(c k8s.Client, kb kbtype.Kibana, actualVersion string) (appsv1.DeploymentStrategy, error) {
+	recreate := appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	if actualVersion != "" && actualVersion != kb.Spec.Version {
+		return recreate, nil
+	}
+	// a version change may still be in progress
+	var pods corev1.PodList
+	if err := c.List(&client.ListOptions{
+		Namespace:     kb.Namespace,
+		LabelSelector: labels.SelectorFromSet(label.NewLabels(kb.Name)),
+	}, &pods); err != nil {
+		return appsv1.DeploymentStrategy{}, err
+	}
+	for _, p := range pods.Items {
+		if v := podVersion(p.Labels, p.Spec); v != "" && v != kb.Spec.Version {
+			return recreate, nil
+		}
+	}
+	return appsv1.DeploymentStrategy{}, nil
+}
+
+// podVersion returns the Kibana version of pods with the given labels and spec. Pods created by older versions of
+// the operator do not have the version label: the version is then parsed from the tag of the Kibana container image.
+// An empty string is returned if the version cannot be determined.
+func podVersion(podLabels map[string]string, spec corev1.PodSpec) string {
+	if v, exists := podLabels[label.KibanaVersionLabelName]; exists {
+		return v
+	}
+	container := pod.GetKibanaContainer(spec)
+	if container == nil {
+		return ""
+	}
+	return imageVersion(container.Image)
+}
+
+// imageVersion returns the version in the tag of the given image, such as 7.2.0 for
+// docker.elastic.co/kibana/kibana:7.2.0, or an empty string if the tag is not a version.
+func imageVersion(image string) string {
+	// ignore the digest, and the registry which may contain a port
+	image = strings.SplitN(image, "@", 2)[0]
+	image = image[strings.LastIndex(image, "/")+1:]
+	separator := strings.LastIndex(image, ":")
+	if separator < 0 {
+		return ""
+	}
+	tag := image[separator+1:]
+	if _, err := version.Parse(tag); err != nil {
+		return ""
+	}
+	return tag
+}
@@ -0,0 +1,351 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibana
+
+import (
+	"testing"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	kbtype "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	eslabel "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/pod"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func upgradeKibanaFixture(v string) kbtype.Kibana {
+	return kbtype.Kibana{
+		ObjectMeta: metav1.ObjectMeta{Name: "kb", Namespace: "ns"},
+		Spec: kbtype.KibanaSpec{
+			Version:          v,
+			NodeCount:        1,
+			ElasticsearchRef: commonv1alpha1.ObjectSelector{Name: "es"},
+		},
+	}
+}
+
+func esPod(name string, v string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns",
+			Labels: map[string]string{
+				eslabel.ClusterNameLabelName: "es",
+				eslabel.VersionLabelName:     v,
+			},
+		},
+	}
+}
+
+func kbPod(name string, v string) *corev1.Pod {
+	labels := label.NewLabels("kb")
+	labels[label.KibanaVersionLabelName] = v
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: labels},
+	}
+}
+
+// unlabeledKbPod returns a Kibana pod created before pods were labeled with their version.
+func unlabeledKbPod(name string, v string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: label.NewLabels("kb")},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: kbtype.KibanaContainerName, Image: "docker.elastic.co/kibana/kibana:" + v},
+		}},
+	}
+}
+
+func kbDeployment(v string) appsv1.Deployment {
+	template := corev1.PodTemplateSpec{}
+	template.Labels = label.NewLabels("kb")
+	template.Labels[label.KibanaVersionLabelName] = v
+	return NewDeployment(DeploymentParams{
+		Name:            "kb-kb",
+		Namespace:       "ns",
+		Selector:        label.NewLabels("kb"),
+		Labels:          label.NewLabels("kb"),
+		Replicas:        1,
+		PodTemplateSpec: template,
+	})
+}
+
+func Test_isCompatible(t *testing.T) {
+	tests := []struct {
+		kb   string
+		es   string
+		want bool
+	}{
+		{kb: "7.3.0", es: "7.3.0", want: true},
+		{kb: "7.3.1", es: "7.3.0", want: true},
+		{kb: "7.3.0", es: "7.4.0", want: true},
+		{kb: "7.3.0", es: "8.0.0", want: true},
+		{kb: "7.3.0", es: "7.2.1", want: false},
+		{kb: "7.0.0", es: "6.8.0", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kb+" against "+tt.es, func(t *testing.T) {
+			require.Equal(t, tt.want, isCompatible(version.MustParse(tt.kb), version.MustParse(tt.es)))
+		})
+	}
+}
+
+func Test_driver_shouldDelayVersionChange(t *testing.T) {
+	tests := []struct {
+		name          string
+		kb            kbtype.Kibana
+		actualVersion string
+		esPods        []runtime.Object
+		want          bool
+	}{
+		{
+			name:          "no existing deployment",
+			kb:            upgradeKibanaFixture("7.3.0"),
+			actualVersion: "",
+			esPods:        []runtime.Object{esPod("es-1", "7.2.0")},
+			want:          false,
+		},
+		{
+			name:          "no version change",
+			kb:            upgradeKibanaFixture("7.3.0"),
+			actualVersion: "7.3.0",
+			esPods:        []runtime.Object{esPod("es-1", "7.2.0")},
+			want:          false,
+		},
+		{
+			name:          "Elasticsearch upgrade in progress",
+			kb:            upgradeKibanaFixture("7.3.0"),
+			actualVersion: "7.2.0",
+			esPods:        []runtime.Object{esPod("es-1", "7.3.0"), esPod("es-2", "7.2.0")},
+			want:          true,
+		},
+		{
+			name:          "Elasticsearch upgrade over",
+			kb:            upgradeKibanaFixture("7.3.0"),
+			actualVersion: "7.2.0",
+			esPods:        []runtime.Object{esPod("es-1", "7.3.0"), esPod("es-2", "7.3.0")},
+			want:          false,
+		},
+		{
+			name:          "no Elasticsearch pods",
+			kb:            upgradeKibanaFixture("7.3.0"),
+			actualVersion: "7.2.0",
+			want:          false,
+		},
+		{
+			name: "no Elasticsearch reference",
+			kb: func() kbtype.Kibana {
+				kb := upgradeKibanaFixture("7.3.0")
+				kb.Spec.ElasticsearchRef = commonv1alpha1.ObjectSelector{}
+				return kb
+			}(),
+			actualVersion: "7.2.0",
+			esPods:        []runtime.Object{esPod("es-1", "7.2.0")},
+			want:          false,
+		},
+		{
+			name: "Elasticsearch in another namespace",
+			kb: func() kbtype.Kibana {
+				kb := upgradeKibanaFixture("7.3.0")
+				kb.Spec.ElasticsearchRef.Namespace = "other"
+				return kb
+			}(),
+			actualVersion: "7.2.0",
+			esPods:        []runtime.Object{esPod("es-1", "7.2.0")},
+			want:          false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &driver{
+				client:   k8s.WrapClient(fake.NewFakeClient(tt.esPods...)),
+				scheme:   scheme.Scheme,
+				recorder: record.NewFakeRecorder(10),
+			}
+			got, err := d.shouldDelayVersionChange(&tt.kb, tt.actualVersion)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_updateStrategy(t *testing.T) {
+	recreate := appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	tests := []struct {
+		name          string
+		actualVersion string
+		kbPods        []runtime.Object
+		want          appsv1.DeploymentStrategy
+	}{
+		{
+			name:          "no existing deployment",
+			actualVersion: "",
+			want:          appsv1.DeploymentStrategy{},
+		},
+		{
+			name:          "no version change",
+			actualVersion: "7.3.0",
+			kbPods:        []runtime.Object{kbPod("kb-1", "7.3.0")},
+			want:          appsv1.DeploymentStrategy{},
+		},
+		{
+			name:          "version change",
+			actualVersion: "7.2.0",
+			kbPods:        []runtime.Object{kbPod("kb-1", "7.2.0")},
+			want:          recreate,
+		},
+		{
+			name:          "version change in progress",
+			actualVersion: "7.3.0",
+			kbPods:        []runtime.Object{kbPod("kb-1", "7.2.0"), kbPod("kb-2", "7.3.0")},
+			want:          recreate,
+		},
+		{
+			name:          "version change in progress with pods created before the version label",
+			actualVersion: "7.3.0",
+			kbPods:        []runtime.Object{unlabeledKbPod("kb-1", "7.2.0"), kbPod("kb-2", "7.3.0")},
+			want:          recreate,
+		},
+		{
+			name:          "no version change with pods created before the version label",
+			actualVersion: "7.3.0",
+			kbPods:        []runtime.Object{unlabeledKbPod("kb-1", "7.3.0")},
+			want:          appsv1.DeploymentStrategy{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.WrapClient(fake.NewFakeClient(tt.kbPods...))
+			got, err := updateStrategy(c, upgradeKibanaFixture("7.3.0"), tt.actualVersion)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// deploymentParamsFor returns the parameters of the deployment of the given Kibana, without its configuration.
+func deploymentParamsFor(kb kbtype.Kibana) DeploymentParams {
+	template := pod.NewPodTemplateSpec(kb, nil)
+	template.Labels[label.KibanaVersionLabelName] = kb.Spec.Version
+	return DeploymentParams{
+		Name:            "kb-kb",
+		Namespace:       "ns",
+		Selector:        label.NewLabels("kb"),
+		Labels:          label.NewLabels("kb"),
+		Replicas:        kb.Spec.NodeCount,
+		PodTemplateSpec: template,
+	}
+}
+
+func Test_driver_pinVersion(t *testing.T) {
+	s := scheme.Scheme
+	require.NoError(t, kbtype.SchemeBuilder.AddToScheme(s))
+	kb := upgradeKibanaFixture("7.3.0")
+	existing := NewDeployment(deploymentParamsFor(upgradeKibanaFixture("7.2.0")))
+
+	// the Elasticsearch upgrade is not over: Kibana is pinned to the existing version and image
+	c := k8s.WrapClient(fake.NewFakeClient(&existing, kbPod("kb-1", "7.2.0"), esPod("es-1", "7.3.0"), esPod("es-2", "7.2.0")))
+	d := &driver{client: c, scheme: s, recorder: record.NewFakeRecorder(10)}
+	deployed, delayed, err := d.pinVersion(&kb)
+	require.NoError(t, err)
+	require.True(t, delayed)
+	require.Equal(t, "7.2.0", deployed.Spec.Version)
+	require.Equal(t, "docker.elastic.co/kibana/kibana:7.2.0", deployed.Spec.Image)
+	require.Equal(t, "7.3.0", kb.Spec.Version)
+	reconciled, err := d.reconcileDeployment(deployed, deploymentParamsFor(*deployed))
+	require.NoError(t, err)
+	require.Equal(t, "7.2.0", reconciled.Spec.Template.Labels[label.KibanaVersionLabelName])
+
+	// the Elasticsearch upgrade is over: the deployment is updated, recreating all pods
+	require.NoError(t, c.Update(esPod("es-2", "7.3.0")))
+	deployed, delayed, err = d.pinVersion(&kb)
+	require.NoError(t, err)
+	require.False(t, delayed)
+	require.Equal(t, &kb, deployed)
+	reconciled, err = d.reconcileDeployment(deployed, deploymentParamsFor(*deployed))
+	require.NoError(t, err)
+	require.Equal(t, "7.3.0", reconciled.Spec.Template.Labels[label.KibanaVersionLabelName])
+	require.Equal(t, appsv1.RecreateDeploymentStrategyType, reconciled.Spec.Strategy.Type)
+}
+
+func Test_driver_pinVersion_OtherChanges(t *testing.T) {
+	s := scheme.Scheme
+	require.NoError(t, kbtype.SchemeBuilder.AddToScheme(s))
+	existing := NewDeployment(deploymentParamsFor(upgradeKibanaFixture("7.2.0")))
+	// the version change comes with other changes
+	kb := upgradeKibanaFixture("7.3.0")
+	kb.Spec.NodeCount = 3
+	kb.Spec.PodTemplate.Labels = map[string]string{"mylabel": "value"}
+
+	c := k8s.WrapClient(fake.NewFakeClient(&existing, kbPod("kb-1", "7.2.0"), esPod("es-1", "7.2.0")))
+	d := &driver{client: c, scheme: s, recorder: record.NewFakeRecorder(10)}
+	deployed, delayed, err := d.pinVersion(&kb)
+	require.NoError(t, err)
+	require.True(t, delayed)
+
+	// the other changes are rolled out progressively in the existing version
+	reconciled, err := d.reconcileDeployment(deployed, deploymentParamsFor(*deployed))
+	require.NoError(t, err)
+	require.Equal(t, int32(3), *reconciled.Spec.Replicas)
+	require.Equal(t, "value", reconciled.Spec.Template.Labels["mylabel"])
+	require.Equal(t, "7.2.0", reconciled.Spec.Template.Labels[label.KibanaVersionLabelName])
+	require.Equal(t, "docker.elastic.co/kibana/kibana:7.2.0", pod.GetKibanaContainer(reconciled.Spec.Template.Spec).Image)
+	require.NotEqual(t, appsv1.RecreateDeploymentStrategyType, reconciled.Spec.Strategy.Type)
+}
+
+func Test_driver_pinVersion_UnlabeledDeployment(t *testing.T) {
+	s := scheme.Scheme
+	require.NoError(t, kbtype.SchemeBuilder.AddToScheme(s))
+	kb := upgradeKibanaFixture("7.3.0")
+	// deployment created before pods were labeled with their version
+	existing := kbDeployment("7.2.0")
+	delete(existing.Spec.Template.Labels, label.KibanaVersionLabelName)
+	existing.Spec.Template.Spec.Containers = unlabeledKbPod("kb-1", "7.2.0").Spec.Containers
+
+	// the version is parsed from the image: the version change is delayed until Elasticsearch is upgraded
+	c := k8s.WrapClient(fake.NewFakeClient(&existing, unlabeledKbPod("kb-1", "7.2.0"), esPod("es-1", "7.2.0")))
+	d := &driver{client: c, scheme: s, recorder: record.NewFakeRecorder(10)}
+	deployed, delayed, err := d.pinVersion(&kb)
+	require.NoError(t, err)
+	require.True(t, delayed)
+	require.Equal(t, "7.2.0", deployed.Spec.Version)
+
+	// then all pods are recreated
+	require.NoError(t, c.Update(esPod("es-1", "7.3.0")))
+	deployed, delayed, err = d.pinVersion(&kb)
+	require.NoError(t, err)
+	require.False(t, delayed)
+	reconciled, err := d.reconcileDeployment(deployed, deploymentParamsFor(*deployed))
+	require.NoError(t, err)
+	require.Equal(t, appsv1.RecreateDeploymentStrategyType, reconciled.Spec.Strategy.Type)
+}
+
+func Test_imageVersion(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "docker.elastic.co/kibana/kibana:7.2.0", want: "7.2.0"},
+		{image: "docker.elastic.co/kibana/kibana:7.3.0-SNAPSHOT", want: "7.3.0-SNAPSHOT"},
+		{image: "registry.local:5000/kibana:6.8.1", want: "6.8.1"},
+		{image: "registry.local:5000/kibana:6.8.1@sha256:0123456789abcdef", want: "6.8.1"},
+		{image: "registry.local:5000/kibana", want: ""},
+		{image: "kibana:latest", want: ""},
+		{image: "kibana", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			require.Equal(t, tt.want, imageVersion(tt.image))
+		})
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package validation
+
+import (
+	"github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/validation"
+	esversion "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/version"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/config"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// Validate runs all validations against the given Kibana and returns the failed ones.
+func Validate(c k8s.Client, kb v1alpha1.Kibana) []validation.Result {
+	return validation.ValidateAssociated(c, validation.Associated{
+		Namespace:                kb.Namespace,
+		Version:                  kb.Spec.Version,
+		LowestVersion:            esversion.SupportedRange.LowestSupportedVersion,
+		HighestVersion:           esversion.SupportedRange.HighestSupportedVersion,
+		Config:                   kb.Spec.Config,
+		Blacklist:                blacklist(kb),
+		ElasticsearchRef:         kb.Spec.ElasticsearchRef,
+		ExternalElasticsearchRef: kb.Spec.ExternalElasticsearchRef,
+		TLS:                      kb.Spec.HTTP.TLS,
+	})
+}
+
+// blacklist returns the settings managed by the operator, which cannot be overridden in the configuration.
+func blacklist(kb v1alpha1.Kibana) []string {
+	if kb.Spec.ElasticsearchRef.IsDefined() || kb.Spec.ExternalElasticsearchRef.IsDefined() {
+		return append(append([]string{}, config.Blacklist...), config.AssociationBlacklist...)
+	}
+	return config.Blacklist
+}
@@ -0,0 +1,117 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package validation
+
+import (
+	"testing"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidate(t *testing.T) {
+	kibana := func(mutate func(kb *v1alpha1.Kibana)) v1alpha1.Kibana {
+		kb := v1alpha1.Kibana{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "kb"},
+			Spec: v1alpha1.KibanaSpec{
+				Version:          "7.2.0",
+				ElasticsearchRef: commonv1alpha1.ObjectSelector{Name: "es"},
+			},
+		}
+		mutate(&kb)
+		return kb
+	}
+	tests := []struct {
+		name        string
+		kb          v1alpha1.Kibana
+		objects     []runtime.Object
+		wantReasons []string
+	}{
+		{
+			name: "valid",
+			kb:   kibana(func(kb *v1alpha1.Kibana) {}),
+		},
+		{
+			name:        "unsupported version",
+			kb:          kibana(func(kb *v1alpha1.Kibana) { kb.Spec.Version = "6.6.0" }),
+			wantReasons: []string{"unsupported version: 6.6.0, supported versions are 6.7.0 to 7.99.99"},
+		},
+		{
+			name: "settings managed by the operator",
+			kb: kibana(func(kb *v1alpha1.Kibana) {
+				kb.Spec.Config = &commonv1alpha1.Config{Data: map[string]interface{}{
+					"server.host":            "localhost",
+					"elasticsearch.password": "changeme",
+				}}
+			}),
+			wantReasons: []string{"elasticsearch.password, server.host is not user configurable"},
+		},
+		{
+			name: "Elasticsearch settings can be set without Elasticsearch reference",
+			kb: kibana(func(kb *v1alpha1.Kibana) {
+				kb.Spec.ElasticsearchRef = commonv1alpha1.ObjectSelector{}
+				kb.Spec.Config = &commonv1alpha1.Config{Data: map[string]interface{}{
+					"elasticsearch.password": "changeme",
+				}}
+			}),
+		},
+		{
+			name: "malformed Elasticsearch reference",
+			kb: kibana(func(kb *v1alpha1.Kibana) {
+				kb.Spec.ElasticsearchRef = commonv1alpha1.ObjectSelector{Namespace: "other"}
+			}),
+			wantReasons: []string{"elasticsearchRef: name is required when namespace is set"},
+		},
+		{
+			name: "external Elasticsearch reference",
+			kb: kibana(func(kb *v1alpha1.Kibana) {
+				kb.Spec.ElasticsearchRef = commonv1alpha1.ObjectSelector{}
+				kb.Spec.ExternalElasticsearchRef = &commonv1alpha1.ExternalElasticsearchRef{
+					URL:                    "https://legacy-es:9200",
+					AdminSecretName:        "legacy-es-admin",
+					CertificateAuthorities: commonv1alpha1.SecretRef{SecretName: "legacy-es-ca"},
+				}
+			}),
+		},
+		{
+			name: "both Elasticsearch references",
+			kb: kibana(func(kb *v1alpha1.Kibana) {
+				kb.Spec.ExternalElasticsearchRef = &commonv1alpha1.ExternalElasticsearchRef{
+					URL:             "https://legacy-es:9200",
+					AdminSecretName: "legacy-es-admin",
+				}
+			}),
+			wantReasons: []string{"externalElasticsearchRef cannot be used together with elasticsearchRef"},
+		},
+		{
+			name: "certificate secret without private key",
+			kb: kibana(func(kb *v1alpha1.Kibana) {
+				kb.Spec.HTTP.TLS.Certificate.SecretName = "my-cert"
+			}),
+			objects: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "my-cert"},
+				Data:       map[string][]byte{"tls.crt": []byte("crt")},
+			}},
+			wantReasons: []string{"Certificate secret my-cert is missing tls.key"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Validate(k8s.WrapClient(fake.NewFakeClient(tt.objects...)), tt.kb)
+			var reasons []string
+			for _, r := range results {
+				require.False(t, r.Allowed)
+				reasons = append(reasons, r.Reason)
+			}
+			require.Equal(t, tt.wantReasons, reasons)
+		})
+	}
+}
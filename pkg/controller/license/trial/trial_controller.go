@@ -44,6 +44,8 @@ type ReconcileTrials struct {
 	k8s.Client
 	scheme   *runtime.Scheme
 	recorder record.EventRecorder
+	// operatorNamespace is the only namespace in which trials are started, since the trial status is looked up there.
+	operatorNamespace string
 	// iteration is the number of times this controller has run its Reconcile method.
 	iteration   int64
 	trialPubKey *rsa.PublicKey
@@ -132,11 +134,12 @@ func (r *ReconcileTrials) reconcileTrialStatus(trialStatus corev1.Secret) error
 
 }
 
-func newReconciler(mgr manager.Manager, _ operator.Parameters) *ReconcileTrials {
+func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileTrials {
 	return &ReconcileTrials{
-		Client:   k8s.WrapClient(mgr.GetClient()),
-		scheme:   mgr.GetScheme(),
-		recorder: mgr.GetRecorder(name),
+		Client:            k8s.WrapClient(mgr.GetClient()),
+		scheme:            mgr.GetScheme(),
+		recorder:          mgr.GetRecorder(name),
+		operatorNamespace: params.OperatorNamespace,
 	}
 }
 
@@ -153,6 +156,11 @@ func add(mgr manager.Manager, r *ReconcileTrials) error {
 			secret, ok := obj.Object.(*corev1.Secret)
 			if !ok {
 				log.Error(fmt.Errorf("object of type %T in secret watch", obj.Object), "dropping event due to type error")
+				return nil
+			}
+			if obj.Meta.GetNamespace() != r.operatorNamespace {
+				// secrets in managed namespaces cannot start a trial
+				return nil
 			}
 			if licensing.IsEnterpriseTrial(*secret) {
 				return []reconcile.Request{
@@ -0,0 +1,27 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package apmserver
+
+import (
+	"github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1alpha1"
+	apmv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1beta1"
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/apmserver/validation"
+	commonvalidation "github.com/elastic/cloud-on-k8s/pkg/controller/common/validation"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/webhook/common"
+)
+
+// NewValidationHandler returns a handler exposing APM Server validations as an admission.Handler.
+func NewValidationHandler() *common.ValidationHandler {
+	return common.NewValidationHandler("apm-validation", common.Validator{
+		NewObjects: func() (commonv1alpha1.Convertible, commonv1alpha1.Hub) {
+			return &v1alpha1.ApmServer{}, &apmv1beta1.ApmServer{}
+		},
+		Validate: func(c k8s.Client, obj commonv1alpha1.Convertible) []commonvalidation.Result {
+			return validation.Validate(c, *obj.(*v1alpha1.ApmServer))
+		},
+	})
+}
@@ -0,0 +1,89 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package common
+
+import (
+	"context"
+	"net/http"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	commonvalidation "github.com/elastic/cloud-on-k8s/pkg/controller/common/validation"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/webhook/conversion"
+	"github.com/go-logr/logr"
+	"k8s.io/api/admission/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+// Validator describes how the resources of a kind are decoded and validated.
+type Validator struct {
+	// NewObjects returns an empty resource of the validated version, and an empty resource of the hub version it is
+	// converted from when the request is sent for the hub version.
+	NewObjects func() (commonv1alpha1.Convertible, commonv1alpha1.Hub)
+	// Validate returns the failed validations of the given resource, of the validated version.
+	Validate func(c k8s.Client, obj commonv1alpha1.Convertible) []commonvalidation.Result
+}
+
+// ValidationHandler exposes the validations of a kind as an admission.Handler.
+type ValidationHandler struct {
+	log       logr.Logger
+	validator Validator
+	client    client.Client
+	decoder   types.Decoder
+}
+
+// NewValidationHandler returns a ValidationHandler running the given validations, logging with the given name.
+func NewValidationHandler(name string, validator Validator) *ValidationHandler {
+	return &ValidationHandler{log: logf.Log.WithName(name), validator: validator}
+}
+
+var _ admission.Handler = &ValidationHandler{}
+
+// Handle processes AdmissionRequests.
+func (v *ValidationHandler) Handle(ctx context.Context, r types.Request) types.Response {
+	if r.AdmissionRequest.Operation == v1beta1.Delete {
+		return admission.ValidationResponse(true, "allowing all deletes")
+	}
+	v.log.V(1).Info("ValidationHandler handler called",
+		"operation", r.AdmissionRequest.Operation,
+		"name", r.AdmissionRequest.Name,
+		"namespace", r.AdmissionRequest.Namespace,
+	)
+	obj, hub := v.validator.NewObjects()
+	if err := conversion.Decode(v.decoder, r, obj, hub); err != nil {
+		v.log.Error(err, "Failed to decode request")
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+	return v.aggregate(v.validator.Validate(k8s.WrapClient(v.client), obj))
+}
+
+func (v *ValidationHandler) aggregate(results []commonvalidation.Result) types.Response {
+	for _, r := range results {
+		if !r.Allowed && r.Error != nil {
+			v.log.Error(r.Error, r.Reason)
+		}
+	}
+	response := commonvalidation.Aggregate(results)
+	v.log.V(1).Info("Admission validation response", "allowed", response.Allowed, "reason", response.Reason)
+	return admission.ValidationResponse(response.Allowed, response.Reason)
+}
+
+var _ inject.Decoder = &ValidationHandler{}
+
+func (v *ValidationHandler) InjectDecoder(d types.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+var _ inject.Client = &ValidationHandler{}
+
+func (v *ValidationHandler) InjectClient(c client.Client) error {
+	v.client = c
+	return nil
+}
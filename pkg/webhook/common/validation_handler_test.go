@@ -0,0 +1,118 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package common
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	kbv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1beta1"
+	commonvalidation "github.com/elastic/cloud-on-k8s/pkg/controller/common/validation"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+type mockDecoder struct {
+	err error
+	obj runtime.Object
+}
+
+func (m mockDecoder) Decode(_ types.Request, o runtime.Object) error {
+	if m.obj != nil {
+		reflect.ValueOf(o).Elem().Set(reflect.ValueOf(m.obj).Elem())
+	}
+	return m.err
+}
+
+// kibanaValidator rejects the Kibana resources with a version other than 7.2.0.
+var kibanaValidator = Validator{
+	NewObjects: func() (commonv1alpha1.Convertible, commonv1alpha1.Hub) {
+		return &v1alpha1.Kibana{}, &kbv1beta1.Kibana{}
+	},
+	Validate: func(_ k8s.Client, obj commonv1alpha1.Convertible) []commonvalidation.Result {
+		kb := obj.(*v1alpha1.Kibana)
+		var results []commonvalidation.Result
+		if kb.Spec.Version != "7.2.0" {
+			results = append(results, commonvalidation.Result{Reason: "unsupported version " + kb.Spec.Version})
+		}
+		if kb.Spec.NodeCount > 3 {
+			results = append(results, commonvalidation.Result{Reason: "too many nodes"})
+		}
+		return results
+	},
+}
+
+func TestValidationHandler_Handle(t *testing.T) {
+	request := func(op admissionv1beta1.Operation) types.Request {
+		return types.Request{AdmissionRequest: &admissionv1beta1.AdmissionRequest{Operation: op, Namespace: "ns", Name: "kb"}}
+	}
+	kibana := func(version string, nodeCount int32) *v1alpha1.Kibana {
+		return &v1alpha1.Kibana{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "kb"},
+			Spec:       v1alpha1.KibanaSpec{Version: version, NodeCount: nodeCount},
+		}
+	}
+	tests := []struct {
+		name        string
+		request     types.Request
+		decoder     mockDecoder
+		wantAllowed bool
+		wantReason  string
+	}{
+		{
+			name:        "deletes are allowed",
+			request:     request(admissionv1beta1.Delete),
+			decoder:     mockDecoder{err: errors.New("should not be decoded")},
+			wantAllowed: true,
+			wantReason:  "allowing all deletes",
+		},
+		{
+			name:        "valid resource",
+			request:     request(admissionv1beta1.Create),
+			decoder:     mockDecoder{obj: kibana("7.2.0", 1)},
+			wantAllowed: true,
+		},
+		{
+			name:        "all failed validations are reported",
+			request:     request(admissionv1beta1.Update),
+			decoder:     mockDecoder{obj: kibana("8.0.0", 4)},
+			wantAllowed: false,
+			wantReason:  "unsupported version 8.0.0. too many nodes",
+		},
+		{
+			name:        "decoding errors are reported",
+			request:     request(admissionv1beta1.Create),
+			decoder:     mockDecoder{err: errors.New("invalid")},
+			wantAllowed: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &ValidationHandler{
+				log:       logf.Log.WithName("test"),
+				validator: kibanaValidator,
+				client:    fake.NewFakeClient(),
+				decoder:   tt.decoder,
+			}
+			got := v.Handle(context.Background(), tt.request)
+			require.Equal(t, tt.wantAllowed, got.Response.Allowed)
+			if tt.wantReason == "" {
+				return
+			}
+			require.NotNil(t, got.Response.Result)
+			require.Equal(t, tt.wantReason, string(got.Response.Result.Reason))
+		})
+	}
+}
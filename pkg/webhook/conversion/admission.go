@@ -0,0 +1,30 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package conversion
+
+import (
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+// HubVersion is the version of the hub API of the kinds converted by the webhook.
+const HubVersion = "v1beta1"
+
+// IsHubRequest returns true if the object of the admission request is in the hub version.
+func IsHubRequest(r types.Request) bool {
+	return r.AdmissionRequest.Kind.Version == HubVersion
+}
+
+// Decode decodes the object of the admission request into obj. Objects in the hub version are decoded into hub
+// first, then converted, so that admission handlers deal with a single version whatever the version of the request.
+func Decode(decoder types.Decoder, r types.Request, obj commonv1alpha1.Convertible, hub commonv1alpha1.Hub) error {
+	if !IsHubRequest(r) {
+		return decoder.Decode(r, obj)
+	}
+	if err := decoder.Decode(r, hub); err != nil {
+		return err
+	}
+	return obj.ConvertFrom(hub)
+}
@@ -0,0 +1,108 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package conversion
+
+import (
+	"encoding/json"
+	"testing"
+
+	esv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	esv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1beta1"
+	"github.com/stretchr/testify/require"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+// jsonDecoder decodes the raw object of the admission request.
+type jsonDecoder struct{}
+
+func (jsonDecoder) Decode(r types.Request, obj runtime.Object) error {
+	return json.Unmarshal(r.AdmissionRequest.Object.Raw, obj)
+}
+
+func admissionRequest(t *testing.T, version string, obj runtime.Object) types.Request {
+	return types.Request{AdmissionRequest: &admissionv1beta1.AdmissionRequest{
+		Kind:   metav1.GroupVersionKind{Group: esv1alpha1.SchemeGroupVersion.Group, Version: version, Kind: esv1alpha1.Kind},
+		Object: runtime.RawExtension{Raw: mustMarshal(t, obj)},
+	}}
+}
+
+func TestDecode(t *testing.T) {
+	alpha := esv1alpha1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "es", Namespace: "ns"},
+		Spec: esv1alpha1.ElasticsearchSpec{
+			Version: "7.2.0",
+			Nodes:   []esv1alpha1.NodeSpec{{Name: "default", NodeCount: 3}},
+		},
+	}
+	hub := esv1beta1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "es", Namespace: "ns"},
+		Spec: esv1beta1.ElasticsearchSpec{
+			Version:  "7.2.0",
+			NodeSets: []esv1beta1.NodeSet{{Name: "default", Count: 3}},
+		},
+	}
+	hubWithBudget := hub.DeepCopy()
+	maxUnavailable := intstr.FromString("50%")
+	hubWithBudget.Spec.UpdateStrategy.ChangeBudget = &esv1beta1.ChangeBudget{MaxUnavailable: &maxUnavailable}
+	alphaWithBudget := alpha.DeepCopy()
+	alphaWithBudget.Spec.UpdateStrategy.ChangeBudget = &esv1alpha1.ChangeBudget{MaxUnavailable: 1, MaxSurge: 1}
+	alphaWithBudget.Annotations = map[string]string{esv1alpha1.ChangeBudgetAnnotation: `{"maxUnavailable":"50%"}`}
+	invalidMaxUnavailable := intstr.FromString("half")
+	hubWithInvalidBudget := hub.DeepCopy()
+	hubWithInvalidBudget.Spec.UpdateStrategy.ChangeBudget = &esv1beta1.ChangeBudget{MaxUnavailable: &invalidMaxUnavailable}
+	malformed := admissionRequest(t, HubVersion, &hub)
+	malformed.AdmissionRequest.Object.Raw = []byte("{")
+
+	tests := []struct {
+		name    string
+		req     types.Request
+		want    esv1alpha1.Elasticsearch
+		wantErr bool
+	}{
+		{
+			name: "v1alpha1 request",
+			req:  admissionRequest(t, "v1alpha1", &alpha),
+			want: alpha,
+		},
+		{
+			name: "v1beta1 request is converted",
+			req:  admissionRequest(t, HubVersion, &hub),
+			want: alpha,
+		},
+		{
+			name: "v1beta1 request with a percentage change budget is converted",
+			req:  admissionRequest(t, HubVersion, hubWithBudget),
+			want: *alphaWithBudget,
+		},
+		{
+			name:    "v1beta1 request with an invalid change budget",
+			req:     admissionRequest(t, HubVersion, hubWithInvalidBudget),
+			wantErr: true,
+		},
+		{
+			name:    "v1beta1 request that cannot be decoded",
+			req:     malformed,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got esv1alpha1.Elasticsearch
+			err := Decode(jsonDecoder{}, tt.req, &got, &esv1beta1.Elasticsearch{})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want.Spec, got.Spec)
+			require.Equal(t, tt.want.Name, got.Name)
+			require.Equal(t, tt.want.Annotations, got.Annotations)
+		})
+	}
+}
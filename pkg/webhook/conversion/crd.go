@@ -0,0 +1,118 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package conversion
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// caCertKey is the key of the CA certificate in the webhook server certificate secret.
+	caCertKey = "ca-cert.pem"
+
+	installRetryPeriod = 10 * time.Second
+)
+
+// CustomResourceDefinitions are the names of the CustomResourceDefinitions served with several API versions.
+var CustomResourceDefinitions = []string{
+	"elasticsearches.elasticsearch.k8s.elastic.co",
+	"kibanas.kibana.k8s.elastic.co",
+	"apmservers.apm.k8s.elastic.co",
+}
+
+// Installer configures the CustomResourceDefinitions to call the conversion webhook through the webhook service,
+// trusting the CA that issued the webhook server certificate. The versions other than the storage version are only
+// served once the conversion webhook is configured.
+type Installer struct {
+	Client  k8s.Client
+	Service types.NamespacedName
+	// CertSecret is the secret containing the webhook server certificates.
+	CertSecret types.NamespacedName
+}
+
+// Start runs the installation until the given channel is closed. It fulfills the manager.Runnable interface.
+// The webhook server certificates are created asynchronously, the installation is retried until they exist. It is
+// then checked periodically, since applying the CustomResourceDefinitions manifests again stops serving the versions
+// that require conversion.
+func (i Installer) Start(stop <-chan struct{}) error {
+	wait.Until(func() {
+		if _, err := i.install(); err != nil {
+			log.Error(err, "Failed to configure the conversion webhook, will retry")
+		}
+	}, installRetryPeriod, stop)
+	return nil
+}
+
+// install configures the conversion webhook in the CustomResourceDefinitions. It returns false if the CA is not
+// available yet.
+func (i Installer) install() (bool, error) {
+	var secret corev1.Secret
+	if err := i.Client.Get(i.CertSecret, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	caBundle := secret.Data[caCertKey]
+	if len(caBundle) == 0 {
+		return false, nil
+	}
+	for _, name := range CustomResourceDefinitions {
+		updated, err := UpdateCustomResourceDefinition(i.Client, name, i.Service, caBundle)
+		if err != nil {
+			return false, err
+		}
+		if updated {
+			log.Info("Conversion webhook configured", "crd_name", name, "service", i.Service)
+		}
+	}
+	return true, nil
+}
+
+// UpdateCustomResourceDefinition configures the conversion webhook of the given CustomResourceDefinition to target
+// the given service, trusting the given CA certificates, and serves all its versions. A CustomResourceDefinition that
+// does not exist is ignored. It returns true if the CustomResourceDefinition was updated.
+func UpdateCustomResourceDefinition(c k8s.Client, name string, service types.NamespacedName, caBundle []byte) (bool, error) {
+	var crd apiextensionsv1beta1.CustomResourceDefinition
+	if err := c.Get(types.NamespacedName{Name: name}, &crd); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	path := Path
+	expected := &apiextensionsv1beta1.CustomResourceConversion{
+		Strategy: apiextensionsv1beta1.WebhookConverter,
+		WebhookClientConfig: &apiextensionsv1beta1.WebhookClientConfig{
+			Service: &apiextensionsv1beta1.ServiceReference{
+				Namespace: service.Namespace,
+				Name:      service.Name,
+				Path:      &path,
+			},
+			CABundle: caBundle,
+		},
+	}
+	needsUpdate := !reflect.DeepEqual(crd.Spec.Conversion, expected)
+	crd.Spec.Conversion = expected
+	// versions are served in the same update, once they can be converted
+	for i := range crd.Spec.Versions {
+		if !crd.Spec.Versions[i].Served {
+			crd.Spec.Versions[i].Served = true
+			needsUpdate = true
+		}
+	}
+	if !needsUpdate {
+		return false, nil
+	}
+	return true, c.Update(&crd)
+}
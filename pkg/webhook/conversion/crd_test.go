@@ -0,0 +1,116 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package conversion
+
+import (
+	"testing"
+
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestInstaller_install(t *testing.T) {
+	require.NoError(t, apiextensionsv1beta1.AddToScheme(scheme.Scheme))
+	service := types.NamespacedName{Namespace: "elastic-system", Name: "elastic-webhook-service"}
+	certSecret := types.NamespacedName{Namespace: "elastic-system", Name: "elastic-webhook-server-cert"}
+	crd := &apiextensionsv1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: CustomResourceDefinitions[0]},
+		Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+			Versions: []apiextensionsv1beta1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: true, Storage: true},
+				{Name: "v1beta1", Served: false},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		objects   []runtime.Object
+		installed bool
+	}{
+		{
+			name:    "certificate secret not created yet",
+			objects: []runtime.Object{crd.DeepCopy()},
+		},
+		{
+			name: "CA not generated yet",
+			objects: []runtime.Object{crd.DeepCopy(), &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: certSecret.Namespace, Name: certSecret.Name},
+			}},
+		},
+		{
+			name: "CustomResourceDefinitions updated, missing ones are ignored",
+			objects: []runtime.Object{crd.DeepCopy(), &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: certSecret.Namespace, Name: certSecret.Name},
+				Data:       map[string][]byte{caCertKey: []byte("ca")},
+			}},
+			installed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.WrapClient(fake.NewFakeClient(tt.objects...))
+			i := Installer{Client: c, Service: service, CertSecret: certSecret}
+			installed, err := i.install()
+			require.NoError(t, err)
+			require.Equal(t, tt.installed, installed)
+
+			var updated apiextensionsv1beta1.CustomResourceDefinition
+			require.NoError(t, c.Get(types.NamespacedName{Name: crd.Name}, &updated))
+			if !tt.installed {
+				// the version requiring conversion is not served
+				require.Nil(t, updated.Spec.Conversion)
+				require.False(t, updated.Spec.Versions[1].Served)
+				return
+			}
+			require.True(t, updated.Spec.Versions[0].Served)
+			require.True(t, updated.Spec.Versions[1].Served)
+			require.Equal(t, apiextensionsv1beta1.WebhookConverter, updated.Spec.Conversion.Strategy)
+			require.Equal(t, []byte("ca"), updated.Spec.Conversion.WebhookClientConfig.CABundle)
+			require.Equal(t, "elastic-webhook-service", updated.Spec.Conversion.WebhookClientConfig.Service.Name)
+			require.Equal(t, Path, *updated.Spec.Conversion.WebhookClientConfig.Service.Path)
+		})
+	}
+}
+
+func TestUpdateCustomResourceDefinition(t *testing.T) {
+	require.NoError(t, apiextensionsv1beta1.AddToScheme(scheme.Scheme))
+	service := types.NamespacedName{Namespace: "elastic-system", Name: "elastic-webhook-service"}
+	crd := &apiextensionsv1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: CustomResourceDefinitions[0]},
+		Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+			Versions: []apiextensionsv1beta1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: true, Storage: true},
+				{Name: "v1beta1", Served: false},
+			},
+		},
+	}
+	c := k8s.WrapClient(fake.NewFakeClient(crd))
+
+	updated, err := UpdateCustomResourceDefinition(c, crd.Name, service, []byte("ca"))
+	require.NoError(t, err)
+	require.True(t, updated)
+	updated, err = UpdateCustomResourceDefinition(c, crd.Name, service, []byte("ca"))
+	require.NoError(t, err)
+	require.False(t, updated)
+
+	// the manifest is applied again: the version requiring conversion is not served anymore
+	var actual apiextensionsv1beta1.CustomResourceDefinition
+	require.NoError(t, c.Get(types.NamespacedName{Name: crd.Name}, &actual))
+	actual.Spec.Versions[1].Served = false
+	require.NoError(t, c.Update(&actual))
+	updated, err = UpdateCustomResourceDefinition(c, crd.Name, service, []byte("ca"))
+	require.NoError(t, err)
+	require.True(t, updated)
+	require.NoError(t, c.Get(types.NamespacedName{Name: crd.Name}, &actual))
+	require.True(t, actual.Spec.Versions[1].Served)
+}
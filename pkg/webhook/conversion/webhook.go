@@ -0,0 +1,173 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package conversion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/types"
+)
+
+const (
+	// Path is the path the conversion webhook is served on.
+	Path = "/convert"
+
+	webhookName = "conversion.k8s.elastic.co"
+	// conversionWebhookType is not a type of admission webhook: the webhook server does not install any
+	// configuration for it, conversion is configured in the CustomResourceDefinitions instead.
+	conversionWebhookType types.WebhookType = 0
+)
+
+var log = logf.Log.WithName("conversion")
+
+// Webhook converts custom resources between the versions of their API on behalf of the API server.
+type Webhook struct {
+	scheme *runtime.Scheme
+}
+
+var _ webhook.Webhook = &Webhook{}
+
+// NewWebhook returns a conversion webhook converting the API versions registered in the given scheme.
+func NewWebhook(scheme *runtime.Scheme) *Webhook {
+	return &Webhook{scheme: scheme}
+}
+
+// GetName returns the name of the webhook.
+func (w *Webhook) GetName() string {
+	return webhookName
+}
+
+// GetPath returns the path the webhook is served on.
+func (w *Webhook) GetPath() string {
+	return Path
+}
+
+// GetType returns the type of the webhook.
+func (w *Webhook) GetType() types.WebhookType {
+	return conversionWebhookType
+}
+
+// Handler returns the http.Handler serving conversion reviews.
+func (w *Webhook) Handler() http.Handler {
+	return w
+}
+
+// Validate validates the webhook.
+func (w *Webhook) Validate() error {
+	if w.scheme == nil {
+		return errors.New("conversion webhook requires a scheme")
+	}
+	return nil
+}
+
+// ServeHTTP handles a ConversionReview sent by the API server.
+func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	var review apiextensionsv1beta1.ConversionReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		log.Error(err, "Failed to decode conversion review")
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(rw, "conversion review without request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = w.convertRequest(*review.Request)
+	review.Request = nil
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(review); err != nil {
+		log.Error(err, "Failed to encode conversion review")
+	}
+}
+
+// convertRequest converts all the objects of the given request, failing the whole request if any object
+// cannot be converted.
+func (w *Webhook) convertRequest(req apiextensionsv1beta1.ConversionRequest) *apiextensionsv1beta1.ConversionResponse {
+	response := apiextensionsv1beta1.ConversionResponse{UID: req.UID}
+	log.V(1).Info("Converting objects", "uid", req.UID, "desired_version", req.DesiredAPIVersion, "count", len(req.Objects))
+
+	desired, err := schema.ParseGroupVersion(req.DesiredAPIVersion)
+	if err != nil {
+		return failure(response, err)
+	}
+	for _, obj := range req.Objects {
+		converted, err := w.convert(obj.Raw, desired)
+		if err != nil {
+			log.Error(err, "Failed to convert object", "uid", req.UID, "desired_version", req.DesiredAPIVersion)
+			return failure(response, err)
+		}
+		response.ConvertedObjects = append(response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	response.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return &response
+}
+
+func failure(response apiextensionsv1beta1.ConversionResponse, err error) *apiextensionsv1beta1.ConversionResponse {
+	response.ConvertedObjects = nil
+	response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+	return &response
+}
+
+// convert converts the given serialized object to the desired version of its API.
+func (w *Webhook) convert(raw []byte, desired schema.GroupVersion) ([]byte, error) {
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, err
+	}
+	gvk := typeMeta.GroupVersionKind()
+	if gvk.GroupVersion() == desired {
+		return raw, nil
+	}
+	if gvk.Group != desired.Group {
+		return nil, fmt.Errorf("cannot convert %s to a different group %s", gvk, desired.Group)
+	}
+
+	src, err := w.scheme.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, src); err != nil {
+		return nil, err
+	}
+	dst, err := w.scheme.New(desired.WithKind(gvk.Kind))
+	if err != nil {
+		return nil, err
+	}
+	if err := Convert(src, dst); err != nil {
+		return nil, err
+	}
+	return json.Marshal(dst)
+}
+
+// Convert converts src into dst, one of them being the Hub version of their kind.
+func Convert(src runtime.Object, dst runtime.Object) error {
+	if hub, isHub := src.(commonv1alpha1.Hub); isHub {
+		spoke, ok := dst.(commonv1alpha1.Convertible)
+		if !ok {
+			return fmt.Errorf("%T is not convertible", dst)
+		}
+		return spoke.ConvertFrom(hub)
+	}
+	if hub, isHub := dst.(commonv1alpha1.Hub); isHub {
+		spoke, ok := src.(commonv1alpha1.Convertible)
+		if !ok {
+			return fmt.Errorf("%T is not convertible", src)
+		}
+		return spoke.ConvertTo(hub)
+	}
+	return fmt.Errorf("cannot convert %T to %T: none of them is a hub version", src, dst)
+}
@@ -0,0 +1,103 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package conversion
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	esv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	esv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1beta1"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	require.NoError(t, esv1alpha1.AddToScheme(scheme))
+	require.NoError(t, esv1beta1.AddToScheme(scheme))
+	return scheme
+}
+
+func mustMarshal(t *testing.T, obj interface{}) []byte {
+	data, err := json.Marshal(obj)
+	require.NoError(t, err)
+	return data
+}
+
+func review(t *testing.T, w *Webhook, desiredVersion string, objects ...runtime.Object) apiextensionsv1beta1.ConversionResponse {
+	req := apiextensionsv1beta1.ConversionReview{
+		Request: &apiextensionsv1beta1.ConversionRequest{UID: "uid", DesiredAPIVersion: desiredVersion},
+	}
+	for _, obj := range objects {
+		req.Request.Objects = append(req.Request.Objects, runtime.RawExtension{Raw: mustMarshal(t, obj)})
+	}
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Path, bytes.NewReader(mustMarshal(t, req))))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp apiextensionsv1beta1.ConversionReview
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Response)
+	require.Equal(t, req.Request.UID, resp.Response.UID)
+	return *resp.Response
+}
+
+func TestWebhook_ServeHTTP(t *testing.T) {
+	w := NewWebhook(newScheme(t))
+	require.NoError(t, w.Validate())
+
+	alpha := esv1alpha1.Elasticsearch{
+		TypeMeta:   metav1.TypeMeta{APIVersion: esv1alpha1.SchemeGroupVersion.String(), Kind: esv1alpha1.Kind},
+		ObjectMeta: metav1.ObjectMeta{Name: "es", Namespace: "ns"},
+		Spec: esv1alpha1.ElasticsearchSpec{
+			Version: "7.2.0",
+			Nodes:   []esv1alpha1.NodeSpec{{Name: "default", NodeCount: 3}},
+		},
+	}
+	var beta esv1beta1.Elasticsearch
+	require.NoError(t, alpha.DeepCopy().ConvertTo(&beta))
+
+	// v1alpha1 to v1beta1
+	resp := review(t, w, esv1beta1.SchemeGroupVersion.String(), &alpha)
+	require.Equal(t, metav1.StatusSuccess, resp.Result.Status)
+	require.Len(t, resp.ConvertedObjects, 1)
+	var converted esv1beta1.Elasticsearch
+	require.NoError(t, json.Unmarshal(resp.ConvertedObjects[0].Raw, &converted))
+	require.Equal(t, beta, converted)
+	require.Equal(t, int32(3), converted.Spec.NodeSets[0].Count)
+
+	// v1beta1 to v1alpha1, along with an object already in the desired version
+	resp = review(t, w, esv1alpha1.SchemeGroupVersion.String(), &beta, &alpha)
+	require.Equal(t, metav1.StatusSuccess, resp.Result.Status)
+	require.Len(t, resp.ConvertedObjects, 2)
+	for _, obj := range resp.ConvertedObjects {
+		var convertedBack esv1alpha1.Elasticsearch
+		require.NoError(t, json.Unmarshal(obj.Raw, &convertedBack))
+		require.Equal(t, alpha, convertedBack)
+	}
+
+	// unknown version
+	resp = review(t, w, "elasticsearch.k8s.elastic.co/v2", &alpha)
+	require.Equal(t, metav1.StatusFailure, resp.Result.Status)
+	require.Empty(t, resp.ConvertedObjects)
+
+	// invalid review
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Path, bytes.NewReader([]byte("{"))))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestConvert(t *testing.T) {
+	require.Error(t, Convert(&esv1alpha1.Elasticsearch{}, &esv1alpha1.Elasticsearch{}))
+	require.Error(t, Convert(&esv1beta1.Elasticsearch{}, &esv1alpha1.ElasticsearchList{}))
+	require.NoError(t, Convert(&esv1beta1.Elasticsearch{}, &esv1alpha1.Elasticsearch{}))
+	require.NoError(t, Convert(&esv1alpha1.Elasticsearch{}, &esv1beta1.Elasticsearch{}))
+}
@@ -0,0 +1,79 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+
+	estype "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	esv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1beta1"
+	esdefaults "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/defaults"
+	"github.com/elastic/cloud-on-k8s/pkg/webhook/conversion"
+	"k8s.io/api/admission/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+var defaultingLog = logf.Log.WithName("es-defaulting")
+
+// DefaultingHandler writes the Elasticsearch defaults into the resource spec, as an admission.Handler.
+type DefaultingHandler struct {
+	decoder types.Decoder
+}
+
+var _ admission.Handler = &DefaultingHandler{}
+
+// Handle processes AdmissionRequests.
+func (d *DefaultingHandler) Handle(_ context.Context, r types.Request) types.Response {
+	if r.AdmissionRequest.Operation == v1beta1.Delete {
+		return admission.ValidationResponse(true, "allowing all deletes")
+	}
+	defaultingLog.Info("DefaultingHandler handler called",
+		"operation", r.AdmissionRequest.Operation,
+		"name", r.AdmissionRequest.Name,
+		"namespace", r.AdmissionRequest.Namespace,
+	)
+	if conversion.IsHubRequest(r) {
+		return d.handleHub(r)
+	}
+	esCluster := estype.Elasticsearch{}
+	if err := d.decoder.Decode(r, &esCluster); err != nil {
+		defaultingLog.Error(err, "Failed to decode request")
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+	defaulted := esCluster.DeepCopy()
+	esdefaults.SetDefaults(defaulted)
+	return admission.PatchResponse(&esCluster, defaulted)
+}
+
+// handleHub sets the defaults of a cluster in the hub version: they are set on the v1alpha1 version, then the
+// defaulted cluster is converted back to compute the patch against the request object.
+func (d *DefaultingHandler) handleHub(r types.Request) types.Response {
+	esCluster := esv1beta1.Elasticsearch{}
+	if err := d.decoder.Decode(r, &esCluster); err != nil {
+		defaultingLog.Error(err, "Failed to decode request")
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+	var spoke estype.Elasticsearch
+	if err := spoke.ConvertFrom(&esCluster); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+	esdefaults.SetDefaults(&spoke)
+	var defaulted esv1beta1.Elasticsearch
+	if err := spoke.ConvertTo(&defaulted); err != nil {
+		return admission.ErrorResponse(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponse(&esCluster, &defaulted)
+}
+
+var _ inject.Decoder = &DefaultingHandler{}
+
+func (d *DefaultingHandler) InjectDecoder(decoder types.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	estype "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	esv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1beta1"
+	esdefaults "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/defaults"
+	"github.com/stretchr/testify/require"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+func TestDefaultingHandler_Handle(t *testing.T) {
+	es := estype.Elasticsearch{
+		ObjectMeta: v1.ObjectMeta{Name: "es", Namespace: "ns"},
+		Spec: estype.ElasticsearchSpec{
+			Version: "7.2.0",
+			Nodes:   []estype.NodeSpec{{Name: "default", NodeCount: 3}},
+		},
+	}
+	defaulted := es.DeepCopy()
+	esdefaults.SetDefaults(defaulted)
+
+	createReq := types.Request{AdmissionRequest: &admissionv1beta1.AdmissionRequest{Operation: admissionv1beta1.Create}}
+	hubCreateReq := types.Request{AdmissionRequest: &admissionv1beta1.AdmissionRequest{
+		Operation: admissionv1beta1.Create,
+		Kind:      v1.GroupVersionKind{Group: "elasticsearch.k8s.elastic.co", Version: "v1beta1", Kind: "Elasticsearch"},
+	}}
+	var hub, defaultedHub esv1beta1.Elasticsearch
+	require.NoError(t, es.ConvertTo(&hub))
+	require.NoError(t, defaulted.ConvertTo(&defaultedHub))
+	tests := []struct {
+		name        string
+		decoder     types.Decoder
+		req         types.Request
+		wantAllowed bool
+		wantPatch   bool
+	}{
+		{
+			name:        "deletes are allowed",
+			decoder:     mockDecoder{err: errors.New("should not be called")},
+			req:         types.Request{AdmissionRequest: &admissionv1beta1.AdmissionRequest{Operation: admissionv1beta1.Delete}},
+			wantAllowed: true,
+		},
+		{
+			name:        "decoding error",
+			decoder:     mockDecoder{err: errors.New("boom")},
+			req:         createReq,
+			wantAllowed: false,
+		},
+		{
+			name:        "defaults are patched into the spec",
+			decoder:     mockDecoder{obj: es.DeepCopy()},
+			req:         createReq,
+			wantAllowed: true,
+			wantPatch:   true,
+		},
+		{
+			name:        "no patch for a defaulted spec",
+			decoder:     mockDecoder{obj: defaulted.DeepCopy()},
+			req:         createReq,
+			wantAllowed: true,
+		},
+		{
+			name:        "defaults are patched into the spec of a v1beta1 cluster",
+			decoder:     mockDecoder{obj: hub.DeepCopy()},
+			req:         hubCreateReq,
+			wantAllowed: true,
+			wantPatch:   true,
+		},
+		{
+			name:        "no patch for a defaulted v1beta1 spec",
+			decoder:     mockDecoder{obj: defaultedHub.DeepCopy()},
+			req:         hubCreateReq,
+			wantAllowed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &DefaultingHandler{}
+			require.NoError(t, d.InjectDecoder(tt.decoder))
+			got := d.Handle(context.Background(), tt.req)
+			require.Equal(t, tt.wantAllowed, got.Response.Allowed)
+			require.Equal(t, tt.wantPatch, len(got.Patches) > 0)
+		})
+	}
+}
@@ -9,9 +9,11 @@ import (
 	"net/http"
 
 	estype "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	esv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1beta1"
 	commonvalidation "github.com/elastic/cloud-on-k8s/pkg/controller/common/validation"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/validation"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/webhook/conversion"
 	"k8s.io/api/admission/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -42,7 +44,7 @@ func (v *ValidationHandler) Handle(ctx context.Context, r types.Request) types.R
 		"name", r.AdmissionRequest.Name,
 		"namespace", r.AdmissionRequest.Namespace,
 	)
-	err := v.decoder.Decode(r, &esCluster)
+	err := conversion.Decode(v.decoder, r, &esCluster, &esv1beta1.Elasticsearch{})
 	if err != nil {
 		log.Error(err, "Failed to decode request")
 		return admission.ErrorResponse(http.StatusBadRequest, err)
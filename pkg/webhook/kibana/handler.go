@@ -0,0 +1,27 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibana
+
+import (
+	commonv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	kbv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1beta1"
+	commonvalidation "github.com/elastic/cloud-on-k8s/pkg/controller/common/validation"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/validation"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/webhook/common"
+)
+
+// NewValidationHandler returns a handler exposing Kibana validations as an admission.Handler.
+func NewValidationHandler() *common.ValidationHandler {
+	return common.NewValidationHandler("kb-validation", common.Validator{
+		NewObjects: func() (commonv1alpha1.Convertible, commonv1alpha1.Hub) {
+			return &v1alpha1.Kibana{}, &kbv1beta1.Kibana{}
+		},
+		Validate: func(c k8s.Client, obj commonv1alpha1.Convertible) []commonvalidation.Result {
+			return validation.Validate(c, *obj.(*v1alpha1.Kibana))
+		},
+	})
+}
@@ -5,6 +5,7 @@
 package webhook
 
 import (
+	"github.com/elastic/cloud-on-k8s/pkg/utils/stringsutil"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -19,20 +20,22 @@ type Parameters struct {
 
 // BootstrapOptionsParams are params to create webhook BootstrapOptions.
 type BootstrapOptionsParams struct {
-	Namespace        string
-	ManagedNamespace string
-	SecretName       string
-	ServiceSelector  string
+	Namespace string
+	// WatchedNamespaces are the namespaces in which the operator watches resources (all namespaces if empty).
+	WatchedNamespaces []string
+	SecretName        string
+	ServiceSelector   string
 }
 
 // NewBootstrapOptions are options for the webhook bootstrap process.
 func NewBootstrapOptions(params BootstrapOptionsParams) webhook.BootstrapOptions {
 	var secret *types.NamespacedName
 	ns := params.Namespace
-	if params.ManagedNamespace != "" {
-		// if we are restricting the operator to a single namespace we have to create the webhook resources in the
-		// managed namespace due to restrictions in the controller runtime (would not be able to list the resources)
-		ns = params.ManagedNamespace
+	if len(params.WatchedNamespaces) > 0 && !stringsutil.StringInSlice(ns, params.WatchedNamespaces) {
+		// if we are restricting the operator to a set of namespaces that does not include its own namespace, we have to
+		// create the webhook resources in a watched namespace due to restrictions in the controller runtime
+		// (would not be able to list the resources)
+		ns = params.WatchedNamespaces[0]
 	}
 	if params.SecretName != "" {
 		secret = &types.NamespacedName{
@@ -7,12 +7,24 @@ package webhook
 import (
 	"context"
 
+	apmv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1alpha1"
+	apmv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1beta1"
 	"github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1alpha1"
+	esv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1beta1"
+	kbv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1alpha1"
+	kbv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1beta1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/webhook/apmserver"
+	"github.com/elastic/cloud-on-k8s/pkg/webhook/conversion"
 	"github.com/elastic/cloud-on-k8s/pkg/webhook/elasticsearch"
+	"github.com/elastic/cloud-on-k8s/pkg/webhook/kibana"
 	"github.com/elastic/cloud-on-k8s/pkg/webhook/license"
 	admission "k8s.io/api/admissionregistration/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
@@ -27,8 +39,21 @@ const (
 	serverPort int32 = 9443
 )
 
-// RegisterValidations registers validating webhooks and a new webhook server with the given manager.
+// RegisterValidations registers validating and defaulting webhooks and a new webhook server with the given manager.
 func RegisterValidations(mgr manager.Manager, params Parameters) error {
+	esDefaultingWh, err := builder.NewWebhookBuilder().
+		Name("mutation.elasticsearch.elastic.co").
+		Mutating().
+		Operations(admission.Create, admission.Update).
+		FailurePolicy(admission.Ignore).
+		ForType(&v1alpha1.Elasticsearch{}).
+		Handlers(&elasticsearch.DefaultingHandler{}).
+		WithManager(mgr).
+		Build()
+	if err != nil {
+		return err
+	}
+
 	esWh, err := builder.NewWebhookBuilder().
 		Name("validation.elasticsearch.elastic.co").
 		Validating().
@@ -53,6 +78,80 @@ func RegisterValidations(mgr manager.Manager, params Parameters) error {
 		return err
 	}
 
+	kbWh, err := builder.NewWebhookBuilder().
+		Name("validation.kibana.elastic.co").
+		Validating().
+		FailurePolicy(admission.Ignore).
+		ForType(&kbv1alpha1.Kibana{}).
+		Handlers(kibana.NewValidationHandler()).
+		WithManager(mgr).
+		Build()
+	if err != nil {
+		return err
+	}
+
+	apmWh, err := builder.NewWebhookBuilder().
+		Name("validation.apmserver.elastic.co").
+		Validating().
+		FailurePolicy(admission.Ignore).
+		ForType(&apmv1alpha1.ApmServer{}).
+		Handlers(apmserver.NewValidationHandler()).
+		WithManager(mgr).
+		Build()
+	if err != nil {
+		return err
+	}
+
+	// v1beta1 resources are handled by the same handlers, which convert them to v1alpha1 before validation
+	esV1beta1DefaultingWh, err := builder.NewWebhookBuilder().
+		Name("mutation.v1beta1.elasticsearch.elastic.co").
+		Mutating().
+		Operations(admission.Create, admission.Update).
+		FailurePolicy(admission.Ignore).
+		ForType(&esv1beta1.Elasticsearch{}).
+		Handlers(&elasticsearch.DefaultingHandler{}).
+		WithManager(mgr).
+		Build()
+	if err != nil {
+		return err
+	}
+
+	esV1beta1Wh, err := builder.NewWebhookBuilder().
+		Name("validation.v1beta1.elasticsearch.elastic.co").
+		Validating().
+		FailurePolicy(admission.Ignore).
+		ForType(&esv1beta1.Elasticsearch{}).
+		Handlers(&elasticsearch.ValidationHandler{}).
+		WithManager(mgr).
+		Build()
+	if err != nil {
+		return err
+	}
+
+	kbV1beta1Wh, err := builder.NewWebhookBuilder().
+		Name("validation.v1beta1.kibana.elastic.co").
+		Validating().
+		FailurePolicy(admission.Ignore).
+		ForType(&kbv1beta1.Kibana{}).
+		Handlers(kibana.NewValidationHandler()).
+		WithManager(mgr).
+		Build()
+	if err != nil {
+		return err
+	}
+
+	apmV1beta1Wh, err := builder.NewWebhookBuilder().
+		Name("validation.v1beta1.apmserver.elastic.co").
+		Validating().
+		FailurePolicy(admission.Ignore).
+		ForType(&apmv1beta1.ApmServer{}).
+		Handlers(apmserver.NewValidationHandler()).
+		WithManager(mgr).
+		Build()
+	if err != nil {
+		return err
+	}
+
 	disabled := !params.AutoInstall
 	if params.AutoInstall {
 		// nasty side effect in register function
@@ -75,5 +174,37 @@ func RegisterValidations(mgr manager.Manager, params Parameters) error {
 		return err
 	}
 
-	return svr.Register(esWh, licWh)
+	if err := svr.Register(
+		esDefaultingWh, esWh, licWh, kbWh, apmWh,
+		esV1beta1DefaultingWh, esV1beta1Wh, kbV1beta1Wh, apmV1beta1Wh,
+		conversion.NewWebhook(mgr.GetScheme()),
+	); err != nil {
+		return err
+	}
+
+	if params.AutoInstall && params.Bootstrap.Secret != nil && params.Bootstrap.Service != nil {
+		// the webhook server only installs admission webhook configurations,
+		// the conversion webhook is configured in the CustomResourceDefinitions
+		return registerConversionInstaller(mgr, params)
+	}
+	return nil
+}
+
+func registerConversionInstaller(mgr manager.Manager, params Parameters) error {
+	if err := apiextensionsv1beta1.AddToScheme(mgr.GetScheme()); err != nil {
+		return err
+	}
+	// use a non-cached client to avoid watching CustomResourceDefinitions
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		return err
+	}
+	return mgr.Add(conversion.Installer{
+		Client: k8s.WrapClient(c),
+		Service: types.NamespacedName{
+			Namespace: params.Bootstrap.Service.Namespace,
+			Name:      params.Bootstrap.Service.Name,
+		},
+		CertSecret: *params.Bootstrap.Secret,
+	})
 }
@@ -92,6 +92,14 @@ func (b Builder) WithNamespace(namespace string) Builder {
 	return b
 }
 
+func (b Builder) WithAnnotation(key, value string) Builder {
+	if b.Elasticsearch.ObjectMeta.Annotations == nil {
+		b.Elasticsearch.ObjectMeta.Annotations = make(map[string]string)
+	}
+	b.Elasticsearch.ObjectMeta.Annotations[key] = value
+	return b
+}
+
 func (b Builder) WithVersion(version string) Builder {
 	b.Elasticsearch.Spec.Version = version
 	return b